@@ -0,0 +1,88 @@
+package cluster_health_checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// How often the clock skew checker compares peer clocks against the local one
+var ClockSkewCheckInterval = 5 * time.Minute
+
+// ClockSkewChecker warns when a peer's clock, as reported by the time field
+// of its galera-init /status endpoint, is further from the local clock than
+// maxSkew. The check is advisory: unreachable peers are skipped.
+type ClockSkewChecker struct {
+	peers               PeerSource
+	statusPort          string
+	clusterProbeTimeout int
+	maxSkew             time.Duration
+	logger              lager.Logger
+}
+
+func NewClockSkewChecker(peers PeerSource, statusPort string, clusterProbeTimeout int, maxSkew time.Duration, logger lager.Logger) *ClockSkewChecker {
+	return &ClockSkewChecker{
+		peers:               peers,
+		statusPort:          statusPort,
+		clusterProbeTimeout: clusterProbeTimeout,
+		maxSkew:             maxSkew,
+		logger:              logger,
+	}
+}
+
+// Run checks the peer clocks every ClockSkewCheckInterval until ctx is cancelled.
+func (c *ClockSkewChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(ClockSkewCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Check()
+		}
+	}
+}
+
+func (c *ClockSkewChecker) Check() {
+	client := http.Client{
+		Timeout: time.Duration(c.clusterProbeTimeout) * time.Second,
+	}
+
+	for _, ip := range c.peers.PeerIps() {
+		skew, err := c.peerSkew(ip, client)
+		if err != nil {
+			c.logger.Debug("clock-skew-check-skipped", lager.Data{"peer": ip, "err": err.Error()})
+			continue
+		}
+
+		if skew > c.maxSkew || skew < -c.maxSkew {
+			c.logger.Info("warning-clock-skew", lager.Data{
+				"peer":        ip,
+				"skewSeconds": skew.Seconds(),
+				"maxSkew":     c.maxSkew.String(),
+			})
+		}
+	}
+}
+
+// The peer's time is compared against the midpoint of the request so that
+// the round trip itself is not mistaken for skew.
+func (c *ClockSkewChecker) peerSkew(ip string, client http.Client) (time.Duration, error) {
+	sent := time.Now()
+	status, err := fetchPeerStatus(ip, c.statusPort, client)
+	received := time.Now()
+	if err != nil {
+		return 0, err
+	}
+	if status.Time.IsZero() {
+		return 0, fmt.Errorf("status did not report a time")
+	}
+
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	return status.Time.Sub(midpoint), nil
+}
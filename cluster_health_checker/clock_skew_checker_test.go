@@ -0,0 +1,101 @@
+package cluster_health_checker_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/cloudfoundry/galera-init/cluster_health_checker"
+	"github.com/cloudfoundry/galera-init/cluster_health_checker/cluster_health_checkerfakes"
+	"github.com/onsi/gomega/gbytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClockSkewChecker", func() {
+	var (
+		testLogger  *lagertest.TestLogger
+		checker     *ClockSkewChecker
+		requestURLs []string
+		peerOffsets map[string]time.Duration
+	)
+
+	statusResponse := func(peerTime time.Time) *http.Response {
+		body := fmt.Sprintf(`{"desynced": false, "time": %q}`, peerTime.Format(time.RFC3339Nano))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	BeforeEach(func() {
+		testLogger = lagertest.NewTestLogger("clock_skew_checker")
+		requestURLs = []string{}
+		peerOffsets = map[string]time.Duration{}
+
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			requestURLs = append(requestURLs, url)
+			for ip, offset := range peerOffsets {
+				if strings.Contains(url, ip) {
+					return statusResponse(time.Now().Add(offset)), nil
+				}
+			}
+			return nil, errors.New("connection refused")
+		}
+
+		checker = NewClockSkewChecker(StaticPeers{"1.2.3.4", "5.6.7.8"}, "8999", 10, 5*time.Second, testLogger)
+	})
+
+	It("queries the status endpoint of every peer", func() {
+		checker.Check()
+
+		Expect(requestURLs).To(Equal([]string{
+			"http://1.2.3.4:8999/status",
+			"http://5.6.7.8:8999/status",
+		}))
+	})
+
+	It("queries whichever peers its source lists at the time of the check", func() {
+		peers := new(cluster_health_checkerfakes.FakePeerSource)
+		peers.PeerIpsReturns([]string{"1.2.3.4"})
+		checker = NewClockSkewChecker(peers, "8999", 10, 5*time.Second, testLogger)
+		peers.PeerIpsReturns([]string{"9.9.9.9"})
+
+		checker.Check()
+
+		Expect(requestURLs).To(Equal([]string{"http://9.9.9.9:8999/status"}))
+	})
+
+	It("warns about peers whose clock is too far ahead or behind", func() {
+		peerOffsets["1.2.3.4"] = time.Minute
+		peerOffsets["5.6.7.8"] = -time.Minute
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`warning-clock-skew.*"peer":"1.2.3.4"`))
+		Expect(testLogger.Buffer()).To(gbytes.Say(`warning-clock-skew.*"peer":"5.6.7.8"`))
+	})
+
+	It("does not warn about peers within the threshold", func() {
+		peerOffsets["1.2.3.4"] = time.Second
+		peerOffsets["5.6.7.8"] = 0
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).NotTo(gbytes.Say("warning-clock-skew"))
+	})
+
+	It("skips peers that cannot be reached", func() {
+		peerOffsets["5.6.7.8"] = time.Minute
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`clock-skew-check-skipped.*"peer":"1.2.3.4"`))
+		Expect(testLogger.Buffer()).To(gbytes.Say(`warning-clock-skew.*"peer":"5.6.7.8"`))
+	})
+})
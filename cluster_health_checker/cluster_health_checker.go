@@ -1,6 +1,8 @@
 package cluster_health_checker
 
 import (
+	"errors"
+	"net"
 	"net/http"
 
 	"time"
@@ -12,39 +14,60 @@ var MakeRequest = func(url string, client http.Client) (*http.Response, error) {
 	return client.Get(url)
 }
 
+// Overridable so tests can control which addresses count as this node's own
+var LocalAddresses = func() ([]net.Addr, error) {
+	return net.InterfaceAddrs()
+}
+var LookupHost = net.LookupHost
+
+// Returned by IsClusterPrimary when no peer's status server answered, so the
+// primary component could not be determined at all.
+var ErrNoPeerStatusReachable = errors.New("no peer's galera-init status server was reachable")
+
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . ClusterHealthChecker
 type ClusterHealthChecker interface {
 	HealthyCluster() bool
+	HealthyPeerCount() int
+	IsClusterPrimary() (bool, error)
 }
 
 type httpClusterHealthChecker struct {
-	clusterIps          []string
+	peers               PeerSource
+	statusPort          string
 	clusterProbeTimeout int
 	logger              lager.Logger
 }
 
-func NewClusterHealthChecker(ips []string, clusterProbeTimeout int, logger lager.Logger) ClusterHealthChecker {
+func NewClusterHealthChecker(ips []string, statusPort string, clusterProbeTimeout int, logger lager.Logger) ClusterHealthChecker {
+	return NewClusterHealthCheckerFromSource(StaticPeers(ips), statusPort, clusterProbeTimeout, logger)
+}
+
+// NewClusterHealthCheckerFromSource probes whichever peers the source lists
+// at the time of each check.
+func NewClusterHealthCheckerFromSource(peers PeerSource, statusPort string, clusterProbeTimeout int, logger lager.Logger) ClusterHealthChecker {
 	return httpClusterHealthChecker{
-		clusterIps:          ips,
+		peers:               peers,
+		statusPort:          statusPort,
 		clusterProbeTimeout: clusterProbeTimeout,
 		logger:              logger,
 	}
 }
 
 func (h httpClusterHealthChecker) HealthyCluster() bool {
+	clusterIps := h.peers.PeerIps()
 	h.logger.Info("Checking for healthy cluster", lager.Data{
-		"ClusterIps": h.clusterIps,
+		"ClusterIps": clusterIps,
 	})
-	for _, ip := range h.clusterIps {
-		h.logger.Info("Checking if node is healthy: " + ip)
-
-		timeout := time.Duration(h.clusterProbeTimeout) * time.Second
-		client := http.Client{
-			Timeout: timeout,
+	localIPs := h.localIPs()
+	for _, ip := range clusterIps {
+		if h.resolvesToLocalNode(ip, localIPs) {
+			// After a disk recreate this node's own healthcheck may answer for
+			// it; joining it would be a self-join, so it does not count as a peer
+			h.logger.Info("skipping-peer-resolving-to-local-node", lager.Data{"peer": ip})
+			continue
 		}
 
-		resp, _ := MakeRequest("http://"+ip+":9200/", client)
-		if resp != nil && resp.StatusCode == 200 {
+		if h.isHealthy(ip) {
 			h.logger.Info("node " + ip + " is healthy - cluster is healthy.")
 			return true
 		}
@@ -53,3 +76,103 @@ func (h httpClusterHealthChecker) HealthyCluster() bool {
 	h.logger.Info("No nodes in cluster are healthy.")
 	return false
 }
+
+// HealthyPeerCount probes every peer other than this node and counts those
+// that report healthy.
+func (h httpClusterHealthChecker) HealthyPeerCount() int {
+	localIPs := h.localIPs()
+	count := 0
+	for _, ip := range h.peers.PeerIps() {
+		if h.resolvesToLocalNode(ip, localIPs) {
+			continue
+		}
+
+		if h.isHealthy(ip) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (h httpClusterHealthChecker) isHealthy(ip string) bool {
+	h.logger.Debug("Checking if node is healthy: " + ip)
+
+	timeout := time.Duration(h.clusterProbeTimeout) * time.Second
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	resp, _ := MakeRequest("http://"+ip+":9200/", client)
+	return resp != nil && resp.StatusCode == 200
+}
+
+// IsClusterPrimary reports whether any peer's galera-init /status endpoint
+// reports its wsrep_cluster_status as Primary, i.e. whether the cluster has
+// quorum for this node to join. Unreachable peers, and peers whose galera-init
+// predates the field, do not count; when no peer answers at all it returns
+// ErrNoPeerStatusReachable.
+func (h httpClusterHealthChecker) IsClusterPrimary() (bool, error) {
+	client := http.Client{
+		Timeout: time.Duration(h.clusterProbeTimeout) * time.Second,
+	}
+
+	reachable := 0
+	localIPs := h.localIPs()
+	for _, ip := range h.peers.PeerIps() {
+		if h.resolvesToLocalNode(ip, localIPs) {
+			continue
+		}
+
+		status, err := fetchPeerStatus(ip, h.statusPort, client)
+		if err != nil {
+			h.logger.Info("peer-status-unreachable", lager.Data{"peer": ip, "err": err.Error()})
+			continue
+		}
+		reachable++
+
+		if status.ClusterStatus == "Primary" {
+			h.logger.Info("peer-in-primary-component", lager.Data{"peer": ip})
+			return true, nil
+		}
+	}
+
+	if reachable == 0 {
+		return false, ErrNoPeerStatusReachable
+	}
+	return false, nil
+}
+
+func (h httpClusterHealthChecker) localIPs() map[string]bool {
+	addrs, err := LocalAddresses()
+	if err != nil {
+		h.logger.Debug("local-addresses-unavailable", lager.Data{"err": err.Error()})
+		return nil
+	}
+
+	localIPs := map[string]bool{}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			localIPs[ipNet.IP.String()] = true
+		}
+	}
+	return localIPs
+}
+
+func (h httpClusterHealthChecker) resolvesToLocalNode(peer string, localIPs map[string]bool) bool {
+	addresses := []string{peer}
+	if net.ParseIP(peer) == nil {
+		resolved, err := LookupHost(peer)
+		if err != nil {
+			return false
+		}
+		addresses = resolved
+	}
+
+	for _, address := range addresses {
+		if ip := net.ParseIP(address); ip != nil && (ip.IsLoopback() || localIPs[ip.String()]) {
+			return true
+		}
+	}
+	return false
+}
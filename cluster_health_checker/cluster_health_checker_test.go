@@ -2,13 +2,17 @@ package cluster_health_checker_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"strings"
 
 	"code.cloudfoundry.org/lager/lagertest"
 	. "github.com/cloudfoundry/galera-init/cluster_health_checker"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("ClusterHealthChecker.HealthyCluster()", func() {
@@ -26,7 +30,7 @@ var _ = Describe("ClusterHealthChecker.HealthyCluster()", func() {
 			return &http.Response{StatusCode: 200}, nil
 		}
 
-		checker := NewClusterHealthChecker([]string{"1.2.3.4"}, clusterProbeTimeout, testLogger)
+		checker := NewClusterHealthChecker([]string{"1.2.3.4"}, "8999", clusterProbeTimeout, testLogger)
 		checker.HealthyCluster()
 
 		Expect(requestURLs).To(Equal([]string{"http://1.2.3.4:9200/"}))
@@ -40,7 +44,7 @@ var _ = Describe("ClusterHealthChecker.HealthyCluster()", func() {
 			return &http.Response{StatusCode: 200}, nil
 		}
 
-		checker := NewClusterHealthChecker([]string{"1.2.3.4"}, clusterProbeTimeout, testLogger)
+		checker := NewClusterHealthChecker([]string{"1.2.3.4"}, "8999", clusterProbeTimeout, testLogger)
 		checker.HealthyCluster()
 
 		Expect(timeout).To(Equal(clusterProbeTimeout))
@@ -53,7 +57,7 @@ var _ = Describe("ClusterHealthChecker.HealthyCluster()", func() {
 			return &http.Response{StatusCode: 200}, nil
 		}
 
-		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, clusterProbeTimeout, testLogger)
+		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, "8999", clusterProbeTimeout, testLogger)
 		healthy := checker.HealthyCluster()
 
 		Expect(healthy).To(BeTrue())
@@ -69,7 +73,7 @@ var _ = Describe("ClusterHealthChecker.HealthyCluster()", func() {
 			return &http.Response{StatusCode: 503}, nil
 		}
 
-		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, clusterProbeTimeout, testLogger)
+		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, "8999", clusterProbeTimeout, testLogger)
 		healthy := checker.HealthyCluster()
 
 		Expect(healthy).To(BeFalse())
@@ -83,10 +87,156 @@ var _ = Describe("ClusterHealthChecker.HealthyCluster()", func() {
 			return nil, errors.New("Timed out")
 		}
 
-		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, clusterProbeTimeout, testLogger)
+		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, "8999", clusterProbeTimeout, testLogger)
 		healthy := checker.HealthyCluster()
 
 		Expect(healthy).To(BeFalse())
 		Expect(len(requestURLs)).To(Equal(2))
 	})
+
+	Context("when a peer resolves to this node", func() {
+		var requestURLs []string
+
+		BeforeEach(func() {
+			requestURLs = []string{}
+			MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+				requestURLs = append(requestURLs, url)
+				return &http.Response{StatusCode: 200}, nil
+			}
+			LocalAddresses = func() ([]net.Addr, error) {
+				return []net.Addr{&net.IPNet{IP: net.ParseIP("10.0.0.5"), Mask: net.CIDRMask(24, 32)}}, nil
+			}
+			LookupHost = func(host string) ([]string, error) {
+				if host == "mysql-0.internal" {
+					return []string{"10.0.0.5"}, nil
+				}
+				return nil, errors.New("no such host")
+			}
+		})
+
+		AfterEach(func() {
+			LocalAddresses = func() ([]net.Addr, error) { return net.InterfaceAddrs() }
+			LookupHost = net.LookupHost
+		})
+
+		It("does not probe its own address", func() {
+			checker := NewClusterHealthChecker([]string{"10.0.0.5", "10.0.0.6"}, "8999", clusterProbeTimeout, testLogger)
+			Expect(checker.HealthyCluster()).To(BeTrue())
+
+			Expect(requestURLs).To(Equal([]string{"http://10.0.0.6:9200/"}))
+			Expect(testLogger.Buffer()).To(gbytes.Say("skipping-peer-resolving-to-local-node"))
+		})
+
+		It("does not probe a hostname that resolves to its own address", func() {
+			checker := NewClusterHealthChecker([]string{"mysql-0.internal"}, "8999", clusterProbeTimeout, testLogger)
+			Expect(checker.HealthyCluster()).To(BeFalse())
+
+			Expect(requestURLs).To(BeEmpty())
+		})
+
+		It("treats the only peer being itself as having no healthy peers", func() {
+			checker := NewClusterHealthChecker([]string{"127.0.0.1"}, "8999", clusterProbeTimeout, testLogger)
+			Expect(checker.HealthyCluster()).To(BeFalse())
+
+			Expect(requestURLs).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("ClusterHealthChecker.HealthyPeerCount()", func() {
+	var testLogger *lagertest.TestLogger
+
+	BeforeEach(func() {
+		testLogger = lagertest.NewTestLogger("cluster_health_checker")
+	})
+
+	It("counts every healthy peer", func() {
+		requestURLs := []string{}
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			requestURLs = append(requestURLs, url)
+			if strings.Contains(url, "5.6.7.8") {
+				return &http.Response{StatusCode: 503}, nil
+			}
+			return &http.Response{StatusCode: 200}, nil
+		}
+
+		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8", "9.10.11.12"}, "8999", 10, testLogger)
+		Expect(checker.HealthyPeerCount()).To(Equal(2))
+		Expect(requestURLs).To(HaveLen(3))
+	})
+
+	It("does not count this node", func() {
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			return &http.Response{StatusCode: 200}, nil
+		}
+
+		checker := NewClusterHealthChecker([]string{"127.0.0.1", "1.2.3.4"}, "8999", 10, testLogger)
+		Expect(checker.HealthyPeerCount()).To(Equal(1))
+	})
+})
+
+var _ = Describe("ClusterHealthChecker.IsClusterPrimary()", func() {
+	var testLogger *lagertest.TestLogger
+
+	BeforeEach(func() {
+		testLogger = lagertest.NewTestLogger("cluster_health_checker")
+	})
+
+	statusResponse := func(body string) *http.Response {
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+	}
+
+	It("queries the peers' galera-init status endpoint", func() {
+		requestURLs := []string{}
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			requestURLs = append(requestURLs, url)
+			return statusResponse(`{"wsrep_cluster_status":"Primary"}`), nil
+		}
+
+		checker := NewClusterHealthChecker([]string{"1.2.3.4"}, "8999", 10, testLogger)
+		primary, err := checker.IsClusterPrimary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(primary).To(BeTrue())
+		Expect(requestURLs).To(Equal([]string{"http://1.2.3.4:8999/status"}))
+	})
+
+	It("is primary when any peer reports a primary component", func() {
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			if strings.Contains(url, "1.2.3.4") {
+				return nil, errors.New("connection refused")
+			}
+			return statusResponse(`{"wsrep_cluster_status":"Primary"}`), nil
+		}
+
+		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, "8999", 10, testLogger)
+		primary, err := checker.IsClusterPrimary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(primary).To(BeTrue())
+		Expect(testLogger.Buffer()).To(gbytes.Say("peer-status-unreachable"))
+	})
+
+	It("is not primary when the reachable peers are non-primary or do not report a status", func() {
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			if strings.Contains(url, "1.2.3.4") {
+				return statusResponse(`{"wsrep_cluster_status":"non-Primary"}`), nil
+			}
+			return statusResponse(`{}`), nil
+		}
+
+		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, "8999", 10, testLogger)
+		primary, err := checker.IsClusterPrimary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(primary).To(BeFalse())
+	})
+
+	It("reports that no peer was reachable, rather than not primary, when none answers", func() {
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}
+
+		checker := NewClusterHealthChecker([]string{"1.2.3.4", "5.6.7.8"}, "8999", 10, testLogger)
+		primary, err := checker.IsClusterPrimary()
+		Expect(err).To(Equal(ErrNoPeerStatusReachable))
+		Expect(primary).To(BeFalse())
+	})
 })
@@ -18,6 +18,28 @@ type FakeClusterHealthChecker struct {
 	healthyClusterReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	HealthyPeerCountStub        func() int
+	healthyPeerCountMutex       sync.RWMutex
+	healthyPeerCountArgsForCall []struct {
+	}
+	healthyPeerCountReturns struct {
+		result1 int
+	}
+	healthyPeerCountReturnsOnCall map[int]struct {
+		result1 int
+	}
+	IsClusterPrimaryStub        func() (bool, error)
+	isClusterPrimaryMutex       sync.RWMutex
+	isClusterPrimaryArgsForCall []struct {
+	}
+	isClusterPrimaryReturns struct {
+		result1 bool
+		result2 error
+	}
+	isClusterPrimaryReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -74,11 +96,122 @@ func (fake *FakeClusterHealthChecker) HealthyClusterReturnsOnCall(i int, result1
 	}{result1}
 }
 
+func (fake *FakeClusterHealthChecker) HealthyPeerCount() int {
+	fake.healthyPeerCountMutex.Lock()
+	ret, specificReturn := fake.healthyPeerCountReturnsOnCall[len(fake.healthyPeerCountArgsForCall)]
+	fake.healthyPeerCountArgsForCall = append(fake.healthyPeerCountArgsForCall, struct {
+	}{})
+	fake.recordInvocation("HealthyPeerCount", []interface{}{})
+	fake.healthyPeerCountMutex.Unlock()
+	if fake.HealthyPeerCountStub != nil {
+		return fake.HealthyPeerCountStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.healthyPeerCountReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeClusterHealthChecker) HealthyPeerCountCallCount() int {
+	fake.healthyPeerCountMutex.RLock()
+	defer fake.healthyPeerCountMutex.RUnlock()
+	return len(fake.healthyPeerCountArgsForCall)
+}
+
+func (fake *FakeClusterHealthChecker) HealthyPeerCountCalls(stub func() int) {
+	fake.healthyPeerCountMutex.Lock()
+	defer fake.healthyPeerCountMutex.Unlock()
+	fake.HealthyPeerCountStub = stub
+}
+
+func (fake *FakeClusterHealthChecker) HealthyPeerCountReturns(result1 int) {
+	fake.healthyPeerCountMutex.Lock()
+	defer fake.healthyPeerCountMutex.Unlock()
+	fake.HealthyPeerCountStub = nil
+	fake.healthyPeerCountReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeClusterHealthChecker) HealthyPeerCountReturnsOnCall(i int, result1 int) {
+	fake.healthyPeerCountMutex.Lock()
+	defer fake.healthyPeerCountMutex.Unlock()
+	fake.HealthyPeerCountStub = nil
+	if fake.healthyPeerCountReturnsOnCall == nil {
+		fake.healthyPeerCountReturnsOnCall = make(map[int]struct {
+			result1 int
+		})
+	}
+	fake.healthyPeerCountReturnsOnCall[i] = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeClusterHealthChecker) IsClusterPrimary() (bool, error) {
+	fake.isClusterPrimaryMutex.Lock()
+	ret, specificReturn := fake.isClusterPrimaryReturnsOnCall[len(fake.isClusterPrimaryArgsForCall)]
+	fake.isClusterPrimaryArgsForCall = append(fake.isClusterPrimaryArgsForCall, struct {
+	}{})
+	fake.recordInvocation("IsClusterPrimary", []interface{}{})
+	fake.isClusterPrimaryMutex.Unlock()
+	if fake.IsClusterPrimaryStub != nil {
+		return fake.IsClusterPrimaryStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.isClusterPrimaryReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClusterHealthChecker) IsClusterPrimaryCallCount() int {
+	fake.isClusterPrimaryMutex.RLock()
+	defer fake.isClusterPrimaryMutex.RUnlock()
+	return len(fake.isClusterPrimaryArgsForCall)
+}
+
+func (fake *FakeClusterHealthChecker) IsClusterPrimaryCalls(stub func() (bool, error)) {
+	fake.isClusterPrimaryMutex.Lock()
+	defer fake.isClusterPrimaryMutex.Unlock()
+	fake.IsClusterPrimaryStub = stub
+}
+
+func (fake *FakeClusterHealthChecker) IsClusterPrimaryReturns(result1 bool, result2 error) {
+	fake.isClusterPrimaryMutex.Lock()
+	defer fake.isClusterPrimaryMutex.Unlock()
+	fake.IsClusterPrimaryStub = nil
+	fake.isClusterPrimaryReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClusterHealthChecker) IsClusterPrimaryReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isClusterPrimaryMutex.Lock()
+	defer fake.isClusterPrimaryMutex.Unlock()
+	fake.IsClusterPrimaryStub = nil
+	if fake.isClusterPrimaryReturnsOnCall == nil {
+		fake.isClusterPrimaryReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isClusterPrimaryReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClusterHealthChecker) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.healthyClusterMutex.RLock()
 	defer fake.healthyClusterMutex.RUnlock()
+	fake.healthyPeerCountMutex.RLock()
+	defer fake.healthyPeerCountMutex.RUnlock()
+	fake.isClusterPrimaryMutex.RLock()
+	defer fake.isClusterPrimaryMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,83 @@
+package cluster_health_checker
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// How often the max_allowed_packet checker compares the values peers report
+var MaxAllowedPacketCheckInterval = 5 * time.Minute
+
+// MaxAllowedPacketChecker warns when peers report different
+// max_allowed_packet values on their galera-init /status endpoint. A write set
+// larger than a peer's limit aborts replication on that peer, so the mismatch
+// only shows up once such a write happens. Unreachable peers are skipped.
+type MaxAllowedPacketChecker struct {
+	peers               PeerSource
+	statusPort          string
+	clusterProbeTimeout int
+	logger              lager.Logger
+}
+
+func NewMaxAllowedPacketChecker(peers PeerSource, statusPort string, clusterProbeTimeout int, logger lager.Logger) *MaxAllowedPacketChecker {
+	return &MaxAllowedPacketChecker{
+		peers:               peers,
+		statusPort:          statusPort,
+		clusterProbeTimeout: clusterProbeTimeout,
+		logger:              logger,
+	}
+}
+
+// Run compares peer values every MaxAllowedPacketCheckInterval until ctx is
+// cancelled.
+func (c *MaxAllowedPacketChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(MaxAllowedPacketCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Check()
+		}
+	}
+}
+
+func (c *MaxAllowedPacketChecker) Check() {
+	client := http.Client{
+		Timeout: time.Duration(c.clusterProbeTimeout) * time.Second,
+	}
+
+	peersByValue := map[string][]string{}
+	for _, ip := range c.peers.PeerIps() {
+		status, err := fetchPeerStatus(ip, c.statusPort, client)
+		if err != nil {
+			c.logger.Debug("max-allowed-packet-check-skipped", lager.Data{"peer": ip, "err": err.Error()})
+			continue
+		}
+		if status.MaxAllowedPacket == 0 {
+			continue
+		}
+
+		value := strconv.FormatUint(status.MaxAllowedPacket, 10)
+		peersByValue[value] = append(peersByValue[value], ip)
+	}
+
+	if len(peersByValue) < 2 {
+		return
+	}
+
+	for _, peers := range peersByValue {
+		sort.Strings(peers)
+	}
+
+	c.logger.Info("warning-max-allowed-packet-mismatch", lager.Data{
+		"peersByMaxAllowedPacket": peersByValue,
+	})
+}
@@ -0,0 +1,71 @@
+package cluster_health_checker_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/cloudfoundry/galera-init/cluster_health_checker"
+	"github.com/onsi/gomega/gbytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MaxAllowedPacketChecker", func() {
+	var (
+		testLogger  *lagertest.TestLogger
+		checker     *MaxAllowedPacketChecker
+		peerPackets map[string]uint64
+	)
+
+	BeforeEach(func() {
+		testLogger = lagertest.NewTestLogger("max_allowed_packet_checker")
+		peerPackets = map[string]uint64{
+			"1.1.1.1": 67108864,
+			"2.2.2.2": 67108864,
+			"3.3.3.3": 67108864,
+		}
+
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			for ip, maxAllowedPacket := range peerPackets {
+				if strings.Contains(url, ip) {
+					body := fmt.Sprintf(`{"max_allowed_packet": %d}`, maxAllowedPacket)
+					return &http.Response{
+						StatusCode: 200,
+						Body:       ioutil.NopCloser(strings.NewReader(body)),
+					}, nil
+				}
+			}
+			return nil, errors.New("connection refused")
+		}
+
+		checker = NewMaxAllowedPacketChecker(StaticPeers{"1.1.1.1", "2.2.2.2", "3.3.3.3"}, "8999", 10, testLogger)
+	})
+
+	It("does not warn when every peer agrees", func() {
+		checker.Check()
+
+		Expect(testLogger.Buffer()).NotTo(gbytes.Say("mismatch"))
+	})
+
+	It("warns naming the peers on each value", func() {
+		peerPackets["2.2.2.2"] = 16777216
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`warning-max-allowed-packet-mismatch.*"16777216":\["2.2.2.2"\],"67108864":\["1.1.1.1","3.3.3.3"\]`))
+	})
+
+	It("skips peers that cannot be reached or do not report a value", func() {
+		delete(peerPackets, "1.1.1.1")
+		peerPackets["2.2.2.2"] = 0
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).NotTo(gbytes.Say("mismatch"))
+	})
+})
@@ -0,0 +1,123 @@
+package cluster_health_checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// How often a FilePeerList re-reads its file
+var PeerListRefreshInterval = 30 * time.Second
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . PeerSource
+type PeerSource interface {
+	PeerIps() []string
+}
+
+// StaticPeers is a fixed peer list, such as the configured ClusterIps
+type StaticPeers []string
+
+func (p StaticPeers) PeerIps() []string {
+	return p
+}
+
+// FilePeerList serves the cluster peers listed in a file, as a JSON array of
+// addresses, so that the peer list can change without a restart. If the file
+// is missing or malformed the last good list is kept, starting with initial.
+type FilePeerList struct {
+	path   string
+	logger lager.Logger
+
+	mu  sync.RWMutex
+	ips []string
+}
+
+func NewFilePeerList(path string, initial []string, logger lager.Logger) *FilePeerList {
+	return &FilePeerList{
+		path:   path,
+		logger: logger,
+		ips:    initial,
+	}
+}
+
+func (p *FilePeerList) PeerIps() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.ips
+}
+
+// Run reloads the file every PeerListRefreshInterval until ctx is cancelled.
+func (p *FilePeerList) Run(ctx context.Context) {
+	ticker := time.NewTicker(PeerListRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Reload()
+		}
+	}
+}
+
+func (p *FilePeerList) Reload() {
+	ips, err := readPeerFile(p.path)
+	if err != nil {
+		p.logger.Error("peer-list-reload-failed", err, lager.Data{
+			"path":    p.path,
+			"keeping": p.PeerIps(),
+		})
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !equalPeers(p.ips, ips) {
+		p.logger.Info("peer-list-changed", lager.Data{
+			"from": p.ips,
+			"to":   ips,
+		})
+	}
+	p.ips = ips
+}
+
+func readPeerFile(path string) ([]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []string
+	if err := json.Unmarshal(contents, &ips); err != nil {
+		return nil, err
+	}
+
+	if len(ips) == 0 {
+		return nil, errors.New("peer list is empty")
+	}
+	for _, ip := range ips {
+		if ip == "" {
+			return nil, errors.New("peer list contains an empty address")
+		}
+	}
+
+	return ips, nil
+}
+
+func equalPeers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package cluster_health_checker_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/cloudfoundry/galera-init/cluster_health_checker"
+	"github.com/onsi/gomega/gbytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FilePeerList", func() {
+	var (
+		testLogger *lagertest.TestLogger
+		tempDir    string
+		peerFile   string
+		peerList   *FilePeerList
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "peer_list")
+		Expect(err).NotTo(HaveOccurred())
+		peerFile = filepath.Join(tempDir, "cluster_ips.json")
+
+		testLogger = lagertest.NewTestLogger("peer_list")
+		peerList = NewFilePeerList(peerFile, []string{"1.1.1.1"}, testLogger)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("starts with the initial list", func() {
+		Expect(peerList.PeerIps()).To(Equal([]string{"1.1.1.1"}))
+	})
+
+	It("picks up the peers listed in the file", func() {
+		Expect(ioutil.WriteFile(peerFile, []byte(`["1.1.1.1", "2.2.2.2"]`), 0644)).To(Succeed())
+
+		peerList.Reload()
+
+		Expect(peerList.PeerIps()).To(Equal([]string{"1.1.1.1", "2.2.2.2"}))
+		Expect(testLogger).To(gbytes.Say("peer-list-changed"))
+	})
+
+	It("keeps the last good list when the file is missing", func() {
+		Expect(ioutil.WriteFile(peerFile, []byte(`["2.2.2.2"]`), 0644)).To(Succeed())
+		peerList.Reload()
+		Expect(os.Remove(peerFile)).To(Succeed())
+
+		peerList.Reload()
+
+		Expect(peerList.PeerIps()).To(Equal([]string{"2.2.2.2"}))
+		Expect(testLogger).To(gbytes.Say("peer-list-reload-failed"))
+	})
+
+	It("keeps the last good list when the file is malformed", func() {
+		Expect(ioutil.WriteFile(peerFile, []byte(`1.1.1.1,2.2.2.2`), 0644)).To(Succeed())
+
+		peerList.Reload()
+
+		Expect(peerList.PeerIps()).To(Equal([]string{"1.1.1.1"}))
+		Expect(testLogger).To(gbytes.Say("peer-list-reload-failed"))
+	})
+
+	It("keeps the last good list when the file lists no peers", func() {
+		Expect(ioutil.WriteFile(peerFile, []byte(`[]`), 0644)).To(Succeed())
+
+		peerList.Reload()
+
+		Expect(peerList.PeerIps()).To(Equal([]string{"1.1.1.1"}))
+		Expect(testLogger).To(gbytes.Say("peer list is empty"))
+	})
+
+	Describe("Run", func() {
+		var originalInterval time.Duration
+
+		BeforeEach(func() {
+			originalInterval = PeerListRefreshInterval
+			PeerListRefreshInterval = 10 * time.Millisecond
+		})
+
+		AfterEach(func() {
+			PeerListRefreshInterval = originalInterval
+		})
+
+		It("re-reads the file until cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go peerList.Run(ctx)
+
+			Expect(ioutil.WriteFile(peerFile, []byte(`["3.3.3.3"]`), 0644)).To(Succeed())
+
+			Eventually(peerList.PeerIps).Should(Equal([]string{"3.3.3.3"}))
+		})
+	})
+
+	It("is used by the health checker for each check", func() {
+		requestURLs := []string{}
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			requestURLs = append(requestURLs, url)
+			return &http.Response{StatusCode: 500}, nil
+		}
+		checker := NewClusterHealthCheckerFromSource(peerList, "8999", 10, testLogger)
+
+		checker.HealthyCluster()
+		Expect(ioutil.WriteFile(peerFile, []byte(`["2.2.2.2"]`), 0644)).To(Succeed())
+		peerList.Reload()
+		checker.HealthyCluster()
+
+		Expect(requestURLs).To(Equal([]string{"http://1.1.1.1:9200/", "http://2.2.2.2:9200/"}))
+	})
+})
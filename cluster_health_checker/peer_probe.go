@@ -0,0 +1,57 @@
+package cluster_health_checker
+
+import (
+	"net/http"
+	"time"
+)
+
+type PeerProbe struct {
+	Peer       string
+	Reachable  bool
+	StatusCode int
+	Latency    time.Duration
+	Err        error
+}
+
+// ProbePeers sends one healthcheck request to every peer, including this
+// node, and reports which answered and how long each took. A peer that
+// answers with any status is reachable; only the network path is checked.
+func ProbePeers(ips []string, clusterProbeTimeout int) []PeerProbe {
+	client := http.Client{
+		Timeout: time.Duration(clusterProbeTimeout) * time.Second,
+	}
+
+	probes := make([]PeerProbe, 0, len(ips))
+	for _, ip := range ips {
+		start := time.Now()
+		resp, err := MakeRequest("http://"+ip+":9200/", client)
+		probe := PeerProbe{
+			Peer:    ip,
+			Latency: time.Since(start),
+			Err:     err,
+		}
+		if resp != nil {
+			probe.Reachable = true
+			probe.StatusCode = resp.StatusCode
+			if resp.Body != nil {
+				resp.Body.Close()
+			}
+		}
+
+		probes = append(probes, probe)
+	}
+
+	return probes
+}
+
+// ReachablePeerCount reports how many of the peers, including this node,
+// answer a healthcheck request.
+func ReachablePeerCount(ips []string, clusterProbeTimeout int) int {
+	reachable := 0
+	for _, probe := range ProbePeers(ips, clusterProbeTimeout) {
+		if probe.Reachable {
+			reachable++
+		}
+	}
+	return reachable
+}
@@ -0,0 +1,56 @@
+package cluster_health_checker_test
+
+import (
+	"errors"
+	"net/http"
+
+	. "github.com/cloudfoundry/galera-init/cluster_health_checker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProbePeers", func() {
+	It("reports every peer as reachable or unreachable", func() {
+		var timeout int
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			timeout = int(client.Timeout.Seconds())
+			switch url {
+			case "http://1.2.3.4:9200/":
+				return &http.Response{StatusCode: 200}, nil
+			case "http://5.6.7.8:9200/":
+				return &http.Response{StatusCode: 503}, nil
+			default:
+				return nil, errors.New("connection refused")
+			}
+		}
+
+		probes := ProbePeers([]string{"1.2.3.4", "5.6.7.8", "9.10.11.12"}, 7)
+
+		Expect(timeout).To(Equal(7))
+		Expect(probes).To(HaveLen(3))
+
+		Expect(probes[0].Peer).To(Equal("1.2.3.4"))
+		Expect(probes[0].Reachable).To(BeTrue())
+		Expect(probes[0].StatusCode).To(Equal(200))
+
+		Expect(probes[1].Peer).To(Equal("5.6.7.8"))
+		Expect(probes[1].Reachable).To(BeTrue())
+		Expect(probes[1].StatusCode).To(Equal(503))
+
+		Expect(probes[2].Peer).To(Equal("9.10.11.12"))
+		Expect(probes[2].Reachable).To(BeFalse())
+		Expect(probes[2].Err).To(MatchError("connection refused"))
+	})
+
+	It("counts the reachable peers", func() {
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			if url == "http://9.10.11.12:9200/" {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{StatusCode: 503}, nil
+		}
+
+		Expect(ReachablePeerCount([]string{"1.2.3.4", "5.6.7.8", "9.10.11.12"}, 7)).To(Equal(2))
+	})
+})
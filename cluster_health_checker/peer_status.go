@@ -0,0 +1,36 @@
+package cluster_health_checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// The parts of a peer's galera-init /status response that peers compare
+type peerStatus struct {
+	Time             time.Time `json:"time"`
+	ServerID         int       `json:"server_id"`
+	WsrepNodeName    string    `json:"wsrep_node_name"`
+	WsrepNodeAddress string    `json:"wsrep_node_address"`
+	MaxAllowedPacket uint64    `json:"max_allowed_packet"`
+	ClusterStatus    string    `json:"wsrep_cluster_status"`
+}
+
+func fetchPeerStatus(ip string, statusPort string, client http.Client) (peerStatus, error) {
+	var status peerStatus
+
+	resp, err := MakeRequest("http://"+net.JoinHostPort(ip, statusPort)+"/status", client)
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return status, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	return status, err
+}
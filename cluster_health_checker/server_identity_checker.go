@@ -0,0 +1,107 @@
+package cluster_health_checker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// How often the server identity checker compares the identities peers report
+var ServerIdentityCheckInterval = 5 * time.Minute
+
+var errDuplicateIdentity = errors.New("cluster members must have distinct server identities")
+
+// ServerIdentityChecker logs an error when two peers report the same
+// server_id, wsrep_node_name or wsrep_node_address on their galera-init
+// /status endpoint, typically a config copied between nodes unedited, a
+// misconfiguration that otherwise only shows up as confusing replication
+// problems. Unreachable peers are skipped.
+type ServerIdentityChecker struct {
+	peers               PeerSource
+	statusPort          string
+	clusterProbeTimeout int
+	logger              lager.Logger
+}
+
+func NewServerIdentityChecker(peers PeerSource, statusPort string, clusterProbeTimeout int, logger lager.Logger) *ServerIdentityChecker {
+	return &ServerIdentityChecker{
+		peers:               peers,
+		statusPort:          statusPort,
+		clusterProbeTimeout: clusterProbeTimeout,
+		logger:              logger,
+	}
+}
+
+// Run compares peer identities every ServerIdentityCheckInterval until ctx is
+// cancelled.
+func (c *ServerIdentityChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(ServerIdentityCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Check()
+		}
+	}
+}
+
+func (c *ServerIdentityChecker) Check() {
+	client := http.Client{
+		Timeout: time.Duration(c.clusterProbeTimeout) * time.Second,
+	}
+
+	peersByServerID := map[string][]string{}
+	peersByNodeName := map[string][]string{}
+	peersByNodeAddress := map[string][]string{}
+	for _, ip := range c.peers.PeerIps() {
+		status, err := fetchPeerStatus(ip, c.statusPort, client)
+		if err != nil {
+			c.logger.Debug("server-identity-check-skipped", lager.Data{"peer": ip, "err": err.Error()})
+			continue
+		}
+
+		if status.ServerID != 0 {
+			serverID := strconv.Itoa(status.ServerID)
+			peersByServerID[serverID] = append(peersByServerID[serverID], ip)
+		}
+		if status.WsrepNodeName != "" {
+			peersByNodeName[status.WsrepNodeName] = append(peersByNodeName[status.WsrepNodeName], ip)
+		}
+		// Empty when Galera detects the address itself, which cannot collide
+		if status.WsrepNodeAddress != "" {
+			peersByNodeAddress[status.WsrepNodeAddress] = append(peersByNodeAddress[status.WsrepNodeAddress], ip)
+		}
+	}
+
+	c.logDuplicates("duplicate-server-id", "serverID", peersByServerID)
+	c.logDuplicates("duplicate-wsrep-node-name", "wsrepNodeName", peersByNodeName)
+	c.logDuplicates("duplicate-wsrep-node-address", "wsrepNodeAddress", peersByNodeAddress)
+}
+
+func (c *ServerIdentityChecker) logDuplicates(action string, key string, peersByValue map[string][]string) {
+	values := []string{}
+	for value := range peersByValue {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		peers := peersByValue[value]
+		if len(peers) < 2 {
+			continue
+		}
+
+		c.logger.Error(action, errDuplicateIdentity, lager.Data{
+			key:     value,
+			"peers": peers,
+		})
+	}
+}
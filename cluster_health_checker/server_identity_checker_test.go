@@ -0,0 +1,104 @@
+package cluster_health_checker_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/cloudfoundry/galera-init/cluster_health_checker"
+	"github.com/onsi/gomega/gbytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ServerIdentityChecker", func() {
+	type identity struct {
+		serverID    int
+		nodeName    string
+		nodeAddress string
+	}
+
+	var (
+		testLogger     *lagertest.TestLogger
+		checker        *ServerIdentityChecker
+		peerIdentities map[string]identity
+	)
+
+	BeforeEach(func() {
+		testLogger = lagertest.NewTestLogger("server_identity_checker")
+		peerIdentities = map[string]identity{
+			"1.1.1.1": {1, "mysql-0", "1.1.1.1:4567"},
+			"2.2.2.2": {2, "mysql-1", "2.2.2.2:4567"},
+			"3.3.3.3": {3, "mysql-2", "3.3.3.3:4567"},
+		}
+
+		MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			for ip, id := range peerIdentities {
+				if strings.Contains(url, ip) {
+					body := fmt.Sprintf(`{"server_id": %d, "wsrep_node_name": %q, "wsrep_node_address": %q}`, id.serverID, id.nodeName, id.nodeAddress)
+					return &http.Response{
+						StatusCode: 200,
+						Body:       ioutil.NopCloser(strings.NewReader(body)),
+					}, nil
+				}
+			}
+			return nil, errors.New("connection refused")
+		}
+
+		checker = NewServerIdentityChecker(StaticPeers{"1.1.1.1", "2.2.2.2", "3.3.3.3"}, "8999", 10, testLogger)
+	})
+
+	It("does not log an error when every peer is distinct", func() {
+		checker.Check()
+
+		Expect(testLogger.Buffer()).NotTo(gbytes.Say("duplicate"))
+	})
+
+	It("logs an error naming the peers that share a server_id", func() {
+		peerIdentities["3.3.3.3"] = identity{1, "mysql-2", "3.3.3.3:4567"}
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`duplicate-server-id.*"peers":\["1.1.1.1","3.3.3.3"\],"serverID":"1"`))
+	})
+
+	It("logs an error when peers share a wsrep_node_name", func() {
+		peerIdentities["2.2.2.2"] = identity{2, "mysql-0", "2.2.2.2:4567"}
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`duplicate-wsrep-node-name.*"wsrepNodeName":"mysql-0"`))
+	})
+
+	It("logs an error when peers share a wsrep_node_address", func() {
+		peerIdentities["3.3.3.3"] = identity{3, "mysql-2", "2.2.2.2:4567"}
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`duplicate-wsrep-node-address.*"peers":\["2.2.2.2","3.3.3.3"\],"wsrepNodeAddress":"2.2.2.2:4567"`))
+	})
+
+	It("does not treat auto-detected, empty node addresses as duplicates", func() {
+		peerIdentities["1.1.1.1"] = identity{1, "mysql-0", ""}
+		peerIdentities["2.2.2.2"] = identity{2, "mysql-1", ""}
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).NotTo(gbytes.Say("duplicate"))
+	})
+
+	It("skips peers that cannot be reached or do not report an identity", func() {
+		delete(peerIdentities, "1.1.1.1")
+		peerIdentities["2.2.2.2"] = identity{0, "", ""}
+		peerIdentities["3.3.3.3"] = identity{0, "", ""}
+
+		checker.Check()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`server-identity-check-skipped.*"peer":"1.1.1.1"`))
+		Expect(testLogger.Buffer()).NotTo(gbytes.Say("duplicate"))
+	})
+})
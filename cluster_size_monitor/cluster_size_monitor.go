@@ -0,0 +1,128 @@
+package cluster_size_monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/cloudfoundry/galera-init/cluster_health_checker"
+)
+
+var errClusterDegraded = errors.New("fewer peers are reachable than are listed")
+
+// How often the monitor compares the reachable peers against the listed ones
+var CheckInterval = 30 * time.Second
+
+// How often the error is repeated while the cluster stays degraded
+var ErrorRepeatInterval = time.Hour
+
+// Monitor warns when fewer of the listed peers, counting this node, have
+// answered a healthcheck than are listed for longer than warnAfter. The
+// warning is escalated to an error once the cluster has been degraded for
+// three times as long, and the error is repeated every ErrorRepeatInterval
+// after that. When reportUnhealthy is set the node also reports itself
+// unhealthy from warnAfter on.
+type Monitor struct {
+	peers               cluster_health_checker.PeerSource
+	clusterProbeTimeout int
+	warnAfter           time.Duration
+	reportUnhealthy     bool
+	logger              lager.Logger
+
+	mutex          sync.Mutex
+	degradedSince  time.Time
+	warned         bool
+	lastErrorAt    time.Time
+	degradedReason string
+}
+
+func NewMonitor(peers cluster_health_checker.PeerSource, clusterProbeTimeout int, warnAfter time.Duration, reportUnhealthy bool, logger lager.Logger) *Monitor {
+	return &Monitor{
+		peers:               peers,
+		clusterProbeTimeout: clusterProbeTimeout,
+		warnAfter:           warnAfter,
+		reportUnhealthy:     reportUnhealthy,
+		logger:              logger,
+	}
+}
+
+// Run checks the reachable peers every CheckInterval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.Check(now)
+		}
+	}
+}
+
+func (m *Monitor) Check(now time.Time) {
+	ips := m.peers.PeerIps()
+	expectedNodes := len(ips)
+	reachablePeers := cluster_health_checker.ReachablePeerCount(ips, m.clusterProbeTimeout)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if reachablePeers >= expectedNodes {
+		if !m.degradedSince.IsZero() {
+			m.logger.Info("cluster-size-restored", lager.Data{
+				"reachablePeers": reachablePeers,
+				"expectedNodes":  expectedNodes,
+			})
+		}
+		m.degradedSince = time.Time{}
+		m.warned = false
+		m.lastErrorAt = time.Time{}
+		m.degradedReason = ""
+		return
+	}
+
+	if m.degradedSince.IsZero() {
+		m.degradedSince = now
+	}
+
+	degradedFor := now.Sub(m.degradedSince)
+	if degradedFor < m.warnAfter {
+		return
+	}
+
+	m.degradedReason = fmt.Sprintf("only %d of %d peers have been reachable for %s", reachablePeers, expectedNodes, degradedFor)
+	data := lager.Data{
+		"reachablePeers": reachablePeers,
+		"expectedNodes":  expectedNodes,
+		"degradedFor":    degradedFor.String(),
+	}
+
+	switch {
+	case degradedFor >= 3*m.warnAfter:
+		if m.lastErrorAt.IsZero() || now.Sub(m.lastErrorAt) >= ErrorRepeatInterval {
+			m.logger.Error("cluster-degraded", errClusterDegraded, data)
+			m.lastErrorAt = now
+		}
+	case !m.warned:
+		m.logger.Info("warning-cluster-degraded", data)
+		m.warned = true
+	}
+}
+
+// Healthy reports false, with the reason, once the cluster has been degraded
+// for longer than warnAfter, if the monitor was asked to report it.
+func (m *Monitor) Healthy() (bool, string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.reportUnhealthy {
+		return true, ""
+	}
+	return m.degradedReason == "", m.degradedReason
+}
@@ -0,0 +1,14 @@
+package cluster_size_monitor_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestClusterSizeMonitor(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Cluster Size Monitor Suite")
+}
@@ -0,0 +1,157 @@
+package cluster_size_monitor_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/cloudfoundry/galera-init/cluster_health_checker"
+	"github.com/cloudfoundry/galera-init/cluster_health_checker/cluster_health_checkerfakes"
+	"github.com/cloudfoundry/galera-init/cluster_size_monitor"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Monitor", func() {
+	var (
+		monitor        *cluster_size_monitor.Monitor
+		peers          *cluster_health_checkerfakes.FakePeerSource
+		reachablePeers int
+		testLogger     *lagertest.TestLogger
+		start          time.Time
+	)
+
+	BeforeEach(func() {
+		testLogger = lagertest.NewTestLogger("cluster_size_monitor")
+		start = time.Date(2020, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+		peers = new(cluster_health_checkerfakes.FakePeerSource)
+		peers.PeerIpsReturns([]string{"1.1.1.1", "2.2.2.2", "3.3.3.3"})
+		reachablePeers = 3
+		cluster_health_checker.MakeRequest = func(url string, client http.Client) (*http.Response, error) {
+			for _, ip := range peers.PeerIps()[:reachablePeers] {
+				if strings.Contains(url, ip) {
+					return &http.Response{StatusCode: 200}, nil
+				}
+			}
+			return nil, errors.New("connection refused")
+		}
+
+		monitor = cluster_size_monitor.NewMonitor(peers, 10, 10*time.Minute, false, testLogger)
+	})
+
+	It("does not warn while every peer is reachable", func() {
+		monitor.Check(start)
+		monitor.Check(start.Add(time.Hour))
+
+		Expect(testLogger.LogMessages()).To(BeEmpty())
+	})
+
+	It("does not warn about a short-lived drop in reachable peers", func() {
+		reachablePeers = 1
+
+		monitor.Check(start)
+		monitor.Check(start.Add(5 * time.Minute))
+
+		Expect(testLogger.LogMessages()).To(BeEmpty())
+	})
+
+	It("warns once the cluster has been degraded for the configured duration, then escalates", func() {
+		reachablePeers = 1
+
+		monitor.Check(start)
+		monitor.Check(start.Add(10 * time.Minute))
+		Expect(testLogger.Buffer()).To(gbytes.Say(`warning-cluster-degraded.*"expectedNodes":3.*"reachablePeers":1`))
+
+		monitor.Check(start.Add(30 * time.Minute))
+		Expect(testLogger.Buffer()).To(gbytes.Say(`cluster-degraded.*"log_level":2`))
+	})
+
+	It("warns once and repeats the error only every ErrorRepeatInterval", func() {
+		reachablePeers = 1
+
+		for elapsed := time.Duration(0); elapsed <= 2*time.Hour; elapsed += 30 * time.Second {
+			monitor.Check(start.Add(elapsed))
+		}
+
+		Expect(testLogger.LogMessages()).To(Equal([]string{
+			"cluster_size_monitor.warning-cluster-degraded",
+			"cluster_size_monitor.cluster-degraded",
+			"cluster_size_monitor.cluster-degraded",
+		}))
+	})
+
+	It("resets once every peer is reachable again", func() {
+		reachablePeers = 2
+		monitor.Check(start)
+
+		reachablePeers = 3
+		monitor.Check(start.Add(time.Minute))
+		Expect(testLogger.Buffer()).To(gbytes.Say("cluster-size-restored"))
+
+		reachablePeers = 2
+		monitor.Check(start.Add(15 * time.Minute))
+		Expect(testLogger.Buffer()).NotTo(gbytes.Say("warning-cluster-degraded"))
+	})
+
+	It("expects as many nodes as its peer source lists at the time of each check", func() {
+		monitor.Check(start)
+
+		peers.PeerIpsReturns([]string{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4", "5.5.5.5"})
+		monitor.Check(start.Add(time.Minute))
+		monitor.Check(start.Add(11 * time.Minute))
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`warning-cluster-degraded.*"expectedNodes":5.*"reachablePeers":3`))
+	})
+
+	It("stays healthy while degraded unless asked to report it", func() {
+		reachablePeers = 1
+		monitor.Check(start)
+		monitor.Check(start.Add(time.Hour))
+
+		healthy, _ := monitor.Healthy()
+		Expect(healthy).To(BeTrue())
+	})
+
+	Context("when asked to report a degraded cluster as unhealthy", func() {
+		BeforeEach(func() {
+			monitor = cluster_size_monitor.NewMonitor(peers, 10, 10*time.Minute, true, testLogger)
+		})
+
+		It("is unhealthy from the warning on, until every peer is reachable again", func() {
+			reachablePeers = 1
+			monitor.Check(start)
+			healthy, _ := monitor.Healthy()
+			Expect(healthy).To(BeTrue())
+
+			monitor.Check(start.Add(10 * time.Minute))
+			healthy, reason := monitor.Healthy()
+			Expect(healthy).To(BeFalse())
+			Expect(reason).To(Equal("only 1 of 3 peers have been reachable for 10m0s"))
+
+			reachablePeers = 3
+			monitor.Check(start.Add(11 * time.Minute))
+			healthy, _ = monitor.Healthy()
+			Expect(healthy).To(BeTrue())
+		})
+	})
+
+	It("stops running when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			monitor.Run(ctx)
+			close(done)
+		}()
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -2,43 +2,189 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"syscall"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 
 	"github.com/cloudfoundry/galera-init/cluster_health_checker"
+	"github.com/cloudfoundry/galera-init/cluster_size_monitor"
 	"github.com/cloudfoundry/galera-init/config"
 	"github.com/cloudfoundry/galera-init/db_helper"
 	"github.com/cloudfoundry/galera-init/galera_init_status_server"
+	"github.com/cloudfoundry/galera-init/maintenance_scheduler"
 	"github.com/cloudfoundry/galera-init/os_helper"
+	"github.com/cloudfoundry/galera-init/recovery_monitor"
+	"github.com/cloudfoundry/galera-init/retry_counters"
 	"github.com/cloudfoundry/galera-init/start_manager"
 	"github.com/cloudfoundry/galera-init/start_manager/node_starter"
+	"github.com/cloudfoundry/galera-init/startup_errors"
+	"github.com/cloudfoundry/galera-init/transition_history"
 	"github.com/cloudfoundry/galera-init/upgrader"
+	"github.com/cloudfoundry/galera-init/user_reconciler"
 	"net"
 )
 
+// Identify the build; set with
+// go build -ldflags "-X main.version=<version> -X main.gitSHA=<sha>"
+var (
+	version = "dev"
+	gitSHA  = "unknown"
+)
+
+// Exit codes used when ClassifiedExitCodes is set; see example-config.yml
+const (
+	exitCodeFailure   = 1
+	exitCodeConfig    = 10
+	exitCodeTimeout   = 11
+	exitCodeBootstrap = 12
+	exitCodeJoin      = 13
+	exitCodeSeed      = 14
+)
+
 func main() {
+	if len(os.Args) > 1 && (os.Args[1] == "--version" || os.Args[1] == "-version") {
+		fmt.Printf("galera-init version %s (git %s)\n", version, gitSHA)
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "selftest" {
+		os.Exit(runSelfTest(append([]string{os.Args[0]}, os.Args[2:]...)))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "leave" {
+		os.Exit(runLeave(append([]string{os.Args[0]}, os.Args[2:]...)))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "check-peers" {
+		os.Exit(runCheckPeers(append([]string{os.Args[0]}, os.Args[2:]...)))
+	}
 
 	cfg, err := config.NewConfig(os.Args)
 	if err != nil {
-		cfg.Logger.Fatal("Error creating config", err)
+		exitWithCode(cfg, "Error creating config", err, exitCodeConfig)
 		return
 	}
 
 	err = cfg.Validate()
 	if err != nil {
-		cfg.Logger.Fatal("Error validating config", err)
+		exitWithCode(cfg, "Error validating config", err, exitCodeConfig)
+		return
+	}
+
+	cfg.Logger.Info("config-source", lager.Data{
+		"origin":   cfg.Source.Origin,
+		"path":     cfg.Source.Path,
+		"sections": cfg.Source.Sections,
+	})
+	cfg.Logger.Info("effective-config", lager.Data{"config": cfg.Redacted()})
+
+	OsHelper := os_helper.NewImpl()
+
+	// LogFileLocation's directory is prepared whatever MysqldOutput is, since
+	// my.cnf may point log-error at it; mysqld's output only falls back to
+	// stderr when it was headed for a directory that never appeared.
+	err = prepareLogFileDir(OsHelper, cfg.LogFileLocation, cfg.LogFileDirMode, cfg.LogFileDirWaitTimeout)
+	if err != nil && cfg.Db.MysqldOutput == config.MysqldOutputErrorLog {
+		cfg.Logger.Error("log-file-dir-unavailable-passing-mysqld-output-to-stderr", err, lager.Data{"logFileLocation": cfg.LogFileLocation})
+		cfg.Db.MysqldOutput = config.MysqldOutputGaleraInit
+	} else if err != nil {
+		cfg.Logger.Error("log-file-dir-unavailable", err, lager.Data{"logFileLocation": cfg.LogFileLocation})
+	}
+
+	if cfg.Db.MysqldOutput == config.MysqldOutputFile {
+		err = prepareLogFileDir(OsHelper, cfg.Db.MysqldOutputFile, cfg.LogFileDirMode, cfg.LogFileDirWaitTimeout)
+		if err != nil {
+			cfg.Logger.Error("mysqld-output-file-dir-unavailable-passing-mysqld-output-to-stderr", err, lager.Data{"mysqldOutputFile": cfg.Db.MysqldOutputFile})
+			cfg.Db.MysqldOutput = config.MysqldOutputGaleraInit
+		}
+	}
+
+	hostname, err := OsHelper.Hostname()
+	if err != nil {
+		cfg.Logger.Error("Error reading hostname", err)
+	} else {
+		cfg.Logger = cfg.Logger.WithData(lager.Data{"hostname": hostname})
+		if cfg.Db.WsrepNodeName == "" {
+			cfg.Db.WsrepNodeName = hostname
+		}
+	}
+
+	processManager, err := db_helper.NewProcessManager(cfg.Db, OsHelper, cfg.Logger)
+	if err != nil {
+		exitWithCode(cfg, "Error creating process manager", err, exitCodeConfig)
 		return
 	}
 
+	// Every component connects through this helper or its ForSteadyState
+	// copies, so a password rotated through the status server reaches all of them.
+	DBHelper := db_helper.NewDBHelper(OsHelper, processManager, &cfg.Db, cfg.LogFileLocation, cfg.Logger)
+
+	if cfg.PidFile != "" {
+		releaseLock, err := OsHelper.AcquireLock(cfg.PidFile)
+		if err != nil {
+			cfg.Logger.Fatal("Error acquiring pid file lock", err)
+			return
+		}
+		defer releaseLock()
+
+		err = writePidFile(OsHelper, cfg.PidFile, cfg.PidFileWriteAttempts)
+		if err != nil {
+			cfg.Logger.Fatal("Error writing pid file", err)
+			return
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	setupSignals(cancel, cfg.Logger)
 
-	startManager, err := managerSetup(cfg)
+	if cfg.LogFileMaxSizeMB > 0 {
+		startLogRotation(ctx, cfg)
+	}
+
+	var peers cluster_health_checker.PeerSource = cluster_health_checker.StaticPeers(cfg.Manager.ClusterIps)
+	if cfg.Manager.ClusterIpsFile != "" {
+		peerList := loadPeerList(cfg)
+		go peerList.Run(ctx)
+		peers = peerList
+	}
+
+	var healthReporters galera_init_status_server.HealthReporters
+	var recoveryMonitor *recovery_monitor.Monitor
+	if cfg.Manager.RecoveryAlertAfter > 0 {
+		recoveryMonitor = recovery_monitor.NewMonitor(
+			DBHelper.ForSteadyState(),
+			time.Duration(cfg.Manager.RecoveryAlertAfter)*time.Second,
+			time.Duration(cfg.Manager.MaxMaintenanceDuration)*time.Second,
+			cfg.Manager.ExitWhenStuckInRecovery,
+			cancel,
+			cfg.Logger,
+		)
+		go recoveryMonitor.Run(ctx)
+		healthReporters = append(healthReporters, recoveryMonitor)
+	}
+
+	if cfg.Manager.DegradedClusterWarningAfter > 0 {
+		monitor := cluster_size_monitor.NewMonitor(
+			peers,
+			cfg.Manager.ClusterProbeTimeout,
+			time.Duration(cfg.Manager.DegradedClusterWarningAfter)*time.Second,
+			cfg.Manager.DegradedClusterFailsHealth,
+			cfg.Logger,
+		)
+		go monitor.Run(ctx)
+		healthReporters = append(healthReporters, monitor)
+	}
+
+	startManager, err := managerSetup(cfg, OsHelper, DBHelper, peers, healthReporters, recoveryMonitor)
 	if err != nil {
 		cfg.Logger.Info("manage-setup-failure", lager.Data{
 			"error": err.Error(),
@@ -46,6 +192,61 @@ func main() {
 		os.Exit(1)
 	}
 
+	if len(cfg.Maintenance.Statements) > 0 {
+		scheduler := maintenance_scheduler.NewScheduler(
+			DBHelper,
+			cfg.Maintenance,
+			cfg.Logger,
+		)
+		go scheduler.Run(ctx)
+	}
+
+	if cfg.Manager.UserReconcileInterval > 0 {
+		reconciler := user_reconciler.NewReconciler(
+			DBHelper,
+			time.Duration(cfg.Manager.UserReconcileInterval)*time.Second,
+			cfg.Logger,
+		)
+		go reconciler.Run(ctx)
+	}
+
+	_, statusPort, err := net.SplitHostPort(cfg.Manager.GaleraInitStatusServerAddress)
+	if err != nil {
+		cfg.Logger.Fatal("Error reading status server port", err)
+		return
+	}
+
+	if cfg.Manager.ClockSkewWarningThreshold > 0 {
+		checker := cluster_health_checker.NewClockSkewChecker(
+			peers,
+			statusPort,
+			cfg.Manager.ClusterProbeTimeout,
+			time.Duration(cfg.Manager.ClockSkewWarningThreshold)*time.Second,
+			cfg.Logger,
+		)
+		go checker.Run(ctx)
+	}
+
+	if cfg.Manager.CheckServerIdentities {
+		checker := cluster_health_checker.NewServerIdentityChecker(
+			peers,
+			statusPort,
+			cfg.Manager.ClusterProbeTimeout,
+			cfg.Logger,
+		)
+		go checker.Run(ctx)
+	}
+
+	if cfg.Manager.CheckMaxAllowedPacket {
+		checker := cluster_health_checker.NewMaxAllowedPacketChecker(
+			peers,
+			statusPort,
+			cfg.Manager.ClusterProbeTimeout,
+			cfg.Logger,
+		)
+		go checker.Run(ctx)
+	}
+
 	cfg.Logger.Info("starting")
 
 	if err := startManager.Execute(ctx); err != nil {
@@ -58,6 +259,8 @@ func main() {
 			} else {
 				os.Exit(ws.ExitStatus())
 			}
+		} else if cfg.ClassifiedExitCodes {
+			os.Exit(exitCodeFor(err))
 		} else {
 			os.Exit(1)
 		}
@@ -65,16 +268,42 @@ func main() {
 	cfg.Logger.Info("exited")
 }
 
-func managerSetup(cfg *config.Config) (start_manager.StartManager, error) {
-	OsHelper := os_helper.NewImpl()
+// exitWithCode exits with code when ClassifiedExitCodes is set, and through
+// the logger's Fatal otherwise, as before the option existed.
+func exitWithCode(cfg *config.Config, message string, err error, code int) {
+	if !cfg.ClassifiedExitCodes {
+		cfg.Logger.Fatal(message, err)
+		return
+	}
 
-	DBHelper := db_helper.NewDBHelper(
-		OsHelper,
-		&cfg.Db,
-		cfg.LogFileLocation,
-		cfg.Logger,
+	cfg.Logger.Error(message, err, lager.Data{"exitCode": code})
+	os.Exit(code)
+}
+
+// exitCodeFor maps the startup_errors types to their exit code.
+func exitCodeFor(err error) int {
+	var (
+		timeoutErr   *startup_errors.TimeoutError
+		bootstrapErr *startup_errors.BootstrapError
+		joinErr      *startup_errors.JoinError
+		seedErr      *startup_errors.SeedError
 	)
 
+	switch {
+	case errors.As(err, &timeoutErr):
+		return exitCodeTimeout
+	case errors.As(err, &bootstrapErr):
+		return exitCodeBootstrap
+	case errors.As(err, &joinErr):
+		return exitCodeJoin
+	case errors.As(err, &seedErr):
+		return exitCodeSeed
+	default:
+		return exitCodeFailure
+	}
+}
+
+func managerSetup(cfg *config.Config, OsHelper os_helper.OsHelper, DBHelper *db_helper.GaleraDBHelper, peers cluster_health_checker.PeerSource, healthReporter galera_init_status_server.HealthReporter, recoveryMonitor *recovery_monitor.Monitor) (start_manager.StartManager, error) {
 	Upgrader := upgrader.NewUpgrader(
 		OsHelper,
 		cfg.Upgrader,
@@ -82,26 +311,68 @@ func managerSetup(cfg *config.Config) (start_manager.StartManager, error) {
 		DBHelper,
 	)
 
-	ClusterHealthChecker := cluster_health_checker.NewClusterHealthChecker(
-		cfg.Manager.ClusterIps,
+	_, statusPort, err := net.SplitHostPort(cfg.Manager.GaleraInitStatusServerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	ClusterHealthChecker := cluster_health_checker.NewClusterHealthCheckerFromSource(
+		peers,
+		statusPort,
 		cfg.Manager.ClusterProbeTimeout,
 		cfg.Logger,
 	)
 
+	counters := retry_counters.New()
+
 	NodeStarter := node_starter.NewStarter(
 		DBHelper,
 		OsHelper,
 		cfg.Manager,
 		cfg.Logger,
 		ClusterHealthChecker,
+		counters,
 	)
 
-	listener, err := net.Listen("tcp", cfg.Manager.GaleraInitStatusServerAddress)
+	listenAddress, err := cfg.Manager.StatusServerListenAddress()
 	if err != nil {
 		return nil, err
 	}
 
-	galeraInitStatusServer := galera_init_status_server.NewGaleraInitStatusServer(listener)
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	history := transition_history.New(cfg.Manager.HistorySize)
+	standby := start_manager.NewStandby()
+
+	// The recovery monitor is armed once the node has started and can be
+	// suspended through /maintenance. Kept nil when it is disabled, rather than
+	// wrapping a nil *Monitor in the interfaces.
+	var recoverySuspender galera_init_status_server.RecoverySuspender
+	var startupListeners []start_manager.StartupListener
+	if recoveryMonitor != nil {
+		recoverySuspender = recoveryMonitor
+		startupListeners = append(startupListeners, recoveryMonitor)
+	}
+
+	galeraInitStatusServer := galera_init_status_server.NewGaleraInitStatusServer(
+		listener,
+		history,
+		counters,
+		DBHelper.ForSteadyState(),
+		DBHelper,
+		cfg.Manager.RotateCredentialsToken,
+		DBHelper,
+		cfg.Manager.ReseedToken,
+		cfg.Manager.DatabaseSeedTries(),
+		healthReporter,
+		standby,
+		cfg.Manager.PromoteToken,
+		recoverySuspender,
+		cfg.Manager.MaintenanceToken,
+	)
 
 	NodeStartManager := start_manager.New(
 		OsHelper,
@@ -112,11 +383,206 @@ func managerSetup(cfg *config.Config) (start_manager.StartManager, error) {
 		cfg.Logger,
 		ClusterHealthChecker,
 		galeraInitStatusServer,
+		history,
+		standby,
+		startupListeners...,
 	)
 
 	return NodeStartManager, nil
 }
 
+func runSelfTest(args []string) int {
+	cfg, err := config.NewConfig(args)
+	if err != nil {
+		cfg.Logger.Fatal("Error creating config", err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		cfg.Logger.Fatal("Error validating config", err)
+	}
+
+	OsHelper := os_helper.NewImpl()
+
+	processManager, err := db_helper.NewProcessManager(cfg.Db, OsHelper, cfg.Logger)
+	if err != nil {
+		cfg.Logger.Fatal("Error creating process manager", err)
+	}
+
+	DBHelper := db_helper.NewDBHelper(
+		OsHelper,
+		processManager,
+		&cfg.Db,
+		cfg.LogFileLocation,
+		cfg.Logger,
+	)
+
+	exitCode := 0
+	for _, result := range DBHelper.SelfTest() {
+		if result.Passed() {
+			fmt.Printf("PASS %s\n", result.Name)
+		} else {
+			fmt.Printf("FAIL %s: %s\n", result.Name, result.Err)
+			exitCode = 1
+		}
+	}
+
+	return exitCode
+}
+
+// mysqld appends to its log files itself, so they are rotated in place and it
+// keeps logging even if galera-init is gone.
+func startLogRotation(ctx context.Context, cfg *config.Config) {
+	logs := []string{cfg.LogFileLocation}
+	if cfg.Db.MysqldOutput == config.MysqldOutputFile {
+		logs = append(logs, cfg.Db.MysqldOutputFile)
+	}
+
+	for _, logFile := range logs {
+		rotator := os_helper.NewLogRotator(logFile, int64(cfg.LogFileMaxSizeMB)*1024*1024, cfg.LogFileMaxBackups, cfg.Logger)
+		go rotator.Run(ctx)
+	}
+}
+
+// Decommissions the node: it leaves the cluster, and galera-init exits
+// cleanly instead of starting mysqld until the state file is removed.
+func runLeave(args []string) int {
+	cfg, err := config.NewConfig(args)
+	if err != nil {
+		cfg.Logger.Fatal("Error creating config", err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		cfg.Logger.Fatal("Error validating config", err)
+	}
+
+	OsHelper := os_helper.NewImpl()
+
+	processManager, err := db_helper.NewProcessManager(cfg.Db, OsHelper, cfg.Logger)
+	if err != nil {
+		cfg.Logger.Fatal("Error creating process manager", err)
+	}
+
+	DBHelper := db_helper.NewDBHelper(
+		OsHelper,
+		processManager,
+		&cfg.Db,
+		cfg.LogFileLocation,
+		cfg.Logger,
+	)
+
+	err = start_manager.Leave(OsHelper, DBHelper, cfg.Manager, cfg.Logger)
+	if err != nil {
+		cfg.Logger.Error("leave-failed", err)
+		return 1
+	}
+
+	return 0
+}
+
+// Probes the healthcheck port of every ClusterIps entry, to diagnose network
+// or firewall problems during cluster bring-up. Exits 1 if any is unreachable.
+func runCheckPeers(args []string) int {
+	cfg, err := config.NewConfig(args)
+	if err != nil {
+		cfg.Logger.Fatal("Error creating config", err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		cfg.Logger.Fatal("Error validating config", err)
+	}
+
+	peers := cfg.Manager.ClusterIps
+	if cfg.Manager.ClusterIpsFile != "" {
+		peers = loadPeerList(cfg).PeerIps()
+	}
+
+	exitCode := 0
+	for _, probe := range cluster_health_checker.ProbePeers(peers, cfg.Manager.ClusterProbeTimeout) {
+		latency := probe.Latency.Round(time.Millisecond)
+		if probe.Reachable {
+			fmt.Printf("REACHABLE %s %s (HTTP %d)\n", probe.Peer, latency, probe.StatusCode)
+		} else {
+			fmt.Printf("UNREACHABLE %s %s: %s\n", probe.Peer, latency, probe.Err)
+			exitCode = 1
+		}
+	}
+
+	return exitCode
+}
+
+// Reads ClusterIpsFile, keeping ClusterIps as the peer list while the file
+// cannot be read.
+func loadPeerList(cfg *config.Config) *cluster_health_checker.FilePeerList {
+	peerList := cluster_health_checker.NewFilePeerList(cfg.Manager.ClusterIpsFile, cfg.Manager.ClusterIps, cfg.Logger)
+	peerList.Reload()
+	return peerList
+}
+
+// How often prepareLogFileDir retries creating the log file directory
+var logFileDirRetryInterval = time.Second
+
+// The log file directory may live on a disk that is mounted shortly after
+// galera-init starts, so creating it is retried for up to waitTimeout
+// seconds before giving up. Only the directory itself is created: while its
+// parent is missing, as under a mount point that is created by mounting,
+// this keeps waiting. A parent that exists before its disk is mounted
+// cannot be told apart from a mounted one.
+func prepareLogFileDir(osHelper os_helper.OsHelper, logFileLocation string, mode string, waitTimeout int) error {
+	dirMode := os.FileMode(0750)
+	if mode != "" {
+		parsed, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			return err
+		}
+		dirMode = os.FileMode(parsed)
+	}
+
+	dir := filepath.Dir(logFileLocation)
+	timeout := time.Duration(waitTimeout) * time.Second
+	for waited := time.Duration(0); ; waited += logFileDirRetryInterval {
+		err := osHelper.MakeDir(dir, dirMode)
+		if err == nil {
+			return nil
+		}
+
+		if waited >= timeout {
+			return fmt.Errorf("creating log file directory %s failed after %s: %s", dir, timeout, err)
+		}
+
+		osHelper.Sleep(logFileDirRetryInterval)
+	}
+}
+
+// Delay before the second pid file write attempt; it doubles for every attempt after that
+var pidFileRetryDelay = 500 * time.Millisecond
+
+// The pid file is written before mysqld is started, so giving up here never
+// leaves an unmanaged mysqld behind.
+func writePidFile(osHelper os_helper.OsHelper, pidFile string, attempts int) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := pidFileRetryDelay
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = osHelper.WriteStringToFile(pidFile, strconv.Itoa(os.Getpid()))
+		if err == nil {
+			return nil
+		}
+
+		if attempt < attempts {
+			osHelper.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	return fmt.Errorf("writing pid file %s failed after %d attempts; check that its directory exists and is writable by this user: %s", pidFile, attempts, err)
+}
+
 func setupSignals(shutdownMySQL func(), log lager.Logger) {
 	sigCh := make(chan os.Signal, 1)
 
@@ -1,8 +1,11 @@
 package main_test
 
 import (
+	"os/exec"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
 )
 
@@ -14,4 +17,49 @@ var _ = Describe("galera-init Start", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Describe("ClassifiedExitCodes", func() {
+		var binPath string
+
+		BeforeEach(func() {
+			var err error
+			binPath, err = gexec.Build("github.com/cloudfoundry/galera-init/cmd/start")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			gexec.CleanupBuildArtifacts()
+		})
+
+		It("exits with the config error code when the config is invalid", func() {
+			session, err := gexec.Start(exec.Command(binPath, "-config", "ClassifiedExitCodes: true"), GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, "10s").Should(gexec.Exit(10))
+			Expect(session.Out).To(gbytes.Say("Error validating config"))
+		})
+
+		It("exits through a fatal log line when it is not set", func() {
+			session, err := gexec.Start(exec.Command(binPath, "-config", "LogLevel: info"), GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, "10s").Should(gexec.Exit())
+			Expect(session.ExitCode()).NotTo(Equal(10))
+			Expect(session.Out).To(gbytes.Say("Error validating config"))
+		})
+	})
+
+	Describe("--version", func() {
+		It("prints the version and git SHA set at build time", func() {
+			binPath, err := gexec.Build(
+				"github.com/cloudfoundry/galera-init/cmd/start",
+				"-ldflags", "-X main.version=1.2.3 -X main.gitSHA=abc1234",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			defer gexec.CleanupBuildArtifacts()
+
+			session, err := gexec.Start(exec.Command(binPath, "--version"), GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`galera-init version 1.2.3 \(git abc1234\)`))
+		})
+	})
 })
@@ -4,6 +4,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagerflags"
@@ -11,37 +17,209 @@ import (
 	"gopkg.in/validator.v2"
 )
 
+const (
+	InconsistencyPolicyFail   = "fail"
+	InconsistencyPolicyResync = "resync"
+
+	MaintenanceWindowLayout = "15:04"
+
+	BootstrapFailurePolicyExit  = "exit"
+	BootstrapFailurePolicyRetry = "retry"
+
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+
+	MembershipCheckPolicyOff  = "off"
+	MembershipCheckPolicyWarn = "warn"
+	MembershipCheckPolicyFail = "fail"
+
+	ProcessManagerDirect = "direct"
+
+	MysqldOutputErrorLog   = "error-log"
+	MysqldOutputGaleraInit = "galera-init"
+	MysqldOutputFile       = "file"
+
+	UpgradeTimingPreStart  = "pre-start"
+	UpgradeTimingPostStart = "post-start"
+
+	StopProtocolSocket = "socket"
+	StopProtocolTCP    = "tcp"
+
+	SteadyStateProtocolSocket = "socket"
+	SteadyStateProtocolTCP    = "tcp"
+
+	ZeroGrastateUUIDPolicyIgnore     = "ignore"
+	ZeroGrastateUUIDPolicyFreshStart = "fresh-start"
+
+	EmptyDatadirPolicyJoin   = "join"
+	EmptyDatadirPolicyIgnore = "ignore"
+
+	UnreadableStateFilePolicyFail       = "fail"
+	UnreadableStateFilePolicyFreshStart = "fresh-start"
+
+	SeedFailurePolicyLeaveRunning = "leave-running"
+	SeedFailurePolicyStop         = "stop"
+
+	ShutdownSignalTerm = "SIGTERM"
+	ShutdownSignalKill = "SIGKILL"
+)
+
+// SST methods that connect to the donor's mysqld and so need wsrep_sst_auth
+var sstMethodsNeedingAuth = map[string]bool{
+	"mariabackup":   true,
+	"mysqldump":     true,
+	"xtrabackup":    true,
+	"xtrabackup-v2": true,
+}
+
+// SSTMethodNeedsAuth reports whether the wsrep_sst_method needs SST credentials
+func SSTMethodNeedsAuth(method string) bool {
+	return sstMethodsNeedingAuth[method]
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 type Config struct {
-	LogFileLocation string       `yaml:"LogFileLocation" validate:"nonzero"`
-	Db              DBHelper     `yaml:"Db"`
-	Manager         StartManager `yaml:"Manager"`
-	Upgrader        Upgrader     `yaml:"Upgrader"`
-	Logger          lager.Logger
+	LogFileLocation       string       `yaml:"LogFileLocation" validate:"nonzero"`
+	LogFileMaxSizeMB      int          `yaml:"LogFileMaxSizeMB"`
+	LogFileMaxBackups     int          `yaml:"LogFileMaxBackups"`
+	LogFileDirMode        string       `yaml:"LogFileDirMode"`
+	LogFileDirWaitTimeout int          `yaml:"LogFileDirWaitTimeout"`
+	LogLevel              string       `yaml:"LogLevel"`
+	LogFormat             string       `yaml:"LogFormat"`
+	DeployID              string       `yaml:"DeployID"`
+	PidFile               string       `yaml:"PidFile"`
+	PidFileWriteAttempts  int          `yaml:"PidFileWriteAttempts"`
+	ClassifiedExitCodes   bool         `yaml:"ClassifiedExitCodes"`
+	Db                    DBHelper     `yaml:"Db"`
+	Manager               StartManager `yaml:"Manager"`
+	Upgrader              Upgrader     `yaml:"Upgrader"`
+	Maintenance           Maintenance  `yaml:"Maintenance"`
+	Logger                lager.Logger `json:"-"`
+	Source                Source       `yaml:"-" json:"-"`
 }
 
 type DBHelper struct {
-	Password           string              `yaml:"Password"`
-	PostStartSQLFiles  []string            `yaml:"PostStartSQLFiles"`
-	PreseededDatabases []PreseededDatabase `yaml:"PreseededDatabases"`
-	SeededUsers        []SeededUser        `yaml:"SeededUsers"`
-	SkipBinlog         bool                `yaml:"SkipBinlog"`
-	Socket             string              `yaml:"Socket"`
-	UpgradePath        string              `yaml:"UpgradePath" validate:"nonzero"`
-	User               string              `yaml:"User" validate:"nonzero"`
+	AllowedSeedDatabases  []string            `yaml:"AllowedSeedDatabases"`
+	BootstrapCommand      string              `yaml:"BootstrapCommand" validate:"nonzero"`
+	ConnectTimeout        int                 `yaml:"ConnectTimeout"`
+	DataDir               string              `yaml:"DataDir"`
+	FatalErrorLogPatterns []string            `yaml:"FatalErrorLogPatterns"`
+	InstallDBPath         string              `yaml:"InstallDBPath"`
+	JoinCommand           string              `yaml:"JoinCommand" validate:"nonzero"`
+	ManageReadOnlyUser    bool                `yaml:"ManageReadOnlyUser"`
+	MysqldOutput          string              `yaml:"MysqldOutput"`
+	MysqldOutputFile      string              `yaml:"MysqldOutputFile"`
+	MysqldPidFile         string              `yaml:"MysqldPidFile"`
+	Password              string              `yaml:"Password"`
+	PostStartSQLFiles     []string            `yaml:"PostStartSQLFiles"`
+	PreseededDatabases    []PreseededDatabase `yaml:"PreseededDatabases"`
+	ProcessManager        string              `yaml:"ProcessManager"`
+	ReadOnlyPassword      string              `yaml:"ReadOnlyPassword"`
+	ReadOnlyUser          string              `yaml:"ReadOnlyUser"`
+	ReadOnlyUserHost      string              `yaml:"ReadOnlyUserHost"`
+	ReadOnlyUsers         []ReadOnlyUser      `yaml:"ReadOnlyUsers"`
+	SeedConnectAttempts   int                 `yaml:"SeedConnectAttempts"`
+	SeededUsers           []SeededUser        `yaml:"SeededUsers"`
+	SkipBinlog            bool                `yaml:"SkipBinlog"`
+	SSTDefaultsFile       string              `yaml:"SSTDefaultsFile"`
+	SSTMethod             string              `yaml:"SSTMethod"`
+	SSTPassword           string              `yaml:"SSTPassword"`
+	SSTUser               string              `yaml:"SSTUser"`
+	TCPAddress            string              `yaml:"TCPAddress"`
+	TCPKeepAliveCount     int                 `yaml:"TCPKeepAliveCount"`
+	TCPKeepAliveInterval  int                 `yaml:"TCPKeepAliveInterval"`
+	Socket                string              `yaml:"Socket"`
+	SteadyStateProtocol   string              `yaml:"SteadyStateProtocol"`
+	StopProtocol          string              `yaml:"StopProtocol"`
+	StopTimeout           int                 `yaml:"StopTimeout"`
+	UpgradePath           string              `yaml:"UpgradePath" validate:"nonzero"`
+	User                  string              `yaml:"User" validate:"nonzero"`
+	WsrepNodeAddress      string              `yaml:"WsrepNodeAddress"`
+	WsrepNodeName         string              `yaml:"WsrepNodeName"`
 }
 
 type StartManager struct {
 	StateFileLocation             string `yaml:"StateFileLocation" validate:"nonzero"`
+	SyncStateFile                 bool   `yaml:"SyncStateFile"`
+	ReadyFileLocation             string `yaml:"ReadyFileLocation"`
+	PreStartHealthCheckScript     string `yaml:"PreStartHealthCheckScript"`
+	MysqlPort                     int    `yaml:"MysqlPort"`
+	ReadinessProbeScript          string `yaml:"ReadinessProbeScript"`
+	LastFailureFileLocation       string `yaml:"LastFailureFileLocation"`
+	StartupCooldown               int    `yaml:"StartupCooldown"`
+	FirstBootSQLFile              string `yaml:"FirstBootSQLFile"`
+	FirstBootMarkerFile           string `yaml:"FirstBootMarkerFile"`
 	GrastateFileLocation          string
-	ClusterIps                    []string `yaml:"ClusterIps" validate:"nonzero"`
-	BootstrapNode                 bool     `yaml:"BootstrapNode"`
-	ClusterProbeTimeout           int      `yaml:"ClusterProbeTimeout" validate:"nonzero"`
-	GaleraInitStatusServerAddress string   `yaml:"GaleraInitStatusServerAddress" validate:"nonzero"`
+	RecoveryBackupDir             string      `yaml:"RecoveryBackupDir"`
+	ClusterIps                    []string    `yaml:"ClusterIps" validate:"nonzero"`
+	ClusterIpsFile                string      `yaml:"ClusterIpsFile"`
+	BootstrapNode                 bool        `yaml:"BootstrapNode"`
+	NeverBootstrap                bool        `yaml:"NeverBootstrap"`
+	RefuseEvenClusterSize         bool        `yaml:"RefuseEvenClusterSize"`
+	ClusterProbeTimeout           int         `yaml:"ClusterProbeTimeout" validate:"nonzero"`
+	ReachabilityProbeWindow       int         `yaml:"ReachabilityProbeWindow"`
+	JoinProgressLogInterval       int         `yaml:"JoinProgressLogInterval"`
+	MaxJoinAttempts               int         `yaml:"MaxJoinAttempts"`
+	JoinWaitForPrimaryTimeout     int         `yaml:"JoinWaitForPrimaryTimeout"`
+	MinPeersBeforeJoin            int         `yaml:"MinPeersBeforeJoin"`
+	MinPeersWaitTimeout           int         `yaml:"MinPeersWaitTimeout"`
+	SeedOnlyOnBootstrap           bool        `yaml:"SeedOnlyOnBootstrap"`
+	SeedReplicationTimeout        int         `yaml:"SeedReplicationTimeout"`
+	SeedTimeout                   int         `yaml:"SeedTimeout"`
+	DonorRejectsQueries           bool        `yaml:"DonorRejectsQueries"`
+	GaleraInitStatusServerAddress string      `yaml:"GaleraInitStatusServerAddress" validate:"nonzero"`
+	HealthBindAddress             string      `yaml:"HealthBindAddress"`
+	HistorySize                   int         `yaml:"HistorySize"`
+	InconsistencyPolicy           string      `yaml:"InconsistencyPolicy"`
+	BootstrapFailurePolicy        string      `yaml:"BootstrapFailurePolicy"`
+	SeedFailurePolicy             string      `yaml:"SeedFailurePolicy"`
+	MaxBootstrapAttempts          int         `yaml:"MaxBootstrapAttempts"`
+	MinExpectedMaxConnections     int         `yaml:"MinExpectedMaxConnections"`
+	MinBufferPoolMemoryFraction   float64     `yaml:"MinBufferPoolMemoryFraction"`
+	DegradedClusterWarningAfter   int         `yaml:"DegradedClusterWarningAfter"`
+	DegradedClusterFailsHealth    bool        `yaml:"DegradedClusterFailsHealth"`
+	RecoveryAlertAfter            int         `yaml:"RecoveryAlertAfter"`
+	ExitWhenStuckInRecovery       bool        `yaml:"ExitWhenStuckInRecovery"`
+	MaintenanceToken              string      `yaml:"MaintenanceToken"`
+	MaxMaintenanceDuration        int         `yaml:"MaxMaintenanceDuration"`
+	UserReconcileInterval         int         `yaml:"UserReconcileInterval"`
+	ClockSkewWarningThreshold     int         `yaml:"ClockSkewWarningThreshold"`
+	CheckServerIdentities         bool        `yaml:"CheckServerIdentities"`
+	CheckMaxAllowedPacket         bool        `yaml:"CheckMaxAllowedPacket"`
+	MaxAllowedPacket              uint64      `yaml:"MaxAllowedPacket"`
+	RotateCredentialsToken        string      `yaml:"RotateCredentialsToken"`
+	ReseedToken                   string      `yaml:"ReseedToken"`
+	MaxDatabaseSeedTries          int         `yaml:"MaxDatabaseSeedTries"`
+	MaxDatabaseSeedTriesByIndex   map[int]int `yaml:"MaxDatabaseSeedTriesByIndex"`
+	JobIndex                      int         `yaml:"JobIndex"`
+	WarmStandby                   bool        `yaml:"WarmStandby"`
+	PromoteToken                  string      `yaml:"PromoteToken"`
+	LeaveDrainTimeout             int         `yaml:"LeaveDrainTimeout"`
+	ShutdownQueueDrainTimeout     int         `yaml:"ShutdownQueueDrainTimeout"`
+	ShutdownSignal                string      `yaml:"ShutdownSignal"`
+	ShutdownKillTimeout           int         `yaml:"ShutdownKillTimeout"`
+	MembershipCheckPolicy         string      `yaml:"MembershipCheckPolicy"`
+	ExpectedClusterUUID           string      `yaml:"ExpectedClusterUUID"`
+	ZeroGrastateUUIDPolicy        string      `yaml:"ZeroGrastateUUIDPolicy"`
+	EmptyDatadirPolicy            string      `yaml:"EmptyDatadirPolicy"`
+	UnreadableStateFilePolicy     string      `yaml:"UnreadableStateFilePolicy"`
 }
 
 type Upgrader struct {
-	PackageVersionFile      string `yaml:"PackageVersionFile" validate:"nonzero"`
-	LastUpgradedVersionFile string `yaml:"LastUpgradedVersionFile" validate:"nonzero"`
+	PackageVersionFile       string `yaml:"PackageVersionFile" validate:"nonzero"`
+	LastUpgradedVersionFile  string `yaml:"LastUpgradedVersionFile" validate:"nonzero"`
+	UpgradeMaxRetries        int    `yaml:"UpgradeMaxRetries"`
+	VersionFileWriteAttempts int    `yaml:"VersionFileWriteAttempts"`
+	PreUpgradeScript         string `yaml:"PreUpgradeScript"`
+	PostUpgradeScript        string `yaml:"PostUpgradeScript"`
+	Timing                   string `yaml:"Timing"`
+}
+
+type Maintenance struct {
+	Statements            []string `yaml:"Statements"`
+	WindowStart           string   `yaml:"WindowStart"`
+	WindowDurationMinutes int      `yaml:"WindowDurationMinutes"`
 }
 
 type PreseededDatabase struct {
@@ -57,6 +235,15 @@ type SeededUser struct {
 	Role     string `yaml:"Role" validate:"nonzero"`
 }
 
+// ReadOnlyUser is granted SELECT on Databases, or on every database when
+// Databases is empty. Host defaults to Db.ReadOnlyUserHost.
+type ReadOnlyUser struct {
+	User      string   `yaml:"User" validate:"nonzero"`
+	Password  string   `yaml:"Password" validate:"nonzero"`
+	Host      string   `yaml:"Host"`
+	Databases []string `yaml:"Databases"`
+}
+
 func NewConfig(osArgs []string) (*Config, error) {
 	var c Config
 
@@ -70,22 +257,102 @@ func NewConfig(osArgs []string) (*Config, error) {
 
 	serviceConfig.AddFlags(flags)
 	serviceConfig.AddDefaults(Config{
+		PidFileWriteAttempts:  3,
+		LogFileMaxBackups:     5,
+		LogFileDirMode:        "0750",
+		LogFileDirWaitTimeout: 30,
 		Db: DBHelper{
-			User: "root",
+			BootstrapCommand:     "mysqld",
+			ConnectTimeout:       5,
+			DataDir:              "/var/vcap/store/pxc-mysql",
+			JoinCommand:          "mysqld",
+			ManageReadOnlyUser:   true,
+			MysqldOutput:         MysqldOutputErrorLog,
+			ProcessManager:       ProcessManagerDirect,
+			ReadOnlyUserHost:     "%",
+			SeedConnectAttempts:  5,
+			SSTDefaultsFile:      "/var/vcap/sys/run/pxc-mysql/galera-init-sst.cnf",
+			SteadyStateProtocol:  SteadyStateProtocolSocket,
+			StopProtocol:         StopProtocolSocket,
+			StopTimeout:          60,
+			TCPAddress:           "127.0.0.1:3306",
+			TCPKeepAliveCount:    3,
+			TCPKeepAliveInterval: 15,
+			User:                 "root",
 		},
 		Manager: StartManager{
-			GrastateFileLocation: "/var/vcap/store/pxc-mysql/grastate.dat",
+			GrastateFileLocation:      "/var/vcap/store/pxc-mysql/grastate.dat",
+			HealthBindAddress:         "127.0.0.1",
+			SyncStateFile:             true,
+			InconsistencyPolicy:       InconsistencyPolicyFail,
+			BootstrapFailurePolicy:    BootstrapFailurePolicyExit,
+			SeedFailurePolicy:         SeedFailurePolicyLeaveRunning,
+			MaxBootstrapAttempts:      3,
+			MaxDatabaseSeedTries:      3,
+			JoinProgressLogInterval:   30,
+			MaxJoinAttempts:           1,
+			MaxMaintenanceDuration:    14400,
+			MinPeersWaitTimeout:       300,
+			HistorySize:               50,
+			MembershipCheckPolicy:     MembershipCheckPolicyOff,
+			LeaveDrainTimeout:         30,
+			ShutdownQueueDrainTimeout: 30,
+			ShutdownSignal:            ShutdownSignalTerm,
+			ShutdownKillTimeout:       120,
+			ZeroGrastateUUIDPolicy:    ZeroGrastateUUIDPolicyIgnore,
+			EmptyDatadirPolicy:        EmptyDatadirPolicyJoin,
+			UnreadableStateFilePolicy: UnreadableStateFilePolicyFail,
+		},
+		Upgrader: Upgrader{
+			UpgradeMaxRetries:        3,
+			VersionFileWriteAttempts: 3,
+			Timing:                   UpgradeTimingPreStart,
 		},
 	})
 	flags.Parse(configurationOptions)
 
 	err := serviceConfig.Read(&c)
+	if configBytes, readErr := serviceConfig.ConfigBytes(); readErr == nil {
+		c.Source = ReadSource(flags, configBytes)
+	}
 
-	c.Logger, _ = lagerflags.NewFromConfig(binaryName, lagerflags.ConfigFromFlags())
+	lagerConfig := lagerflags.ConfigFromFlags()
+	if isValidLogLevel(c.LogLevel) {
+		lagerConfig.LogLevel = c.LogLevel
+	}
+
+	if c.LogFormat == LogFormatText {
+		c.Logger = newTextLogger(binaryName, lagerConfig.LogLevel)
+	} else {
+		c.Logger, _ = lagerflags.NewFromConfig(binaryName, lagerConfig)
+	}
+
+	if c.DeployID != "" {
+		c.Logger = c.Logger.WithData(lager.Data{"deploy_id": c.DeployID})
+	}
 
 	return &c, err
 }
 
+func newTextLogger(component string, logLevel string) lager.Logger {
+	minLogLevel, err := lager.LogLevelFromString(logLevel)
+	if err != nil {
+		minLogLevel = lager.INFO
+	}
+
+	logger := lager.NewLogger(component)
+	logger.RegisterSink(lager.NewReconfigurableSink(NewTextSink(os.Stdout), minLogLevel))
+	return logger
+}
+
+func isValidLogLevel(logLevel string) bool {
+	switch logLevel {
+	case lagerflags.DEBUG, lagerflags.INFO, lagerflags.ERROR, lagerflags.FATAL:
+		return true
+	}
+	return false
+}
+
 func (c Config) Validate() error {
 	errString := ""
 	err := validator.Validate(c)
@@ -104,6 +371,187 @@ func (c Config) Validate() error {
 		}
 	}
 
+	for i, user := range c.Db.ReadOnlyUsers {
+		userErr := validator.Validate(user)
+		if userErr != nil {
+			errString += formatErrorString(
+				userErr,
+				fmt.Sprintf("Db.ReadOnlyUsers[%d].", i),
+			)
+		}
+	}
+
+	if c.LogLevel != "" && !isValidLogLevel(c.LogLevel) {
+		errString += fmt.Sprintf("LogLevel : must be one of debug, info, error or fatal, got '%s'\n", c.LogLevel)
+	}
+
+	if c.LogFileDirMode != "" {
+		if _, err := strconv.ParseUint(c.LogFileDirMode, 8, 32); err != nil {
+			errString += fmt.Sprintf("LogFileDirMode : must be an octal file mode such as 0750, got '%s'\n", c.LogFileDirMode)
+		}
+	}
+
+	switch c.LogFormat {
+	case "", LogFormatJSON, LogFormatText:
+	default:
+		errString += fmt.Sprintf("LogFormat : must be one of json or text, got '%s'\n", c.LogFormat)
+	}
+
+	if c.Db.ReadOnlyUser != "" && c.Db.ReadOnlyPassword == "" {
+		errString += "Db.ReadOnlyPassword : must be set when Db.ReadOnlyUser is configured\n"
+	}
+
+	for i, pattern := range c.Db.FatalErrorLogPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errString += fmt.Sprintf("Db.FatalErrorLogPatterns[%d] : invalid regular expression: %s\n", i, err)
+		}
+	}
+
+	if (c.Db.SSTUser == "") != (c.Db.SSTPassword == "") {
+		errString += "Db.SSTUser and Db.SSTPassword : must be set together\n"
+	}
+
+	if SSTMethodNeedsAuth(c.Db.SSTMethod) && c.Db.SSTUser == "" {
+		errString += fmt.Sprintf("Db.SSTUser and Db.SSTPassword : must be set when Db.SSTMethod is %s\n", c.Db.SSTMethod)
+	}
+
+	if c.Db.SSTUser != "" && c.Db.SSTDefaultsFile == "" {
+		errString += "Db.SSTDefaultsFile : must be set when Db.SSTUser is configured\n"
+	}
+
+	switch c.Db.ProcessManager {
+	case "", ProcessManagerDirect:
+	default:
+		errString += fmt.Sprintf("Db.ProcessManager : must be direct, got '%s'\n", c.Db.ProcessManager)
+	}
+
+	switch c.Db.MysqldOutput {
+	case "", MysqldOutputErrorLog, MysqldOutputGaleraInit:
+	case MysqldOutputFile:
+		if c.Db.MysqldOutputFile == "" {
+			errString += "Db.MysqldOutputFile : must be set when Db.MysqldOutput is file\n"
+		}
+	default:
+		errString += fmt.Sprintf("Db.MysqldOutput : must be error-log, galera-init or file, got '%s'\n", c.Db.MysqldOutput)
+	}
+
+	switch c.Upgrader.Timing {
+	case "", UpgradeTimingPreStart, UpgradeTimingPostStart:
+	default:
+		errString += fmt.Sprintf("Upgrader.Timing : must be pre-start or post-start, got '%s'\n", c.Upgrader.Timing)
+	}
+
+	switch c.Db.StopProtocol {
+	case "", StopProtocolSocket, StopProtocolTCP:
+	default:
+		errString += fmt.Sprintf("Db.StopProtocol : must be socket or tcp, got '%s'\n", c.Db.StopProtocol)
+	}
+
+	switch c.Db.SteadyStateProtocol {
+	case "", SteadyStateProtocolSocket, SteadyStateProtocolTCP:
+	default:
+		errString += fmt.Sprintf("Db.SteadyStateProtocol : must be socket or tcp, got '%s'\n", c.Db.SteadyStateProtocol)
+	}
+
+	if c.Db.TCPAddress != "" {
+		if _, _, err := net.SplitHostPort(c.Db.TCPAddress); err != nil {
+			errString += fmt.Sprintf("Db.TCPAddress : must be formatted as host:port, %s\n", err)
+		}
+	}
+
+	if c.Db.WsrepNodeAddress != "" {
+		if _, _, err := net.SplitHostPort(c.Db.WsrepNodeAddress); err != nil {
+			errString += fmt.Sprintf("Db.WsrepNodeAddress : must be formatted as host:port, %s\n", err)
+		}
+	}
+
+	if c.Manager.StartupCooldown > 0 && c.Manager.LastFailureFileLocation == "" {
+		errString += "Manager.LastFailureFileLocation : must be set when Manager.StartupCooldown is configured\n"
+	}
+
+	if c.Manager.FirstBootSQLFile != "" && c.Manager.FirstBootMarkerFile == "" {
+		errString += "Manager.FirstBootMarkerFile : must be set when Manager.FirstBootSQLFile is configured\n"
+	}
+
+	if c.Manager.NeverBootstrap && c.Manager.BootstrapNode {
+		errString += "Manager.NeverBootstrap : cannot be set on the bootstrap node\n"
+	}
+
+	if _, _, err := net.SplitHostPort(c.Manager.GaleraInitStatusServerAddress); c.Manager.GaleraInitStatusServerAddress != "" && err != nil {
+		errString += fmt.Sprintf("Manager.GaleraInitStatusServerAddress : %s\n", err)
+	}
+
+	if len(c.Maintenance.Statements) > 0 {
+		if _, err := time.Parse(MaintenanceWindowLayout, c.Maintenance.WindowStart); err != nil {
+			errString += fmt.Sprintf("Maintenance.WindowStart : must be a time of day formatted as HH:MM, got '%s'\n", c.Maintenance.WindowStart)
+		}
+		if c.Maintenance.WindowDurationMinutes <= 0 {
+			errString += "Maintenance.WindowDurationMinutes : must be positive when Maintenance.Statements are configured\n"
+		}
+	}
+
+	switch c.Manager.InconsistencyPolicy {
+	case "", InconsistencyPolicyFail, InconsistencyPolicyResync:
+	default:
+		errString += fmt.Sprintf("Manager.InconsistencyPolicy : must be one of fail or resync, got '%s'\n", c.Manager.InconsistencyPolicy)
+	}
+
+	switch c.Manager.BootstrapFailurePolicy {
+	case "", BootstrapFailurePolicyExit, BootstrapFailurePolicyRetry:
+	default:
+		errString += fmt.Sprintf("Manager.BootstrapFailurePolicy : must be one of exit or retry, got '%s'\n", c.Manager.BootstrapFailurePolicy)
+	}
+
+	switch c.Manager.SeedFailurePolicy {
+	case "", SeedFailurePolicyLeaveRunning, SeedFailurePolicyStop:
+	default:
+		errString += fmt.Sprintf("Manager.SeedFailurePolicy : must be one of leave-running or stop, got '%s'\n", c.Manager.SeedFailurePolicy)
+	}
+
+	switch c.Manager.MembershipCheckPolicy {
+	case "", MembershipCheckPolicyOff, MembershipCheckPolicyWarn, MembershipCheckPolicyFail:
+	default:
+		errString += fmt.Sprintf("Manager.MembershipCheckPolicy : must be one of off, warn or fail, got '%s'\n", c.Manager.MembershipCheckPolicy)
+	}
+
+	switch c.Manager.ZeroGrastateUUIDPolicy {
+	case "", ZeroGrastateUUIDPolicyIgnore, ZeroGrastateUUIDPolicyFreshStart:
+	default:
+		errString += fmt.Sprintf("Manager.ZeroGrastateUUIDPolicy : must be one of ignore or fresh-start, got '%s'\n", c.Manager.ZeroGrastateUUIDPolicy)
+	}
+
+	switch c.Manager.EmptyDatadirPolicy {
+	case "", EmptyDatadirPolicyJoin, EmptyDatadirPolicyIgnore:
+	default:
+		errString += fmt.Sprintf("Manager.EmptyDatadirPolicy : must be one of join or ignore, got '%s'\n", c.Manager.EmptyDatadirPolicy)
+	}
+
+	switch c.Manager.UnreadableStateFilePolicy {
+	case "", UnreadableStateFilePolicyFail, UnreadableStateFilePolicyFreshStart:
+	default:
+		errString += fmt.Sprintf("Manager.UnreadableStateFilePolicy : must be one of fail or fresh-start, got '%s'\n", c.Manager.UnreadableStateFilePolicy)
+	}
+
+	switch c.Manager.ShutdownSignal {
+	case "", ShutdownSignalTerm, ShutdownSignalKill:
+	default:
+		errString += fmt.Sprintf("Manager.ShutdownSignal : must be one of SIGTERM or SIGKILL, got '%s'\n", c.Manager.ShutdownSignal)
+	}
+
+	if peerStatusReaders := c.Manager.peerStatusReaders(); len(peerStatusReaders) > 0 && c.Manager.statusServerOnLoopback() {
+		errString += fmt.Sprintf("Manager.HealthBindAddress : must be reachable from the other nodes when %s read their /status, got a loopback address\n", strings.Join(peerStatusReaders, ", "))
+	}
+
+	for index := range c.Manager.MaxDatabaseSeedTriesByIndex {
+		if index < 0 || index >= len(c.Manager.ClusterIps) {
+			errString += fmt.Sprintf("Manager.MaxDatabaseSeedTriesByIndex : index %d is not within the %d nodes in ClusterIps\n", index, len(c.Manager.ClusterIps))
+		}
+	}
+
+	if c.Manager.ExpectedClusterUUID != "" && !uuidPattern.MatchString(c.Manager.ExpectedClusterUUID) {
+		errString += fmt.Sprintf("Manager.ExpectedClusterUUID : must be a UUID, got '%s'\n", c.Manager.ExpectedClusterUUID)
+	}
+
 	if len(errString) > 0 {
 		return errors.New(fmt.Sprintf("Validation errors: %s\n", errString))
 	}
@@ -111,6 +559,107 @@ func (c Config) Validate() error {
 	return nil
 }
 
+// DatabaseSeedTries is the MaxDatabaseSeedTriesByIndex entry for this node's
+// JobIndex, falling back to MaxDatabaseSeedTries.
+func (m StartManager) DatabaseSeedTries() int {
+	if tries, ok := m.MaxDatabaseSeedTriesByIndex[m.JobIndex]; ok {
+		return tries
+	}
+	return m.MaxDatabaseSeedTries
+}
+
+// The enabled features that read the peers' /status, which a status server
+// listening on loopback would hide from them.
+func (m StartManager) peerStatusReaders() []string {
+	var readers []string
+	if m.JoinWaitForPrimaryTimeout > 0 {
+		readers = append(readers, "Manager.JoinWaitForPrimaryTimeout")
+	}
+	if m.ClockSkewWarningThreshold > 0 {
+		readers = append(readers, "Manager.ClockSkewWarningThreshold")
+	}
+	if m.CheckServerIdentities {
+		readers = append(readers, "Manager.CheckServerIdentities")
+	}
+	if m.CheckMaxAllowedPacket {
+		readers = append(readers, "Manager.CheckMaxAllowedPacket")
+	}
+	return readers
+}
+
+func (m StartManager) statusServerOnLoopback() bool {
+	listenAddress, err := m.StatusServerListenAddress()
+	if err != nil {
+		return false
+	}
+
+	host, _, _ := net.SplitHostPort(listenAddress)
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// StatusServerListenAddress combines the port from GaleraInitStatusServerAddress
+// with HealthBindAddress when it is set, so an operator can move the status
+// server to another interface without changing the advertised address.
+func (m StartManager) StatusServerListenAddress() (string, error) {
+	host, port, err := net.SplitHostPort(m.GaleraInitStatusServerAddress)
+	if err != nil {
+		return "", err
+	}
+
+	if m.HealthBindAddress != "" {
+		host = m.HealthBindAddress
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+const redacted = "<redacted>"
+
+// Redacted returns a copy of the config that is safe to log, with every
+// password replaced by a placeholder.
+func (c Config) Redacted() Config {
+	r := c
+
+	r.Db.Password = redactString(c.Db.Password)
+	r.Db.ReadOnlyPassword = redactString(c.Db.ReadOnlyPassword)
+	r.Db.SSTPassword = redactString(c.Db.SSTPassword)
+	r.Manager.RotateCredentialsToken = redactString(c.Manager.RotateCredentialsToken)
+	r.Manager.ReseedToken = redactString(c.Manager.ReseedToken)
+	r.Manager.PromoteToken = redactString(c.Manager.PromoteToken)
+	r.Manager.MaintenanceToken = redactString(c.Manager.MaintenanceToken)
+
+	r.Db.PreseededDatabases = make([]PreseededDatabase, len(c.Db.PreseededDatabases))
+	for i, db := range c.Db.PreseededDatabases {
+		db.Password = redactString(db.Password)
+		r.Db.PreseededDatabases[i] = db
+	}
+
+	r.Db.ReadOnlyUsers = make([]ReadOnlyUser, len(c.Db.ReadOnlyUsers))
+	for i, user := range c.Db.ReadOnlyUsers {
+		user.Password = redactString(user.Password)
+		r.Db.ReadOnlyUsers[i] = user
+	}
+
+	r.Db.SeededUsers = make([]SeededUser, len(c.Db.SeededUsers))
+	for i, user := range c.Db.SeededUsers {
+		user.Password = redactString(user.Password)
+		r.Db.SeededUsers[i] = user
+	}
+
+	return r
+}
+
+func redactString(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
 func formatErrorString(err error, keyPrefix string) string {
 	errs := err.(validator.ErrorMap)
 	var errsString string
@@ -1,6 +1,7 @@
 package config_test
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -21,6 +22,7 @@ var _ = Describe("Config", func() {
 		var serviceConfig *service_config.ServiceConfig
 
 		BeforeEach(func() {
+			rootConfig = config.Config{}
 			serviceConfig = service_config.New()
 			flags := flag.NewFlagSet("galera-init", flag.ExitOnError)
 			serviceConfig.AddFlags(flags)
@@ -104,25 +106,471 @@ var _ = Describe("Config", func() {
 
 		Describe("Config", func() {
 			It("returns an error if LogFileLocation is blank", isRequiredField("LogFileLocation"))
+			It("does not return an error if LogLevel is blank", isOptionalField("LogLevel"))
+
+			It("returns an error if LogLevel is not a known level", func() {
+				rootConfig.LogLevel = "verbose"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("LogLevel"))
+			})
+
+			It("does not return an error if LogFormat is blank", isOptionalField("LogFormat"))
+			It("does not return an error if DeployID is blank", isOptionalField("DeployID"))
+			It("does not return an error if PidFileWriteAttempts is blank", isOptionalField("PidFileWriteAttempts"))
+			It("does not return an error if ClassifiedExitCodes is blank", isOptionalField("ClassifiedExitCodes"))
+			It("does not return an error if LogFileMaxSizeMB is blank", isOptionalField("LogFileMaxSizeMB"))
+			It("does not return an error if LogFileMaxBackups is blank", isOptionalField("LogFileMaxBackups"))
+			It("does not return an error if LogFileDirMode is blank", isOptionalField("LogFileDirMode"))
+			It("does not return an error if LogFileDirWaitTimeout is blank", isOptionalField("LogFileDirWaitTimeout"))
+
+			It("returns an error if LogFileDirMode is not an octal file mode", func() {
+				rootConfig.LogFileDirMode = "rwxr-x---"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("LogFileDirMode"))
+			})
+
+			It("returns an error if LogFormat is not a known format", func() {
+				rootConfig.LogFormat = "xml"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("LogFormat"))
+			})
 		})
 
 		Describe("Upgrader", func() {
 			It("returns an error if Upgrader.PackageVersionFile is blank", isRequiredField("Upgrader.PackageVersionFile"))
 			It("returns an error if Upgrader.LastUpgradedVersionFile is blank", isRequiredField("Upgrader.LastUpgradedVersionFile"))
+			It("does not return an error if Upgrader.Timing is blank", isOptionalField("Upgrader.Timing"))
+			It("does not return an error if Upgrader.VersionFileWriteAttempts is blank", isOptionalField("Upgrader.VersionFileWriteAttempts"))
+
+			It("returns an error if Upgrader.Timing is not pre-start or post-start", func() {
+				rootConfig.Upgrader.Timing = "never"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Upgrader.Timing"))
+			})
 		})
 
 		Describe("StartManager", func() {
 			It("returns an error if Manager.StateFileLocation is blank", isRequiredField("Manager.StateFileLocation"))
+			It("does not return an error if Manager.SyncStateFile is blank", isOptionalField("Manager.SyncStateFile"))
+			It("does not return an error if Db.ManageReadOnlyUser is blank", isOptionalField("Db.ManageReadOnlyUser"))
+			It("does not return an error if Db.FatalErrorLogPatterns is blank", isOptionalField("Db.FatalErrorLogPatterns"))
 			It("returns an error if Manager.ClusterIps is blank", isRequiredField("Manager.ClusterIps"))
 			It("returns an error if Manager.ClusterProbeTimeout is blank", isRequiredField("Manager.ClusterProbeTimeout"))
+			It("returns an error if Manager.NeverBootstrap is set on the bootstrap node", func() {
+				rootConfig.Manager.BootstrapNode = true
+				rootConfig.Manager.NeverBootstrap = true
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("NeverBootstrap"))
+			})
+
+			It("does not return an error if Manager.InconsistencyPolicy is blank", isOptionalField("Manager.InconsistencyPolicy"))
+			It("does not return an error if Manager.BootstrapFailurePolicy is blank", isOptionalField("Manager.BootstrapFailurePolicy"))
+			It("does not return an error if Manager.SeedFailurePolicy is blank", isOptionalField("Manager.SeedFailurePolicy"))
+			It("does not return an error if Manager.MembershipCheckPolicy is blank", isOptionalField("Manager.MembershipCheckPolicy"))
+			It("does not return an error if Manager.ExpectedClusterUUID is blank", isOptionalField("Manager.ExpectedClusterUUID"))
+			It("does not return an error if Manager.SeedReplicationTimeout is blank", isOptionalField("Manager.SeedReplicationTimeout"))
+			It("does not return an error if Manager.SeedTimeout is blank", isOptionalField("Manager.SeedTimeout"))
+			It("does not return an error if Manager.DonorRejectsQueries is blank", isOptionalField("Manager.DonorRejectsQueries"))
+			It("does not return an error if Manager.ZeroGrastateUUIDPolicy is blank", isOptionalField("Manager.ZeroGrastateUUIDPolicy"))
+			It("does not return an error if Manager.RecoveryBackupDir is blank", isOptionalField("Manager.RecoveryBackupDir"))
+
+			It("does not return an error if Manager.EmptyDatadirPolicy is blank", isOptionalField("Manager.EmptyDatadirPolicy"))
+
+			It("returns an error if Manager.EmptyDatadirPolicy is not a known policy", func() {
+				rootConfig.Manager.EmptyDatadirPolicy = "bootstrap"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("EmptyDatadirPolicy"))
+			})
+			It("does not return an error if Manager.UnreadableStateFilePolicy is blank", isOptionalField("Manager.UnreadableStateFilePolicy"))
+
+			It("returns an error if Manager.UnreadableStateFilePolicy is not a known policy", func() {
+				rootConfig.Manager.UnreadableStateFilePolicy = "ignore"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("UnreadableStateFilePolicy"))
+			})
+
+			It("returns an error if Manager.ZeroGrastateUUIDPolicy is not a known policy", func() {
+				rootConfig.Manager.ZeroGrastateUUIDPolicy = "recover"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("ZeroGrastateUUIDPolicy"))
+			})
+
+			It("returns an error if Manager.ExpectedClusterUUID is not a UUID", func() {
+				rootConfig.Manager.ExpectedClusterUUID = "not-a-uuid"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("ExpectedClusterUUID"))
+			})
+
+			It("returns an error if Manager.MembershipCheckPolicy is not a known policy", func() {
+				rootConfig.Manager.MembershipCheckPolicy = "strict"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("MembershipCheckPolicy"))
+			})
+			It("does not return an error if Manager.MaxBootstrapAttempts is blank", isOptionalField("Manager.MaxBootstrapAttempts"))
+
+			It("returns an error if Manager.BootstrapFailurePolicy is not a known policy", func() {
+				rootConfig.Manager.BootstrapFailurePolicy = "loop"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("BootstrapFailurePolicy"))
+			})
+			It("returns an error if Manager.SeedFailurePolicy is not a known policy", func() {
+				rootConfig.Manager.SeedFailurePolicy = "retry"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("SeedFailurePolicy"))
+			})
+			It("does not return an error if Manager.JoinProgressLogInterval is blank", isOptionalField("Manager.JoinProgressLogInterval"))
+
+			It("returns an error if Manager.InconsistencyPolicy is not a known policy", func() {
+				rootConfig.Manager.InconsistencyPolicy = "ignore"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("InconsistencyPolicy"))
+			})
+
+			It("does not return an error if Manager.HealthBindAddress is blank", isOptionalField("Manager.HealthBindAddress"))
+
+			Context("when the status server listens on loopback", func() {
+				BeforeEach(func() {
+					rootConfig.Manager.HealthBindAddress = "127.0.0.1"
+					rootConfig.Manager.JoinWaitForPrimaryTimeout = 0
+					rootConfig.Manager.ClockSkewWarningThreshold = 0
+					rootConfig.Manager.CheckServerIdentities = false
+					rootConfig.Manager.CheckMaxAllowedPacket = false
+				})
+
+				It("does not return an error when nothing reads the peers' /status", func() {
+					Expect(rootConfig.Validate()).To(Succeed())
+				})
+
+				It("returns an error when Manager.JoinWaitForPrimaryTimeout reads the peers' /status", func() {
+					rootConfig.Manager.JoinWaitForPrimaryTimeout = 300
+
+					err := rootConfig.Validate()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Manager.HealthBindAddress : must be reachable from the other nodes when Manager.JoinWaitForPrimaryTimeout"))
+				})
+
+				It("returns an error when Manager.ClockSkewWarningThreshold reads the peers' /status", func() {
+					rootConfig.Manager.ClockSkewWarningThreshold = 5
+
+					err := rootConfig.Validate()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Manager.HealthBindAddress : must be reachable from the other nodes when Manager.ClockSkewWarningThreshold"))
+				})
+
+				It("returns an error when Manager.CheckServerIdentities reads the peers' /status", func() {
+					rootConfig.Manager.CheckServerIdentities = true
+
+					err := rootConfig.Validate()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Manager.HealthBindAddress : must be reachable from the other nodes when Manager.CheckServerIdentities"))
+				})
+
+				It("returns an error when Manager.CheckMaxAllowedPacket reads the peers' /status", func() {
+					rootConfig.Manager.CheckMaxAllowedPacket = true
+
+					err := rootConfig.Validate()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Manager.HealthBindAddress : must be reachable from the other nodes when Manager.CheckMaxAllowedPacket"))
+				})
+
+				It("treats a blank HealthBindAddress as the host of GaleraInitStatusServerAddress", func() {
+					rootConfig.Manager.HealthBindAddress = ""
+					rootConfig.Manager.GaleraInitStatusServerAddress = "localhost:8999"
+					rootConfig.Manager.CheckServerIdentities = true
+
+					err := rootConfig.Validate()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Manager.HealthBindAddress"))
+				})
+			})
+			It("does not return an error if Manager.ReadyFileLocation is blank", isOptionalField("Manager.ReadyFileLocation"))
+			It("does not return an error if Manager.PreStartHealthCheckScript is blank", isOptionalField("Manager.PreStartHealthCheckScript"))
+			It("does not return an error if Manager.MysqlPort is blank", isOptionalField("Manager.MysqlPort"))
+			It("does not return an error if Manager.ReadinessProbeScript is blank", isOptionalField("Manager.ReadinessProbeScript"))
+			It("does not return an error if Manager.StartupCooldown is blank", isOptionalField("Manager.StartupCooldown"))
+			It("does not return an error if Manager.RefuseEvenClusterSize is blank", isOptionalField("Manager.RefuseEvenClusterSize"))
+
+			It("returns an error if Manager.StartupCooldown is set without Manager.LastFailureFileLocation", func() {
+				rootConfig.Manager.StartupCooldown = 60
+				rootConfig.Manager.LastFailureFileLocation = ""
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("LastFailureFileLocation"))
+			})
+			It("does not return an error if Manager.FirstBootSQLFile is blank", isOptionalField("Manager.FirstBootSQLFile"))
+
+			It("returns an error if Manager.FirstBootSQLFile is set without Manager.FirstBootMarkerFile", func() {
+				rootConfig.Manager.FirstBootMarkerFile = ""
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Manager.FirstBootMarkerFile : must be set"))
+			})
+			It("does not return an error if Manager.HistorySize is blank", isOptionalField("Manager.HistorySize"))
+			It("does not return an error if Manager.DegradedClusterWarningAfter is blank", isOptionalField("Manager.DegradedClusterWarningAfter"))
+			It("does not return an error if Manager.DegradedClusterFailsHealth is blank", isOptionalField("Manager.DegradedClusterFailsHealth"))
+			It("does not return an error if Manager.RecoveryAlertAfter is blank", isOptionalField("Manager.RecoveryAlertAfter"))
+			It("does not return an error if Manager.ExitWhenStuckInRecovery is blank", isOptionalField("Manager.ExitWhenStuckInRecovery"))
+			It("does not return an error if Manager.MaintenanceToken is blank", isOptionalField("Manager.MaintenanceToken"))
+			It("does not return an error if Manager.MaxMaintenanceDuration is blank", isOptionalField("Manager.MaxMaintenanceDuration"))
+			It("does not return an error if Manager.UserReconcileInterval is blank", isOptionalField("Manager.UserReconcileInterval"))
+			It("does not return an error if Manager.ClockSkewWarningThreshold is blank", isOptionalField("Manager.ClockSkewWarningThreshold"))
+			It("does not return an error if Manager.CheckServerIdentities is blank", isOptionalField("Manager.CheckServerIdentities"))
+			It("does not return an error if Manager.CheckMaxAllowedPacket is blank", isOptionalField("Manager.CheckMaxAllowedPacket"))
+			It("does not return an error if Manager.MaxAllowedPacket is blank", isOptionalField("Manager.MaxAllowedPacket"))
+			It("does not return an error if Manager.ClusterIpsFile is blank", isOptionalField("Manager.ClusterIpsFile"))
+			It("does not return an error if Manager.RotateCredentialsToken is blank", isOptionalField("Manager.RotateCredentialsToken"))
+			It("does not return an error if Manager.ReseedToken is blank", isOptionalField("Manager.ReseedToken"))
+			It("does not return an error if Manager.MaxDatabaseSeedTries is blank", isOptionalField("Manager.MaxDatabaseSeedTries"))
+			It("does not return an error if Manager.MaxDatabaseSeedTriesByIndex is blank", isOptionalField("Manager.MaxDatabaseSeedTriesByIndex"))
+			It("does not return an error if Manager.JobIndex is blank", isOptionalField("Manager.JobIndex"))
+
+			It("returns an error if a Manager.MaxDatabaseSeedTriesByIndex index is not within ClusterIps", func() {
+				rootConfig.Manager.MaxDatabaseSeedTriesByIndex = map[int]int{3: 5}
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Manager.MaxDatabaseSeedTriesByIndex : index 3 is not within the 3 nodes in ClusterIps"))
+			})
+
+			It("returns an error if a Manager.MaxDatabaseSeedTriesByIndex index is negative", func() {
+				rootConfig.Manager.MaxDatabaseSeedTriesByIndex = map[int]int{-1: 5}
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("index -1 is not within"))
+			})
+			It("does not return an error if Manager.WarmStandby is blank", isOptionalField("Manager.WarmStandby"))
+			It("does not return an error if Manager.PromoteToken is blank", isOptionalField("Manager.PromoteToken"))
+			It("does not return an error if Manager.LeaveDrainTimeout is blank", isOptionalField("Manager.LeaveDrainTimeout"))
+			It("does not return an error if Manager.ShutdownQueueDrainTimeout is blank", isOptionalField("Manager.ShutdownQueueDrainTimeout"))
+			It("does not return an error if Manager.ShutdownSignal is blank", isOptionalField("Manager.ShutdownSignal"))
+			It("does not return an error if Manager.ShutdownKillTimeout is blank", isOptionalField("Manager.ShutdownKillTimeout"))
+
+			It("returns an error if Manager.ShutdownSignal is not a known signal", func() {
+				rootConfig.Manager.ShutdownSignal = "SIGHUP"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("ShutdownSignal"))
+			})
+			It("does not return an error if Manager.MaxJoinAttempts is blank", isOptionalField("Manager.MaxJoinAttempts"))
+			It("does not return an error if Manager.JoinWaitForPrimaryTimeout is blank", isOptionalField("Manager.JoinWaitForPrimaryTimeout"))
+			It("does not return an error if Manager.MinPeersBeforeJoin is blank", isOptionalField("Manager.MinPeersBeforeJoin"))
+			It("does not return an error if Manager.MinPeersWaitTimeout is blank", isOptionalField("Manager.MinPeersWaitTimeout"))
+			It("does not return an error if Manager.SeedOnlyOnBootstrap is blank", isOptionalField("Manager.SeedOnlyOnBootstrap"))
+
+			It("returns an error if Manager.GaleraInitStatusServerAddress has no port", func() {
+				rootConfig.Manager.GaleraInitStatusServerAddress = "127.0.0.1"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("GaleraInitStatusServerAddress"))
+			})
+		})
+
+		Describe("Maintenance", func() {
+			It("does not return an error if Maintenance.Statements is blank", isOptionalField("Maintenance.Statements"))
+
+			It("returns an error if Maintenance.WindowStart is not a time of day", func() {
+				rootConfig.Maintenance.WindowStart = "3am"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Maintenance.WindowStart"))
+			})
+
+			It("returns an error if Maintenance.WindowDurationMinutes is not positive", func() {
+				rootConfig.Maintenance.WindowDurationMinutes = 0
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Maintenance.WindowDurationMinutes"))
+			})
+
+			It("does not validate the window when there are no statements", func() {
+				rootConfig.Maintenance = config.Maintenance{}
+
+				Expect(rootConfig.Validate()).To(Succeed())
+			})
 		})
 
 		Describe("DBHelper", func() {
 			It("returns an error if Db.UpgradePath is blank", isRequiredField("Db.UpgradePath"))
 			It("returns an error if Db.User is blank", isRequiredField("Db.User"))
+			It("returns an error if Db.BootstrapCommand is blank", isRequiredField("Db.BootstrapCommand"))
+			It("returns an error if Db.JoinCommand is blank", isRequiredField("Db.JoinCommand"))
 
 			It("does not return an error if Db.Password is blank", isOptionalField("Db.Password"))
+			It("does not return an error if Db.ConnectTimeout is blank", isOptionalField("Db.ConnectTimeout"))
+			It("does not return an error if Db.WsrepNodeName is blank", isOptionalField("Db.WsrepNodeName"))
+			It("does not return an error if Db.AllowedSeedDatabases is blank", isOptionalField("Db.AllowedSeedDatabases"))
+			It("does not return an error if Db.SeedConnectAttempts is blank", isOptionalField("Db.SeedConnectAttempts"))
+			It("does not return an error if Db.ProcessManager is blank", isOptionalField("Db.ProcessManager"))
+
+			It("returns an error if Db.ProcessManager is not a known process manager", func() {
+				rootConfig.Db.ProcessManager = "systemd"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("ProcessManager"))
+			})
+			It("does not return an error if Db.MysqldOutput is blank", isOptionalField("Db.MysqldOutput"))
+			It("does not return an error if Db.MysqldOutputFile is blank", isOptionalField("Db.MysqldOutputFile"))
+
+			It("returns an error if Db.MysqldOutput is not a known destination", func() {
+				rootConfig.Db.MysqldOutput = "syslog"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("MysqldOutput"))
+			})
+
+			It("returns an error if Db.MysqldOutput is file without Db.MysqldOutputFile", func() {
+				rootConfig.Db.MysqldOutput = config.MysqldOutputFile
+				rootConfig.Db.MysqldOutputFile = ""
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Db.MysqldOutputFile : must be set"))
+			})
+
+			It("does not return an error if Db.StopProtocol is blank", isOptionalField("Db.StopProtocol"))
+			It("does not return an error if Db.StopTimeout is blank", isOptionalField("Db.StopTimeout"))
+
+			It("returns an error if Db.StopProtocol is not socket or tcp", func() {
+				rootConfig.Db.StopProtocol = "pipe"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("StopProtocol"))
+			})
+			It("does not return an error if Db.SteadyStateProtocol is blank", isOptionalField("Db.SteadyStateProtocol"))
+			It("does not return an error if Db.TCPAddress is blank", isOptionalField("Db.TCPAddress"))
+			It("does not return an error if Db.TCPKeepAliveInterval is blank", isOptionalField("Db.TCPKeepAliveInterval"))
+			It("does not return an error if Db.TCPKeepAliveCount is blank", isOptionalField("Db.TCPKeepAliveCount"))
+
+			It("returns an error if Db.SteadyStateProtocol is not socket or tcp", func() {
+				rootConfig.Db.SteadyStateProtocol = "pipe"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("SteadyStateProtocol"))
+			})
+
+			It("returns an error if Db.TCPAddress is not host:port", func() {
+				rootConfig.Db.TCPAddress = "127.0.0.1"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Db.TCPAddress : must be formatted as host:port"))
+			})
+			It("does not return an error if Db.WsrepNodeAddress is blank", isOptionalField("Db.WsrepNodeAddress"))
+
+			It("returns an error if Db.WsrepNodeAddress is not host:port", func() {
+				rootConfig.Db.WsrepNodeAddress = "10.0.0.1"
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("WsrepNodeAddress"))
+			})
 			It("does not return an error if Db.PreseededDatabases is blank", isOptionalField("Db.PreseededDatabases"))
+			It("does not return an error if Db.ReadOnlyUser is blank", isOptionalField("Db.ReadOnlyUser"))
+
+			It("returns an error if Db.ReadOnlyPassword is blank when Db.ReadOnlyUser is set", func() {
+				rootConfig.Db.ReadOnlyUser = "read-only-user"
+				rootConfig.Db.ReadOnlyPassword = ""
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("ReadOnlyPassword"))
+			})
+
+			It("does not return an error if Db.SSTUser and Db.SSTPassword are blank and Db.SSTMethod needs no credentials", func() {
+				rootConfig.Db.SSTMethod = ""
+				rootConfig.Db.SSTUser = ""
+				rootConfig.Db.SSTPassword = ""
+
+				Expect(rootConfig.Validate()).To(Succeed())
+			})
+
+			It("returns an error if a Db.FatalErrorLogPatterns entry is not a valid regular expression", func() {
+				rootConfig.Db.FatalErrorLogPatterns = []string{"Tablespace .* is missing", "[unclosed"}
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Db.FatalErrorLogPatterns[1] : invalid regular expression"))
+			})
+
+			It("returns an error if only one of Db.SSTUser and Db.SSTPassword is set", func() {
+				rootConfig.Db.SSTPassword = ""
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Db.SSTUser and Db.SSTPassword : must be set together"))
+			})
+
+			It("does not return an error if Db.SSTMethod is blank", isOptionalField("Db.SSTMethod"))
+
+			It("returns an error if Db.SSTMethod needs credentials and Db.SSTUser is blank", func() {
+				rootConfig.Db.SSTMethod = "xtrabackup-v2"
+				rootConfig.Db.SSTUser = ""
+				rootConfig.Db.SSTPassword = ""
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Db.SSTUser and Db.SSTPassword : must be set when Db.SSTMethod is xtrabackup-v2"))
+			})
+
+			It("does not return an error if Db.SSTMethod needs no credentials and Db.SSTUser is blank", func() {
+				rootConfig.Db.SSTMethod = "rsync"
+				rootConfig.Db.SSTUser = ""
+				rootConfig.Db.SSTPassword = ""
+
+				Expect(rootConfig.Validate()).To(Succeed())
+			})
+
+			It("returns an error if Db.SSTDefaultsFile is blank when Db.SSTUser is set", func() {
+				rootConfig.Db.SSTDefaultsFile = ""
+
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Db.SSTDefaultsFile : must be set when Db.SSTUser is configured"))
+			})
+
+			Describe("ReadOnlyUsers", func() {
+				It("returns an error if Db.ReadOnlyUsers.User is blank", isRequiredField("Db.ReadOnlyUsers.User"))
+				It("returns an error if Db.ReadOnlyUsers.Password is blank", isRequiredField("Db.ReadOnlyUsers.Password"))
+				It("does not return an error if Db.ReadOnlyUsers.Host is blank", isOptionalField("Db.ReadOnlyUsers.Host"))
+				It("does not return an error if Db.ReadOnlyUsers.Databases is blank", isOptionalField("Db.ReadOnlyUsers.Databases"))
+			})
 
 			Describe("PreseededDatabase", func() {
 				It("returns an error if Db.PreseededDatabases.DBName is blank", isRequiredField("Db.PreseededDatabases.DBName"))
@@ -132,4 +580,168 @@ var _ = Describe("Config", func() {
 			})
 		})
 	})
+
+	Describe("ReadSource", func() {
+		var flags *flag.FlagSet
+
+		BeforeEach(func() {
+			flags = flag.NewFlagSet("galera-init", flag.ExitOnError)
+			service_config.New().AddFlags(flags)
+		})
+
+		It("reports the absolute path of a -configPath file", func() {
+			Expect(flags.Parse([]string{"-configPath=../example-config.yml"})).To(Succeed())
+
+			source := config.ReadSource(flags, []byte("Db:\n  User: root\n"))
+			Expect(source.Origin).To(Equal("-configPath"))
+			Expect(source.Path).To(HavePrefix("/"))
+			Expect(source.Path).To(HaveSuffix("/example-config.yml"))
+		})
+
+		It("reports an inline -config without a path", func() {
+			Expect(flags.Parse([]string{"-config=Db: {User: root}"})).To(Succeed())
+
+			source := config.ReadSource(flags, []byte("Db: {User: root}"))
+			Expect(source.Origin).To(Equal("-config"))
+			Expect(source.Path).To(BeEmpty())
+		})
+
+		It("reports which top-level sections are left entirely to defaults", func() {
+			source := config.ReadSource(flags, []byte("Db:\n  User: root\nManager:\n  ClusterIps: [1.1.1.1]\n"))
+
+			Expect(source.Sections).To(Equal(map[string]string{
+				"Db":          config.SectionConfigured,
+				"Manager":     config.SectionConfigured,
+				"Upgrader":    config.SectionDefaults,
+				"Maintenance": config.SectionDefaults,
+			}))
+		})
+	})
+
+	Describe("DatabaseSeedTries", func() {
+		var manager config.StartManager
+
+		BeforeEach(func() {
+			manager = config.StartManager{
+				MaxDatabaseSeedTries:        3,
+				MaxDatabaseSeedTriesByIndex: map[int]int{2: 5},
+			}
+		})
+
+		It("uses the override for this node's JobIndex", func() {
+			manager.JobIndex = 2
+			Expect(manager.DatabaseSeedTries()).To(Equal(5))
+		})
+
+		It("falls back to MaxDatabaseSeedTries for a node without an override", func() {
+			manager.JobIndex = 1
+			Expect(manager.DatabaseSeedTries()).To(Equal(3))
+		})
+	})
+
+	Describe("StatusServerListenAddress", func() {
+		It("binds the configured port to HealthBindAddress", func() {
+			manager := config.StartManager{
+				GaleraInitStatusServerAddress: "0.0.0.0:8999",
+				HealthBindAddress:             "127.0.0.1",
+			}
+
+			Expect(manager.StatusServerListenAddress()).To(Equal("127.0.0.1:8999"))
+		})
+
+		It("uses the host from GaleraInitStatusServerAddress when HealthBindAddress is blank", func() {
+			manager := config.StartManager{
+				GaleraInitStatusServerAddress: "10.0.0.1:8999",
+			}
+
+			Expect(manager.StatusServerListenAddress()).To(Equal("10.0.0.1:8999"))
+		})
+
+		It("returns an error when GaleraInitStatusServerAddress is malformed", func() {
+			manager := config.StartManager{
+				GaleraInitStatusServerAddress: "8999",
+				HealthBindAddress:             "127.0.0.1",
+			}
+
+			_, err := manager.StatusServerListenAddress()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Redacted", func() {
+		var cfg config.Config
+
+		BeforeEach(func() {
+			cfg = config.Config{
+				Db: config.DBHelper{
+					User:             "root",
+					Password:         "root-password",
+					ReadOnlyUser:     "reader",
+					ReadOnlyPassword: "reader-password",
+					SSTUser:          "sst",
+					SSTPassword:      "sst-password",
+					PreseededDatabases: []config.PreseededDatabase{
+						{DBName: "db1", User: "user1", Password: "db-password"},
+					},
+					SeededUsers: []config.SeededUser{
+						{User: "user2", Password: "user-password", Host: "any", Role: "admin"},
+					},
+					ReadOnlyUsers: []config.ReadOnlyUser{
+						{User: "reporting", Password: "reporting-password", Databases: []string{"orders"}},
+					},
+				},
+				Manager: config.StartManager{
+					RotateCredentialsToken: "rotate-token",
+					ReseedToken:            "reseed-token",
+					PromoteToken:           "promote-token",
+					MaintenanceToken:       "maintenance-token",
+				},
+			}
+		})
+
+		It("replaces every password with a placeholder", func() {
+			r := cfg.Redacted()
+
+			Expect(r.Db.User).To(Equal("root"))
+			Expect(r.Db.Password).To(Equal("<redacted>"))
+			Expect(r.Db.ReadOnlyPassword).To(Equal("<redacted>"))
+			Expect(r.Db.SSTUser).To(Equal("sst"))
+			Expect(r.Db.SSTPassword).To(Equal("<redacted>"))
+			Expect(r.Manager.RotateCredentialsToken).To(Equal("<redacted>"))
+			Expect(r.Manager.ReseedToken).To(Equal("<redacted>"))
+			Expect(r.Manager.PromoteToken).To(Equal("<redacted>"))
+			Expect(r.Manager.MaintenanceToken).To(Equal("<redacted>"))
+			Expect(r.Db.PreseededDatabases[0].DBName).To(Equal("db1"))
+			Expect(r.Db.PreseededDatabases[0].Password).To(Equal("<redacted>"))
+			Expect(r.Db.SeededUsers[0].User).To(Equal("user2"))
+			Expect(r.Db.SeededUsers[0].Password).To(Equal("<redacted>"))
+			Expect(r.Db.ReadOnlyUsers[0].User).To(Equal("reporting"))
+			Expect(r.Db.ReadOnlyUsers[0].Password).To(Equal("<redacted>"))
+		})
+
+		It("does not modify the original config", func() {
+			cfg.Redacted()
+
+			Expect(cfg.Db.Password).To(Equal("root-password"))
+			Expect(cfg.Db.PreseededDatabases[0].Password).To(Equal("db-password"))
+			Expect(cfg.Db.SeededUsers[0].Password).To(Equal("user-password"))
+			Expect(cfg.Db.ReadOnlyUsers[0].Password).To(Equal("reporting-password"))
+		})
+
+		It("leaves empty passwords empty", func() {
+			cfg.Db.Password = ""
+
+			Expect(cfg.Redacted().Db.Password).To(BeEmpty())
+		})
+
+		It("does not expose any password when marshalled for logging", func() {
+			logged, err := json.Marshal(cfg.Redacted())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(logged)).NotTo(ContainSubstring("root-password"))
+			Expect(string(logged)).NotTo(ContainSubstring("reader-password"))
+			Expect(string(logged)).NotTo(ContainSubstring("db-password"))
+			Expect(string(logged)).NotTo(ContainSubstring("user-password"))
+		})
+	})
 })
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pivotal-cf-experimental/service-config"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	SectionConfigured = "configured"
+	SectionDefaults   = "defaults"
+)
+
+// Source records where the config was read from, so that the config in
+// effect can be traced back to a file when several are in play.
+type Source struct {
+	// The -config or -configPath flag, or the CONFIG or CONFIG_PATH
+	// environment variable, in the order service-config checks them
+	Origin string
+	// The absolute path read, when the config came from a file
+	Path string
+	// For each top-level section, whether the config set it or it is
+	// entirely defaults
+	Sections map[string]string
+}
+
+// ReadSource determines which of the flags or environment variables the
+// config was read from, and which sections configBytes sets.
+func ReadSource(flags *flag.FlagSet, configBytes []byte) Source {
+	var source Source
+
+	switch {
+	case flagValue(flags, "config") != "":
+		source.Origin = "-config"
+	case flagValue(flags, "configPath") != "":
+		source.Origin = "-configPath"
+		source.Path = absolutePath(flagValue(flags, "configPath"))
+	case os.Getenv(service_config.ConfigEnvVar) != "":
+		source.Origin = service_config.ConfigEnvVar
+	case os.Getenv(service_config.ConfigPathEnvVar) != "":
+		source.Origin = service_config.ConfigPathEnvVar
+		source.Path = absolutePath(os.Getenv(service_config.ConfigPathEnvVar))
+	}
+
+	var raw map[string]interface{}
+	_ = yaml.Unmarshal(configBytes, &raw)
+
+	source.Sections = map[string]string{}
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		if field.Type.Kind() != reflect.Struct {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, ok := raw[name]; ok {
+			source.Sections[name] = SectionConfigured
+		} else {
+			source.Sections[name] = SectionDefaults
+		}
+	}
+
+	return source
+}
+
+func flagValue(flags *flag.FlagSet, name string) string {
+	if f := flags.Lookup(name); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+func absolutePath(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		return absolute
+	}
+	return path
+}
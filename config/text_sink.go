@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+type textSink struct {
+	writer io.Writer
+	writeL sync.Mutex
+}
+
+// NewTextSink returns a sink that writes one human-readable line per log
+// entry, e.g. `2020-03-10T12:00:00.000Z INFO galera-init.starting key=value`.
+func NewTextSink(writer io.Writer) lager.Sink {
+	return &textSink{writer: writer}
+}
+
+func (sink *textSink) Log(log lager.LogFormat) {
+	var line strings.Builder
+
+	fmt.Fprintf(&line, "%s %s %s",
+		time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		strings.ToUpper(log.LogLevel.String()),
+		log.Message,
+	)
+
+	keys := make([]string, 0, len(log.Data))
+	for key := range log.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := fmt.Sprint(log.Data[key])
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&line, " %s=%s", key, value)
+	}
+	line.WriteString("\n")
+
+	sink.writeL.Lock()
+	defer sink.writeL.Unlock()
+	io.WriteString(sink.writer, line.String())
+}
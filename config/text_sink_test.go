@@ -0,0 +1,37 @@
+package config_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/cloudfoundry/galera-init/config"
+)
+
+var _ = Describe("TextSink", func() {
+	var (
+		buffer *gbytes.Buffer
+		logger lager.Logger
+	)
+
+	BeforeEach(func() {
+		buffer = gbytes.NewBuffer()
+		logger = lager.NewLogger("galera-init")
+		logger.RegisterSink(config.NewTextSink(buffer))
+	})
+
+	It("writes the level, message and sorted data on one line", func() {
+		logger.Info("starting", lager.Data{"b": 2, "a": "one"})
+
+		Expect(buffer).To(gbytes.Say(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z INFO galera-init\.starting a=one b=2\n`))
+	})
+
+	It("quotes values that contain spaces", func() {
+		logger.Error("failed", errors.New("no such file"))
+
+		Expect(buffer).To(gbytes.Say(`ERROR galera-init\.failed error="no such file"\n`))
+	})
+})
@@ -1,10 +1,19 @@
 package db_helper
 
 import (
+	"bufio"
 	"database/sql"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"github.com/go-sql-driver/mysql"
@@ -22,34 +31,110 @@ type DBHelper interface {
 	StartMysqldInBootstrap() (*exec.Cmd, error)
 	StopMysqld()
 	Upgrade() (output string, err error)
+	InitializeDatadirIfNeeded() error
+	CheckDatadirWritable() error
+	CheckErrorLog() error
 	IsDatabaseReachable() bool
+	Ping() bool
+	IsNodeEvicted() bool
+	GetMaxConnections() (int, error)
+	GetBufferPoolSize() (uint64, error)
+	GetMaxAllowedPacket() (uint64, error)
+	SetMaxAllowedPacket(bytes uint64) error
+	GetWsrepStatus() (WsrepStatus, error)
+	IsFlowControlActive() (bool, error)
+	GetIncomingAddresses() ([]string, error)
+	GetClusterStateUUID() (string, error)
+	GetServerIdentity() (ServerIdentity, error)
+	SetDesync(desync bool) error
+	IsDesynced() (bool, error)
+	GetReplicationQueues() (ReplicationQueues, error)
+	SetDonorRejectsQueries(reject bool) error
+	CheckSSTAuth()
+	IsDonorRejectingQueries() (bool, error)
+	CountClientConnections() (int, error)
+	RotateUserPassword(username string, newPassword string) error
+	RunQuery(query string) error
+	MissingPreseededDatabases() []string
 	IsProcessRunning() bool
 	Seed() error
 	SeedUsers() error
+	ReconcileUsers() ([]string, error)
 	RunPostStartSQL() error
+	RunSQLFile(path string) error
+	CheckAllowedDatabases() error
 }
 
 type GaleraDBHelper struct {
 	osHelper        os_helper.OsHelper
+	processManager  ProcessManager
 	dbSeeder        s.Seeder
 	logFileLocation string
+	logOffset       *int64
 	logger          lager.Logger
 	config          *config.DBHelper
+	credentials     *credentials
+	protocol        string
+}
+
+// The password galera-init connects with. It is shared by a helper and its
+// ForSteadyState copies, so a password rotated through any of them is used by
+// all of them; config.Password is only the starting value.
+type credentials struct {
+	mu       sync.RWMutex
+	password string
+}
+
+func (c *credentials) get() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.password
+}
+
+func (c *credentials) set(password string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.password = password
 }
 
 func NewDBHelper(
 	osHelper os_helper.OsHelper,
+	processManager ProcessManager,
 	config *config.DBHelper,
 	logFileLocation string,
 	logger lager.Logger) *GaleraDBHelper {
+	if config.TCPKeepAliveInterval > 0 {
+		registerKeepAliveDial(*config)
+	}
+
 	return &GaleraDBHelper{
 		osHelper:        osHelper,
+		processManager:  processManager,
 		config:          config,
 		logFileLocation: logFileLocation,
+		logOffset:       new(int64),
 		logger:          logger,
+		credentials:     &credentials{password: config.Password},
 	}
 }
 
+// ForSteadyState returns a helper for checks made once startup has finished,
+// which connect with Db.SteadyStateProtocol. Startup checks keep using the
+// socket, since a freshly bootstrapped mysqld may not yet accept TCP clients,
+// while steady-state checks over TCP exercise the path clients take.
+func (m *GaleraDBHelper) ForSteadyState() *GaleraDBHelper {
+	helper := *m
+	helper.protocol = m.config.SteadyStateProtocol
+	return &helper
+}
+
+// Returns the config to connect with, carrying the current password.
+func (m GaleraDBHelper) connectionConfig() *config.DBHelper {
+	connectionConfig := *m.config
+	connectionConfig.Password = m.credentials.get()
+	return &connectionConfig
+}
+
 var BuildSeeder = func(db *sql.DB, config config.PreseededDatabase, logger lager.Logger) s.Seeder {
 	return s.NewSeeder(db, config, logger)
 }
@@ -57,14 +142,28 @@ var BuildUserSeeder = func(db *sql.DB, logger lager.Logger) UserSeeder {
 	return NewUserSeeder(db, logger)
 }
 
-func FormatDSN(config config.DBHelper) string {
+// FormatDSN connects to dbConfig.TCPAddress when protocol is
+// config.SteadyStateProtocolTCP, and to dbConfig.Socket otherwise. TCP
+// connections use the keepalive dial NewDBHelper registers when
+// Db.TCPKeepAliveInterval is set.
+func FormatDSN(dbConfig config.DBHelper, protocol string) string {
 	connectorConfig := mysql.Config{
-		User:   config.User,
-		Passwd: config.Password,
+		User:   dbConfig.User,
+		Passwd: dbConfig.Password,
 		Net:    "unix",
-		Addr:   config.Socket,
+		Addr:   dbConfig.Socket,
 	}
-	if config.SkipBinlog {
+	if protocol == config.SteadyStateProtocolTCP {
+		connectorConfig.Net = "tcp"
+		if dbConfig.TCPKeepAliveInterval > 0 {
+			connectorConfig.Net = keepAliveNetwork
+		}
+		connectorConfig.Addr = dbConfig.TCPAddress
+	}
+	if dbConfig.ConnectTimeout > 0 {
+		connectorConfig.Timeout = time.Duration(dbConfig.ConnectTimeout) * time.Second
+	}
+	if dbConfig.SkipBinlog {
 		connectorConfig.Params = map[string]string{
 			"sql_log_bin": "off",
 		}
@@ -73,9 +172,12 @@ func FormatDSN(config config.DBHelper) string {
 	return connectorConfig.FormatDSN()
 }
 
+// Delay between attempts to connect before seeding
+var SeedConnectRetryDelay = 2 * time.Second
+
 // Overridable methods to allow mocking DB connections in tests
-var OpenDBConnection = func(config *config.DBHelper) (*sql.DB, error) {
-	db, err := sql.Open("mysql", FormatDSN(*config))
+var OpenDBConnection = func(config *config.DBHelper, protocol string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", FormatDSN(*config, protocol))
 	if err != nil {
 		return nil, err
 	}
@@ -95,8 +197,14 @@ func (m GaleraDBHelper) IsProcessRunning() bool {
 }
 
 func (m GaleraDBHelper) StartMysqldForUpgrade() (*exec.Cmd, error) {
-	cmd, err := m.osHelper.StartCommand(
-		m.logFileLocation,
+	if err := m.removeStaleMysqldFiles(); err != nil {
+		return nil, err
+	}
+
+	m.markErrorLogOffset()
+
+	cmd, err := m.processManager.Start(
+		m.mysqldOutputLocation(),
 		"mysqld",
 		"--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf",
 		"--wsrep-on=OFF",
@@ -115,7 +223,11 @@ func (m GaleraDBHelper) StartMysqldForUpgrade() (*exec.Cmd, error) {
 
 func (m GaleraDBHelper) StartMysqldInJoin() (*exec.Cmd, error) {
 	m.logger.Info("Starting mysqld with 'join'.")
-	cmd, err := m.startMysqldAsChildProcess("--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf")
+	args, err := m.clusterArgs()
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := m.startMysqldAsChildProcess(m.config.JoinCommand, args...)
 
 	if err != nil {
 		m.logger.Info(fmt.Sprintf("Error starting mysqld: %s", err.Error()))
@@ -126,7 +238,11 @@ func (m GaleraDBHelper) StartMysqldInJoin() (*exec.Cmd, error) {
 
 func (m GaleraDBHelper) StartMysqldInBootstrap() (*exec.Cmd, error) {
 	m.logger.Info("Starting mysql with 'bootstrap'.")
-	cmd, err := m.startMysqldAsChildProcess("--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf", "--wsrep-new-cluster")
+	args, err := m.clusterArgs()
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := m.startMysqldAsChildProcess(m.config.BootstrapCommand, append(args, "--wsrep-new-cluster")...)
 
 	if err != nil {
 		m.logger.Info(fmt.Sprintf("Error starting node with 'bootstrap': %s", err.Error()))
@@ -137,39 +253,289 @@ func (m GaleraDBHelper) StartMysqldInBootstrap() (*exec.Cmd, error) {
 
 func (m GaleraDBHelper) StopMysqld() {
 	m.logger.Info("Stopping node")
-	_, err := m.osHelper.RunCommand(
-		"mysqladmin",
-		"--defaults-file=/var/vcap/jobs/pxc-mysql/config/mylogin.cnf",
-		"shutdown")
+	err := m.processManager.Stop()
 	if err != nil {
 		m.logger.Fatal("Error stopping mysqld", err)
 	}
 }
 
-func (m GaleraDBHelper) startMysqldAsChildProcess(mysqlArgs ...string) (*exec.Cmd, error) {
-	return m.osHelper.StartCommand(
-		m.logFileLocation,
-		"mysqld",
+const mysqldDefaultsFile = "/var/vcap/jobs/pxc-mysql/config/my.cnf"
+
+func (m GaleraDBHelper) clusterArgs() ([]string, error) {
+	defaultsFile, err := m.writeSSTDefaultsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"--defaults-file=" + defaultsFile}
+	if m.config.WsrepNodeName != "" {
+		args = append(args, "--wsrep-node-name="+m.config.WsrepNodeName)
+	}
+	if m.config.WsrepNodeAddress != "" {
+		args = append(args, "--wsrep-node-address="+m.config.WsrepNodeAddress)
+	}
+	return args, nil
+}
+
+// Without SSTUser mysqld reads my.cnf as it is. Otherwise SSTDefaultsFile is
+// rewritten to include my.cnf and add wsrep_sst_auth, so the credentials stay
+// off mysqld's command line. It replaces my.cnf as the --defaults-file rather
+// than being passed as a --defaults-extra-file, which mysqld ignores once
+// --defaults-file is given.
+func (m GaleraDBHelper) writeSSTDefaultsFile() (string, error) {
+	if m.config.SSTUser == "" {
+		return mysqldDefaultsFile, nil
+	}
+
+	contents := "!include " + mysqldDefaultsFile + "\n\n[mysqld]\n"
+	if m.config.SSTMethod != "" {
+		contents += "wsrep_sst_method = " + optionFileValue(m.config.SSTMethod) + "\n"
+	}
+	contents += "wsrep_sst_auth = " + optionFileValue(m.config.SSTUser+":"+m.config.SSTPassword) + "\n"
+
+	err := m.osHelper.WriteSecretFile(m.config.SSTDefaultsFile, contents)
+	if err != nil {
+		return "", errors.Wrap(err, "Error writing SST defaults file")
+	}
+	m.logger.Info("sst-defaults-file-written", lager.Data{
+		"file":      m.config.SSTDefaultsFile,
+		"sstMethod": m.config.SSTMethod,
+		"sstUser":   m.config.SSTUser,
+	})
+
+	return m.config.SSTDefaultsFile, nil
+}
+
+// Quotes a value for a MySQL option file, escaping what would otherwise end
+// it early or be read as an escape sequence
+func optionFileValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`).Replace(value) + `"`
+}
+
+func (m GaleraDBHelper) startMysqldAsChildProcess(command string, mysqlArgs ...string) (*exec.Cmd, error) {
+	if err := m.removeStaleMysqldFiles(); err != nil {
+		return nil, err
+	}
+
+	m.markErrorLogOffset()
+
+	return m.processManager.Start(
+		m.mysqldOutputLocation(),
+		command,
 		mysqlArgs...)
 }
 
+// Where the process manager sends mysqld's stdout and stderr, and so its
+// error log unless my.cnf sets log-error. CheckErrorLog scans this file; it is
+// empty when the output passes through galera-init's own.
+func (m GaleraDBHelper) mysqldOutputLocation() string {
+	switch m.config.MysqldOutput {
+	case config.MysqldOutputGaleraInit:
+		return ""
+	case config.MysqldOutputFile:
+		return m.config.MysqldOutputFile
+	default:
+		return m.logFileLocation
+	}
+}
+
+// Log lines that mean mysqld cannot come up without operator intervention.
+// Db.FatalErrorLogPatterns adds site-specific regular expressions to these.
+var fatalErrorLogPatterns = []string{
+	"Input/output error",
+	"No space left on device",
+	"Read-only file system",
+	"Database page corruption",
+	"Operating system error number",
+	"InnoDB: Unable to lock",
+}
+
+// Remembers where the error log ends so that CheckErrorLog only looks at
+// what the mysqld about to be started writes.
+func (m GaleraDBHelper) markErrorLogOffset() {
+	*m.logOffset = 0
+
+	errorLog := m.mysqldOutputLocation()
+	if errorLog == "" {
+		m.logger.Info("mysqld-output-not-scanned-for-fatal-errors", lager.Data{
+			"mysqldOutput": m.config.MysqldOutput,
+			"hint":         "set Db.MysqldOutput to error-log or file to fail startup with the cause of a disk or corruption error",
+		})
+		return
+	}
+
+	if info, err := os.Stat(errorLog); err == nil {
+		*m.logOffset = info.Size()
+	}
+}
+
+// CheckErrorLog scans what mysqld has written to its error log since it was
+// last started for disk I/O and corruption errors, so that a node which will
+// never become reachable fails with the cause instead of a timeout. The error
+// log is wherever MysqldOutput sends mysqld's output; there is nothing to scan
+// when that is galera-init's own output.
+func (m GaleraDBHelper) CheckErrorLog() error {
+	errorLog := m.mysqldOutputLocation()
+	if errorLog == "" {
+		return nil
+	}
+
+	logFile, err := os.Open(errorLog)
+	if err != nil {
+		return nil
+	}
+	defer logFile.Close()
+
+	offset := *m.logOffset
+	if info, err := logFile.Stat(); err == nil && info.Size() < offset {
+		// The log has been rotated since mysqld started
+		offset = 0
+	}
+
+	if _, err := logFile.Seek(offset, io.SeekStart); err != nil {
+		return nil
+	}
+
+	var extraPatterns []*regexp.Regexp
+	for _, pattern := range m.config.FatalErrorLogPatterns {
+		// Validated when the config is loaded
+		if re, err := regexp.Compile(pattern); err == nil {
+			extraPatterns = append(extraPatterns, re)
+		}
+	}
+
+	scanner := bufio.NewScanner(logFile)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if isFatalErrorLogLine(line, extraPatterns) {
+			return fmt.Errorf("mysqld reported a fatal error in %s: %s", errorLog, strings.TrimSpace(line))
+		}
+	}
+
+	return nil
+}
+
+func isFatalErrorLogLine(line string, extraPatterns []*regexp.Regexp) bool {
+	for _, pattern := range fatalErrorLogPatterns {
+		if strings.Contains(line, pattern) {
+			return true
+		}
+	}
+	for _, pattern := range extraPatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// A mysqld that crashed leaves its pid file and socket behind, which confuses
+// the next mysqld started against the same data directory. Clean them up when
+// the recorded process is gone, and refuse to start a second mysqld when it
+// is still alive.
+func (m GaleraDBHelper) removeStaleMysqldFiles() error {
+	if m.config.MysqldPidFile == "" || !m.osHelper.FileExists(m.config.MysqldPidFile) {
+		return nil
+	}
+
+	contents, err := m.osHelper.ReadFile(m.config.MysqldPidFile)
+	if err != nil {
+		return errors.Wrap(err, "Error reading mysqld pid file")
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(contents))
+	if err == nil && m.osHelper.ProcessExists(pid) {
+		return fmt.Errorf("mysqld is already running with pid %d; refusing to start a second instance", pid)
+	}
+
+	m.logger.Info("Removing stale mysqld files", lager.Data{
+		"pidFile": m.config.MysqldPidFile,
+		"socket":  m.config.Socket,
+	})
+
+	if err := m.osHelper.RemoveFile(m.config.MysqldPidFile); err != nil {
+		return errors.Wrap(err, "Error removing stale mysqld pid file")
+	}
+
+	if m.config.Socket != "" {
+		if err := m.osHelper.RemoveFile(m.config.Socket); err != nil {
+			return errors.Wrap(err, "Error removing stale mysqld socket")
+		}
+	}
+
+	return nil
+}
+
+// Upgrade runs MySQL upgrade without writing its changes to the binary log,
+// so a node upgraded while it is a cluster member does not replicate them to
+// asynchronous replicas or record them as local GTIDs.
 func (m GaleraDBHelper) Upgrade() (output string, err error) {
 	return m.osHelper.RunCommand(
 		m.config.UpgradePath,
 		"--defaults-file=/var/vcap/jobs/pxc-mysql/config/mylogin.cnf",
+		"--skip-write-binlog",
 	)
 }
 
+// Creates the system tables when the datadir has never been initialized.
+// The presence of the mysql schema directory marks an initialized datadir.
+func (m GaleraDBHelper) InitializeDatadirIfNeeded() error {
+	if m.config.InstallDBPath == "" {
+		return nil
+	}
+
+	if m.osHelper.FileExists(filepath.Join(m.config.DataDir, "mysql")) {
+		m.logger.Debug("Datadir already initialized", lager.Data{"dataDir": m.config.DataDir})
+		return nil
+	}
+
+	m.logger.Info("Initializing empty datadir", lager.Data{"dataDir": m.config.DataDir})
+	output, err := m.osHelper.RunCommand(
+		m.config.InstallDBPath,
+		"--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf",
+		"--datadir="+m.config.DataDir,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "Error initializing datadir: %s", output)
+	}
+
+	return nil
+}
+
+// CheckDatadirWritable catches a datadir whose filesystem has gone read-only,
+// typically after a disk failure, before mysqld fails on it with a cryptic error.
+func (m GaleraDBHelper) CheckDatadirWritable() error {
+	if m.config.DataDir == "" || !m.osHelper.FileExists(m.config.DataDir) {
+		return nil
+	}
+
+	err := m.osHelper.ProbeWritable(m.config.DataDir)
+	if err != nil {
+		return errors.Wrapf(err, "datadir %s not writable; check the persistent disk and its mount", m.config.DataDir)
+	}
+
+	return nil
+}
+
 func (m GaleraDBHelper) IsDatabaseReachable() bool {
-	m.logger.Info(fmt.Sprintf("Determining if database is reachable"))
+	m.logger.Debug(fmt.Sprintf("Determining if database is reachable"))
 
-	db, err := OpenDBConnection(m.config)
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
 	if err != nil {
-		m.logger.Info("database not reachable", lager.Data{"err": err})
+		m.logger.Debug("database not reachable", lager.Data{"err": err})
 		return false
 	}
 	defer CloseDBConnection(db)
 
+	// A mysqld can accept connections while being unable to serve queries,
+	// so only treat it as reachable once a trivial query succeeds.
+	var one int
+	err = db.QueryRow(`SELECT 1`).Scan(&one)
+	if err != nil {
+		m.logger.Debug("database accepted connection but could not serve a query", lager.Data{"err": err})
+		return false
+	}
+
 	var (
 		unused string
 		value  string
@@ -196,10 +562,507 @@ func (m GaleraDBHelper) IsDatabaseReachable() bool {
 		return false
 	}
 
-	m.logger.Info(fmt.Sprintf("Galera Database state is %s", value))
+	m.logger.Debug(fmt.Sprintf("Galera Database state is %s", value))
 	return value == "Synced"
 }
 
+// Ping only checks that mysqld accepts connections, without running a query
+// or looking at the Galera state.
+func (m GaleraDBHelper) Ping() bool {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return false
+	}
+	defer CloseDBConnection(db)
+
+	return db.Ping() == nil
+}
+
+// IsNodeEvicted reports whether the local node has dropped out of the primary
+// component because the rest of the cluster voted it out for inconsistency.
+func (m GaleraDBHelper) IsNodeEvicted() bool {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		m.logger.Debug("database not reachable", lager.Data{"err": err})
+		return false
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused        string
+		clusterStatus string
+		localUUID     string
+		evictList     string
+	)
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_cluster\_status'`).Scan(&unused, &clusterStatus)
+	if err != nil || clusterStatus == "Primary" {
+		return false
+	}
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_gcomm\_uuid'`).Scan(&unused, &localUUID)
+	if err != nil || localUUID == "" {
+		return false
+	}
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_evs\_evict\_list'`).Scan(&unused, &evictList)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(evictList, localUUID)
+}
+
+func (m GaleraDBHelper) GetMaxConnections() (int, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return 0, err
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused         string
+		maxConnections int
+	)
+
+	err = db.QueryRow(`SHOW GLOBAL VARIABLES LIKE 'max\_connections'`).Scan(&unused, &maxConnections)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error reading max_connections")
+	}
+
+	return maxConnections, nil
+}
+
+func (m GaleraDBHelper) GetBufferPoolSize() (uint64, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return 0, err
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused         string
+		bufferPoolSize uint64
+	)
+
+	err = db.QueryRow(`SHOW GLOBAL VARIABLES LIKE 'innodb\_buffer\_pool\_size'`).Scan(&unused, &bufferPoolSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error reading innodb_buffer_pool_size")
+	}
+
+	return bufferPoolSize, nil
+}
+
+func (m GaleraDBHelper) GetMaxAllowedPacket() (uint64, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return 0, err
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused           string
+		maxAllowedPacket uint64
+	)
+
+	err = db.QueryRow(`SHOW GLOBAL VARIABLES LIKE 'max\_allowed\_packet'`).Scan(&unused, &maxAllowedPacket)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error reading max_allowed_packet")
+	}
+
+	return maxAllowedPacket, nil
+}
+
+// SetMaxAllowedPacket only affects connections opened afterwards, which at
+// startup is every client connection.
+func (m GaleraDBHelper) SetMaxAllowedPacket(bytes uint64) error {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return err
+	}
+	defer CloseDBConnection(db)
+
+	_, err = db.Exec(fmt.Sprintf("SET GLOBAL max_allowed_packet = %d", bytes))
+	if err != nil {
+		return errors.Wrap(err, "Error setting max_allowed_packet")
+	}
+
+	return nil
+}
+
+type WsrepStatus struct {
+	LocalStateComment string
+	ClusterStatus     string
+	ClusterSize       int
+}
+
+// GetWsrepStatus reports the local node's state transfer progress, whether
+// its cluster component is primary, and how many members it can currently
+// see in that component.
+func (m GaleraDBHelper) GetWsrepStatus() (WsrepStatus, error) {
+	var status WsrepStatus
+
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return status, err
+	}
+	defer CloseDBConnection(db)
+
+	var unused string
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_local\_state\_comment'`).Scan(&unused, &status.LocalStateComment)
+	if err != nil {
+		return status, errors.Wrap(err, "Error reading wsrep_local_state_comment")
+	}
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_cluster\_status'`).Scan(&unused, &status.ClusterStatus)
+	if err != nil {
+		return status, errors.Wrap(err, "Error reading wsrep_cluster_status")
+	}
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_cluster\_size'`).Scan(&unused, &status.ClusterSize)
+	if err != nil {
+		return status, errors.Wrap(err, "Error reading wsrep_cluster_size")
+	}
+
+	return status, nil
+}
+
+// The settings that must differ between members of a cluster
+type ServerIdentity struct {
+	ServerID         int
+	WsrepNodeName    string
+	WsrepNodeAddress string
+}
+
+func (m GaleraDBHelper) GetServerIdentity() (ServerIdentity, error) {
+	var identity ServerIdentity
+
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return identity, err
+	}
+	defer CloseDBConnection(db)
+
+	err = db.QueryRow("SELECT @@server_id, @@wsrep_node_name, @@wsrep_node_address").Scan(&identity.ServerID, &identity.WsrepNodeName, &identity.WsrepNodeAddress)
+	if err != nil {
+		return identity, errors.Wrap(err, "Error reading server identity")
+	}
+
+	return identity, nil
+}
+
+// GetClusterStateUUID returns the wsrep_cluster_state_uuid of the cluster the
+// node is a member of.
+func (m GaleraDBHelper) GetClusterStateUUID() (string, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return "", err
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused string
+		uuid   string
+	)
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_cluster\_state\_uuid'`).Scan(&unused, &uuid)
+	if err != nil {
+		return "", errors.Wrap(err, "Error reading wsrep_cluster_state_uuid")
+	}
+
+	return uuid, nil
+}
+
+// GetIncomingAddresses returns the client addresses of every member of the
+// cluster as this node sees it, taken from wsrep_incoming_addresses.
+func (m GaleraDBHelper) GetIncomingAddresses() ([]string, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused    string
+		addresses string
+	)
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_incoming\_addresses'`).Scan(&unused, &addresses)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading wsrep_incoming_addresses")
+	}
+
+	if addresses == "" {
+		return []string{}, nil
+	}
+
+	return strings.Split(addresses, ","), nil
+}
+
+func (m GaleraDBHelper) IsFlowControlActive() (bool, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return false, err
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused string
+		status string
+	)
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_flow\_control\_status'`).Scan(&unused, &status)
+	if err != nil {
+		return false, errors.Wrap(err, "Error reading wsrep_flow_control_status")
+	}
+
+	return status == "ON", nil
+}
+
+// SetDesync toggles wsrep_desync, which lets this node fall behind the rest of
+// the cluster without triggering flow control.
+func (m GaleraDBHelper) SetDesync(desync bool) error {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return err
+	}
+	defer CloseDBConnection(db)
+
+	value := "OFF"
+	if desync {
+		value = "ON"
+	}
+
+	_, err = db.Exec("SET GLOBAL wsrep_desync = " + value)
+	return errors.Wrap(err, "Error setting wsrep_desync")
+}
+
+func (m GaleraDBHelper) IsDesynced() (bool, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return false, err
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused string
+		value  string
+	)
+
+	err = db.QueryRow(`SHOW GLOBAL VARIABLES LIKE 'wsrep\_desync'`).Scan(&unused, &value)
+	if err != nil {
+		return false, errors.Wrap(err, "Error reading wsrep_desync")
+	}
+
+	return value == "ON", nil
+}
+
+// Write-sets waiting to be applied on this node (Recv) and waiting to be
+// replicated from it (Send)
+type ReplicationQueues struct {
+	Recv int
+	Send int
+}
+
+func (m GaleraDBHelper) GetReplicationQueues() (ReplicationQueues, error) {
+	var queues ReplicationQueues
+
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return queues, err
+	}
+	defer CloseDBConnection(db)
+
+	var unused string
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_local\_recv\_queue'`).Scan(&unused, &queues.Recv)
+	if err != nil {
+		return queues, errors.Wrap(err, "Error reading wsrep_local_recv_queue")
+	}
+
+	err = db.QueryRow(`SHOW GLOBAL STATUS LIKE 'wsrep\_local\_send\_queue'`).Scan(&unused, &queues.Send)
+	if err != nil {
+		return queues, errors.Wrap(err, "Error reading wsrep_local_send_queue")
+	}
+
+	return queues, nil
+}
+
+// SetDonorRejectsQueries sets wsrep_sst_donor_rejects_queries, which makes
+// the node refuse client queries while it serves as an SST donor.
+func (m GaleraDBHelper) SetDonorRejectsQueries(reject bool) error {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return err
+	}
+	defer CloseDBConnection(db)
+
+	value := "OFF"
+	if reject {
+		value = "ON"
+	}
+
+	_, err = db.Exec("SET GLOBAL wsrep_sst_donor_rejects_queries = " + value)
+	return errors.Wrap(err, "Error setting wsrep_sst_donor_rejects_queries")
+}
+
+func (m GaleraDBHelper) IsDonorRejectingQueries() (bool, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return false, err
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		unused string
+		value  string
+	)
+
+	err = db.QueryRow(`SHOW GLOBAL VARIABLES LIKE 'wsrep\_sst\_donor\_rejects\_queries'`).Scan(&unused, &value)
+	if err != nil {
+		return false, errors.Wrap(err, "Error reading wsrep_sst_donor_rejects_queries")
+	}
+
+	return value == "ON", nil
+}
+
+// CheckSSTAuth warns when mysqld's wsrep_sst_method needs credentials that
+// neither SSTUser nor my.cnf provides. It never fails: the check is advisory.
+func (m GaleraDBHelper) CheckSSTAuth() {
+	if m.config.SSTUser != "" {
+		return
+	}
+
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		m.logger.Error("sst-settings-check-failed", err)
+		return
+	}
+	defer CloseDBConnection(db)
+
+	var (
+		method string
+		auth   sql.NullString
+	)
+	err = db.QueryRow("SELECT @@wsrep_sst_method, @@wsrep_sst_auth").Scan(&method, &auth)
+	if err != nil {
+		m.logger.Error("sst-settings-check-failed", errors.Wrap(err, "Error reading wsrep_sst_method"))
+		return
+	}
+
+	if config.SSTMethodNeedsAuth(method) && auth.String == "" {
+		m.logger.Info("warning-sst-credentials-missing", lager.Data{"sstMethod": method})
+	}
+}
+
+// CountClientConnections counts connections other than this one and mysqld's
+// own system threads.
+func (m GaleraDBHelper) CountClientConnections() (int, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return 0, err
+	}
+	defer CloseDBConnection(db)
+
+	var count int
+	err = db.QueryRow(`SELECT COUNT(*) FROM information_schema.PROCESSLIST WHERE ID != CONNECTION_ID() AND USER NOT IN ('system user', 'event_scheduler')`).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error counting client connections")
+	}
+
+	return count, nil
+}
+
+// RotateUserPassword changes the password of every account named username.
+// When username is the user galera-init connects as, later connections use
+// the new password, including those of the helper's ForSteadyState copies.
+// The rotation of a seeded user is recorded in the database, so that no node
+// re-applies the user's configured password, at startup or in ReconcileUsers,
+// until that password is changed; callers must update the deployment's config.
+func (m GaleraDBHelper) RotateUserPassword(username string, newPassword string) error {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return err
+	}
+	defer CloseDBConnection(db)
+
+	rows, err := db.Query("SELECT Host FROM mysql.user WHERE User = ?", username)
+	if err != nil {
+		return errors.Wrap(err, "Error looking up user")
+	}
+	defer rows.Close()
+
+	hosts := []string{}
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return errors.Wrap(err, "Error looking up user")
+		}
+		hosts = append(hosts, host)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "Error looking up user")
+	}
+
+	if len(hosts) == 0 {
+		return fmt.Errorf("User %s does not exist", username)
+	}
+
+	if err := m.recordRotation(db, username); err != nil {
+		return err
+	}
+
+	for _, host := range hosts {
+		_, err = db.Exec(fmt.Sprintf(
+			"ALTER USER '%s'@'%s' IDENTIFIED BY '%s'",
+			escapeSQLString(username),
+			escapeSQLString(host),
+			escapeSQLString(newPassword)))
+		if err != nil {
+			return errors.Wrapf(err, "Error changing password for %s", username)
+		}
+	}
+
+	if username == m.config.User {
+		m.credentials.set(newPassword)
+	}
+
+	m.logger.Info("user-password-rotated", lager.Data{"user": username, "hosts": hosts})
+	return nil
+}
+
+func escapeSQLString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+func (m GaleraDBHelper) RunQuery(query string) error {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return err
+	}
+	defer CloseDBConnection(db)
+
+	_, err = db.Exec(query)
+	return err
+}
+
+// MissingPreseededDatabases lists the preseeded databases that do not exist
+// on this node, such as on a joining node whose replication has stalled.
+func (m GaleraDBHelper) MissingPreseededDatabases() []string {
+	missing := []string{}
+	for _, preseeded := range m.config.PreseededDatabases {
+		query := fmt.Sprintf("SHOW TABLES FROM `%s`", strings.Replace(preseeded.DBName, "`", "``", -1))
+		if err := m.RunQuery(query); err != nil {
+			m.logger.Debug("preseeded-database-not-found", lager.Data{
+				"database": preseeded.DBName,
+				"error":    err.Error(),
+			})
+			missing = append(missing, preseeded.DBName)
+		}
+	}
+	return missing
+}
+
 func (m GaleraDBHelper) Seed() error {
 	if m.config.PreseededDatabases == nil || len(m.config.PreseededDatabases) == 0 {
 		m.logger.Info("No preseeded databases specified, skipping seeding.")
@@ -208,13 +1071,17 @@ func (m GaleraDBHelper) Seed() error {
 
 	m.logger.Info("Preseeding Databases")
 
-	db, err := OpenDBConnection(m.config)
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
 	if err != nil {
 		m.logger.Error("database not reachable", err)
 		return err
 	}
 	defer CloseDBConnection(db)
 
+	if err := m.waitForSeedConnection(db); err != nil {
+		return err
+	}
+
 	for _, dbToCreate := range m.config.PreseededDatabases {
 		seeder := BuildSeeder(db, dbToCreate, m.logger)
 
@@ -249,22 +1116,98 @@ func (m GaleraDBHelper) Seed() error {
 	return nil
 }
 
+// A freshly bootstrapped mysqld can be reachable before it accepts the
+// seeding user, so retry authenticating up to SeedConnectAttempts times
+// before seeding. Failing here means seeding never started, as opposed to a
+// seed statement failing.
+func (m GaleraDBHelper) waitForSeedConnection(db *sql.DB) error {
+	attempts := m.config.SeedConnectAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := db.Ping()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= attempts {
+			m.logger.Error("seed-connection-failed", err, lager.Data{"attempts": attempt})
+			return errors.Wrap(err, "Could not connect to seed the database")
+		}
+
+		m.logger.Info("seed-connection-not-ready", lager.Data{
+			"attempt": attempt,
+			"error":   err.Error(),
+		})
+		m.osHelper.Sleep(SeedConnectRetryDelay)
+	}
+}
+
+// usersToSeed returns the users seeded with a role and the read-only users
+// scoped to databases, leaving out the read-only users when they are managed
+// outside galera-init.
+func (m GaleraDBHelper) usersToSeed() ([]config.SeededUser, []config.ReadOnlyUser) {
+	usersToCreate := append([]config.SeededUser{}, m.config.SeededUsers...)
+	if !m.config.ManageReadOnlyUser {
+		return usersToCreate, nil
+	}
+
+	if m.config.ReadOnlyUser != "" {
+		usersToCreate = append(usersToCreate, config.SeededUser{
+			User:     m.config.ReadOnlyUser,
+			Password: m.config.ReadOnlyPassword,
+			Host:     m.config.ReadOnlyUserHost,
+			Role:     "read-only",
+		})
+	}
+
+	readOnlyUsers := make([]config.ReadOnlyUser, len(m.config.ReadOnlyUsers))
+	for i, readOnlyUser := range m.config.ReadOnlyUsers {
+		if readOnlyUser.Host == "" {
+			readOnlyUser.Host = m.config.ReadOnlyUserHost
+		}
+		readOnlyUsers[i] = readOnlyUser
+	}
+
+	return usersToCreate, readOnlyUsers
+}
+
 func (m GaleraDBHelper) SeedUsers() error {
-	if m.config.SeededUsers == nil || len(m.config.SeededUsers) == 0 {
+	usersToCreate, readOnlyUsers := m.usersToSeed()
+	return m.seedUsers(usersToCreate, readOnlyUsers)
+}
+
+func (m GaleraDBHelper) seedUsers(usersToCreate []config.SeededUser, readOnlyUsers []config.ReadOnlyUser) error {
+	if !m.config.ManageReadOnlyUser && (m.config.ReadOnlyUser != "" || len(m.config.ReadOnlyUsers) > 0) {
+		m.logger.Info("skipping-read-only-users-not-managed")
+	}
+
+	if len(usersToCreate) == 0 && len(readOnlyUsers) == 0 {
 		m.logger.Info("No seeded users specified, skipping seeding.")
 		return nil
 	}
 
 	m.logger.Info("Seeding Users")
 
-	db, err := OpenDBConnection(m.config)
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
 	if err != nil {
 		m.logger.Error("database not reachable", err)
 		return err
 	}
 	defer CloseDBConnection(db)
 
-	for _, userToCreate := range m.config.SeededUsers {
+	if err := m.waitForSeedConnection(db); err != nil {
+		return err
+	}
+
+	usersToCreate, readOnlyUsers, err = m.withoutRotatedUsers(db, usersToCreate, readOnlyUsers)
+	if err != nil {
+		return err
+	}
+
+	for _, userToCreate := range usersToCreate {
 		seeder := BuildUserSeeder(db, m.logger)
 
 		err = seeder.SeedUser(
@@ -279,6 +1222,20 @@ func (m GaleraDBHelper) SeedUsers() error {
 
 	}
 
+	for _, readOnlyUser := range readOnlyUsers {
+		seeder := BuildUserSeeder(db, m.logger)
+
+		err = seeder.SeedReadOnlyUser(
+			readOnlyUser.User,
+			readOnlyUser.Password,
+			readOnlyUser.Host,
+			readOnlyUser.Databases,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -294,7 +1251,7 @@ func (m GaleraDBHelper) flushPrivileges(db *sql.DB) error {
 func (m GaleraDBHelper) RunPostStartSQL() error {
 	m.logger.Info("Running Post Start SQL Queries")
 
-	db, err := OpenDBConnection(m.config)
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
 	if err != nil {
 		m.logger.Error("database not reachable", err)
 		return err
@@ -317,3 +1274,84 @@ func (m GaleraDBHelper) RunPostStartSQL() error {
 
 	return nil
 }
+
+// RunSQLFile executes the contents of a single file. Unlike
+// RunPostStartSQL, a file that cannot be read is an error.
+func (m GaleraDBHelper) RunSQLFile(path string) error {
+	sqlString, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "Error reading SQL file %s", path)
+	}
+
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		m.logger.Error("database not reachable", err)
+		return err
+	}
+	defer CloseDBConnection(db)
+
+	if _, err := db.Exec(string(sqlString)); err != nil {
+		return errors.Wrapf(err, "Error running SQL file %s", path)
+	}
+
+	return nil
+}
+
+var systemDatabases = map[string]bool{
+	"information_schema": true,
+	"mysql":              true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+// CheckAllowedDatabases guards against seeding creating unexpected schemas: it
+// fails if any database exists other than the system schemas, the preseeded
+// databases and AllowedSeedDatabases. It does nothing when no allowlist is set.
+func (m GaleraDBHelper) CheckAllowedDatabases() error {
+	if len(m.config.AllowedSeedDatabases) == 0 {
+		return nil
+	}
+
+	allowed := map[string]bool{}
+	for _, name := range m.config.AllowedSeedDatabases {
+		allowed[name] = true
+	}
+	for _, preseeded := range m.config.PreseededDatabases {
+		allowed[preseeded.DBName] = true
+	}
+
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return err
+	}
+	defer CloseDBConnection(db)
+
+	rows, err := db.Query("SHOW DATABASES")
+	if err != nil {
+		return errors.Wrap(err, "Error listing databases")
+	}
+	defer rows.Close()
+
+	unexpected := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return errors.Wrap(err, "Error listing databases")
+		}
+		if !systemDatabases[name] && !allowed[name] {
+			unexpected = append(unexpected, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "Error listing databases")
+	}
+
+	if len(unexpected) > 0 {
+		m.logger.Error("unexpected-databases", errors.New("databases outside AllowedSeedDatabases"), lager.Data{
+			"databases": unexpected,
+		})
+		return fmt.Errorf("Found databases not in AllowedSeedDatabases: %s", strings.Join(unexpected, ", "))
+	}
+
+	return nil
+}
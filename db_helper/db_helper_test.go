@@ -2,15 +2,20 @@ package db_helper_test
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
@@ -52,7 +57,7 @@ var _ = Describe("GaleraDBHelper", func() {
 
 		fakeDB, mock, err = sqlmock.New()
 		Expect(err).ToNot(HaveOccurred())
-		db_helper.OpenDBConnection = func(*config.DBHelper) (*sql.DB, error) {
+		db_helper.OpenDBConnection = func(*config.DBHelper, string) (*sql.DB, error) {
 			return fakeDB, nil
 		}
 		db_helper.CloseDBConnection = func(*sql.DB) error {
@@ -78,9 +83,14 @@ var _ = Describe("GaleraDBHelper", func() {
 		ioutil.WriteFile(sqlFile2.Name(), []byte(fakeSupplementalQuery2), 755)
 
 		dbConfig = &config.DBHelper{
-			UpgradePath: "/mysql_upgrade",
-			User:        "user",
-			Password:    "password",
+			BootstrapCommand:   "/bootstrap-mysqld",
+			JoinCommand:        "/join-mysqld",
+			ManageReadOnlyUser: true,
+			UpgradePath:        "/mysql_upgrade",
+			DataDir:            "/datadir",
+			InstallDBPath:      "/mysql_install_db",
+			User:               "user",
+			Password:           "password",
 			PreseededDatabases: []config.PreseededDatabase{
 				config.PreseededDatabase{
 					DBName:   "DB1",
@@ -114,6 +124,7 @@ var _ = Describe("GaleraDBHelper", func() {
 	JustBeforeEach(func() {
 		helper = db_helper.NewDBHelper(
 			fakeOs,
+			db_helper.NewDirectProcessManager(fakeOs),
 			dbConfig,
 			logFile,
 			testLogger,
@@ -124,6 +135,13 @@ var _ = Describe("GaleraDBHelper", func() {
 		Expect(mock.ExpectationsWereMet()).To(Succeed())
 	})
 
+	const rotatedUsersQuery = "SELECT User, ConfiguredPasswordHash FROM mysql.galera_init_rotated_users"
+
+	expectNoRotatedUsers := func() {
+		mock.ExpectQuery(rotatedUsersQuery).
+			WillReturnError(&mysql.MySQLError{Number: 1146, Message: "Table 'mysql.galera_init_rotated_users' doesn't exist"})
+	}
+
 	Describe("StartMysqldForUpgrade", func() {
 		BeforeEach(func() {
 			fakeOs.StartCommandStub = func(logFile string, executable string, args ...string) (cmd *exec.Cmd, e error) {
@@ -166,6 +184,222 @@ var _ = Describe("GaleraDBHelper", func() {
 		})
 	})
 
+	Describe("StartMysqldInJoin", func() {
+		BeforeEach(func() {
+			fakeOs.StartCommandReturns(exec.Command("stub"), nil)
+		})
+
+		It("starts mysqld using the configured join command", func() {
+			_, err := helper.StartMysqldInJoin()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeOs.StartCommandCallCount()).To(Equal(1))
+			_, executable, args := fakeOs.StartCommandArgsForCall(0)
+			Expect(executable).To(Equal("/join-mysqld"))
+			Expect(args).To(Equal([]string{"--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf"}))
+			Expect(fakeOs.WriteSecretFileCallCount()).To(Equal(0))
+		})
+
+		It("passes the configured wsrep node name and address", func() {
+			dbConfig.WsrepNodeName = "mysql-0"
+			dbConfig.WsrepNodeAddress = "10.0.0.1:4567"
+
+			_, err := helper.StartMysqldInJoin()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, args := fakeOs.StartCommandArgsForCall(0)
+			Expect(args).To(Equal([]string{
+				"--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf",
+				"--wsrep-node-name=mysql-0",
+				"--wsrep-node-address=10.0.0.1:4567",
+			}))
+		})
+
+		Context("when SSTUser is set", func() {
+			BeforeEach(func() {
+				dbConfig.SSTUser = "sst"
+				dbConfig.SSTPassword = `it's "secret"`
+				dbConfig.SSTDefaultsFile = "/run/galera-init-sst.cnf"
+			})
+
+			It("starts mysqld with a defaults file that adds wsrep_sst_auth to my.cnf", func() {
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeOs.WriteSecretFileCallCount()).To(Equal(1))
+				filename, contents := fakeOs.WriteSecretFileArgsForCall(0)
+				Expect(filename).To(Equal("/run/galera-init-sst.cnf"))
+				Expect(contents).To(Equal("!include /var/vcap/jobs/pxc-mysql/config/my.cnf\n\n" +
+					"[mysqld]\n" +
+					`wsrep_sst_auth = "sst:it's \"secret\""` + "\n"))
+
+				_, _, args := fakeOs.StartCommandArgsForCall(0)
+				Expect(args).To(Equal([]string{"--defaults-file=/run/galera-init-sst.cnf"}))
+				Expect(strings.Join(args, " ")).NotTo(ContainSubstring("secret"))
+				Expect(testLogger.Buffer()).NotTo(Say("secret"))
+			})
+
+			It("adds the configured SST method", func() {
+				dbConfig.SSTMethod = "mariabackup"
+
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+
+				_, contents := fakeOs.WriteSecretFileArgsForCall(0)
+				Expect(contents).To(ContainSubstring(`wsrep_sst_method = "mariabackup"` + "\n"))
+			})
+
+			It("does not start mysqld when the defaults file cannot be written", func() {
+				fakeOs.WriteSecretFileReturns(errors.New("read-only file system"))
+
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).To(MatchError("Error writing SST defaults file: read-only file system"))
+				Expect(fakeOs.StartCommandCallCount()).To(Equal(0))
+			})
+		})
+
+		Describe("routing mysqld output", func() {
+			It("appends to the error log by default", func() {
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+
+				outputFile, _, _ := fakeOs.StartCommandArgsForCall(0)
+				Expect(outputFile).To(Equal(logFile))
+			})
+
+			It("passes output through to galera-init when configured to", func() {
+				dbConfig.MysqldOutput = config.MysqldOutputGaleraInit
+
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+
+				outputFile, _, _ := fakeOs.StartCommandArgsForCall(0)
+				Expect(outputFile).To(BeEmpty())
+			})
+
+			It("appends to a separate file when configured to", func() {
+				dbConfig.MysqldOutput = config.MysqldOutputFile
+				dbConfig.MysqldOutputFile = "/mysqld.out"
+
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+
+				outputFile, _, _ := fakeOs.StartCommandArgsForCall(0)
+				Expect(outputFile).To(Equal("/mysqld.out"))
+			})
+		})
+	})
+
+	Describe("removing stale mysqld files before start", func() {
+		BeforeEach(func() {
+			dbConfig.MysqldPidFile = "/mysqld.pid"
+			dbConfig.Socket = "/mysqld.sock"
+			fakeOs.StartCommandReturns(exec.Command("stub"), nil)
+		})
+
+		It("does nothing when there is no pid file", func() {
+			fakeOs.FileExistsReturns(false)
+
+			_, err := helper.StartMysqldInJoin()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeOs.RemoveFileCallCount()).To(Equal(0))
+			Expect(fakeOs.StartCommandCallCount()).To(Equal(1))
+		})
+
+		Context("when the pid file belongs to a dead process", func() {
+			BeforeEach(func() {
+				fakeOs.FileExistsReturns(true)
+				fakeOs.ReadFileReturns("1234\n", nil)
+				fakeOs.ProcessExistsReturns(false)
+			})
+
+			It("removes the stale pid file and socket before starting", func() {
+				_, err := helper.StartMysqldInBootstrap()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeOs.ProcessExistsArgsForCall(0)).To(Equal(1234))
+				Expect(fakeOs.RemoveFileCallCount()).To(Equal(2))
+				Expect(fakeOs.RemoveFileArgsForCall(0)).To(Equal("/mysqld.pid"))
+				Expect(fakeOs.RemoveFileArgsForCall(1)).To(Equal("/mysqld.sock"))
+				Expect(fakeOs.StartCommandCallCount()).To(Equal(1))
+			})
+
+			It("returns an error when the stale files cannot be removed", func() {
+				fakeOs.RemoveFileReturns(errors.New("permission denied"))
+
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).To(MatchError("Error removing stale mysqld pid file: permission denied"))
+				Expect(fakeOs.StartCommandCallCount()).To(Equal(0))
+			})
+		})
+
+		It("treats an unparseable pid file as stale", func() {
+			fakeOs.FileExistsReturns(true)
+			fakeOs.ReadFileReturns("garbage", nil)
+
+			_, err := helper.StartMysqldForUpgrade()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeOs.ProcessExistsCallCount()).To(Equal(0))
+			Expect(fakeOs.RemoveFileCallCount()).To(Equal(2))
+		})
+
+		It("refuses to start when the pid file belongs to a live mysqld", func() {
+			fakeOs.FileExistsReturns(true)
+			fakeOs.ReadFileReturns("1234", nil)
+			fakeOs.ProcessExistsReturns(true)
+
+			_, err := helper.StartMysqldInJoin()
+			Expect(err).To(MatchError(ContainSubstring("mysqld is already running with pid 1234")))
+			Expect(fakeOs.RemoveFileCallCount()).To(Equal(0))
+			Expect(fakeOs.StartCommandCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("StartMysqldInBootstrap", func() {
+		BeforeEach(func() {
+			fakeOs.StartCommandReturns(exec.Command("stub"), nil)
+		})
+
+		It("starts mysqld using the configured bootstrap command", func() {
+			_, err := helper.StartMysqldInBootstrap()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeOs.StartCommandCallCount()).To(Equal(1))
+			_, executable, args := fakeOs.StartCommandArgsForCall(0)
+			Expect(executable).To(Equal("/bootstrap-mysqld"))
+			Expect(args).To(Equal([]string{"--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf", "--wsrep-new-cluster"}))
+		})
+
+		It("passes the configured wsrep node name and address", func() {
+			dbConfig.WsrepNodeName = "mysql-0"
+			dbConfig.WsrepNodeAddress = "10.0.0.1:4567"
+
+			_, err := helper.StartMysqldInBootstrap()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, args := fakeOs.StartCommandArgsForCall(0)
+			Expect(args).To(Equal([]string{
+				"--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf",
+				"--wsrep-node-name=mysql-0",
+				"--wsrep-node-address=10.0.0.1:4567",
+				"--wsrep-new-cluster",
+			}))
+		})
+
+		It("starts mysqld with the generated SST defaults file when SSTUser is set", func() {
+			dbConfig.SSTUser = "sst"
+			dbConfig.SSTPassword = "secret"
+			dbConfig.SSTDefaultsFile = "/run/galera-init-sst.cnf"
+
+			_, err := helper.StartMysqldInBootstrap()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeOs.WriteSecretFileCallCount()).To(Equal(1))
+			_, _, args := fakeOs.StartCommandArgsForCall(0)
+			Expect(args).To(Equal([]string{"--defaults-file=/run/galera-init-sst.cnf", "--wsrep-new-cluster"}))
+		})
+	})
+
 	Describe("StopMysqld", func() {
 		It("calls the mysql daemon with the stop command", func() {
 			fakeOs.RunCommandReturns("", nil)
@@ -222,7 +456,7 @@ var _ = Describe("GaleraDBHelper", func() {
 
 			executable, args := fakeOs.RunCommandArgsForCall(0)
 			Expect(executable).To(Equal(dbConfig.UpgradePath))
-			Expect(args).To(Equal([]string{"--defaults-file=/var/vcap/jobs/pxc-mysql/config/mylogin.cnf"}))
+			Expect(args).To(Equal([]string{"--defaults-file=/var/vcap/jobs/pxc-mysql/config/mylogin.cnf", "--skip-write-binlog"}))
 		})
 
 		It("returns the output and error", func() {
@@ -234,12 +468,264 @@ var _ = Describe("GaleraDBHelper", func() {
 		})
 	})
 
+	Describe("CheckDatadirWritable", func() {
+		BeforeEach(func() {
+			fakeOs.FileExistsReturns(true)
+		})
+
+		It("probes the datadir", func() {
+			Expect(helper.CheckDatadirWritable()).To(Succeed())
+
+			Expect(fakeOs.ProbeWritableCallCount()).To(Equal(1))
+			Expect(fakeOs.ProbeWritableArgsForCall(0)).To(Equal("/datadir"))
+		})
+
+		It("returns a datadir not writable error when the probe fails", func() {
+			fakeOs.ProbeWritableReturns(errors.New("read-only file system"))
+
+			err := helper.CheckDatadirWritable()
+			Expect(err).To(MatchError(ContainSubstring("datadir /datadir not writable")))
+			Expect(err).To(MatchError(ContainSubstring("read-only file system")))
+		})
+
+		It("skips the probe when the datadir does not exist yet", func() {
+			fakeOs.FileExistsReturns(false)
+
+			Expect(helper.CheckDatadirWritable()).To(Succeed())
+			Expect(fakeOs.ProbeWritableCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("CheckErrorLog", func() {
+		var tempLog string
+
+		appendToLog := func(lines string) {
+			f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+			_, err = f.WriteString(lines)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			f, err := ioutil.TempFile("", "mysqld-log")
+			Expect(err).NotTo(HaveOccurred())
+			f.Close()
+			tempLog = f.Name()
+			logFile = tempLog
+
+			appendToLog("[ERROR] InnoDB: Operating system error number 5 in a file operation.\n")
+		})
+
+		AfterEach(func() {
+			os.Remove(tempLog)
+		})
+
+		It("ignores errors logged before mysqld was started", func() {
+			_, err := helper.StartMysqldInJoin()
+			Expect(err).NotTo(HaveOccurred())
+
+			appendToLog("[Note] WSREP: Shifting OPEN -> PRIMARY\n")
+
+			Expect(helper.CheckErrorLog()).To(Succeed())
+		})
+
+		It("reports a disk error logged by the running mysqld", func() {
+			_, err := helper.StartMysqldInBootstrap()
+			Expect(err).NotTo(HaveOccurred())
+
+			appendToLog("[Note] InnoDB: Starting crash recovery.\n[ERROR] InnoDB: Error number 28 means 'No space left on device'\n")
+
+			err = helper.CheckErrorLog()
+			Expect(err).To(MatchError(ContainSubstring("mysqld reported a fatal error in " + logFile)))
+			Expect(err).To(MatchError(ContainSubstring("No space left on device")))
+		})
+
+		It("reads the whole log when it was rotated after mysqld started", func() {
+			appendToLog("[Note] padding so the log is longer than after rotation\n")
+			_, err := helper.StartMysqldInJoin()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.Truncate(logFile, 0)).To(Succeed())
+			appendToLog("[ERROR] mysqld: Read-only file system\n")
+
+			Expect(helper.CheckErrorLog()).To(MatchError(ContainSubstring("Read-only file system")))
+		})
+
+		It("reports a line matching a configured pattern", func() {
+			dbConfig.FatalErrorLogPatterns = []string{`\[ERROR\] .*Tablespace .* is missing`}
+			_, err := helper.StartMysqldInJoin()
+			Expect(err).NotTo(HaveOccurred())
+
+			appendToLog("[Warning] Tablespace 12 was not found\n[ERROR] InnoDB: Tablespace 12 is missing for table app/orders\n")
+
+			Expect(helper.CheckErrorLog()).To(MatchError(ContainSubstring("[ERROR] InnoDB: Tablespace 12 is missing for table app/orders")))
+		})
+
+		Context("when the log does not exist", func() {
+			BeforeEach(func() {
+				logFile = "/does-not-exist.log"
+			})
+
+			It("succeeds", func() {
+				Expect(helper.CheckErrorLog()).To(Succeed())
+			})
+		})
+
+		Context("when MysqldOutput is error-log", func() {
+			BeforeEach(func() {
+				dbConfig.MysqldOutput = config.MysqldOutputErrorLog
+			})
+
+			It("scans LogFileLocation", func() {
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+
+				appendToLog("[ERROR] mysqld: Read-only file system\n")
+
+				Expect(helper.CheckErrorLog()).To(MatchError(ContainSubstring("mysqld reported a fatal error in " + logFile)))
+			})
+		})
+
+		Context("when MysqldOutput is file", func() {
+			var outputFile string
+
+			appendToOutput := func(lines string) {
+				f, err := os.OpenFile(outputFile, os.O_APPEND|os.O_WRONLY, 0644)
+				Expect(err).NotTo(HaveOccurred())
+				defer f.Close()
+				_, err = f.WriteString(lines)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			BeforeEach(func() {
+				f, err := ioutil.TempFile("", "mysqld-output")
+				Expect(err).NotTo(HaveOccurred())
+				f.Close()
+				outputFile = f.Name()
+
+				dbConfig.MysqldOutput = config.MysqldOutputFile
+				dbConfig.MysqldOutputFile = outputFile
+			})
+
+			AfterEach(func() {
+				os.Remove(outputFile)
+			})
+
+			It("scans MysqldOutputFile", func() {
+				appendToOutput("[ERROR] InnoDB: Operating system error number 5 in a file operation.\n")
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+
+				appendToOutput("[ERROR] InnoDB: Database page corruption on disk or a failed file read\n")
+
+				err = helper.CheckErrorLog()
+				Expect(err).To(MatchError(ContainSubstring("mysqld reported a fatal error in " + outputFile)))
+				Expect(err).To(MatchError(ContainSubstring("Database page corruption")))
+			})
+
+			It("ignores LogFileLocation", func() {
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+
+				appendToLog("[ERROR] mysqld: Read-only file system\n")
+
+				Expect(helper.CheckErrorLog()).To(Succeed())
+			})
+		})
+
+		Context("when MysqldOutput is galera-init", func() {
+			BeforeEach(func() {
+				dbConfig.MysqldOutput = config.MysqldOutputGaleraInit
+			})
+
+			It("has no file to scan and says so when mysqld starts", func() {
+				_, err := helper.StartMysqldInJoin()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(testLogger.Buffer()).To(Say("mysqld-output-not-scanned-for-fatal-errors"))
+
+				appendToLog("[ERROR] mysqld: Read-only file system\n")
+
+				Expect(helper.CheckErrorLog()).To(Succeed())
+			})
+		})
+	})
+
+	Describe("InitializeDatadirIfNeeded", func() {
+		Context("when the datadir has no mysql schema directory", func() {
+			BeforeEach(func() {
+				fakeOs.FileExistsReturns(false)
+			})
+
+			It("runs the install script against the datadir", func() {
+				Expect(helper.InitializeDatadirIfNeeded()).To(Succeed())
+
+				Expect(fakeOs.FileExistsArgsForCall(0)).To(Equal("/datadir/mysql"))
+				Expect(fakeOs.RunCommandCallCount()).To(Equal(1))
+				executable, args := fakeOs.RunCommandArgsForCall(0)
+				Expect(executable).To(Equal("/mysql_install_db"))
+				Expect(args).To(Equal([]string{
+					"--defaults-file=/var/vcap/jobs/pxc-mysql/config/my.cnf",
+					"--datadir=/datadir",
+				}))
+			})
+
+			It("returns the script output when it fails", func() {
+				fakeOs.RunCommandReturns("some output", errors.New("exit status 1"))
+
+				err := helper.InitializeDatadirIfNeeded()
+				Expect(err).To(MatchError("Error initializing datadir: some output: exit status 1"))
+			})
+		})
+
+		Context("when the datadir is already initialized", func() {
+			BeforeEach(func() {
+				fakeOs.FileExistsReturns(true)
+			})
+
+			It("does nothing", func() {
+				Expect(helper.InitializeDatadirIfNeeded()).To(Succeed())
+				Expect(fakeOs.RunCommandCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when no install script is configured", func() {
+			BeforeEach(func() {
+				dbConfig.InstallDBPath = ""
+			})
+
+			It("does nothing", func() {
+				Expect(helper.InitializeDatadirIfNeeded()).To(Succeed())
+				Expect(fakeOs.FileExistsCallCount()).To(Equal(0))
+				Expect(fakeOs.RunCommandCallCount()).To(Equal(0))
+			})
+		})
+	})
+
 	Describe("IsDatabaseReachable", func() {
 		galeraReadyQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_local\\_state\\_comment'`
 		wsrepProviderQuery := `SHOW GLOBAL VARIABLES LIKE 'wsrep\\_provider'`
+		selectOneQuery := `SELECT 1`
+
+		expectQueriesServed := func() {
+			mock.ExpectQuery(selectOneQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow("1"))
+		}
+
+		Describe("when the database connects but cannot serve queries", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(selectOneQuery).
+					WillReturnError(fmt.Errorf("Table 'mysql.user' doesn't exist"))
+			})
+
+			It("returns false", func() {
+				Expect(helper.IsDatabaseReachable()).To(BeFalse())
+			})
+		})
 
 		Describe("when the ready check fails", func() {
 			BeforeEach(func() {
+				expectQueriesServed()
 				mock.ExpectQuery(wsrepProviderQuery).
 					WillReturnError(fmt.Errorf("some error"))
 
@@ -252,6 +738,7 @@ var _ = Describe("GaleraDBHelper", func() {
 
 		Describe("when galera is enabled", func() {
 			BeforeEach(func() {
+				expectQueriesServed()
 				mock.ExpectQuery(wsrepProviderQuery).
 					WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
 						AddRow("wsrep_provider", "something other than none"))
@@ -287,6 +774,7 @@ var _ = Describe("GaleraDBHelper", func() {
 
 			Describe("when wsrep_provider is not even specified", func() {
 				It("returns true if it can query the db", func() {
+					expectQueriesServed()
 					mock.ExpectQuery(wsrepProviderQuery).
 						WillReturnError(sql.ErrNoRows)
 
@@ -295,6 +783,7 @@ var _ = Describe("GaleraDBHelper", func() {
 			})
 
 			It("returns true", func() {
+				expectQueriesServed()
 				mock.ExpectQuery(wsrepProviderQuery).
 					WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
 						AddRow("wsrep_provider", "none"))
@@ -304,7 +793,7 @@ var _ = Describe("GaleraDBHelper", func() {
 
 		Describe("when db connection can't be opened", func() {
 			BeforeEach(func() {
-				db_helper.OpenDBConnection = func(*config.DBHelper) (*sql.DB, error) {
+				db_helper.OpenDBConnection = func(*config.DBHelper, string) (*sql.DB, error) {
 					return nil, fmt.Errorf("whoops")
 				}
 			})
@@ -316,38 +805,583 @@ var _ = Describe("GaleraDBHelper", func() {
 
 	})
 
-	Describe("Seed", func() {
-		Context("when there are pre-seeded databases", func() {
-			Context("if the users already exist", func() {
-				BeforeEach(func() {
-					fakeSeeder.IsExistingUserReturns(true, nil)
+	Describe("Ping", func() {
+		It("returns true when mysqld accepts connections", func() {
+			Expect(helper.Ping()).To(BeTrue())
+		})
 
-					mock.ExpectExec("FLUSH PRIVILEGES").
-						WithArgs().
-						WillReturnResult(sqlmock.NewResult(lastInsertId, rowsAffected))
-				})
+		It("returns false when the connection can't be opened", func() {
+			db_helper.OpenDBConnection = func(*config.DBHelper, string) (*sql.DB, error) {
+				return nil, fmt.Errorf("whoops")
+			}
 
-				It("creates the specified databases if they don't exist and updates the users", func() {
-					helper.Seed()
+			Expect(helper.Ping()).To(BeFalse())
+		})
+	})
 
-					Expect(fakeSeeder.CreateDBIfNeededCallCount()).To(Equal(2))
-					Expect(fakeSeeder.IsExistingUserCallCount()).To(Equal(2))
-					Expect(fakeSeeder.CreateUserCallCount()).To(Equal(0))
-					Expect(fakeSeeder.UpdateUserCallCount()).To(Equal(2))
-					Expect(fakeSeeder.GrantUserPrivilegesCallCount()).To(Equal(2))
-				})
-			})
+	Describe("IsNodeEvicted", func() {
+		clusterStatusQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_cluster\\_status'`
+		gcommUUIDQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_gcomm\\_uuid'`
+		evictListQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_evs\\_evict\\_list'`
 
-			Context("if the users do not exist", func() {
-				BeforeEach(func() {
-					fakeSeeder.IsExistingUserReturns(false, nil)
+		Context("when the node is part of the primary component", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(clusterStatusQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+						AddRow("wsrep_cluster_status", "Primary"))
+			})
 
-					mock.ExpectExec("FLUSH PRIVILEGES").
-						WithArgs().
-						WillReturnResult(sqlmock.NewResult(lastInsertId, rowsAffected))
-				})
+			It("returns false", func() {
+				Expect(helper.IsNodeEvicted()).To(BeFalse())
+			})
+		})
 
-				It("creates the specified databases if they don't exist and creates users", func() {
+		Context("when the node is non-primary", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(clusterStatusQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+						AddRow("wsrep_cluster_status", "non-Primary"))
+				mock.ExpectQuery(gcommUUIDQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+						AddRow("wsrep_gcomm_uuid", "local-uuid"))
+			})
+
+			It("returns true when the evict list contains the local node", func() {
+				mock.ExpectQuery(evictListQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+						AddRow("wsrep_evs_evict_list", "other-uuid,local-uuid"))
+
+				Expect(helper.IsNodeEvicted()).To(BeTrue())
+			})
+
+			It("returns false when the evict list does not contain the local node", func() {
+				mock.ExpectQuery(evictListQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+						AddRow("wsrep_evs_evict_list", ""))
+
+				Expect(helper.IsNodeEvicted()).To(BeFalse())
+			})
+		})
+
+		Context("when the status query fails", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(clusterStatusQuery).WillReturnError(fmt.Errorf("some error"))
+			})
+
+			It("returns false", func() {
+				Expect(helper.IsNodeEvicted()).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("GetMaxConnections", func() {
+		maxConnectionsQuery := `SHOW GLOBAL VARIABLES LIKE 'max\\_connections'`
+
+		It("returns the configured max_connections", func() {
+			mock.ExpectQuery(maxConnectionsQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("max_connections", "151"))
+
+			maxConnections, err := helper.GetMaxConnections()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(maxConnections).To(Equal(151))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(maxConnectionsQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.GetMaxConnections()
+			Expect(err).To(MatchError("Error reading max_connections: some error"))
+		})
+	})
+
+	Describe("GetWsrepStatus", func() {
+		localStateCommentQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_local\\_state\\_comment'`
+		clusterStatusQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_cluster\\_status'`
+		clusterSizeQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_cluster\\_size'`
+
+		It("returns the local state, cluster status and cluster size", func() {
+			mock.ExpectQuery(localStateCommentQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_local_state_comment", "Joining: receiving State Transfer"))
+			mock.ExpectQuery(clusterStatusQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_cluster_status", "Primary"))
+			mock.ExpectQuery(clusterSizeQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_cluster_size", "3"))
+
+			status, err := helper.GetWsrepStatus()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(db_helper.WsrepStatus{
+				LocalStateComment: "Joining: receiving State Transfer",
+				ClusterStatus:     "Primary",
+				ClusterSize:       3,
+			}))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(localStateCommentQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.GetWsrepStatus()
+			Expect(err).To(MatchError("Error reading wsrep_local_state_comment: some error"))
+		})
+	})
+
+	Describe("GetServerIdentity", func() {
+		It("returns the server_id, wsrep_node_name and wsrep_node_address", func() {
+			mock.ExpectQuery(`SELECT @@server_id, @@wsrep_node_name, @@wsrep_node_address`).
+				WillReturnRows(sqlmock.NewRows([]string{"@@server_id", "@@wsrep_node_name", "@@wsrep_node_address"}).AddRow(2, "mysql-1", "10.0.0.2"))
+
+			Expect(helper.GetServerIdentity()).To(Equal(db_helper.ServerIdentity{ServerID: 2, WsrepNodeName: "mysql-1", WsrepNodeAddress: "10.0.0.2"}))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(`SELECT @@server_id`).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.GetServerIdentity()
+			Expect(err).To(MatchError("Error reading server identity: some error"))
+		})
+	})
+
+	Describe("GetClusterStateUUID", func() {
+		clusterStateUUIDQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_cluster\\_state\\_uuid'`
+
+		It("returns the cluster state UUID", func() {
+			mock.ExpectQuery(clusterStateUUIDQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_cluster_state_uuid", "6f2bbf1c-8c2a-11ee-9d8e-6b1f4a2b3c4d"))
+
+			Expect(helper.GetClusterStateUUID()).To(Equal("6f2bbf1c-8c2a-11ee-9d8e-6b1f4a2b3c4d"))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(clusterStateUUIDQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.GetClusterStateUUID()
+			Expect(err).To(MatchError("Error reading wsrep_cluster_state_uuid: some error"))
+		})
+	})
+
+	Describe("GetIncomingAddresses", func() {
+		incomingAddressesQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_incoming\\_addresses'`
+
+		It("returns each member's address", func() {
+			mock.ExpectQuery(incomingAddressesQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_incoming_addresses", "10.0.0.1:3306,10.0.0.2:3306"))
+
+			Expect(helper.GetIncomingAddresses()).To(Equal([]string{"10.0.0.1:3306", "10.0.0.2:3306"}))
+		})
+
+		It("returns no addresses when the value is empty", func() {
+			mock.ExpectQuery(incomingAddressesQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_incoming_addresses", ""))
+
+			Expect(helper.GetIncomingAddresses()).To(BeEmpty())
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(incomingAddressesQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.GetIncomingAddresses()
+			Expect(err).To(MatchError("Error reading wsrep_incoming_addresses: some error"))
+		})
+	})
+
+	Describe("IsFlowControlActive", func() {
+		flowControlQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_flow\\_control\\_status'`
+
+		It("returns true when flow control is engaged", func() {
+			mock.ExpectQuery(flowControlQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_flow_control_status", "ON"))
+
+			Expect(helper.IsFlowControlActive()).To(BeTrue())
+		})
+
+		It("returns false when flow control is not engaged", func() {
+			mock.ExpectQuery(flowControlQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_flow_control_status", "OFF"))
+
+			Expect(helper.IsFlowControlActive()).To(BeFalse())
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(flowControlQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.IsFlowControlActive()
+			Expect(err).To(MatchError("Error reading wsrep_flow_control_status: some error"))
+		})
+	})
+
+	Describe("SetDesync", func() {
+		It("turns wsrep_desync on", func() {
+			mock.ExpectExec("SET GLOBAL wsrep_desync = ON").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.SetDesync(true)).To(Succeed())
+		})
+
+		It("turns wsrep_desync off", func() {
+			mock.ExpectExec("SET GLOBAL wsrep_desync = OFF").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.SetDesync(false)).To(Succeed())
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectExec("SET GLOBAL wsrep_desync = ON").WillReturnError(fmt.Errorf("some error"))
+
+			Expect(helper.SetDesync(true)).To(MatchError("Error setting wsrep_desync: some error"))
+		})
+	})
+
+	Describe("SetDonorRejectsQueries", func() {
+		It("turns wsrep_sst_donor_rejects_queries on", func() {
+			mock.ExpectExec("SET GLOBAL wsrep_sst_donor_rejects_queries = ON").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.SetDonorRejectsQueries(true)).To(Succeed())
+		})
+
+		It("turns wsrep_sst_donor_rejects_queries off", func() {
+			mock.ExpectExec("SET GLOBAL wsrep_sst_donor_rejects_queries = OFF").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.SetDonorRejectsQueries(false)).To(Succeed())
+		})
+
+		It("returns an error when the statement fails", func() {
+			mock.ExpectExec("SET GLOBAL wsrep_sst_donor_rejects_queries").WillReturnError(fmt.Errorf("some error"))
+
+			Expect(helper.SetDonorRejectsQueries(true)).To(MatchError("Error setting wsrep_sst_donor_rejects_queries: some error"))
+		})
+	})
+
+	Describe("CheckSSTAuth", func() {
+		sstSettingsQuery := `SELECT @@wsrep_sst_method, @@wsrep_sst_auth`
+
+		It("leaves the check to the generated defaults file when SSTUser is set", func() {
+			dbConfig.SSTUser = "sst"
+			dbConfig.SSTPassword = "secret"
+
+			helper.CheckSSTAuth()
+			Expect(testLogger.Buffer()).NotTo(Say("sst-settings-check-failed"))
+			Expect(testLogger.Buffer()).NotTo(Say("warning-sst-credentials-missing"))
+		})
+
+		It("warns when the SST method needs credentials and none are configured", func() {
+			mock.ExpectQuery(sstSettingsQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"@@wsrep_sst_method", "@@wsrep_sst_auth"}).AddRow("mariabackup", nil))
+
+			helper.CheckSSTAuth()
+			Expect(testLogger.Buffer()).To(Say("warning-sst-credentials-missing"))
+		})
+
+		It("does not warn when my.cnf already provides wsrep_sst_auth", func() {
+			mock.ExpectQuery(sstSettingsQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"@@wsrep_sst_method", "@@wsrep_sst_auth"}).AddRow("mariabackup", "********"))
+
+			helper.CheckSSTAuth()
+			Expect(testLogger.Buffer()).NotTo(Say("warning-sst-credentials-missing"))
+		})
+
+		It("logs and carries on when the SST settings cannot be read and no SSTUser is set", func() {
+			mock.ExpectQuery(sstSettingsQuery).WillReturnError(fmt.Errorf("some error"))
+
+			helper.CheckSSTAuth()
+			Expect(testLogger.Buffer()).To(Say("sst-settings-check-failed"))
+		})
+
+		It("does not warn for SST methods without credentials", func() {
+			mock.ExpectQuery(sstSettingsQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"@@wsrep_sst_method", "@@wsrep_sst_auth"}).AddRow("rsync", nil))
+
+			helper.CheckSSTAuth()
+			Expect(testLogger.Buffer()).NotTo(Say("warning-sst-credentials-missing"))
+		})
+	})
+
+	Describe("IsDonorRejectingQueries", func() {
+		donorRejectsQueriesQuery := `SHOW GLOBAL VARIABLES LIKE 'wsrep\\_sst\\_donor\\_rejects\\_queries'`
+
+		It("reports the current setting", func() {
+			mock.ExpectQuery(donorRejectsQueriesQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_sst_donor_rejects_queries", "ON"))
+
+			Expect(helper.IsDonorRejectingQueries()).To(BeTrue())
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(donorRejectsQueriesQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.IsDonorRejectingQueries()
+			Expect(err).To(MatchError("Error reading wsrep_sst_donor_rejects_queries: some error"))
+		})
+	})
+
+	Describe("GetReplicationQueues", func() {
+		recvQueueQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_local\\_recv\\_queue'`
+		sendQueueQuery := `SHOW GLOBAL STATUS LIKE 'wsrep\\_local\\_send\\_queue'`
+
+		It("returns the receive and send queue depths", func() {
+			mock.ExpectQuery(recvQueueQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_local_recv_queue", "3"))
+			mock.ExpectQuery(sendQueueQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_local_send_queue", "1"))
+
+			Expect(helper.GetReplicationQueues()).To(Equal(db_helper.ReplicationQueues{Recv: 3, Send: 1}))
+		})
+
+		It("returns an error when a queue cannot be read", func() {
+			mock.ExpectQuery(recvQueueQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.GetReplicationQueues()
+			Expect(err).To(MatchError("Error reading wsrep_local_recv_queue: some error"))
+		})
+	})
+
+	Describe("IsDesynced", func() {
+		desyncQuery := `SHOW GLOBAL VARIABLES LIKE 'wsrep\\_desync'`
+
+		It("returns true when wsrep_desync is on", func() {
+			mock.ExpectQuery(desyncQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_desync", "ON"))
+
+			Expect(helper.IsDesynced()).To(BeTrue())
+		})
+
+		It("returns false when wsrep_desync is off", func() {
+			mock.ExpectQuery(desyncQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_desync", "OFF"))
+
+			Expect(helper.IsDesynced()).To(BeFalse())
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(desyncQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.IsDesynced()
+			Expect(err).To(MatchError("Error reading wsrep_desync: some error"))
+		})
+	})
+
+	Describe("CountClientConnections", func() {
+		processListQuery := `SELECT COUNT\(\*\) FROM information_schema.PROCESSLIST WHERE ID != CONNECTION_ID\(\)`
+
+		It("returns the number of client connections", func() {
+			mock.ExpectQuery(processListQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(4))
+
+			Expect(helper.CountClientConnections()).To(Equal(4))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(processListQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.CountClientConnections()
+			Expect(err).To(MatchError("Error counting client connections: some error"))
+		})
+	})
+
+	Describe("RotateUserPassword", func() {
+		userHostsQuery := `SELECT Host FROM mysql.user WHERE User = \?`
+
+		It("changes the password for every host the user is defined on", func() {
+			mock.ExpectQuery(userHostsQuery).WithArgs("app").
+				WillReturnRows(sqlmock.NewRows([]string{"Host"}).AddRow("%").AddRow("localhost"))
+			mock.ExpectExec(`ALTER USER 'app'@'%' IDENTIFIED BY 'it\\'s-new'`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`ALTER USER 'app'@'localhost' IDENTIFIED BY 'it\\'s-new'`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.RotateUserPassword("app", "it's-new")).To(Succeed())
+			Expect(dbConfig.Password).To(Equal("password"))
+		})
+
+		It("uses the new password for its own connections when rotating the admin user", func() {
+			mock.ExpectQuery(userHostsQuery).WithArgs("user").
+				WillReturnRows(sqlmock.NewRows([]string{"Host"}).AddRow("localhost"))
+			mock.ExpectExec(`ALTER USER 'user'@'localhost' IDENTIFIED BY 'new-password'`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.RotateUserPassword("user", "new-password")).To(Succeed())
+
+			var openedWith *config.DBHelper
+			db_helper.OpenDBConnection = func(cfg *config.DBHelper, _ string) (*sql.DB, error) {
+				openedWith = cfg
+				return fakeDB, nil
+			}
+			helper.Ping()
+
+			Expect(openedWith.Password).To(Equal("new-password"))
+			Expect(dbConfig.Password).To(Equal("password"))
+		})
+
+		It("returns an error when the user does not exist", func() {
+			mock.ExpectQuery(userHostsQuery).WithArgs("missing").
+				WillReturnRows(sqlmock.NewRows([]string{"Host"}))
+
+			Expect(helper.RotateUserPassword("missing", "new-password")).To(MatchError("User missing does not exist"))
+		})
+
+		It("returns an error when the password cannot be changed", func() {
+			mock.ExpectQuery(userHostsQuery).WithArgs("app").
+				WillReturnRows(sqlmock.NewRows([]string{"Host"}).AddRow("%"))
+			mock.ExpectExec(`ALTER USER`).WillReturnError(fmt.Errorf("some error"))
+
+			Expect(helper.RotateUserPassword("app", "new-password")).To(MatchError("Error changing password for app: some error"))
+		})
+
+		It("records the rotation of a seeded user before changing its password", func() {
+			mock.ExpectQuery(userHostsQuery).WithArgs("user1").
+				WillReturnRows(sqlmock.NewRows([]string{"Host"}).AddRow("host1"))
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS mysql.galera_init_rotated_users").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`REPLACE INTO mysql.galera_init_rotated_users \(User, ConfiguredPasswordHash\) VALUES \(\?, \?\)`).
+				WithArgs("user1", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(`ALTER USER 'user1'@'host1' IDENTIFIED BY 'new-password'`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.RotateUserPassword("user1", "new-password")).To(Succeed())
+		})
+
+		It("does not change the password when the rotation cannot be recorded", func() {
+			mock.ExpectQuery(userHostsQuery).WithArgs("user1").
+				WillReturnRows(sqlmock.NewRows([]string{"Host"}).AddRow("host1"))
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS mysql.galera_init_rotated_users").
+				WillReturnError(fmt.Errorf("read only"))
+
+			Expect(helper.RotateUserPassword("user1", "new-password")).To(MatchError("Error recording rotation for user1: read only"))
+		})
+	})
+
+	Describe("MissingPreseededDatabases", func() {
+		It("returns the preseeded databases that do not exist locally", func() {
+			mock.ExpectExec("SHOW TABLES FROM `DB1`").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("SHOW TABLES FROM `DB2`").WillReturnError(fmt.Errorf("Unknown database 'DB2'"))
+
+			Expect(helper.MissingPreseededDatabases()).To(Equal([]string{"DB2"}))
+		})
+
+		It("returns nothing when every preseeded database exists", func() {
+			mock.ExpectExec("SHOW TABLES FROM `DB1`").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("SHOW TABLES FROM `DB2`").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.MissingPreseededDatabases()).To(BeEmpty())
+		})
+	})
+
+	Describe("RunQuery", func() {
+		It("executes the query", func() {
+			mock.ExpectExec("ANALYZE TABLE foo.bar").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.RunQuery("ANALYZE TABLE foo.bar")).To(Succeed())
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectExec("ANALYZE TABLE foo.bar").WillReturnError(fmt.Errorf("some error"))
+
+			Expect(helper.RunQuery("ANALYZE TABLE foo.bar")).To(MatchError("some error"))
+		})
+	})
+
+	Describe("GetBufferPoolSize", func() {
+		bufferPoolSizeQuery := `SHOW GLOBAL VARIABLES LIKE 'innodb\\_buffer\\_pool\\_size'`
+
+		It("returns the configured innodb_buffer_pool_size", func() {
+			mock.ExpectQuery(bufferPoolSizeQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("innodb_buffer_pool_size", "134217728"))
+
+			size, err := helper.GetBufferPoolSize()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(size).To(Equal(uint64(134217728)))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(bufferPoolSizeQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.GetBufferPoolSize()
+			Expect(err).To(MatchError("Error reading innodb_buffer_pool_size: some error"))
+		})
+	})
+
+	Describe("GetMaxAllowedPacket", func() {
+		maxAllowedPacketQuery := `SHOW GLOBAL VARIABLES LIKE 'max\\_allowed\\_packet'`
+
+		It("returns max_allowed_packet", func() {
+			mock.ExpectQuery(maxAllowedPacketQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("max_allowed_packet", "16777216"))
+
+			size, err := helper.GetMaxAllowedPacket()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(size).To(Equal(uint64(16777216)))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery(maxAllowedPacketQuery).WillReturnError(fmt.Errorf("some error"))
+
+			_, err := helper.GetMaxAllowedPacket()
+			Expect(err).To(MatchError("Error reading max_allowed_packet: some error"))
+		})
+	})
+
+	Describe("SetMaxAllowedPacket", func() {
+		It("sets the global value", func() {
+			mock.ExpectExec("SET GLOBAL max_allowed_packet = 67108864").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(helper.SetMaxAllowedPacket(67108864)).To(Succeed())
+		})
+
+		It("returns an error when the statement fails", func() {
+			mock.ExpectExec("SET GLOBAL max_allowed_packet = 67108864").WillReturnError(fmt.Errorf("some error"))
+
+			Expect(helper.SetMaxAllowedPacket(67108864)).To(MatchError("Error setting max_allowed_packet: some error"))
+		})
+	})
+
+	Describe("Seed", func() {
+		Context("when there are pre-seeded databases", func() {
+			Context("if the users already exist", func() {
+				BeforeEach(func() {
+					fakeSeeder.IsExistingUserReturns(true, nil)
+
+					mock.ExpectExec("FLUSH PRIVILEGES").
+						WithArgs().
+						WillReturnResult(sqlmock.NewResult(lastInsertId, rowsAffected))
+				})
+
+				It("creates the specified databases if they don't exist and updates the users", func() {
+					helper.Seed()
+
+					Expect(fakeSeeder.CreateDBIfNeededCallCount()).To(Equal(2))
+					Expect(fakeSeeder.IsExistingUserCallCount()).To(Equal(2))
+					Expect(fakeSeeder.CreateUserCallCount()).To(Equal(0))
+					Expect(fakeSeeder.UpdateUserCallCount()).To(Equal(2))
+					Expect(fakeSeeder.GrantUserPrivilegesCallCount()).To(Equal(2))
+				})
+			})
+
+			Context("if the users do not exist", func() {
+				BeforeEach(func() {
+					fakeSeeder.IsExistingUserReturns(false, nil)
+
+					mock.ExpectExec("FLUSH PRIVILEGES").
+						WithArgs().
+						WillReturnResult(sqlmock.NewResult(lastInsertId, rowsAffected))
+				})
+
+				It("creates the specified databases if they don't exist and creates users", func() {
 					helper.Seed()
 
 					Expect(fakeSeeder.CreateDBIfNeededCallCount()).To(Equal(2))
@@ -357,6 +1391,25 @@ var _ = Describe("GaleraDBHelper", func() {
 				})
 			})
 
+			Context("when the database does not accept connections yet", func() {
+				BeforeEach(func() {
+					dbConfig.SeedConnectAttempts = 3
+					db_helper.OpenDBConnection = func(*config.DBHelper, string) (*sql.DB, error) {
+						return sql.Open("mysql", "user@unix(/does-not-exist/mysqld.sock)/")
+					}
+				})
+
+				It("retries connecting before giving up without seeding", func() {
+					err := helper.Seed()
+					Expect(err).To(MatchError(ContainSubstring("Could not connect to seed the database")))
+
+					Expect(fakeOs.SleepCallCount()).To(Equal(2))
+					Expect(testLogger.Buffer()).To(Say("seed-connection-not-ready"))
+					Expect(testLogger.Buffer()).To(Say("seed-connection-failed"))
+					Expect(fakeSeeder.CreateDBIfNeededCallCount()).To(Equal(0))
+				})
+			})
+
 			Context("when a seeder function call returns an error", func() {
 				It("returns the error back", func() {
 					fakeSeeder.CreateDBIfNeededReturns(errors.New("Error"))
@@ -401,6 +1454,10 @@ var _ = Describe("GaleraDBHelper", func() {
 	})
 
 	Describe("SeedUsers", func() {
+		BeforeEach(func() {
+			expectNoRotatedUsers()
+		})
+
 		It("seeds the users", func() {
 			helper.SeedUsers()
 			Expect(fakeUserSeeder.SeedUserCallCount()).To(Equal(2))
@@ -416,6 +1473,86 @@ var _ = Describe("GaleraDBHelper", func() {
 			Expect(call1role).To(Equal("role2"))
 		})
 
+		Context("when a read-only user is configured", func() {
+			BeforeEach(func() {
+				dbConfig.ReadOnlyUser = "read-only-user"
+				dbConfig.ReadOnlyPassword = "read-only-password"
+				dbConfig.ReadOnlyUserHost = "%"
+			})
+
+			It("seeds the read-only user after the other users", func() {
+				Expect(helper.SeedUsers()).To(Succeed())
+				Expect(fakeUserSeeder.SeedUserCallCount()).To(Equal(3))
+				user, password, host, role := fakeUserSeeder.SeedUserArgsForCall(2)
+				Expect(user).To(Equal("read-only-user"))
+				Expect(password).To(Equal("read-only-password"))
+				Expect(host).To(Equal("%"))
+				Expect(role).To(Equal("read-only"))
+			})
+
+			Context("when no other users are configured", func() {
+				BeforeEach(func() {
+					dbConfig.SeededUsers = nil
+				})
+
+				It("still seeds the read-only user", func() {
+					Expect(helper.SeedUsers()).To(Succeed())
+					Expect(fakeUserSeeder.SeedUserCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when read-only users are not managed by galera-init", func() {
+				BeforeEach(func() {
+					dbConfig.ManageReadOnlyUser = false
+					dbConfig.ReadOnlyUsers = []config.ReadOnlyUser{
+						{User: "reporting", Password: "reporting-password"},
+					}
+				})
+
+				It("seeds only the other users", func() {
+					Expect(helper.SeedUsers()).To(Succeed())
+					Expect(fakeUserSeeder.SeedUserCallCount()).To(Equal(2))
+					for i := 0; i < fakeUserSeeder.SeedUserCallCount(); i++ {
+						user, _, _, _ := fakeUserSeeder.SeedUserArgsForCall(i)
+						Expect(user).NotTo(Equal("read-only-user"))
+					}
+					Expect(fakeUserSeeder.SeedReadOnlyUserCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when scoped read-only users are configured", func() {
+			BeforeEach(func() {
+				dbConfig.SeededUsers = nil
+				dbConfig.ReadOnlyUserHost = "%"
+				dbConfig.ReadOnlyUsers = []config.ReadOnlyUser{
+					{User: "reporting", Password: "reporting-password", Host: "localhost", Databases: []string{"orders", "invoices"}},
+					{User: "auditor", Password: "auditor-password"},
+				}
+			})
+
+			It("seeds each user with its own databases", func() {
+				Expect(helper.SeedUsers()).To(Succeed())
+				Expect(fakeUserSeeder.SeedReadOnlyUserCallCount()).To(Equal(2))
+
+				user, password, host, databases := fakeUserSeeder.SeedReadOnlyUserArgsForCall(0)
+				Expect(user).To(Equal("reporting"))
+				Expect(password).To(Equal("reporting-password"))
+				Expect(host).To(Equal("localhost"))
+				Expect(databases).To(Equal([]string{"orders", "invoices"}))
+
+				user, _, host, databases = fakeUserSeeder.SeedReadOnlyUserArgsForCall(1)
+				Expect(user).To(Equal("auditor"))
+				Expect(host).To(Equal("%"))
+				Expect(databases).To(BeEmpty())
+			})
+
+			It("returns the error when seeding a read-only user fails", func() {
+				fakeUserSeeder.SeedReadOnlyUserReturns(errors.New("Error"))
+				Expect(helper.SeedUsers()).To(MatchError("Error"))
+			})
+		})
+
 		Context("when a seeder function call returns an error", func() {
 			It("returns the error back", func() {
 				fakeUserSeeder.SeedUserReturns(errors.New("Error"))
@@ -425,18 +1562,168 @@ var _ = Describe("GaleraDBHelper", func() {
 		})
 	})
 
-	Describe("RunPostStartSQL", func() {
-		It("runs the contents of the specified files", func() {
-			mock.ExpectExec(fakeSupplementalQuery1).WillReturnResult(sqlmock.NewResult(lastInsertId, rowsAffected))
-			mock.ExpectExec(fakeSupplementalQuery2).WillReturnResult(sqlmock.NewResult(lastInsertId, rowsAffected))
+	Describe("SeedUsers after a rotation", func() {
+		It("returns an error when the rotated users cannot be read", func() {
+			mock.ExpectQuery(rotatedUsersQuery).WillReturnError(fmt.Errorf("some error"))
 
-			err := helper.RunPostStartSQL()
+			Expect(helper.SeedUsers()).To(MatchError("Error reading rotated users: some error"))
+			Expect(fakeUserSeeder.SeedUserCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("ReconcileUsers", func() {
+		grantsRows := func(grants ...string) sqlmock.Rows {
+			rows := sqlmock.NewRows([]string{"Grants"})
+			for _, grant := range grants {
+				rows = rows.AddRow(grant)
+			}
+			return rows
+		}
+
+		It("re-applies the users and returns the accounts whose grants changed", func() {
+			mock.ExpectQuery("SHOW GRANTS FOR `user1`@`host1`").
+				WillReturnRows(grantsRows("GRANT ALL PRIVILEGES ON *.* TO `user1`@`host1`"))
+			mock.ExpectQuery("SHOW GRANTS FOR `user2`@`host2`").
+				WillReturnError(fmt.Errorf("There is no such grant defined for user 'user2' on host 'host2'"))
+			expectNoRotatedUsers()
+			mock.ExpectQuery("SHOW GRANTS FOR `user1`@`host1`").
+				WillReturnRows(grantsRows("GRANT ALL PRIVILEGES ON *.* TO `user1`@`host1`"))
+			mock.ExpectQuery("SHOW GRANTS FOR `user2`@`host2`").
+				WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user2`@`host2`"))
+
+			corrected, err := helper.ReconcileUsers()
 			Expect(err).NotTo(HaveOccurred())
+			Expect(corrected).To(Equal([]string{"`user2`@`host2`"}))
+			Expect(fakeUserSeeder.SeedUserCallCount()).To(Equal(2))
+		})
+
+		It("returns an error when re-applying the users fails", func() {
+			mock.ExpectQuery("SHOW GRANTS FOR `user1`@`host1`").
+				WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user1`@`host1`"))
+			mock.ExpectQuery("SHOW GRANTS FOR `user2`@`host2`").
+				WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user2`@`host2`"))
+			expectNoRotatedUsers()
+			fakeUserSeeder.SeedUserReturns(errors.New("grant failed"))
+
+			_, err := helper.ReconcileUsers()
+			Expect(err).To(MatchError(ContainSubstring("grant failed")))
+		})
+
+		Context("when another helper rotated a user's password", func() {
+			var recordedHash *capturedArg
+
+			BeforeEach(func() {
+				recordedHash = &capturedArg{}
+
+				rotatingHelper := db_helper.NewDBHelper(
+					fakeOs,
+					db_helper.NewDirectProcessManager(fakeOs),
+					dbConfig,
+					logFile,
+					testLogger,
+				)
+
+				mock.ExpectQuery(`SELECT Host FROM mysql.user WHERE User = \?`).WithArgs("user1").
+					WillReturnRows(sqlmock.NewRows([]string{"Host"}).AddRow("host1"))
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS mysql.galera_init_rotated_users").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("REPLACE INTO mysql.galera_init_rotated_users").
+					WithArgs("user1", recordedHash).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`ALTER USER 'user1'@'host1' IDENTIFIED BY 'rotated-password'`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				Expect(rotatingHelper.RotateUserPassword("user1", "rotated-password")).To(Succeed())
+			})
+
+			It("leaves the rotated user alone", func() {
+				mock.ExpectQuery("SHOW GRANTS FOR `user1`@`host1`").
+					WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user1`@`host1`"))
+				mock.ExpectQuery("SHOW GRANTS FOR `user2`@`host2`").
+					WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user2`@`host2`"))
+				mock.ExpectQuery(rotatedUsersQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"User", "ConfiguredPasswordHash"}).AddRow("user1", recordedHash.value))
+				mock.ExpectQuery("SHOW GRANTS FOR `user1`@`host1`").
+					WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user1`@`host1`"))
+				mock.ExpectQuery("SHOW GRANTS FOR `user2`@`host2`").
+					WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user2`@`host2`"))
+
+				corrected, err := helper.ReconcileUsers()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(corrected).To(BeEmpty())
+				Expect(fakeUserSeeder.SeedUserCallCount()).To(Equal(1))
+				user, _, _, _ := fakeUserSeeder.SeedUserArgsForCall(0)
+				Expect(user).To(Equal("user2"))
+				Expect(testLogger.Buffer()).To(Say("skipping-rotated-user"))
+			})
+
+			It("seeds the user again and forgets the rotation once its configured password changes", func() {
+				dbConfig.SeededUsers[0].Password = "rotated-password"
+
+				mock.ExpectQuery("SHOW GRANTS FOR `user1`@`host1`").
+					WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user1`@`host1`"))
+				mock.ExpectQuery("SHOW GRANTS FOR `user2`@`host2`").
+					WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user2`@`host2`"))
+				mock.ExpectQuery(rotatedUsersQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"User", "ConfiguredPasswordHash"}).AddRow("user1", recordedHash.value))
+				mock.ExpectExec(`DELETE FROM mysql.galera_init_rotated_users WHERE User = \?`).WithArgs("user1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SHOW GRANTS FOR `user1`@`host1`").
+					WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user1`@`host1`"))
+				mock.ExpectQuery("SHOW GRANTS FOR `user2`@`host2`").
+					WillReturnRows(grantsRows("GRANT USAGE ON *.* TO `user2`@`host2`"))
+
+				_, err := helper.ReconcileUsers()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeUserSeeder.SeedUserCallCount()).To(Equal(2))
+				user, password, _, _ := fakeUserSeeder.SeedUserArgsForCall(0)
+				Expect(user).To(Equal("user1"))
+				Expect(password).To(Equal("rotated-password"))
+				Expect(testLogger.Buffer()).To(Say("rotated-user-configuration-changed"))
+			})
+		})
+	})
+
+	Describe("ForSteadyState", func() {
+		var (
+			openedWith []config.DBHelper
+			openedOver []string
+		)
+
+		BeforeEach(func() {
+			openedWith = nil
+			openedOver = nil
+			db_helper.OpenDBConnection = func(cfg *config.DBHelper, protocol string) (*sql.DB, error) {
+				openedWith = append(openedWith, *cfg)
+				openedOver = append(openedOver, protocol)
+				return fakeDB, nil
+			}
 		})
 
-		It("returns an error when the database failes to execute a query", func() {
-			err := helper.RunPostStartSQL()
-			Expect(err).To(HaveOccurred())
+		It("connects with SteadyStateProtocol, leaving the startup helper on the socket", func() {
+			dbConfig.SteadyStateProtocol = "tcp"
+
+			helper.ForSteadyState().Ping()
+			helper.Ping()
+
+			Expect(openedOver).To(Equal([]string{"tcp", ""}))
+		})
+
+		It("shares a password rotated through either helper", func() {
+			steadyStateHelper := helper.ForSteadyState()
+
+			mock.ExpectQuery(`SELECT Host FROM mysql.user WHERE User = \?`).WithArgs("user").
+				WillReturnRows(sqlmock.NewRows([]string{"Host"}).AddRow("localhost"))
+			mock.ExpectExec(`ALTER USER 'user'@'localhost' IDENTIFIED BY 'new-password'`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			Expect(helper.RotateUserPassword("user", "new-password")).To(Succeed())
+
+			openedWith = nil
+			steadyStateHelper.Ping()
+			helper.ForSteadyState().Ping()
+
+			Expect(openedWith).To(HaveLen(2))
+			Expect(openedWith[0].Password).To(Equal("new-password"))
+			Expect(openedWith[1].Password).To(Equal("new-password"))
 		})
 	})
 
@@ -450,7 +1737,7 @@ var _ = Describe("GaleraDBHelper", func() {
 					User:       "some-user",
 				}
 
-				Expect(db_helper.FormatDSN(config)).To(Equal(`some-user:some-password@unix(/some/socket/path.sock)/?sql_log_bin=off`))
+				Expect(db_helper.FormatDSN(config, "")).To(Equal(`some-user:some-password@unix(/some/socket/path.sock)/?sql_log_bin=off`))
 			})
 		})
 
@@ -462,8 +1749,106 @@ var _ = Describe("GaleraDBHelper", func() {
 					User:     "some-user",
 				}
 
-				Expect(db_helper.FormatDSN(config)).To(Equal(`some-user:some-password@unix(/some/socket/path.sock)/`))
+				Expect(db_helper.FormatDSN(config, "")).To(Equal(`some-user:some-password@unix(/some/socket/path.sock)/`))
+			})
+		})
+
+		Context("When connecting over TCP", func() {
+			It("formats a connection string for TCPAddress", func() {
+				config := config.DBHelper{
+					Password:   "some-password",
+					Socket:     "/some/socket/path.sock",
+					TCPAddress: "127.0.0.1:3306",
+					User:       "some-user",
+				}
+
+				Expect(db_helper.FormatDSN(config, "tcp")).To(Equal(`some-user:some-password@tcp(127.0.0.1:3306)/`))
+			})
+
+			Context("with TCPKeepAliveInterval set", func() {
+				var (
+					listener net.Listener
+					accepted chan struct{}
+					dbConfig config.DBHelper
+				)
+
+				BeforeEach(func() {
+					var err error
+					listener, err = net.Listen("tcp", "127.0.0.1:0")
+					Expect(err).NotTo(HaveOccurred())
+
+					accepted = make(chan struct{}, 1)
+					go func() {
+						for {
+							conn, err := listener.Accept()
+							if err != nil {
+								return
+							}
+							select {
+							case accepted <- struct{}{}:
+							default:
+							}
+							conn.Close()
+						}
+					}()
+
+					dbConfig = config.DBHelper{
+						ConnectTimeout:       1,
+						Password:             "some-password",
+						TCPAddress:           listener.Addr().String(),
+						TCPKeepAliveCount:    3,
+						TCPKeepAliveInterval: 15,
+						User:                 "some-user",
+					}
+				})
+
+				AfterEach(func() {
+					listener.Close()
+				})
+
+				It("connects to TCPAddress through the keepalive dial", func() {
+					db_helper.NewDBHelper(nil, nil, &dbConfig, "", lagertest.NewTestLogger("db_helper"))
+
+					dsn := db_helper.FormatDSN(dbConfig, "tcp")
+					Expect(dsn).To(HavePrefix(`some-user:some-password@galera-init-tcp(` + listener.Addr().String() + `)/`))
+
+					db, err := sql.Open("mysql", dsn)
+					Expect(err).NotTo(HaveOccurred())
+					defer db.Close()
+
+					db.Ping()
+					Eventually(accepted).Should(Receive())
+				})
+			})
+		})
+
+		Context("When ConnectTimeout is set", func() {
+			It("applies the timeout to connection attempts", func() {
+				config := config.DBHelper{
+					ConnectTimeout: 3,
+					Password:       "some-password",
+					Socket:         "/some/socket/path.sock",
+					User:           "some-user",
+				}
+
+				dsn := db_helper.FormatDSN(config, "")
+				Expect(dsn).To(Equal(`some-user:some-password@unix(/some/socket/path.sock)/?timeout=3s`))
+
+				parsed, err := mysql.ParseDSN(dsn)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parsed.Timeout).To(Equal(3 * time.Second))
 			})
 		})
 	})
 })
+
+// capturedArg matches any query argument and keeps the value, so that what one
+// helper writes can be handed to the rows another helper reads.
+type capturedArg struct {
+	value driver.Value
+}
+
+func (a *capturedArg) Match(v driver.Value) bool {
+	a.value = v
+	return true
+}
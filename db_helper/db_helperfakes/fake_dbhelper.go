@@ -9,6 +9,158 @@ import (
 )
 
 type FakeDBHelper struct {
+	CheckAllowedDatabasesStub        func() error
+	checkAllowedDatabasesMutex       sync.RWMutex
+	checkAllowedDatabasesArgsForCall []struct {
+	}
+	checkAllowedDatabasesReturns struct {
+		result1 error
+	}
+	checkAllowedDatabasesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CheckDatadirWritableStub        func() error
+	checkDatadirWritableMutex       sync.RWMutex
+	checkDatadirWritableArgsForCall []struct {
+	}
+	checkDatadirWritableReturns struct {
+		result1 error
+	}
+	checkDatadirWritableReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CheckErrorLogStub        func() error
+	checkErrorLogMutex       sync.RWMutex
+	checkErrorLogArgsForCall []struct {
+	}
+	checkErrorLogReturns struct {
+		result1 error
+	}
+	checkErrorLogReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CheckSSTAuthStub        func()
+	checkSSTAuthMutex       sync.RWMutex
+	checkSSTAuthArgsForCall []struct {
+	}
+	CountClientConnectionsStub        func() (int, error)
+	countClientConnectionsMutex       sync.RWMutex
+	countClientConnectionsArgsForCall []struct {
+	}
+	countClientConnectionsReturns struct {
+		result1 int
+		result2 error
+	}
+	countClientConnectionsReturnsOnCall map[int]struct {
+		result1 int
+		result2 error
+	}
+	GetBufferPoolSizeStub        func() (uint64, error)
+	getBufferPoolSizeMutex       sync.RWMutex
+	getBufferPoolSizeArgsForCall []struct {
+	}
+	getBufferPoolSizeReturns struct {
+		result1 uint64
+		result2 error
+	}
+	getBufferPoolSizeReturnsOnCall map[int]struct {
+		result1 uint64
+		result2 error
+	}
+	GetClusterStateUUIDStub        func() (string, error)
+	getClusterStateUUIDMutex       sync.RWMutex
+	getClusterStateUUIDArgsForCall []struct {
+	}
+	getClusterStateUUIDReturns struct {
+		result1 string
+		result2 error
+	}
+	getClusterStateUUIDReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	GetIncomingAddressesStub        func() ([]string, error)
+	getIncomingAddressesMutex       sync.RWMutex
+	getIncomingAddressesArgsForCall []struct {
+	}
+	getIncomingAddressesReturns struct {
+		result1 []string
+		result2 error
+	}
+	getIncomingAddressesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	GetMaxAllowedPacketStub        func() (uint64, error)
+	getMaxAllowedPacketMutex       sync.RWMutex
+	getMaxAllowedPacketArgsForCall []struct {
+	}
+	getMaxAllowedPacketReturns struct {
+		result1 uint64
+		result2 error
+	}
+	getMaxAllowedPacketReturnsOnCall map[int]struct {
+		result1 uint64
+		result2 error
+	}
+	GetMaxConnectionsStub        func() (int, error)
+	getMaxConnectionsMutex       sync.RWMutex
+	getMaxConnectionsArgsForCall []struct {
+	}
+	getMaxConnectionsReturns struct {
+		result1 int
+		result2 error
+	}
+	getMaxConnectionsReturnsOnCall map[int]struct {
+		result1 int
+		result2 error
+	}
+	GetReplicationQueuesStub        func() (db_helper.ReplicationQueues, error)
+	getReplicationQueuesMutex       sync.RWMutex
+	getReplicationQueuesArgsForCall []struct {
+	}
+	getReplicationQueuesReturns struct {
+		result1 db_helper.ReplicationQueues
+		result2 error
+	}
+	getReplicationQueuesReturnsOnCall map[int]struct {
+		result1 db_helper.ReplicationQueues
+		result2 error
+	}
+	GetServerIdentityStub        func() (db_helper.ServerIdentity, error)
+	getServerIdentityMutex       sync.RWMutex
+	getServerIdentityArgsForCall []struct {
+	}
+	getServerIdentityReturns struct {
+		result1 db_helper.ServerIdentity
+		result2 error
+	}
+	getServerIdentityReturnsOnCall map[int]struct {
+		result1 db_helper.ServerIdentity
+		result2 error
+	}
+	GetWsrepStatusStub        func() (db_helper.WsrepStatus, error)
+	getWsrepStatusMutex       sync.RWMutex
+	getWsrepStatusArgsForCall []struct {
+	}
+	getWsrepStatusReturns struct {
+		result1 db_helper.WsrepStatus
+		result2 error
+	}
+	getWsrepStatusReturnsOnCall map[int]struct {
+		result1 db_helper.WsrepStatus
+		result2 error
+	}
+	InitializeDatadirIfNeededStub        func() error
+	initializeDatadirIfNeededMutex       sync.RWMutex
+	initializeDatadirIfNeededArgsForCall []struct {
+	}
+	initializeDatadirIfNeededReturns struct {
+		result1 error
+	}
+	initializeDatadirIfNeededReturnsOnCall map[int]struct {
+		result1 error
+	}
 	IsDatabaseReachableStub        func() bool
 	isDatabaseReachableMutex       sync.RWMutex
 	isDatabaseReachableArgsForCall []struct {
@@ -19,6 +171,52 @@ type FakeDBHelper struct {
 	isDatabaseReachableReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	IsDesyncedStub        func() (bool, error)
+	isDesyncedMutex       sync.RWMutex
+	isDesyncedArgsForCall []struct {
+	}
+	isDesyncedReturns struct {
+		result1 bool
+		result2 error
+	}
+	isDesyncedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	IsDonorRejectingQueriesStub        func() (bool, error)
+	isDonorRejectingQueriesMutex       sync.RWMutex
+	isDonorRejectingQueriesArgsForCall []struct {
+	}
+	isDonorRejectingQueriesReturns struct {
+		result1 bool
+		result2 error
+	}
+	isDonorRejectingQueriesReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	IsFlowControlActiveStub        func() (bool, error)
+	isFlowControlActiveMutex       sync.RWMutex
+	isFlowControlActiveArgsForCall []struct {
+	}
+	isFlowControlActiveReturns struct {
+		result1 bool
+		result2 error
+	}
+	isFlowControlActiveReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	IsNodeEvictedStub        func() bool
+	isNodeEvictedMutex       sync.RWMutex
+	isNodeEvictedArgsForCall []struct {
+	}
+	isNodeEvictedReturns struct {
+		result1 bool
+	}
+	isNodeEvictedReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	IsProcessRunningStub        func() bool
 	isProcessRunningMutex       sync.RWMutex
 	isProcessRunningArgsForCall []struct {
@@ -29,6 +227,50 @@ type FakeDBHelper struct {
 	isProcessRunningReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	MissingPreseededDatabasesStub        func() []string
+	missingPreseededDatabasesMutex       sync.RWMutex
+	missingPreseededDatabasesArgsForCall []struct {
+	}
+	missingPreseededDatabasesReturns struct {
+		result1 []string
+	}
+	missingPreseededDatabasesReturnsOnCall map[int]struct {
+		result1 []string
+	}
+	PingStub        func() bool
+	pingMutex       sync.RWMutex
+	pingArgsForCall []struct {
+	}
+	pingReturns struct {
+		result1 bool
+	}
+	pingReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	ReconcileUsersStub        func() ([]string, error)
+	reconcileUsersMutex       sync.RWMutex
+	reconcileUsersArgsForCall []struct {
+	}
+	reconcileUsersReturns struct {
+		result1 []string
+		result2 error
+	}
+	reconcileUsersReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	RotateUserPasswordStub        func(string, string) error
+	rotateUserPasswordMutex       sync.RWMutex
+	rotateUserPasswordArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	rotateUserPasswordReturns struct {
+		result1 error
+	}
+	rotateUserPasswordReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RunPostStartSQLStub        func() error
 	runPostStartSQLMutex       sync.RWMutex
 	runPostStartSQLArgsForCall []struct {
@@ -39,6 +281,28 @@ type FakeDBHelper struct {
 	runPostStartSQLReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RunQueryStub        func(string) error
+	runQueryMutex       sync.RWMutex
+	runQueryArgsForCall []struct {
+		arg1 string
+	}
+	runQueryReturns struct {
+		result1 error
+	}
+	runQueryReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RunSQLFileStub        func(string) error
+	runSQLFileMutex       sync.RWMutex
+	runSQLFileArgsForCall []struct {
+		arg1 string
+	}
+	runSQLFileReturns struct {
+		result1 error
+	}
+	runSQLFileReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SeedStub        func() error
 	seedMutex       sync.RWMutex
 	seedArgsForCall []struct {
@@ -59,6 +323,39 @@ type FakeDBHelper struct {
 	seedUsersReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetDesyncStub        func(bool) error
+	setDesyncMutex       sync.RWMutex
+	setDesyncArgsForCall []struct {
+		arg1 bool
+	}
+	setDesyncReturns struct {
+		result1 error
+	}
+	setDesyncReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetDonorRejectsQueriesStub        func(bool) error
+	setDonorRejectsQueriesMutex       sync.RWMutex
+	setDonorRejectsQueriesArgsForCall []struct {
+		arg1 bool
+	}
+	setDonorRejectsQueriesReturns struct {
+		result1 error
+	}
+	setDonorRejectsQueriesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetMaxAllowedPacketStub        func(uint64) error
+	setMaxAllowedPacketMutex       sync.RWMutex
+	setMaxAllowedPacketArgsForCall []struct {
+		arg1 uint64
+	}
+	setMaxAllowedPacketReturns struct {
+		result1 error
+	}
+	setMaxAllowedPacketReturnsOnCall map[int]struct {
+		result1 error
+	}
 	StartMysqldForUpgradeStub        func() (*exec.Cmd, error)
 	startMysqldForUpgradeMutex       sync.RWMutex
 	startMysqldForUpgradeArgsForCall []struct {
@@ -107,62 +404,1005 @@ type FakeDBHelper struct {
 		result1 string
 		result2 error
 	}
-	upgradeReturnsOnCall map[int]struct {
-		result1 string
+	upgradeReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDBHelper) CheckAllowedDatabases() error {
+	fake.checkAllowedDatabasesMutex.Lock()
+	ret, specificReturn := fake.checkAllowedDatabasesReturnsOnCall[len(fake.checkAllowedDatabasesArgsForCall)]
+	fake.checkAllowedDatabasesArgsForCall = append(fake.checkAllowedDatabasesArgsForCall, struct {
+	}{})
+	fake.recordInvocation("CheckAllowedDatabases", []interface{}{})
+	fake.checkAllowedDatabasesMutex.Unlock()
+	if fake.CheckAllowedDatabasesStub != nil {
+		return fake.CheckAllowedDatabasesStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.checkAllowedDatabasesReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) CheckAllowedDatabasesCallCount() int {
+	fake.checkAllowedDatabasesMutex.RLock()
+	defer fake.checkAllowedDatabasesMutex.RUnlock()
+	return len(fake.checkAllowedDatabasesArgsForCall)
+}
+
+func (fake *FakeDBHelper) CheckAllowedDatabasesCalls(stub func() error) {
+	fake.checkAllowedDatabasesMutex.Lock()
+	defer fake.checkAllowedDatabasesMutex.Unlock()
+	fake.CheckAllowedDatabasesStub = stub
+}
+
+func (fake *FakeDBHelper) CheckAllowedDatabasesReturns(result1 error) {
+	fake.checkAllowedDatabasesMutex.Lock()
+	defer fake.checkAllowedDatabasesMutex.Unlock()
+	fake.CheckAllowedDatabasesStub = nil
+	fake.checkAllowedDatabasesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) CheckAllowedDatabasesReturnsOnCall(i int, result1 error) {
+	fake.checkAllowedDatabasesMutex.Lock()
+	defer fake.checkAllowedDatabasesMutex.Unlock()
+	fake.CheckAllowedDatabasesStub = nil
+	if fake.checkAllowedDatabasesReturnsOnCall == nil {
+		fake.checkAllowedDatabasesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkAllowedDatabasesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) CheckDatadirWritable() error {
+	fake.checkDatadirWritableMutex.Lock()
+	ret, specificReturn := fake.checkDatadirWritableReturnsOnCall[len(fake.checkDatadirWritableArgsForCall)]
+	fake.checkDatadirWritableArgsForCall = append(fake.checkDatadirWritableArgsForCall, struct {
+	}{})
+	fake.recordInvocation("CheckDatadirWritable", []interface{}{})
+	fake.checkDatadirWritableMutex.Unlock()
+	if fake.CheckDatadirWritableStub != nil {
+		return fake.CheckDatadirWritableStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.checkDatadirWritableReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) CheckDatadirWritableCallCount() int {
+	fake.checkDatadirWritableMutex.RLock()
+	defer fake.checkDatadirWritableMutex.RUnlock()
+	return len(fake.checkDatadirWritableArgsForCall)
+}
+
+func (fake *FakeDBHelper) CheckDatadirWritableCalls(stub func() error) {
+	fake.checkDatadirWritableMutex.Lock()
+	defer fake.checkDatadirWritableMutex.Unlock()
+	fake.CheckDatadirWritableStub = stub
+}
+
+func (fake *FakeDBHelper) CheckDatadirWritableReturns(result1 error) {
+	fake.checkDatadirWritableMutex.Lock()
+	defer fake.checkDatadirWritableMutex.Unlock()
+	fake.CheckDatadirWritableStub = nil
+	fake.checkDatadirWritableReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) CheckDatadirWritableReturnsOnCall(i int, result1 error) {
+	fake.checkDatadirWritableMutex.Lock()
+	defer fake.checkDatadirWritableMutex.Unlock()
+	fake.CheckDatadirWritableStub = nil
+	if fake.checkDatadirWritableReturnsOnCall == nil {
+		fake.checkDatadirWritableReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkDatadirWritableReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) CheckErrorLog() error {
+	fake.checkErrorLogMutex.Lock()
+	ret, specificReturn := fake.checkErrorLogReturnsOnCall[len(fake.checkErrorLogArgsForCall)]
+	fake.checkErrorLogArgsForCall = append(fake.checkErrorLogArgsForCall, struct {
+	}{})
+	fake.recordInvocation("CheckErrorLog", []interface{}{})
+	fake.checkErrorLogMutex.Unlock()
+	if fake.CheckErrorLogStub != nil {
+		return fake.CheckErrorLogStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.checkErrorLogReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) CheckErrorLogCallCount() int {
+	fake.checkErrorLogMutex.RLock()
+	defer fake.checkErrorLogMutex.RUnlock()
+	return len(fake.checkErrorLogArgsForCall)
+}
+
+func (fake *FakeDBHelper) CheckErrorLogCalls(stub func() error) {
+	fake.checkErrorLogMutex.Lock()
+	defer fake.checkErrorLogMutex.Unlock()
+	fake.CheckErrorLogStub = stub
+}
+
+func (fake *FakeDBHelper) CheckErrorLogReturns(result1 error) {
+	fake.checkErrorLogMutex.Lock()
+	defer fake.checkErrorLogMutex.Unlock()
+	fake.CheckErrorLogStub = nil
+	fake.checkErrorLogReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) CheckErrorLogReturnsOnCall(i int, result1 error) {
+	fake.checkErrorLogMutex.Lock()
+	defer fake.checkErrorLogMutex.Unlock()
+	fake.CheckErrorLogStub = nil
+	if fake.checkErrorLogReturnsOnCall == nil {
+		fake.checkErrorLogReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkErrorLogReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) CheckSSTAuth() {
+	fake.checkSSTAuthMutex.Lock()
+	fake.checkSSTAuthArgsForCall = append(fake.checkSSTAuthArgsForCall, struct {
+	}{})
+	fake.recordInvocation("CheckSSTAuth", []interface{}{})
+	fake.checkSSTAuthMutex.Unlock()
+	if fake.CheckSSTAuthStub != nil {
+		fake.CheckSSTAuthStub()
+	}
+}
+
+func (fake *FakeDBHelper) CheckSSTAuthCallCount() int {
+	fake.checkSSTAuthMutex.RLock()
+	defer fake.checkSSTAuthMutex.RUnlock()
+	return len(fake.checkSSTAuthArgsForCall)
+}
+
+func (fake *FakeDBHelper) CheckSSTAuthCalls(stub func()) {
+	fake.checkSSTAuthMutex.Lock()
+	defer fake.checkSSTAuthMutex.Unlock()
+	fake.CheckSSTAuthStub = stub
+}
+
+func (fake *FakeDBHelper) CountClientConnections() (int, error) {
+	fake.countClientConnectionsMutex.Lock()
+	ret, specificReturn := fake.countClientConnectionsReturnsOnCall[len(fake.countClientConnectionsArgsForCall)]
+	fake.countClientConnectionsArgsForCall = append(fake.countClientConnectionsArgsForCall, struct {
+	}{})
+	fake.recordInvocation("CountClientConnections", []interface{}{})
+	fake.countClientConnectionsMutex.Unlock()
+	if fake.CountClientConnectionsStub != nil {
+		return fake.CountClientConnectionsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.countClientConnectionsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) CountClientConnectionsCallCount() int {
+	fake.countClientConnectionsMutex.RLock()
+	defer fake.countClientConnectionsMutex.RUnlock()
+	return len(fake.countClientConnectionsArgsForCall)
+}
+
+func (fake *FakeDBHelper) CountClientConnectionsCalls(stub func() (int, error)) {
+	fake.countClientConnectionsMutex.Lock()
+	defer fake.countClientConnectionsMutex.Unlock()
+	fake.CountClientConnectionsStub = stub
+}
+
+func (fake *FakeDBHelper) CountClientConnectionsReturns(result1 int, result2 error) {
+	fake.countClientConnectionsMutex.Lock()
+	defer fake.countClientConnectionsMutex.Unlock()
+	fake.CountClientConnectionsStub = nil
+	fake.countClientConnectionsReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) CountClientConnectionsReturnsOnCall(i int, result1 int, result2 error) {
+	fake.countClientConnectionsMutex.Lock()
+	defer fake.countClientConnectionsMutex.Unlock()
+	fake.CountClientConnectionsStub = nil
+	if fake.countClientConnectionsReturnsOnCall == nil {
+		fake.countClientConnectionsReturnsOnCall = make(map[int]struct {
+			result1 int
+			result2 error
+		})
+	}
+	fake.countClientConnectionsReturnsOnCall[i] = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetBufferPoolSize() (uint64, error) {
+	fake.getBufferPoolSizeMutex.Lock()
+	ret, specificReturn := fake.getBufferPoolSizeReturnsOnCall[len(fake.getBufferPoolSizeArgsForCall)]
+	fake.getBufferPoolSizeArgsForCall = append(fake.getBufferPoolSizeArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetBufferPoolSize", []interface{}{})
+	fake.getBufferPoolSizeMutex.Unlock()
+	if fake.GetBufferPoolSizeStub != nil {
+		return fake.GetBufferPoolSizeStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getBufferPoolSizeReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) GetBufferPoolSizeCallCount() int {
+	fake.getBufferPoolSizeMutex.RLock()
+	defer fake.getBufferPoolSizeMutex.RUnlock()
+	return len(fake.getBufferPoolSizeArgsForCall)
+}
+
+func (fake *FakeDBHelper) GetBufferPoolSizeCalls(stub func() (uint64, error)) {
+	fake.getBufferPoolSizeMutex.Lock()
+	defer fake.getBufferPoolSizeMutex.Unlock()
+	fake.GetBufferPoolSizeStub = stub
+}
+
+func (fake *FakeDBHelper) GetBufferPoolSizeReturns(result1 uint64, result2 error) {
+	fake.getBufferPoolSizeMutex.Lock()
+	defer fake.getBufferPoolSizeMutex.Unlock()
+	fake.GetBufferPoolSizeStub = nil
+	fake.getBufferPoolSizeReturns = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetBufferPoolSizeReturnsOnCall(i int, result1 uint64, result2 error) {
+	fake.getBufferPoolSizeMutex.Lock()
+	defer fake.getBufferPoolSizeMutex.Unlock()
+	fake.GetBufferPoolSizeStub = nil
+	if fake.getBufferPoolSizeReturnsOnCall == nil {
+		fake.getBufferPoolSizeReturnsOnCall = make(map[int]struct {
+			result1 uint64
+			result2 error
+		})
+	}
+	fake.getBufferPoolSizeReturnsOnCall[i] = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetClusterStateUUID() (string, error) {
+	fake.getClusterStateUUIDMutex.Lock()
+	ret, specificReturn := fake.getClusterStateUUIDReturnsOnCall[len(fake.getClusterStateUUIDArgsForCall)]
+	fake.getClusterStateUUIDArgsForCall = append(fake.getClusterStateUUIDArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetClusterStateUUID", []interface{}{})
+	fake.getClusterStateUUIDMutex.Unlock()
+	if fake.GetClusterStateUUIDStub != nil {
+		return fake.GetClusterStateUUIDStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getClusterStateUUIDReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) GetClusterStateUUIDCallCount() int {
+	fake.getClusterStateUUIDMutex.RLock()
+	defer fake.getClusterStateUUIDMutex.RUnlock()
+	return len(fake.getClusterStateUUIDArgsForCall)
+}
+
+func (fake *FakeDBHelper) GetClusterStateUUIDCalls(stub func() (string, error)) {
+	fake.getClusterStateUUIDMutex.Lock()
+	defer fake.getClusterStateUUIDMutex.Unlock()
+	fake.GetClusterStateUUIDStub = stub
+}
+
+func (fake *FakeDBHelper) GetClusterStateUUIDReturns(result1 string, result2 error) {
+	fake.getClusterStateUUIDMutex.Lock()
+	defer fake.getClusterStateUUIDMutex.Unlock()
+	fake.GetClusterStateUUIDStub = nil
+	fake.getClusterStateUUIDReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetClusterStateUUIDReturnsOnCall(i int, result1 string, result2 error) {
+	fake.getClusterStateUUIDMutex.Lock()
+	defer fake.getClusterStateUUIDMutex.Unlock()
+	fake.GetClusterStateUUIDStub = nil
+	if fake.getClusterStateUUIDReturnsOnCall == nil {
+		fake.getClusterStateUUIDReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getClusterStateUUIDReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetIncomingAddresses() ([]string, error) {
+	fake.getIncomingAddressesMutex.Lock()
+	ret, specificReturn := fake.getIncomingAddressesReturnsOnCall[len(fake.getIncomingAddressesArgsForCall)]
+	fake.getIncomingAddressesArgsForCall = append(fake.getIncomingAddressesArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetIncomingAddresses", []interface{}{})
+	fake.getIncomingAddressesMutex.Unlock()
+	if fake.GetIncomingAddressesStub != nil {
+		return fake.GetIncomingAddressesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getIncomingAddressesReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) GetIncomingAddressesCallCount() int {
+	fake.getIncomingAddressesMutex.RLock()
+	defer fake.getIncomingAddressesMutex.RUnlock()
+	return len(fake.getIncomingAddressesArgsForCall)
+}
+
+func (fake *FakeDBHelper) GetIncomingAddressesCalls(stub func() ([]string, error)) {
+	fake.getIncomingAddressesMutex.Lock()
+	defer fake.getIncomingAddressesMutex.Unlock()
+	fake.GetIncomingAddressesStub = stub
+}
+
+func (fake *FakeDBHelper) GetIncomingAddressesReturns(result1 []string, result2 error) {
+	fake.getIncomingAddressesMutex.Lock()
+	defer fake.getIncomingAddressesMutex.Unlock()
+	fake.GetIncomingAddressesStub = nil
+	fake.getIncomingAddressesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetIncomingAddressesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.getIncomingAddressesMutex.Lock()
+	defer fake.getIncomingAddressesMutex.Unlock()
+	fake.GetIncomingAddressesStub = nil
+	if fake.getIncomingAddressesReturnsOnCall == nil {
+		fake.getIncomingAddressesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.getIncomingAddressesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetMaxAllowedPacket() (uint64, error) {
+	fake.getMaxAllowedPacketMutex.Lock()
+	ret, specificReturn := fake.getMaxAllowedPacketReturnsOnCall[len(fake.getMaxAllowedPacketArgsForCall)]
+	fake.getMaxAllowedPacketArgsForCall = append(fake.getMaxAllowedPacketArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetMaxAllowedPacket", []interface{}{})
+	fake.getMaxAllowedPacketMutex.Unlock()
+	if fake.GetMaxAllowedPacketStub != nil {
+		return fake.GetMaxAllowedPacketStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getMaxAllowedPacketReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) GetMaxAllowedPacketCallCount() int {
+	fake.getMaxAllowedPacketMutex.RLock()
+	defer fake.getMaxAllowedPacketMutex.RUnlock()
+	return len(fake.getMaxAllowedPacketArgsForCall)
+}
+
+func (fake *FakeDBHelper) GetMaxAllowedPacketCalls(stub func() (uint64, error)) {
+	fake.getMaxAllowedPacketMutex.Lock()
+	defer fake.getMaxAllowedPacketMutex.Unlock()
+	fake.GetMaxAllowedPacketStub = stub
+}
+
+func (fake *FakeDBHelper) GetMaxAllowedPacketReturns(result1 uint64, result2 error) {
+	fake.getMaxAllowedPacketMutex.Lock()
+	defer fake.getMaxAllowedPacketMutex.Unlock()
+	fake.GetMaxAllowedPacketStub = nil
+	fake.getMaxAllowedPacketReturns = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetMaxAllowedPacketReturnsOnCall(i int, result1 uint64, result2 error) {
+	fake.getMaxAllowedPacketMutex.Lock()
+	defer fake.getMaxAllowedPacketMutex.Unlock()
+	fake.GetMaxAllowedPacketStub = nil
+	if fake.getMaxAllowedPacketReturnsOnCall == nil {
+		fake.getMaxAllowedPacketReturnsOnCall = make(map[int]struct {
+			result1 uint64
+			result2 error
+		})
+	}
+	fake.getMaxAllowedPacketReturnsOnCall[i] = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetMaxConnections() (int, error) {
+	fake.getMaxConnectionsMutex.Lock()
+	ret, specificReturn := fake.getMaxConnectionsReturnsOnCall[len(fake.getMaxConnectionsArgsForCall)]
+	fake.getMaxConnectionsArgsForCall = append(fake.getMaxConnectionsArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetMaxConnections", []interface{}{})
+	fake.getMaxConnectionsMutex.Unlock()
+	if fake.GetMaxConnectionsStub != nil {
+		return fake.GetMaxConnectionsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getMaxConnectionsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) GetMaxConnectionsCallCount() int {
+	fake.getMaxConnectionsMutex.RLock()
+	defer fake.getMaxConnectionsMutex.RUnlock()
+	return len(fake.getMaxConnectionsArgsForCall)
+}
+
+func (fake *FakeDBHelper) GetMaxConnectionsCalls(stub func() (int, error)) {
+	fake.getMaxConnectionsMutex.Lock()
+	defer fake.getMaxConnectionsMutex.Unlock()
+	fake.GetMaxConnectionsStub = stub
+}
+
+func (fake *FakeDBHelper) GetMaxConnectionsReturns(result1 int, result2 error) {
+	fake.getMaxConnectionsMutex.Lock()
+	defer fake.getMaxConnectionsMutex.Unlock()
+	fake.GetMaxConnectionsStub = nil
+	fake.getMaxConnectionsReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetMaxConnectionsReturnsOnCall(i int, result1 int, result2 error) {
+	fake.getMaxConnectionsMutex.Lock()
+	defer fake.getMaxConnectionsMutex.Unlock()
+	fake.GetMaxConnectionsStub = nil
+	if fake.getMaxConnectionsReturnsOnCall == nil {
+		fake.getMaxConnectionsReturnsOnCall = make(map[int]struct {
+			result1 int
+			result2 error
+		})
+	}
+	fake.getMaxConnectionsReturnsOnCall[i] = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetReplicationQueues() (db_helper.ReplicationQueues, error) {
+	fake.getReplicationQueuesMutex.Lock()
+	ret, specificReturn := fake.getReplicationQueuesReturnsOnCall[len(fake.getReplicationQueuesArgsForCall)]
+	fake.getReplicationQueuesArgsForCall = append(fake.getReplicationQueuesArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetReplicationQueues", []interface{}{})
+	fake.getReplicationQueuesMutex.Unlock()
+	if fake.GetReplicationQueuesStub != nil {
+		return fake.GetReplicationQueuesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getReplicationQueuesReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) GetReplicationQueuesCallCount() int {
+	fake.getReplicationQueuesMutex.RLock()
+	defer fake.getReplicationQueuesMutex.RUnlock()
+	return len(fake.getReplicationQueuesArgsForCall)
+}
+
+func (fake *FakeDBHelper) GetReplicationQueuesCalls(stub func() (db_helper.ReplicationQueues, error)) {
+	fake.getReplicationQueuesMutex.Lock()
+	defer fake.getReplicationQueuesMutex.Unlock()
+	fake.GetReplicationQueuesStub = stub
+}
+
+func (fake *FakeDBHelper) GetReplicationQueuesReturns(result1 db_helper.ReplicationQueues, result2 error) {
+	fake.getReplicationQueuesMutex.Lock()
+	defer fake.getReplicationQueuesMutex.Unlock()
+	fake.GetReplicationQueuesStub = nil
+	fake.getReplicationQueuesReturns = struct {
+		result1 db_helper.ReplicationQueues
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetReplicationQueuesReturnsOnCall(i int, result1 db_helper.ReplicationQueues, result2 error) {
+	fake.getReplicationQueuesMutex.Lock()
+	defer fake.getReplicationQueuesMutex.Unlock()
+	fake.GetReplicationQueuesStub = nil
+	if fake.getReplicationQueuesReturnsOnCall == nil {
+		fake.getReplicationQueuesReturnsOnCall = make(map[int]struct {
+			result1 db_helper.ReplicationQueues
+			result2 error
+		})
+	}
+	fake.getReplicationQueuesReturnsOnCall[i] = struct {
+		result1 db_helper.ReplicationQueues
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetServerIdentity() (db_helper.ServerIdentity, error) {
+	fake.getServerIdentityMutex.Lock()
+	ret, specificReturn := fake.getServerIdentityReturnsOnCall[len(fake.getServerIdentityArgsForCall)]
+	fake.getServerIdentityArgsForCall = append(fake.getServerIdentityArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetServerIdentity", []interface{}{})
+	fake.getServerIdentityMutex.Unlock()
+	if fake.GetServerIdentityStub != nil {
+		return fake.GetServerIdentityStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getServerIdentityReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) GetServerIdentityCallCount() int {
+	fake.getServerIdentityMutex.RLock()
+	defer fake.getServerIdentityMutex.RUnlock()
+	return len(fake.getServerIdentityArgsForCall)
+}
+
+func (fake *FakeDBHelper) GetServerIdentityCalls(stub func() (db_helper.ServerIdentity, error)) {
+	fake.getServerIdentityMutex.Lock()
+	defer fake.getServerIdentityMutex.Unlock()
+	fake.GetServerIdentityStub = stub
+}
+
+func (fake *FakeDBHelper) GetServerIdentityReturns(result1 db_helper.ServerIdentity, result2 error) {
+	fake.getServerIdentityMutex.Lock()
+	defer fake.getServerIdentityMutex.Unlock()
+	fake.GetServerIdentityStub = nil
+	fake.getServerIdentityReturns = struct {
+		result1 db_helper.ServerIdentity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetServerIdentityReturnsOnCall(i int, result1 db_helper.ServerIdentity, result2 error) {
+	fake.getServerIdentityMutex.Lock()
+	defer fake.getServerIdentityMutex.Unlock()
+	fake.GetServerIdentityStub = nil
+	if fake.getServerIdentityReturnsOnCall == nil {
+		fake.getServerIdentityReturnsOnCall = make(map[int]struct {
+			result1 db_helper.ServerIdentity
+			result2 error
+		})
+	}
+	fake.getServerIdentityReturnsOnCall[i] = struct {
+		result1 db_helper.ServerIdentity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetWsrepStatus() (db_helper.WsrepStatus, error) {
+	fake.getWsrepStatusMutex.Lock()
+	ret, specificReturn := fake.getWsrepStatusReturnsOnCall[len(fake.getWsrepStatusArgsForCall)]
+	fake.getWsrepStatusArgsForCall = append(fake.getWsrepStatusArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetWsrepStatus", []interface{}{})
+	fake.getWsrepStatusMutex.Unlock()
+	if fake.GetWsrepStatusStub != nil {
+		return fake.GetWsrepStatusStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getWsrepStatusReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) GetWsrepStatusCallCount() int {
+	fake.getWsrepStatusMutex.RLock()
+	defer fake.getWsrepStatusMutex.RUnlock()
+	return len(fake.getWsrepStatusArgsForCall)
+}
+
+func (fake *FakeDBHelper) GetWsrepStatusCalls(stub func() (db_helper.WsrepStatus, error)) {
+	fake.getWsrepStatusMutex.Lock()
+	defer fake.getWsrepStatusMutex.Unlock()
+	fake.GetWsrepStatusStub = stub
+}
+
+func (fake *FakeDBHelper) GetWsrepStatusReturns(result1 db_helper.WsrepStatus, result2 error) {
+	fake.getWsrepStatusMutex.Lock()
+	defer fake.getWsrepStatusMutex.Unlock()
+	fake.GetWsrepStatusStub = nil
+	fake.getWsrepStatusReturns = struct {
+		result1 db_helper.WsrepStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) GetWsrepStatusReturnsOnCall(i int, result1 db_helper.WsrepStatus, result2 error) {
+	fake.getWsrepStatusMutex.Lock()
+	defer fake.getWsrepStatusMutex.Unlock()
+	fake.GetWsrepStatusStub = nil
+	if fake.getWsrepStatusReturnsOnCall == nil {
+		fake.getWsrepStatusReturnsOnCall = make(map[int]struct {
+			result1 db_helper.WsrepStatus
+			result2 error
+		})
+	}
+	fake.getWsrepStatusReturnsOnCall[i] = struct {
+		result1 db_helper.WsrepStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) InitializeDatadirIfNeeded() error {
+	fake.initializeDatadirIfNeededMutex.Lock()
+	ret, specificReturn := fake.initializeDatadirIfNeededReturnsOnCall[len(fake.initializeDatadirIfNeededArgsForCall)]
+	fake.initializeDatadirIfNeededArgsForCall = append(fake.initializeDatadirIfNeededArgsForCall, struct {
+	}{})
+	fake.recordInvocation("InitializeDatadirIfNeeded", []interface{}{})
+	fake.initializeDatadirIfNeededMutex.Unlock()
+	if fake.InitializeDatadirIfNeededStub != nil {
+		return fake.InitializeDatadirIfNeededStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.initializeDatadirIfNeededReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) InitializeDatadirIfNeededCallCount() int {
+	fake.initializeDatadirIfNeededMutex.RLock()
+	defer fake.initializeDatadirIfNeededMutex.RUnlock()
+	return len(fake.initializeDatadirIfNeededArgsForCall)
+}
+
+func (fake *FakeDBHelper) InitializeDatadirIfNeededCalls(stub func() error) {
+	fake.initializeDatadirIfNeededMutex.Lock()
+	defer fake.initializeDatadirIfNeededMutex.Unlock()
+	fake.InitializeDatadirIfNeededStub = stub
+}
+
+func (fake *FakeDBHelper) InitializeDatadirIfNeededReturns(result1 error) {
+	fake.initializeDatadirIfNeededMutex.Lock()
+	defer fake.initializeDatadirIfNeededMutex.Unlock()
+	fake.InitializeDatadirIfNeededStub = nil
+	fake.initializeDatadirIfNeededReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) InitializeDatadirIfNeededReturnsOnCall(i int, result1 error) {
+	fake.initializeDatadirIfNeededMutex.Lock()
+	defer fake.initializeDatadirIfNeededMutex.Unlock()
+	fake.InitializeDatadirIfNeededStub = nil
+	if fake.initializeDatadirIfNeededReturnsOnCall == nil {
+		fake.initializeDatadirIfNeededReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.initializeDatadirIfNeededReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) IsDatabaseReachable() bool {
+	fake.isDatabaseReachableMutex.Lock()
+	ret, specificReturn := fake.isDatabaseReachableReturnsOnCall[len(fake.isDatabaseReachableArgsForCall)]
+	fake.isDatabaseReachableArgsForCall = append(fake.isDatabaseReachableArgsForCall, struct {
+	}{})
+	fake.recordInvocation("IsDatabaseReachable", []interface{}{})
+	fake.isDatabaseReachableMutex.Unlock()
+	if fake.IsDatabaseReachableStub != nil {
+		return fake.IsDatabaseReachableStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.isDatabaseReachableReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) IsDatabaseReachableCallCount() int {
+	fake.isDatabaseReachableMutex.RLock()
+	defer fake.isDatabaseReachableMutex.RUnlock()
+	return len(fake.isDatabaseReachableArgsForCall)
+}
+
+func (fake *FakeDBHelper) IsDatabaseReachableCalls(stub func() bool) {
+	fake.isDatabaseReachableMutex.Lock()
+	defer fake.isDatabaseReachableMutex.Unlock()
+	fake.IsDatabaseReachableStub = stub
+}
+
+func (fake *FakeDBHelper) IsDatabaseReachableReturns(result1 bool) {
+	fake.isDatabaseReachableMutex.Lock()
+	defer fake.isDatabaseReachableMutex.Unlock()
+	fake.IsDatabaseReachableStub = nil
+	fake.isDatabaseReachableReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeDBHelper) IsDatabaseReachableReturnsOnCall(i int, result1 bool) {
+	fake.isDatabaseReachableMutex.Lock()
+	defer fake.isDatabaseReachableMutex.Unlock()
+	fake.IsDatabaseReachableStub = nil
+	if fake.isDatabaseReachableReturnsOnCall == nil {
+		fake.isDatabaseReachableReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.isDatabaseReachableReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeDBHelper) IsDesynced() (bool, error) {
+	fake.isDesyncedMutex.Lock()
+	ret, specificReturn := fake.isDesyncedReturnsOnCall[len(fake.isDesyncedArgsForCall)]
+	fake.isDesyncedArgsForCall = append(fake.isDesyncedArgsForCall, struct {
+	}{})
+	fake.recordInvocation("IsDesynced", []interface{}{})
+	fake.isDesyncedMutex.Unlock()
+	if fake.IsDesyncedStub != nil {
+		return fake.IsDesyncedStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.isDesyncedReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) IsDesyncedCallCount() int {
+	fake.isDesyncedMutex.RLock()
+	defer fake.isDesyncedMutex.RUnlock()
+	return len(fake.isDesyncedArgsForCall)
+}
+
+func (fake *FakeDBHelper) IsDesyncedCalls(stub func() (bool, error)) {
+	fake.isDesyncedMutex.Lock()
+	defer fake.isDesyncedMutex.Unlock()
+	fake.IsDesyncedStub = stub
+}
+
+func (fake *FakeDBHelper) IsDesyncedReturns(result1 bool, result2 error) {
+	fake.isDesyncedMutex.Lock()
+	defer fake.isDesyncedMutex.Unlock()
+	fake.IsDesyncedStub = nil
+	fake.isDesyncedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) IsDesyncedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isDesyncedMutex.Lock()
+	defer fake.isDesyncedMutex.Unlock()
+	fake.IsDesyncedStub = nil
+	if fake.isDesyncedReturnsOnCall == nil {
+		fake.isDesyncedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isDesyncedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) IsDonorRejectingQueries() (bool, error) {
+	fake.isDonorRejectingQueriesMutex.Lock()
+	ret, specificReturn := fake.isDonorRejectingQueriesReturnsOnCall[len(fake.isDonorRejectingQueriesArgsForCall)]
+	fake.isDonorRejectingQueriesArgsForCall = append(fake.isDonorRejectingQueriesArgsForCall, struct {
+	}{})
+	fake.recordInvocation("IsDonorRejectingQueries", []interface{}{})
+	fake.isDonorRejectingQueriesMutex.Unlock()
+	if fake.IsDonorRejectingQueriesStub != nil {
+		return fake.IsDonorRejectingQueriesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.isDonorRejectingQueriesReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) IsDonorRejectingQueriesCallCount() int {
+	fake.isDonorRejectingQueriesMutex.RLock()
+	defer fake.isDonorRejectingQueriesMutex.RUnlock()
+	return len(fake.isDonorRejectingQueriesArgsForCall)
+}
+
+func (fake *FakeDBHelper) IsDonorRejectingQueriesCalls(stub func() (bool, error)) {
+	fake.isDonorRejectingQueriesMutex.Lock()
+	defer fake.isDonorRejectingQueriesMutex.Unlock()
+	fake.IsDonorRejectingQueriesStub = stub
+}
+
+func (fake *FakeDBHelper) IsDonorRejectingQueriesReturns(result1 bool, result2 error) {
+	fake.isDonorRejectingQueriesMutex.Lock()
+	defer fake.isDonorRejectingQueriesMutex.Unlock()
+	fake.IsDonorRejectingQueriesStub = nil
+	fake.isDonorRejectingQueriesReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) IsDonorRejectingQueriesReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isDonorRejectingQueriesMutex.Lock()
+	defer fake.isDonorRejectingQueriesMutex.Unlock()
+	fake.IsDonorRejectingQueriesStub = nil
+	if fake.isDonorRejectingQueriesReturnsOnCall == nil {
+		fake.isDonorRejectingQueriesReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isDonorRejectingQueriesReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) IsFlowControlActive() (bool, error) {
+	fake.isFlowControlActiveMutex.Lock()
+	ret, specificReturn := fake.isFlowControlActiveReturnsOnCall[len(fake.isFlowControlActiveArgsForCall)]
+	fake.isFlowControlActiveArgsForCall = append(fake.isFlowControlActiveArgsForCall, struct {
+	}{})
+	fake.recordInvocation("IsFlowControlActive", []interface{}{})
+	fake.isFlowControlActiveMutex.Unlock()
+	if fake.IsFlowControlActiveStub != nil {
+		return fake.IsFlowControlActiveStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.isFlowControlActiveReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) IsFlowControlActiveCallCount() int {
+	fake.isFlowControlActiveMutex.RLock()
+	defer fake.isFlowControlActiveMutex.RUnlock()
+	return len(fake.isFlowControlActiveArgsForCall)
+}
+
+func (fake *FakeDBHelper) IsFlowControlActiveCalls(stub func() (bool, error)) {
+	fake.isFlowControlActiveMutex.Lock()
+	defer fake.isFlowControlActiveMutex.Unlock()
+	fake.IsFlowControlActiveStub = stub
+}
+
+func (fake *FakeDBHelper) IsFlowControlActiveReturns(result1 bool, result2 error) {
+	fake.isFlowControlActiveMutex.Lock()
+	defer fake.isFlowControlActiveMutex.Unlock()
+	fake.IsFlowControlActiveStub = nil
+	fake.isFlowControlActiveReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) IsFlowControlActiveReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isFlowControlActiveMutex.Lock()
+	defer fake.isFlowControlActiveMutex.Unlock()
+	fake.IsFlowControlActiveStub = nil
+	if fake.isFlowControlActiveReturnsOnCall == nil {
+		fake.isFlowControlActiveReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isFlowControlActiveReturnsOnCall[i] = struct {
+		result1 bool
 		result2 error
-	}
-	invocations      map[string][][]interface{}
-	invocationsMutex sync.RWMutex
+	}{result1, result2}
 }
 
-func (fake *FakeDBHelper) IsDatabaseReachable() bool {
-	fake.isDatabaseReachableMutex.Lock()
-	ret, specificReturn := fake.isDatabaseReachableReturnsOnCall[len(fake.isDatabaseReachableArgsForCall)]
-	fake.isDatabaseReachableArgsForCall = append(fake.isDatabaseReachableArgsForCall, struct {
+func (fake *FakeDBHelper) IsNodeEvicted() bool {
+	fake.isNodeEvictedMutex.Lock()
+	ret, specificReturn := fake.isNodeEvictedReturnsOnCall[len(fake.isNodeEvictedArgsForCall)]
+	fake.isNodeEvictedArgsForCall = append(fake.isNodeEvictedArgsForCall, struct {
 	}{})
-	fake.recordInvocation("IsDatabaseReachable", []interface{}{})
-	fake.isDatabaseReachableMutex.Unlock()
-	if fake.IsDatabaseReachableStub != nil {
-		return fake.IsDatabaseReachableStub()
+	fake.recordInvocation("IsNodeEvicted", []interface{}{})
+	fake.isNodeEvictedMutex.Unlock()
+	if fake.IsNodeEvictedStub != nil {
+		return fake.IsNodeEvictedStub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.isDatabaseReachableReturns
+	fakeReturns := fake.isNodeEvictedReturns
 	return fakeReturns.result1
 }
 
-func (fake *FakeDBHelper) IsDatabaseReachableCallCount() int {
-	fake.isDatabaseReachableMutex.RLock()
-	defer fake.isDatabaseReachableMutex.RUnlock()
-	return len(fake.isDatabaseReachableArgsForCall)
+func (fake *FakeDBHelper) IsNodeEvictedCallCount() int {
+	fake.isNodeEvictedMutex.RLock()
+	defer fake.isNodeEvictedMutex.RUnlock()
+	return len(fake.isNodeEvictedArgsForCall)
 }
 
-func (fake *FakeDBHelper) IsDatabaseReachableCalls(stub func() bool) {
-	fake.isDatabaseReachableMutex.Lock()
-	defer fake.isDatabaseReachableMutex.Unlock()
-	fake.IsDatabaseReachableStub = stub
+func (fake *FakeDBHelper) IsNodeEvictedCalls(stub func() bool) {
+	fake.isNodeEvictedMutex.Lock()
+	defer fake.isNodeEvictedMutex.Unlock()
+	fake.IsNodeEvictedStub = stub
 }
 
-func (fake *FakeDBHelper) IsDatabaseReachableReturns(result1 bool) {
-	fake.isDatabaseReachableMutex.Lock()
-	defer fake.isDatabaseReachableMutex.Unlock()
-	fake.IsDatabaseReachableStub = nil
-	fake.isDatabaseReachableReturns = struct {
+func (fake *FakeDBHelper) IsNodeEvictedReturns(result1 bool) {
+	fake.isNodeEvictedMutex.Lock()
+	defer fake.isNodeEvictedMutex.Unlock()
+	fake.IsNodeEvictedStub = nil
+	fake.isNodeEvictedReturns = struct {
 		result1 bool
 	}{result1}
 }
 
-func (fake *FakeDBHelper) IsDatabaseReachableReturnsOnCall(i int, result1 bool) {
-	fake.isDatabaseReachableMutex.Lock()
-	defer fake.isDatabaseReachableMutex.Unlock()
-	fake.IsDatabaseReachableStub = nil
-	if fake.isDatabaseReachableReturnsOnCall == nil {
-		fake.isDatabaseReachableReturnsOnCall = make(map[int]struct {
+func (fake *FakeDBHelper) IsNodeEvictedReturnsOnCall(i int, result1 bool) {
+	fake.isNodeEvictedMutex.Lock()
+	defer fake.isNodeEvictedMutex.Unlock()
+	fake.IsNodeEvictedStub = nil
+	if fake.isNodeEvictedReturnsOnCall == nil {
+		fake.isNodeEvictedReturnsOnCall = make(map[int]struct {
 			result1 bool
 		})
 	}
-	fake.isDatabaseReachableReturnsOnCall[i] = struct {
+	fake.isNodeEvictedReturnsOnCall[i] = struct {
 		result1 bool
 	}{result1}
 }
@@ -219,6 +1459,226 @@ func (fake *FakeDBHelper) IsProcessRunningReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *FakeDBHelper) MissingPreseededDatabases() []string {
+	fake.missingPreseededDatabasesMutex.Lock()
+	ret, specificReturn := fake.missingPreseededDatabasesReturnsOnCall[len(fake.missingPreseededDatabasesArgsForCall)]
+	fake.missingPreseededDatabasesArgsForCall = append(fake.missingPreseededDatabasesArgsForCall, struct {
+	}{})
+	fake.recordInvocation("MissingPreseededDatabases", []interface{}{})
+	fake.missingPreseededDatabasesMutex.Unlock()
+	if fake.MissingPreseededDatabasesStub != nil {
+		return fake.MissingPreseededDatabasesStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.missingPreseededDatabasesReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) MissingPreseededDatabasesCallCount() int {
+	fake.missingPreseededDatabasesMutex.RLock()
+	defer fake.missingPreseededDatabasesMutex.RUnlock()
+	return len(fake.missingPreseededDatabasesArgsForCall)
+}
+
+func (fake *FakeDBHelper) MissingPreseededDatabasesCalls(stub func() []string) {
+	fake.missingPreseededDatabasesMutex.Lock()
+	defer fake.missingPreseededDatabasesMutex.Unlock()
+	fake.MissingPreseededDatabasesStub = stub
+}
+
+func (fake *FakeDBHelper) MissingPreseededDatabasesReturns(result1 []string) {
+	fake.missingPreseededDatabasesMutex.Lock()
+	defer fake.missingPreseededDatabasesMutex.Unlock()
+	fake.MissingPreseededDatabasesStub = nil
+	fake.missingPreseededDatabasesReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeDBHelper) MissingPreseededDatabasesReturnsOnCall(i int, result1 []string) {
+	fake.missingPreseededDatabasesMutex.Lock()
+	defer fake.missingPreseededDatabasesMutex.Unlock()
+	fake.MissingPreseededDatabasesStub = nil
+	if fake.missingPreseededDatabasesReturnsOnCall == nil {
+		fake.missingPreseededDatabasesReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.missingPreseededDatabasesReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeDBHelper) Ping() bool {
+	fake.pingMutex.Lock()
+	ret, specificReturn := fake.pingReturnsOnCall[len(fake.pingArgsForCall)]
+	fake.pingArgsForCall = append(fake.pingArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Ping", []interface{}{})
+	fake.pingMutex.Unlock()
+	if fake.PingStub != nil {
+		return fake.PingStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.pingReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) PingCallCount() int {
+	fake.pingMutex.RLock()
+	defer fake.pingMutex.RUnlock()
+	return len(fake.pingArgsForCall)
+}
+
+func (fake *FakeDBHelper) PingCalls(stub func() bool) {
+	fake.pingMutex.Lock()
+	defer fake.pingMutex.Unlock()
+	fake.PingStub = stub
+}
+
+func (fake *FakeDBHelper) PingReturns(result1 bool) {
+	fake.pingMutex.Lock()
+	defer fake.pingMutex.Unlock()
+	fake.PingStub = nil
+	fake.pingReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeDBHelper) PingReturnsOnCall(i int, result1 bool) {
+	fake.pingMutex.Lock()
+	defer fake.pingMutex.Unlock()
+	fake.PingStub = nil
+	if fake.pingReturnsOnCall == nil {
+		fake.pingReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.pingReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeDBHelper) ReconcileUsers() ([]string, error) {
+	fake.reconcileUsersMutex.Lock()
+	ret, specificReturn := fake.reconcileUsersReturnsOnCall[len(fake.reconcileUsersArgsForCall)]
+	fake.reconcileUsersArgsForCall = append(fake.reconcileUsersArgsForCall, struct {
+	}{})
+	fake.recordInvocation("ReconcileUsers", []interface{}{})
+	fake.reconcileUsersMutex.Unlock()
+	if fake.ReconcileUsersStub != nil {
+		return fake.ReconcileUsersStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.reconcileUsersReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDBHelper) ReconcileUsersCallCount() int {
+	fake.reconcileUsersMutex.RLock()
+	defer fake.reconcileUsersMutex.RUnlock()
+	return len(fake.reconcileUsersArgsForCall)
+}
+
+func (fake *FakeDBHelper) ReconcileUsersCalls(stub func() ([]string, error)) {
+	fake.reconcileUsersMutex.Lock()
+	defer fake.reconcileUsersMutex.Unlock()
+	fake.ReconcileUsersStub = stub
+}
+
+func (fake *FakeDBHelper) ReconcileUsersReturns(result1 []string, result2 error) {
+	fake.reconcileUsersMutex.Lock()
+	defer fake.reconcileUsersMutex.Unlock()
+	fake.ReconcileUsersStub = nil
+	fake.reconcileUsersReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) ReconcileUsersReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.reconcileUsersMutex.Lock()
+	defer fake.reconcileUsersMutex.Unlock()
+	fake.ReconcileUsersStub = nil
+	if fake.reconcileUsersReturnsOnCall == nil {
+		fake.reconcileUsersReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.reconcileUsersReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBHelper) RotateUserPassword(arg1 string, arg2 string) error {
+	fake.rotateUserPasswordMutex.Lock()
+	ret, specificReturn := fake.rotateUserPasswordReturnsOnCall[len(fake.rotateUserPasswordArgsForCall)]
+	fake.rotateUserPasswordArgsForCall = append(fake.rotateUserPasswordArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("RotateUserPassword", []interface{}{arg1, arg2})
+	fake.rotateUserPasswordMutex.Unlock()
+	if fake.RotateUserPasswordStub != nil {
+		return fake.RotateUserPasswordStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.rotateUserPasswordReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) RotateUserPasswordCallCount() int {
+	fake.rotateUserPasswordMutex.RLock()
+	defer fake.rotateUserPasswordMutex.RUnlock()
+	return len(fake.rotateUserPasswordArgsForCall)
+}
+
+func (fake *FakeDBHelper) RotateUserPasswordCalls(stub func(string, string) error) {
+	fake.rotateUserPasswordMutex.Lock()
+	defer fake.rotateUserPasswordMutex.Unlock()
+	fake.RotateUserPasswordStub = stub
+}
+
+func (fake *FakeDBHelper) RotateUserPasswordArgsForCall(i int) (string, string) {
+	fake.rotateUserPasswordMutex.RLock()
+	defer fake.rotateUserPasswordMutex.RUnlock()
+	argsForCall := fake.rotateUserPasswordArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeDBHelper) RotateUserPasswordReturns(result1 error) {
+	fake.rotateUserPasswordMutex.Lock()
+	defer fake.rotateUserPasswordMutex.Unlock()
+	fake.RotateUserPasswordStub = nil
+	fake.rotateUserPasswordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) RotateUserPasswordReturnsOnCall(i int, result1 error) {
+	fake.rotateUserPasswordMutex.Lock()
+	defer fake.rotateUserPasswordMutex.Unlock()
+	fake.RotateUserPasswordStub = nil
+	if fake.rotateUserPasswordReturnsOnCall == nil {
+		fake.rotateUserPasswordReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rotateUserPasswordReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeDBHelper) RunPostStartSQL() error {
 	fake.runPostStartSQLMutex.Lock()
 	ret, specificReturn := fake.runPostStartSQLReturnsOnCall[len(fake.runPostStartSQLArgsForCall)]
@@ -271,6 +1731,126 @@ func (fake *FakeDBHelper) RunPostStartSQLReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeDBHelper) RunQuery(arg1 string) error {
+	fake.runQueryMutex.Lock()
+	ret, specificReturn := fake.runQueryReturnsOnCall[len(fake.runQueryArgsForCall)]
+	fake.runQueryArgsForCall = append(fake.runQueryArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("RunQuery", []interface{}{arg1})
+	fake.runQueryMutex.Unlock()
+	if fake.RunQueryStub != nil {
+		return fake.RunQueryStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.runQueryReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) RunQueryCallCount() int {
+	fake.runQueryMutex.RLock()
+	defer fake.runQueryMutex.RUnlock()
+	return len(fake.runQueryArgsForCall)
+}
+
+func (fake *FakeDBHelper) RunQueryCalls(stub func(string) error) {
+	fake.runQueryMutex.Lock()
+	defer fake.runQueryMutex.Unlock()
+	fake.RunQueryStub = stub
+}
+
+func (fake *FakeDBHelper) RunQueryArgsForCall(i int) string {
+	fake.runQueryMutex.RLock()
+	defer fake.runQueryMutex.RUnlock()
+	argsForCall := fake.runQueryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDBHelper) RunQueryReturns(result1 error) {
+	fake.runQueryMutex.Lock()
+	defer fake.runQueryMutex.Unlock()
+	fake.RunQueryStub = nil
+	fake.runQueryReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) RunQueryReturnsOnCall(i int, result1 error) {
+	fake.runQueryMutex.Lock()
+	defer fake.runQueryMutex.Unlock()
+	fake.RunQueryStub = nil
+	if fake.runQueryReturnsOnCall == nil {
+		fake.runQueryReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.runQueryReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) RunSQLFile(arg1 string) error {
+	fake.runSQLFileMutex.Lock()
+	ret, specificReturn := fake.runSQLFileReturnsOnCall[len(fake.runSQLFileArgsForCall)]
+	fake.runSQLFileArgsForCall = append(fake.runSQLFileArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("RunSQLFile", []interface{}{arg1})
+	fake.runSQLFileMutex.Unlock()
+	if fake.RunSQLFileStub != nil {
+		return fake.RunSQLFileStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.runSQLFileReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) RunSQLFileCallCount() int {
+	fake.runSQLFileMutex.RLock()
+	defer fake.runSQLFileMutex.RUnlock()
+	return len(fake.runSQLFileArgsForCall)
+}
+
+func (fake *FakeDBHelper) RunSQLFileCalls(stub func(string) error) {
+	fake.runSQLFileMutex.Lock()
+	defer fake.runSQLFileMutex.Unlock()
+	fake.RunSQLFileStub = stub
+}
+
+func (fake *FakeDBHelper) RunSQLFileArgsForCall(i int) string {
+	fake.runSQLFileMutex.RLock()
+	defer fake.runSQLFileMutex.RUnlock()
+	argsForCall := fake.runSQLFileArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDBHelper) RunSQLFileReturns(result1 error) {
+	fake.runSQLFileMutex.Lock()
+	defer fake.runSQLFileMutex.Unlock()
+	fake.RunSQLFileStub = nil
+	fake.runSQLFileReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) RunSQLFileReturnsOnCall(i int, result1 error) {
+	fake.runSQLFileMutex.Lock()
+	defer fake.runSQLFileMutex.Unlock()
+	fake.RunSQLFileStub = nil
+	if fake.runSQLFileReturnsOnCall == nil {
+		fake.runSQLFileReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.runSQLFileReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeDBHelper) Seed() error {
 	fake.seedMutex.Lock()
 	ret, specificReturn := fake.seedReturnsOnCall[len(fake.seedArgsForCall)]
@@ -375,6 +1955,186 @@ func (fake *FakeDBHelper) SeedUsersReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeDBHelper) SetDesync(arg1 bool) error {
+	fake.setDesyncMutex.Lock()
+	ret, specificReturn := fake.setDesyncReturnsOnCall[len(fake.setDesyncArgsForCall)]
+	fake.setDesyncArgsForCall = append(fake.setDesyncArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	fake.recordInvocation("SetDesync", []interface{}{arg1})
+	fake.setDesyncMutex.Unlock()
+	if fake.SetDesyncStub != nil {
+		return fake.SetDesyncStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.setDesyncReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) SetDesyncCallCount() int {
+	fake.setDesyncMutex.RLock()
+	defer fake.setDesyncMutex.RUnlock()
+	return len(fake.setDesyncArgsForCall)
+}
+
+func (fake *FakeDBHelper) SetDesyncCalls(stub func(bool) error) {
+	fake.setDesyncMutex.Lock()
+	defer fake.setDesyncMutex.Unlock()
+	fake.SetDesyncStub = stub
+}
+
+func (fake *FakeDBHelper) SetDesyncArgsForCall(i int) bool {
+	fake.setDesyncMutex.RLock()
+	defer fake.setDesyncMutex.RUnlock()
+	argsForCall := fake.setDesyncArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDBHelper) SetDesyncReturns(result1 error) {
+	fake.setDesyncMutex.Lock()
+	defer fake.setDesyncMutex.Unlock()
+	fake.SetDesyncStub = nil
+	fake.setDesyncReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) SetDesyncReturnsOnCall(i int, result1 error) {
+	fake.setDesyncMutex.Lock()
+	defer fake.setDesyncMutex.Unlock()
+	fake.SetDesyncStub = nil
+	if fake.setDesyncReturnsOnCall == nil {
+		fake.setDesyncReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setDesyncReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) SetDonorRejectsQueries(arg1 bool) error {
+	fake.setDonorRejectsQueriesMutex.Lock()
+	ret, specificReturn := fake.setDonorRejectsQueriesReturnsOnCall[len(fake.setDonorRejectsQueriesArgsForCall)]
+	fake.setDonorRejectsQueriesArgsForCall = append(fake.setDonorRejectsQueriesArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	fake.recordInvocation("SetDonorRejectsQueries", []interface{}{arg1})
+	fake.setDonorRejectsQueriesMutex.Unlock()
+	if fake.SetDonorRejectsQueriesStub != nil {
+		return fake.SetDonorRejectsQueriesStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.setDonorRejectsQueriesReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) SetDonorRejectsQueriesCallCount() int {
+	fake.setDonorRejectsQueriesMutex.RLock()
+	defer fake.setDonorRejectsQueriesMutex.RUnlock()
+	return len(fake.setDonorRejectsQueriesArgsForCall)
+}
+
+func (fake *FakeDBHelper) SetDonorRejectsQueriesCalls(stub func(bool) error) {
+	fake.setDonorRejectsQueriesMutex.Lock()
+	defer fake.setDonorRejectsQueriesMutex.Unlock()
+	fake.SetDonorRejectsQueriesStub = stub
+}
+
+func (fake *FakeDBHelper) SetDonorRejectsQueriesArgsForCall(i int) bool {
+	fake.setDonorRejectsQueriesMutex.RLock()
+	defer fake.setDonorRejectsQueriesMutex.RUnlock()
+	argsForCall := fake.setDonorRejectsQueriesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDBHelper) SetDonorRejectsQueriesReturns(result1 error) {
+	fake.setDonorRejectsQueriesMutex.Lock()
+	defer fake.setDonorRejectsQueriesMutex.Unlock()
+	fake.SetDonorRejectsQueriesStub = nil
+	fake.setDonorRejectsQueriesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) SetDonorRejectsQueriesReturnsOnCall(i int, result1 error) {
+	fake.setDonorRejectsQueriesMutex.Lock()
+	defer fake.setDonorRejectsQueriesMutex.Unlock()
+	fake.SetDonorRejectsQueriesStub = nil
+	if fake.setDonorRejectsQueriesReturnsOnCall == nil {
+		fake.setDonorRejectsQueriesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setDonorRejectsQueriesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) SetMaxAllowedPacket(arg1 uint64) error {
+	fake.setMaxAllowedPacketMutex.Lock()
+	ret, specificReturn := fake.setMaxAllowedPacketReturnsOnCall[len(fake.setMaxAllowedPacketArgsForCall)]
+	fake.setMaxAllowedPacketArgsForCall = append(fake.setMaxAllowedPacketArgsForCall, struct {
+		arg1 uint64
+	}{arg1})
+	fake.recordInvocation("SetMaxAllowedPacket", []interface{}{arg1})
+	fake.setMaxAllowedPacketMutex.Unlock()
+	if fake.SetMaxAllowedPacketStub != nil {
+		return fake.SetMaxAllowedPacketStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.setMaxAllowedPacketReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeDBHelper) SetMaxAllowedPacketCallCount() int {
+	fake.setMaxAllowedPacketMutex.RLock()
+	defer fake.setMaxAllowedPacketMutex.RUnlock()
+	return len(fake.setMaxAllowedPacketArgsForCall)
+}
+
+func (fake *FakeDBHelper) SetMaxAllowedPacketCalls(stub func(uint64) error) {
+	fake.setMaxAllowedPacketMutex.Lock()
+	defer fake.setMaxAllowedPacketMutex.Unlock()
+	fake.SetMaxAllowedPacketStub = stub
+}
+
+func (fake *FakeDBHelper) SetMaxAllowedPacketArgsForCall(i int) uint64 {
+	fake.setMaxAllowedPacketMutex.RLock()
+	defer fake.setMaxAllowedPacketMutex.RUnlock()
+	argsForCall := fake.setMaxAllowedPacketArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDBHelper) SetMaxAllowedPacketReturns(result1 error) {
+	fake.setMaxAllowedPacketMutex.Lock()
+	defer fake.setMaxAllowedPacketMutex.Unlock()
+	fake.SetMaxAllowedPacketStub = nil
+	fake.setMaxAllowedPacketReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBHelper) SetMaxAllowedPacketReturnsOnCall(i int, result1 error) {
+	fake.setMaxAllowedPacketMutex.Lock()
+	defer fake.setMaxAllowedPacketMutex.Unlock()
+	fake.SetMaxAllowedPacketStub = nil
+	if fake.setMaxAllowedPacketReturnsOnCall == nil {
+		fake.setMaxAllowedPacketReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setMaxAllowedPacketReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeDBHelper) StartMysqldForUpgrade() (*exec.Cmd, error) {
 	fake.startMysqldForUpgradeMutex.Lock()
 	ret, specificReturn := fake.startMysqldForUpgradeReturnsOnCall[len(fake.startMysqldForUpgradeArgsForCall)]
@@ -621,16 +2381,70 @@ func (fake *FakeDBHelper) UpgradeReturnsOnCall(i int, result1 string, result2 er
 func (fake *FakeDBHelper) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.checkAllowedDatabasesMutex.RLock()
+	defer fake.checkAllowedDatabasesMutex.RUnlock()
+	fake.checkDatadirWritableMutex.RLock()
+	defer fake.checkDatadirWritableMutex.RUnlock()
+	fake.checkErrorLogMutex.RLock()
+	defer fake.checkErrorLogMutex.RUnlock()
+	fake.checkSSTAuthMutex.RLock()
+	defer fake.checkSSTAuthMutex.RUnlock()
+	fake.countClientConnectionsMutex.RLock()
+	defer fake.countClientConnectionsMutex.RUnlock()
+	fake.getBufferPoolSizeMutex.RLock()
+	defer fake.getBufferPoolSizeMutex.RUnlock()
+	fake.getClusterStateUUIDMutex.RLock()
+	defer fake.getClusterStateUUIDMutex.RUnlock()
+	fake.getIncomingAddressesMutex.RLock()
+	defer fake.getIncomingAddressesMutex.RUnlock()
+	fake.getMaxAllowedPacketMutex.RLock()
+	defer fake.getMaxAllowedPacketMutex.RUnlock()
+	fake.getMaxConnectionsMutex.RLock()
+	defer fake.getMaxConnectionsMutex.RUnlock()
+	fake.getReplicationQueuesMutex.RLock()
+	defer fake.getReplicationQueuesMutex.RUnlock()
+	fake.getServerIdentityMutex.RLock()
+	defer fake.getServerIdentityMutex.RUnlock()
+	fake.getWsrepStatusMutex.RLock()
+	defer fake.getWsrepStatusMutex.RUnlock()
+	fake.initializeDatadirIfNeededMutex.RLock()
+	defer fake.initializeDatadirIfNeededMutex.RUnlock()
 	fake.isDatabaseReachableMutex.RLock()
 	defer fake.isDatabaseReachableMutex.RUnlock()
+	fake.isDesyncedMutex.RLock()
+	defer fake.isDesyncedMutex.RUnlock()
+	fake.isDonorRejectingQueriesMutex.RLock()
+	defer fake.isDonorRejectingQueriesMutex.RUnlock()
+	fake.isFlowControlActiveMutex.RLock()
+	defer fake.isFlowControlActiveMutex.RUnlock()
+	fake.isNodeEvictedMutex.RLock()
+	defer fake.isNodeEvictedMutex.RUnlock()
 	fake.isProcessRunningMutex.RLock()
 	defer fake.isProcessRunningMutex.RUnlock()
+	fake.missingPreseededDatabasesMutex.RLock()
+	defer fake.missingPreseededDatabasesMutex.RUnlock()
+	fake.pingMutex.RLock()
+	defer fake.pingMutex.RUnlock()
+	fake.reconcileUsersMutex.RLock()
+	defer fake.reconcileUsersMutex.RUnlock()
+	fake.rotateUserPasswordMutex.RLock()
+	defer fake.rotateUserPasswordMutex.RUnlock()
 	fake.runPostStartSQLMutex.RLock()
 	defer fake.runPostStartSQLMutex.RUnlock()
+	fake.runQueryMutex.RLock()
+	defer fake.runQueryMutex.RUnlock()
+	fake.runSQLFileMutex.RLock()
+	defer fake.runSQLFileMutex.RUnlock()
 	fake.seedMutex.RLock()
 	defer fake.seedMutex.RUnlock()
 	fake.seedUsersMutex.RLock()
 	defer fake.seedUsersMutex.RUnlock()
+	fake.setDesyncMutex.RLock()
+	defer fake.setDesyncMutex.RUnlock()
+	fake.setDonorRejectsQueriesMutex.RLock()
+	defer fake.setDonorRejectsQueriesMutex.RUnlock()
+	fake.setMaxAllowedPacketMutex.RLock()
+	defer fake.setMaxAllowedPacketMutex.RUnlock()
 	fake.startMysqldForUpgradeMutex.RLock()
 	defer fake.startMysqldForUpgradeMutex.RUnlock()
 	fake.startMysqldInBootstrapMutex.RLock()
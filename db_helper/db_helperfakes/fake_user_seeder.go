@@ -8,6 +8,20 @@ import (
 )
 
 type FakeUserSeeder struct {
+	SeedReadOnlyUserStub        func(string, string, string, []string) error
+	seedReadOnlyUserMutex       sync.RWMutex
+	seedReadOnlyUserArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	seedReadOnlyUserReturns struct {
+		result1 error
+	}
+	seedReadOnlyUserReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SeedUserStub        func(string, string, string, string) error
 	seedUserMutex       sync.RWMutex
 	seedUserArgsForCall []struct {
@@ -26,6 +40,74 @@ type FakeUserSeeder struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeUserSeeder) SeedReadOnlyUser(arg1 string, arg2 string, arg3 string, arg4 []string) error {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.seedReadOnlyUserMutex.Lock()
+	ret, specificReturn := fake.seedReadOnlyUserReturnsOnCall[len(fake.seedReadOnlyUserArgsForCall)]
+	fake.seedReadOnlyUserArgsForCall = append(fake.seedReadOnlyUserArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	fake.recordInvocation("SeedReadOnlyUser", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.seedReadOnlyUserMutex.Unlock()
+	if fake.SeedReadOnlyUserStub != nil {
+		return fake.SeedReadOnlyUserStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.seedReadOnlyUserReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserSeeder) SeedReadOnlyUserCallCount() int {
+	fake.seedReadOnlyUserMutex.RLock()
+	defer fake.seedReadOnlyUserMutex.RUnlock()
+	return len(fake.seedReadOnlyUserArgsForCall)
+}
+
+func (fake *FakeUserSeeder) SeedReadOnlyUserCalls(stub func(string, string, string, []string) error) {
+	fake.seedReadOnlyUserMutex.Lock()
+	defer fake.seedReadOnlyUserMutex.Unlock()
+	fake.SeedReadOnlyUserStub = stub
+}
+
+func (fake *FakeUserSeeder) SeedReadOnlyUserArgsForCall(i int) (string, string, string, []string) {
+	fake.seedReadOnlyUserMutex.RLock()
+	defer fake.seedReadOnlyUserMutex.RUnlock()
+	argsForCall := fake.seedReadOnlyUserArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeUserSeeder) SeedReadOnlyUserReturns(result1 error) {
+	fake.seedReadOnlyUserMutex.Lock()
+	defer fake.seedReadOnlyUserMutex.Unlock()
+	fake.SeedReadOnlyUserStub = nil
+	fake.seedReadOnlyUserReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserSeeder) SeedReadOnlyUserReturnsOnCall(i int, result1 error) {
+	fake.seedReadOnlyUserMutex.Lock()
+	defer fake.seedReadOnlyUserMutex.Unlock()
+	fake.SeedReadOnlyUserStub = nil
+	if fake.seedReadOnlyUserReturnsOnCall == nil {
+		fake.seedReadOnlyUserReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.seedReadOnlyUserReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeUserSeeder) SeedUser(arg1 string, arg2 string, arg3 string, arg4 string) error {
 	fake.seedUserMutex.Lock()
 	ret, specificReturn := fake.seedUserReturnsOnCall[len(fake.seedUserArgsForCall)]
@@ -92,6 +174,8 @@ func (fake *FakeUserSeeder) SeedUserReturnsOnCall(i int, result1 error) {
 func (fake *FakeUserSeeder) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.seedReadOnlyUserMutex.RLock()
+	defer fake.seedReadOnlyUserMutex.RUnlock()
 	fake.seedUserMutex.RLock()
 	defer fake.seedUserMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -0,0 +1,43 @@
+package db_helper
+
+import (
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/sys/unix"
+
+	"github.com/cloudfoundry/galera-init/config"
+)
+
+// Network FormatDSN connects over TCP with when Db.TCPKeepAliveInterval is
+// set, so that a connection whose peer silently went away fails instead of
+// hanging on the half-open socket.
+const keepAliveNetwork = "galera-init-tcp"
+
+// Registers the driver dial function behind keepAliveNetwork. The driver's
+// registry is not safe for concurrent use, so NewDBHelper does this before any
+// connection is opened.
+func registerKeepAliveDial(dbConfig config.DBHelper) {
+	dialer := net.Dialer{
+		Timeout:   time.Duration(dbConfig.ConnectTimeout) * time.Second,
+		KeepAlive: time.Duration(dbConfig.TCPKeepAliveInterval) * time.Second,
+	}
+	if dbConfig.TCPKeepAliveCount > 0 {
+		dialer.Control = func(network, address string, conn syscall.RawConn) error {
+			var err error
+			controlErr := conn.Control(func(fd uintptr) {
+				err = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPCNT, dbConfig.TCPKeepAliveCount)
+			})
+			if controlErr != nil {
+				return controlErr
+			}
+			return err
+		}
+	}
+
+	mysql.RegisterDial(keepAliveNetwork, func(addr string) (net.Conn, error) {
+		return dialer.Dial("tcp", addr)
+	})
+}
@@ -0,0 +1,162 @@
+package db_helper
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+
+	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/os_helper"
+)
+
+// ProcessManager starts and stops mysqld. Containerized deployments where
+// mysqld runs under a supervisor such as systemd can provide their own
+// implementation instead of having galera-init exec mysqld directly.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . ProcessManager
+type ProcessManager interface {
+	Start(logFileName string, executable string, args ...string) (*exec.Cmd, error)
+	Stop() error
+}
+
+// NewProcessManager returns the implementation selected by Db.ProcessManager.
+func NewProcessManager(dbConfig config.DBHelper, osHelper os_helper.OsHelper, logger lager.Logger) (ProcessManager, error) {
+	switch dbConfig.ProcessManager {
+	case "", config.ProcessManagerDirect:
+		return NewDirectProcessManagerWithStopOptions(osHelper, StopOptions{
+			Protocol: dbConfig.StopProtocol,
+			Socket:   dbConfig.Socket,
+			PidFile:  dbConfig.MysqldPidFile,
+			Timeout:  time.Duration(dbConfig.StopTimeout) * time.Second,
+		}, logger), nil
+	default:
+		return nil, fmt.Errorf("Unsupported process manager: %s", dbConfig.ProcessManager)
+	}
+}
+
+// How often to check whether mysqld has exited after signalling it
+var StopPollInterval = 1 * time.Second
+
+// StopOptions control how the direct process manager shuts mysqld down.
+type StopOptions struct {
+	// config.StopProtocolSocket or config.StopProtocolTCP; empty leaves the
+	// choice to mylogin.cnf
+	Protocol string
+	Socket   string
+	// When set, a failed mysqladmin shutdown falls back to signalling the
+	// pid recorded here
+	PidFile string
+	// Zero waits as long as mysqladmin does
+	Timeout time.Duration
+}
+
+type directProcessManager struct {
+	osHelper    os_helper.OsHelper
+	stopOptions StopOptions
+	logger      lager.Logger
+}
+
+// NewDirectProcessManager runs mysqld as a child process of galera-init and
+// stops it with mysqladmin.
+func NewDirectProcessManager(osHelper os_helper.OsHelper) ProcessManager {
+	return directProcessManager{osHelper: osHelper, logger: lager.NewLogger("process-manager")}
+}
+
+// NewDirectProcessManagerWithStopOptions is NewDirectProcessManager with
+// control over the shutdown connection, timeout and pid fallback, logging
+// whether each stop was graceful or forced.
+func NewDirectProcessManagerWithStopOptions(osHelper os_helper.OsHelper, stopOptions StopOptions, logger lager.Logger) ProcessManager {
+	return directProcessManager{osHelper: osHelper, stopOptions: stopOptions, logger: logger}
+}
+
+func (p directProcessManager) Start(logFileName string, executable string, args ...string) (*exec.Cmd, error) {
+	return p.osHelper.StartCommand(logFileName, executable, args...)
+}
+
+// Stop shuts mysqld down with mysqladmin. mysqld started stand-alone runs
+// with --skip-networking, so the socket is preferred when one is configured.
+// If mysqladmin cannot shut it down, the process in the pid file is sent
+// SIGTERM, then SIGKILL once the timeout has passed.
+func (p directProcessManager) Stop() error {
+	args := []string{"--defaults-file=/var/vcap/jobs/pxc-mysql/config/mylogin.cnf"}
+
+	switch p.stopOptions.Protocol {
+	case config.StopProtocolSocket:
+		if p.stopOptions.Socket != "" {
+			args = append(args, "--protocol=socket", "--socket="+p.stopOptions.Socket)
+		}
+	case config.StopProtocolTCP:
+		args = append(args, "--protocol=tcp")
+	}
+
+	if timeoutSeconds := int(p.stopOptions.Timeout / time.Second); timeoutSeconds > 0 {
+		args = append(args,
+			fmt.Sprintf("--connect-timeout=%d", timeoutSeconds),
+			fmt.Sprintf("--shutdown-timeout=%d", timeoutSeconds))
+	}
+
+	_, err := p.osHelper.RunCommand("mysqladmin", append(args, "shutdown")...)
+	if err == nil {
+		p.logger.Info("mysqld-stopped", lager.Data{"forced": false, "method": "mysqladmin"})
+		return nil
+	}
+	if p.stopOptions.PidFile == "" {
+		return err
+	}
+
+	p.logger.Info("mysqladmin-shutdown-failed-signalling-pid", lager.Data{"err": err.Error()})
+	method, killErr := p.killFromPidFile()
+	if killErr != nil {
+		return errors.Wrapf(killErr, "mysqladmin shutdown failed (%s) and falling back to the pid file failed", err)
+	}
+
+	p.logger.Info("mysqld-stopped", lager.Data{"forced": method == "SIGKILL", "method": method})
+	return nil
+}
+
+// killFromPidFile returns how mysqld was stopped: "exited" when it was
+// already gone, otherwise the last signal it was sent.
+func (p directProcessManager) killFromPidFile() (string, error) {
+	if !p.osHelper.FileExists(p.stopOptions.PidFile) {
+		// mysqld removes its pid file on a clean exit
+		return "exited", nil
+	}
+
+	contents, err := p.osHelper.ReadFile(p.stopOptions.PidFile)
+	if err != nil {
+		return "", errors.Wrap(err, "Error reading mysqld pid file")
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(contents))
+	if err != nil {
+		return "", errors.Wrapf(err, "Invalid mysqld pid file %s", p.stopOptions.PidFile)
+	}
+
+	if !p.osHelper.ProcessExists(pid) {
+		return "exited", nil
+	}
+
+	if err := p.osHelper.SignalProcess(pid, syscall.SIGTERM); err != nil {
+		return "", err
+	}
+
+	polls := int(p.stopOptions.Timeout / StopPollInterval)
+	for i := 0; i < polls; i++ {
+		p.osHelper.Sleep(StopPollInterval)
+		if !p.osHelper.ProcessExists(pid) {
+			return "SIGTERM", nil
+		}
+	}
+
+	if err := p.osHelper.SignalProcess(pid, syscall.SIGKILL); err != nil {
+		return "", err
+	}
+
+	return "SIGKILL", nil
+}
@@ -0,0 +1,185 @@
+package db_helper_test
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/os_helper/os_helperfakes"
+)
+
+var _ = Describe("ProcessManager", func() {
+	var (
+		fakeOs     *os_helperfakes.FakeOsHelper
+		testLogger *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		fakeOs = new(os_helperfakes.FakeOsHelper)
+		testLogger = lagertest.NewTestLogger("process_manager")
+	})
+
+	Describe("NewProcessManager", func() {
+		It("defaults to running mysqld directly", func() {
+			processManager, err := db_helper.NewProcessManager(config.DBHelper{}, fakeOs, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processManager).To(Equal(db_helper.NewDirectProcessManagerWithStopOptions(fakeOs, db_helper.StopOptions{}, testLogger)))
+
+			processManager, err = db_helper.NewProcessManager(config.DBHelper{ProcessManager: config.ProcessManagerDirect}, fakeOs, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processManager).To(Equal(db_helper.NewDirectProcessManagerWithStopOptions(fakeOs, db_helper.StopOptions{}, testLogger)))
+		})
+
+		It("passes the stop settings to the direct process manager", func() {
+			processManager, err := db_helper.NewProcessManager(config.DBHelper{
+				StopProtocol:  config.StopProtocolSocket,
+				Socket:        "/mysqld.sock",
+				MysqldPidFile: "/mysqld.pid",
+				StopTimeout:   60,
+			}, fakeOs, testLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processManager).To(Equal(db_helper.NewDirectProcessManagerWithStopOptions(fakeOs, db_helper.StopOptions{
+				Protocol: config.StopProtocolSocket,
+				Socket:   "/mysqld.sock",
+				PidFile:  "/mysqld.pid",
+				Timeout:  60 * time.Second,
+			}, testLogger)))
+		})
+
+		It("returns an error for an unknown process manager", func() {
+			_, err := db_helper.NewProcessManager(config.DBHelper{ProcessManager: "systemd"}, fakeOs, testLogger)
+			Expect(err).To(MatchError("Unsupported process manager: systemd"))
+		})
+	})
+
+	Describe("direct", func() {
+		var processManager db_helper.ProcessManager
+
+		BeforeEach(func() {
+			processManager = db_helper.NewDirectProcessManager(fakeOs)
+		})
+
+		It("starts mysqld as a child process", func() {
+			cmd := exec.Command("mysqld")
+			fakeOs.StartCommandReturns(cmd, nil)
+
+			Expect(processManager.Start("/log-file.log", "mysqld", "--wsrep-new-cluster")).To(Equal(cmd))
+
+			logFile, executable, args := fakeOs.StartCommandArgsForCall(0)
+			Expect(logFile).To(Equal("/log-file.log"))
+			Expect(executable).To(Equal("mysqld"))
+			Expect(args).To(Equal([]string{"--wsrep-new-cluster"}))
+		})
+
+		It("stops mysqld with mysqladmin", func() {
+			Expect(processManager.Stop()).To(Succeed())
+
+			executable, args := fakeOs.RunCommandArgsForCall(0)
+			Expect(executable).To(Equal("mysqladmin"))
+			Expect(args).To(Equal([]string{"--defaults-file=/var/vcap/jobs/pxc-mysql/config/mylogin.cnf", "shutdown"}))
+		})
+
+		Context("with stop options", func() {
+			BeforeEach(func() {
+				db_helper.StopPollInterval = 1 * time.Second
+				processManager = db_helper.NewDirectProcessManagerWithStopOptions(fakeOs, db_helper.StopOptions{
+					Protocol: config.StopProtocolSocket,
+					Socket:   "/mysqld.sock",
+					PidFile:  "/mysqld.pid",
+					Timeout:  3 * time.Second,
+				}, testLogger)
+				fakeOs.FileExistsReturns(true)
+				fakeOs.ReadFileReturns("1234\n", nil)
+			})
+
+			It("shuts down over the socket with a timeout", func() {
+				Expect(processManager.Stop()).To(Succeed())
+
+				executable, args := fakeOs.RunCommandArgsForCall(0)
+				Expect(executable).To(Equal("mysqladmin"))
+				Expect(args).To(Equal([]string{
+					"--defaults-file=/var/vcap/jobs/pxc-mysql/config/mylogin.cnf",
+					"--protocol=socket",
+					"--socket=/mysqld.sock",
+					"--connect-timeout=3",
+					"--shutdown-timeout=3",
+					"shutdown",
+				}))
+				Expect(fakeOs.SignalProcessCallCount()).To(Equal(0))
+				Expect(testLogger.Buffer()).To(gbytes.Say(`mysqld-stopped".*"forced":false,"method":"mysqladmin"`))
+			})
+
+			It("forces a tcp connection when configured to", func() {
+				processManager = db_helper.NewDirectProcessManagerWithStopOptions(fakeOs, db_helper.StopOptions{
+					Protocol: config.StopProtocolTCP,
+					Socket:   "/mysqld.sock",
+				}, testLogger)
+
+				Expect(processManager.Stop()).To(Succeed())
+
+				_, args := fakeOs.RunCommandArgsForCall(0)
+				Expect(args).To(Equal([]string{
+					"--defaults-file=/var/vcap/jobs/pxc-mysql/config/mylogin.cnf",
+					"--protocol=tcp",
+					"shutdown",
+				}))
+			})
+
+			Context("when mysqladmin fails", func() {
+				BeforeEach(func() {
+					fakeOs.RunCommandReturns("", errors.New("Can't connect"))
+				})
+
+				It("sends SIGTERM to the pid in the pid file", func() {
+					fakeOs.ProcessExistsReturnsOnCall(0, true)
+					fakeOs.ProcessExistsReturnsOnCall(1, false)
+
+					Expect(processManager.Stop()).To(Succeed())
+
+					Expect(fakeOs.ReadFileArgsForCall(0)).To(Equal("/mysqld.pid"))
+					Expect(fakeOs.SignalProcessCallCount()).To(Equal(1))
+					pid, signal := fakeOs.SignalProcessArgsForCall(0)
+					Expect(pid).To(Equal(1234))
+					Expect(signal).To(Equal(syscall.SIGTERM))
+					Expect(testLogger.Buffer()).To(gbytes.Say(`"forced":false,"method":"SIGTERM"`))
+				})
+
+				It("sends SIGKILL when mysqld outlives the timeout", func() {
+					fakeOs.ProcessExistsReturns(true)
+
+					Expect(processManager.Stop()).To(Succeed())
+
+					Expect(fakeOs.SleepCallCount()).To(Equal(3))
+					Expect(fakeOs.SignalProcessCallCount()).To(Equal(2))
+					_, signal := fakeOs.SignalProcessArgsForCall(1)
+					Expect(signal).To(Equal(syscall.SIGKILL))
+					Expect(testLogger.Buffer()).To(gbytes.Say(`mysqladmin-shutdown-failed-signalling-pid`))
+					Expect(testLogger.Buffer()).To(gbytes.Say(`"forced":true,"method":"SIGKILL"`))
+				})
+
+				It("succeeds without signalling when mysqld has already exited", func() {
+					fakeOs.FileExistsReturns(false)
+
+					Expect(processManager.Stop()).To(Succeed())
+					Expect(fakeOs.SignalProcessCallCount()).To(Equal(0))
+				})
+
+				It("returns both errors when the fallback fails", func() {
+					fakeOs.ReadFileReturns("not-a-pid", nil)
+
+					err := processManager.Stop()
+					Expect(err).To(MatchError(ContainSubstring("mysqladmin shutdown failed (Can't connect)")))
+					Expect(err).To(MatchError(ContainSubstring("Invalid mysqld pid file /mysqld.pid")))
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package db_helper
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/cloudfoundry/galera-init/config"
+)
+
+// ReconcileUsers re-applies the configured users and grants and returns the
+// accounts whose grants, including the password hash that SHOW GRANTS
+// reports, were missing or had drifted from the configuration. Users whose
+// password was rotated through RotateUserPassword, on any node, are skipped
+// until their configured password changes, since re-applying them would
+// revert the rotation.
+func (m GaleraDBHelper) ReconcileUsers() ([]string, error) {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error connecting to database")
+	}
+	defer CloseDBConnection(db)
+
+	usersToCreate, readOnlyUsers := m.usersToSeed()
+	accounts := seededAccounts(usersToCreate, readOnlyUsers)
+
+	before := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		before[account] = showGrants(db, account)
+	}
+
+	if err := m.seedUsers(usersToCreate, readOnlyUsers); err != nil {
+		return nil, errors.Wrap(err, "Error re-applying users")
+	}
+
+	var corrected []string
+	for _, account := range accounts {
+		if showGrants(db, account) != before[account] {
+			corrected = append(corrected, account)
+		}
+	}
+
+	return corrected, nil
+}
+
+func seededAccounts(usersToCreate []config.SeededUser, readOnlyUsers []config.ReadOnlyUser) []string {
+	var accounts []string
+	for _, user := range usersToCreate {
+		accounts = append(accounts, accountName(user.User, user.Host))
+	}
+	for _, user := range readOnlyUsers {
+		accounts = append(accounts, accountName(user.User, user.Host))
+	}
+	return accounts
+}
+
+func accountName(user, host string) string {
+	if hostString, err := getHostString(host); err == nil {
+		host = hostString
+	}
+	return fmt.Sprintf("`%s`@`%s`", user, host)
+}
+
+// showGrants returns an empty string for an account that does not exist
+func showGrants(db *sql.DB, account string) string {
+	rows, err := db.Query("SHOW GRANTS FOR " + account)
+	if err != nil {
+		return ""
+	}
+	defer rows.Close()
+
+	var grants []string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return ""
+		}
+		grants = append(grants, grant)
+	}
+	return strings.Join(grants, "\n")
+}
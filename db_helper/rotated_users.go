@@ -0,0 +1,147 @@
+package db_helper
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+
+	"github.com/cloudfoundry/galera-init/config"
+)
+
+// Seeded users whose password was rotated at runtime, with a hash of the
+// password configured for them at the time. It is an InnoDB table so that
+// Galera replicates it: every node, including after a restart, knows not to
+// re-apply a configured password that the rotation replaced.
+const rotatedUsersTable = "mysql.galera_init_rotated_users"
+
+const errNoSuchTable = 1146
+
+// Records a rotation of username, if galera-init seeds that user. Nothing
+// needs recording for other users, since seeding never touches them.
+func (m GaleraDBHelper) recordRotation(db *sql.DB, username string) error {
+	configuredPassword, seeded := m.configuredPassword(username)
+	if !seeded {
+		return nil
+	}
+
+	_, err := db.Exec("CREATE TABLE IF NOT EXISTS " + rotatedUsersTable + " (User VARCHAR(128) NOT NULL PRIMARY KEY, ConfiguredPasswordHash CHAR(64) NOT NULL) ENGINE=InnoDB")
+	if err != nil {
+		return errors.Wrapf(err, "Error recording rotation for %s", username)
+	}
+
+	_, err = db.Exec("REPLACE INTO "+rotatedUsersTable+" (User, ConfiguredPasswordHash) VALUES (?, ?)", username, passwordHash(username, configuredPassword))
+	if err != nil {
+		return errors.Wrapf(err, "Error recording rotation for %s", username)
+	}
+
+	return nil
+}
+
+func (m GaleraDBHelper) configuredPassword(username string) (string, bool) {
+	usersToCreate, readOnlyUsers := m.usersToSeed()
+	for _, user := range usersToCreate {
+		if user.User == username {
+			return user.Password, true
+		}
+	}
+	for _, user := range readOnlyUsers {
+		if user.User == username {
+			return user.Password, true
+		}
+	}
+	return "", false
+}
+
+// Leaves out the users whose password was rotated while their configured
+// password has not changed since. Once it has, the configuration is taken as
+// the source of truth again: the rotation is forgotten and the user is seeded.
+func (m GaleraDBHelper) withoutRotatedUsers(db *sql.DB, usersToCreate []config.SeededUser, readOnlyUsers []config.ReadOnlyUser) ([]config.SeededUser, []config.ReadOnlyUser, error) {
+	rotated, err := rotatedUsers(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rotated) == 0 {
+		return usersToCreate, readOnlyUsers, nil
+	}
+
+	var keptUsers []config.SeededUser
+	for _, user := range usersToCreate {
+		skip, err := m.stillRotated(db, rotated, user.User, user.Password)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !skip {
+			keptUsers = append(keptUsers, user)
+		}
+	}
+
+	var keptReadOnlyUsers []config.ReadOnlyUser
+	for _, user := range readOnlyUsers {
+		skip, err := m.stillRotated(db, rotated, user.User, user.Password)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !skip {
+			keptReadOnlyUsers = append(keptReadOnlyUsers, user)
+		}
+	}
+
+	return keptUsers, keptReadOnlyUsers, nil
+}
+
+func (m GaleraDBHelper) stillRotated(db *sql.DB, rotated map[string]string, username string, configuredPassword string) (bool, error) {
+	hashAtRotation, ok := rotated[username]
+	if !ok {
+		return false, nil
+	}
+
+	if passwordHash(username, configuredPassword) == hashAtRotation {
+		m.logger.Info("skipping-rotated-user", lager.Data{"user": username})
+		return true, nil
+	}
+
+	m.logger.Info("rotated-user-configuration-changed", lager.Data{"user": username})
+	if _, err := db.Exec("DELETE FROM "+rotatedUsersTable+" WHERE User = ?", username); err != nil {
+		return false, errors.Wrap(err, "Error forgetting rotated user")
+	}
+	return false, nil
+}
+
+// rotatedUsers maps each rotated user to the hash of the password configured
+// for it when it was rotated. The table only exists once a user was rotated.
+func rotatedUsers(db *sql.DB) (map[string]string, error) {
+	rotated := map[string]string{}
+
+	rows, err := db.Query("SELECT User, ConfiguredPasswordHash FROM " + rotatedUsersTable)
+	if err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == errNoSuchTable {
+			return rotated, nil
+		}
+		return nil, errors.Wrap(err, "Error reading rotated users")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user, hashAtRotation string
+		if err := rows.Scan(&user, &hashAtRotation); err != nil {
+			return nil, errors.Wrap(err, "Error reading rotated users")
+		}
+		rotated[user] = hashAtRotation
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "Error reading rotated users")
+	}
+
+	return rotated, nil
+}
+
+// Hashed in galera-init rather than with the server's PASSWORD(), which MySQL
+// 8.0 no longer has. The user name salts the hash.
+func passwordHash(username string, password string) string {
+	sum := sha256.Sum256([]byte(username + "\x00" + password))
+	return hex.EncodeToString(sum[:])
+}
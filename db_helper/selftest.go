@@ -0,0 +1,126 @@
+package db_helper
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const selfTestDatabase = "galera_init_selftest"
+
+type SelfTestResult struct {
+	Name string
+	Err  error
+}
+
+func (r SelfTestResult) Passed() bool {
+	return r.Err == nil
+}
+
+// SelfTest checks connectivity, a write/read round trip through a scratch
+// table, and that every seeded user with the minimal or read-only role, and
+// every read-only user galera-init manages, is unable to write.
+// The scratch databases are dropped however the checks went, so that they are
+// not left behind for CheckAllowedDatabases to trip over; failing to drop them
+// adds a failed result.
+func (m GaleraDBHelper) SelfTest() (results []SelfTestResult) {
+	defer func() {
+		if err := m.dropSelfTestDatabases(); err != nil {
+			results = append(results, SelfTestResult{Name: "drop-scratch-databases", Err: err})
+		}
+	}()
+
+	results = []SelfTestResult{
+		{Name: "write-and-read", Err: m.selfTestWriteAndRead()},
+	}
+
+	usersToCreate, readOnlyUsers := m.usersToSeed()
+	for _, user := range usersToCreate {
+		if user.Role != "minimal" && user.Role != "read-only" {
+			continue
+		}
+
+		results = append(results, SelfTestResult{
+			Name: fmt.Sprintf("user-%s-cannot-write", user.User),
+			Err:  m.selfTestCannotWrite(user.User, user.Password),
+		})
+	}
+
+	for _, user := range readOnlyUsers {
+		results = append(results, SelfTestResult{
+			Name: fmt.Sprintf("user-%s-cannot-write", user.User),
+			Err:  m.selfTestCannotWrite(user.User, user.Password),
+		})
+	}
+
+	return results
+}
+
+func (m GaleraDBHelper) selfTestWriteAndRead() error {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return errors.Wrap(err, "Error connecting to database")
+	}
+	defer CloseDBConnection(db)
+
+	statements := []string{
+		fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", selfTestDatabase),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s`.`scratch` (id INT PRIMARY KEY, value VARCHAR(255))", selfTestDatabase),
+		fmt.Sprintf("REPLACE INTO `%s`.`scratch` (id, value) VALUES (1, 'galera-init')", selfTestDatabase),
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return errors.Wrap(err, "Error writing to scratch table")
+		}
+	}
+
+	var value string
+	err = db.QueryRow(fmt.Sprintf("SELECT value FROM `%s`.`scratch` WHERE id = 1", selfTestDatabase)).Scan(&value)
+	if err != nil {
+		return errors.Wrap(err, "Error reading from scratch table")
+	}
+	if value != "galera-init" {
+		return fmt.Errorf("Read back unexpected value from scratch table: %q", value)
+	}
+
+	return nil
+}
+
+func (m GaleraDBHelper) dropSelfTestDatabases() error {
+	db, err := OpenDBConnection(m.connectionConfig(), m.protocol)
+	if err != nil {
+		return errors.Wrap(err, "Error connecting to database")
+	}
+	defer CloseDBConnection(db)
+
+	for _, database := range []string{selfTestDatabase, selfTestDatabase + "_denied"} {
+		if _, err := db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", database)); err != nil {
+			return errors.Wrap(err, "Error dropping scratch database")
+		}
+	}
+
+	return nil
+}
+
+func (m GaleraDBHelper) selfTestCannotWrite(user, password string) error {
+	userConfig := *m.config
+	userConfig.User = user
+	userConfig.Password = password
+
+	db, err := OpenDBConnection(&userConfig, m.protocol)
+	if err != nil {
+		return errors.Wrapf(err, "Error connecting as %s", user)
+	}
+	defer CloseDBConnection(db)
+
+	if err := db.Ping(); err != nil {
+		return errors.Wrapf(err, "Error connecting as %s", user)
+	}
+
+	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE `%s_denied`", selfTestDatabase))
+	if err == nil {
+		return fmt.Errorf("User %s was able to create a database", user)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package db_helper_test
+
+import (
+	"database/sql"
+	"errors"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/db_helper/db_helperfakes"
+	"github.com/cloudfoundry/galera-init/os_helper/os_helperfakes"
+)
+
+var _ = Describe("SelfTest", func() {
+	var (
+		helper      *db_helper.GaleraDBHelper
+		dbConfig    *config.DBHelper
+		fakeDB      *sql.DB
+		mock        sqlmock.Sqlmock
+		openedUsers []string
+	)
+
+	BeforeEach(func() {
+		var err error
+		fakeDB, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+
+		openedUsers = nil
+		db_helper.OpenDBConnection = func(cfg *config.DBHelper, _ string) (*sql.DB, error) {
+			openedUsers = append(openedUsers, cfg.User)
+			return fakeDB, nil
+		}
+		db_helper.CloseDBConnection = func(*sql.DB) error {
+			return nil
+		}
+
+		dbConfig = &config.DBHelper{
+			User:     "root",
+			Password: "password",
+			SeededUsers: []config.SeededUser{
+				{User: "admin-user", Password: "admin-password", Host: "localhost", Role: "admin"},
+				{User: "minimal-user", Password: "minimal-password", Host: "localhost", Role: "minimal"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		helper = db_helper.NewDBHelper(
+			new(os_helperfakes.FakeOsHelper),
+			new(db_helperfakes.FakeProcessManager),
+			dbConfig,
+			"/log-file.log",
+			lagertest.NewTestLogger("db_helper"),
+		)
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	expectWriteAndRead := func() {
+		mock.ExpectExec("CREATE DATABASE IF NOT EXISTS `galera_init_selftest`").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS `galera_init_selftest`.`scratch`").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("REPLACE INTO `galera_init_selftest`.`scratch`").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT value FROM `galera_init_selftest`.`scratch` WHERE id = 1").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("galera-init"))
+	}
+
+	expectCleanup := func() {
+		mock.ExpectExec("DROP DATABASE IF EXISTS `galera_init_selftest`").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DROP DATABASE IF EXISTS `galera_init_selftest_denied`").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	It("passes when writes succeed and minimal users are denied", func() {
+		expectWriteAndRead()
+		mock.ExpectExec("CREATE DATABASE `galera_init_selftest_denied`").
+			WillReturnError(errors.New("access denied"))
+		expectCleanup()
+
+		results := helper.SelfTest()
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Name).To(Equal("write-and-read"))
+		Expect(results[0].Passed()).To(BeTrue())
+		Expect(results[1].Name).To(Equal("user-minimal-user-cannot-write"))
+		Expect(results[1].Passed()).To(BeTrue())
+		Expect(openedUsers).To(Equal([]string{"root", "minimal-user", "root"}))
+	})
+
+	Context("when read-only users are managed", func() {
+		BeforeEach(func() {
+			dbConfig.SeededUsers = nil
+			dbConfig.ManageReadOnlyUser = true
+			dbConfig.ReadOnlyUser = "read-only-user"
+			dbConfig.ReadOnlyPassword = "read-only-password"
+			dbConfig.ReadOnlyUsers = []config.ReadOnlyUser{
+				{User: "reporting", Password: "reporting-password", Databases: []string{"orders"}},
+			}
+		})
+
+		It("checks that each of them is unable to write", func() {
+			expectWriteAndRead()
+			mock.ExpectExec("CREATE DATABASE `galera_init_selftest_denied`").
+				WillReturnError(errors.New("access denied"))
+			mock.ExpectExec("CREATE DATABASE `galera_init_selftest_denied`").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectCleanup()
+
+			results := helper.SelfTest()
+			Expect(results).To(HaveLen(3))
+			Expect(results[1].Name).To(Equal("user-read-only-user-cannot-write"))
+			Expect(results[1].Passed()).To(BeTrue())
+			Expect(results[2].Name).To(Equal("user-reporting-cannot-write"))
+			Expect(results[2].Err).To(MatchError("User reporting was able to create a database"))
+			Expect(openedUsers).To(Equal([]string{"root", "read-only-user", "reporting", "root"}))
+		})
+
+		It("skips them when galera-init does not manage them", func() {
+			dbConfig.ManageReadOnlyUser = false
+			expectWriteAndRead()
+			expectCleanup()
+
+			Expect(helper.SelfTest()).To(HaveLen(1))
+		})
+	})
+
+	It("fails when a minimal user is able to write, and drops the database it created", func() {
+		expectWriteAndRead()
+		mock.ExpectExec("CREATE DATABASE `galera_init_selftest_denied`").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DROP DATABASE IF EXISTS `galera_init_selftest`").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DROP DATABASE IF EXISTS `galera_init_selftest_denied`").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		results := helper.SelfTest()
+		Expect(results).To(HaveLen(2))
+		Expect(results[1].Err).To(MatchError("User minimal-user was able to create a database"))
+	})
+
+	It("fails when the scratch table cannot be written, and still drops the scratch database", func() {
+		dbConfig.SeededUsers = nil
+		mock.ExpectExec("CREATE DATABASE IF NOT EXISTS `galera_init_selftest`").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS `galera_init_selftest`.`scratch`").
+			WillReturnError(errors.New("read only"))
+		expectCleanup()
+
+		results := helper.SelfTest()
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Err).To(MatchError("Error writing to scratch table: read only"))
+	})
+
+	It("fails when the scratch table cannot be read back, and still drops the scratch database", func() {
+		dbConfig.SeededUsers = nil
+		mock.ExpectExec("CREATE DATABASE IF NOT EXISTS `galera_init_selftest`").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS `galera_init_selftest`.`scratch`").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("REPLACE INTO `galera_init_selftest`.`scratch`").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT value FROM `galera_init_selftest`.`scratch` WHERE id = 1").
+			WillReturnError(errors.New("lost connection"))
+		expectCleanup()
+
+		results := helper.SelfTest()
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Err).To(MatchError("Error reading from scratch table: lost connection"))
+	})
+
+	It("reports a failure to drop the scratch databases", func() {
+		dbConfig.SeededUsers = nil
+		expectWriteAndRead()
+		mock.ExpectExec("DROP DATABASE IF EXISTS `galera_init_selftest`").
+			WillReturnError(errors.New("lock wait timeout"))
+
+		results := helper.SelfTest()
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Passed()).To(BeTrue())
+		Expect(results[1].Name).To(Equal("drop-scratch-databases"))
+		Expect(results[1].Err).To(MatchError("Error dropping scratch database: lock wait timeout"))
+	})
+})
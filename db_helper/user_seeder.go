@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"code.cloudfoundry.org/lager"
 )
@@ -11,6 +12,7 @@ import (
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . UserSeeder
 type UserSeeder interface {
 	SeedUser(username string, password string, host string, role string) error
+	SeedReadOnlyUser(username string, password string, host string, databases []string) error
 }
 
 type userSeeder struct {
@@ -35,13 +37,66 @@ func (seeder userSeeder) SeedUser(user string, password string, host string, rol
 		return err
 	}
 
+	hostString, err := seeder.createOrUpdateUser(user, password, host)
+	if err != nil {
+		return err
+	}
+
+	_, err = seeder.db.Exec(fmt.Sprintf(
+		roleQuery,
+		user,
+		hostString))
+	if err != nil {
+		seeder.logger.Error("Error changing grants on user", err, lager.Data{
+			"user": user,
+		})
+		return err
+	}
+	return nil
+}
+
+// SeedReadOnlyUser grants SELECT on each of databases, or on every database
+// when none are listed. Existing grants are revoked first, so a database
+// removed from the list loses its grant on the next start.
+func (seeder userSeeder) SeedReadOnlyUser(user string, password string, host string, databases []string) error {
+	hostString, err := seeder.createOrUpdateUser(user, password, host)
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		fmt.Sprintf("REVOKE ALL PRIVILEGES, GRANT OPTION FROM `%s`@`%s`", user, hostString),
+	}
+	if len(databases) == 0 {
+		queries = append(queries, fmt.Sprintf("GRANT SELECT ON *.* TO `%s`@`%s`", user, hostString))
+	}
+	for _, database := range databases {
+		queries = append(queries, fmt.Sprintf(
+			"GRANT SELECT ON `%s`.* TO `%s`@`%s`",
+			strings.Replace(database, "`", "``", -1),
+			user,
+			hostString))
+	}
+
+	for _, query := range queries {
+		if _, err := seeder.db.Exec(query); err != nil {
+			seeder.logger.Error("Error changing grants on user", err, lager.Data{
+				"user": user,
+			})
+			return err
+		}
+	}
+	return nil
+}
+
+func (seeder userSeeder) createOrUpdateUser(user string, password string, host string) (string, error) {
 	hostString, err := getHostString(host)
 	if err != nil {
 		seeder.logger.Error("Invalid host", err, lager.Data{
 			"user": user,
 			"host": host,
 		})
-		return err
+		return "", err
 	}
 
 	_, err = seeder.db.Exec(fmt.Sprintf(
@@ -53,7 +108,7 @@ func (seeder userSeeder) SeedUser(user string, password string, host string, rol
 		seeder.logger.Error("Error creating user", err, lager.Data{
 			"user": user,
 		})
-		return err
+		return "", err
 	}
 
 	_, err = seeder.db.Exec(fmt.Sprintf(
@@ -65,20 +120,10 @@ func (seeder userSeeder) SeedUser(user string, password string, host string, rol
 		seeder.logger.Error("Error updating user password", err, lager.Data{
 			"user": user,
 		})
-		return err
+		return "", err
 	}
 
-	_, err = seeder.db.Exec(fmt.Sprintf(
-		roleQuery,
-		user,
-		hostString))
-	if err != nil {
-		seeder.logger.Error("Error changing grants on user", err, lager.Data{
-			"user": user,
-		})
-		return err
-	}
-	return nil
+	return hostString, nil
 }
 
 func getRoleQuery(role string) (string, error) {
@@ -86,6 +131,8 @@ func getRoleQuery(role string) (string, error) {
 		return "GRANT ALL PRIVILEGES ON *.* TO `%s`@`%s` WITH GRANT OPTION", nil
 	} else if role == "minimal" {
 		return "REVOKE ALL PRIVILEGES ON *.* FROM `%s`@`%s`", nil
+	} else if role == "read-only" {
+		return "GRANT SELECT ON *.* TO `%s`@`%s`", nil
 	}
 	return "", errors.New(fmt.Sprintf("Invalid role: %s", role))
 }
@@ -96,7 +143,7 @@ func getHostString(host string) (string, error) {
 		return "localhost", nil
 	case "loopback":
 		return "127.0.0.1", nil
-	case "any":
+	case "any", "%":
 		return "%", nil
 	default:
 		return "", errors.New(fmt.Sprintf("Invalid host: %s", host))
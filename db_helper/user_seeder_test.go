@@ -2,6 +2,7 @@ package db_helper_test
 
 import (
 	"database/sql"
+	"errors"
 
 	"code.cloudfoundry.org/lager/lagertest"
 	"github.com/DATA-DOG/go-sqlmock"
@@ -65,6 +66,17 @@ var _ = Describe("User Seeder", func() {
 			userSeeder.SeedUser("username", "password", "loopback", "minimal")
 		})
 
+		It("grants select access when the role is read-only", func() {
+			mock.ExpectExec("CREATE USER IF NOT EXISTS `username`@`127.0.0.1` IDENTIFIED BY 'password'").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("ALTER USER `username`@`127.0.0.1` IDENTIFIED BY 'password'").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("GRANT SELECT ON *.* TO `username`@`127.0.0.1`").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			userSeeder.SeedUser("username", "password", "loopback", "read-only")
+		})
+
 		It("errors when the role in unknown", func() {
 			err := userSeeder.SeedUser("username", "password", "loopback", "foo")
 			Expect(err).To(HaveOccurred())
@@ -104,10 +116,64 @@ var _ = Describe("User Seeder", func() {
 			userSeeder.SeedUser("username", "password", "localhost", "minimal")
 		})
 
+		It("accepts % as a synonym for any host", func() {
+			mock.ExpectExec("CREATE USER IF NOT EXISTS `username`@`%` IDENTIFIED BY 'password'").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("ALTER USER `username`@`%` IDENTIFIED BY 'password'").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("REVOKE ALL PRIVILEGES ON *.* FROM `username`@`%`").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			userSeeder.SeedUser("username", "password", "%", "minimal")
+		})
+
 		It("errors when the host in unknown", func() {
 			err := userSeeder.SeedUser("username", "password", "unknown", "admin")
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError("Invalid host: unknown"))
 		})
 	})
+
+	Describe("SeedReadOnlyUser", func() {
+		It("replaces the user's grants with select on each database", func() {
+			mock.ExpectExec("CREATE USER IF NOT EXISTS `username`@`%` IDENTIFIED BY 'password'").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("ALTER USER `username`@`%` IDENTIFIED BY 'password'").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("REVOKE ALL PRIVILEGES, GRANT OPTION FROM `username`@`%`").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("GRANT SELECT ON `orders`.* TO `username`@`%`").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("GRANT SELECT ON `invoices`.* TO `username`@`%`").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			Expect(userSeeder.SeedReadOnlyUser("username", "password", "%", []string{"orders", "invoices"})).To(Succeed())
+		})
+
+		It("grants select on every database when none are listed", func() {
+			mock.ExpectExec("CREATE USER IF NOT EXISTS `username`@`localhost` IDENTIFIED BY 'password'").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("ALTER USER `username`@`localhost` IDENTIFIED BY 'password'").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("REVOKE ALL PRIVILEGES, GRANT OPTION FROM `username`@`localhost`").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("GRANT SELECT ON \\*\\.\\* TO `username`@`localhost`").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			Expect(userSeeder.SeedReadOnlyUser("username", "password", "localhost", nil)).To(Succeed())
+		})
+
+		It("returns the error when a grant fails", func() {
+			mock.ExpectExec("CREATE USER IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("ALTER USER").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("REVOKE ALL PRIVILEGES").WillReturnError(errors.New("revoke failed"))
+
+			Expect(userSeeder.SeedReadOnlyUser("username", "password", "%", []string{"orders"})).To(MatchError("revoke failed"))
+		})
+
+		It("errors when the host in unknown", func() {
+			err := userSeeder.SeedReadOnlyUser("username", "password", "unknown", nil)
+			Expect(err).To(MatchError("Invalid host: unknown"))
+		})
+	})
 })
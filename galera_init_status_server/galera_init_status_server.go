@@ -1,26 +1,196 @@
 package galera_init_status_server
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/retry_counters"
+	"github.com/cloudfoundry/galera-init/transition_history"
 )
 
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . NodeStateChecker
+type NodeStateChecker interface {
+	IsDesynced() (bool, error)
+	IsDonorRejectingQueries() (bool, error)
+	GetServerIdentity() (db_helper.ServerIdentity, error)
+	GetReplicationQueues() (db_helper.ReplicationQueues, error)
+	GetMaxAllowedPacket() (uint64, error)
+	Ping() bool
+	GetWsrepStatus() (db_helper.WsrepStatus, error)
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . CredentialRotator
+type CredentialRotator interface {
+	RotateUserPassword(username string, newPassword string) error
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Reseeder
+type Reseeder interface {
+	Seed() error
+	SeedUsers() error
+}
+
+// HealthReporter reports whether the node needs an operator's attention.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . HealthReporter
+type HealthReporter interface {
+	Healthy() (bool, string)
+}
+
+// HealthReporters is healthy while every reporter in it is, and otherwise
+// reports the reasons of those that are not.
+type HealthReporters []HealthReporter
+
+func (r HealthReporters) Healthy() (bool, string) {
+	var reasons []string
+	for _, reporter := range r {
+		if healthy, reason := reporter.Healthy(); !healthy {
+			reasons = append(reasons, reason)
+		}
+	}
+	return len(reasons) == 0, strings.Join(reasons, "; ")
+}
+
+// Promoter starts a node waiting in warm standby.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Promoter
+type Promoter interface {
+	Promote() error
+	InStandby() bool
+}
+
+// RecoverySuspender pauses the recovery monitor for a bounded time.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . RecoverySuspender
+type RecoverySuspender interface {
+	Suspend(now time.Time, duration time.Duration) (time.Time, error)
+}
+
 type GaleraInitStatusServer struct {
-	listener net.Listener
+	listener               net.Listener
+	history                *transition_history.History
+	counters               *retry_counters.Counters
+	nodeStateChecker       NodeStateChecker
+	credentialRotator      CredentialRotator
+	rotateCredentialsToken string
+	reseeder               Reseeder
+	reseedToken            string
+	maxDatabaseSeedTries   int
+	reseed                 *reseedState
+	healthReporter         HealthReporter
+	promoter               Promoter
+	promoteToken           string
+	recoverySuspender      RecoverySuspender
+	maintenanceToken       string
 }
 
-func NewGaleraInitStatusServer(listener net.Listener) *GaleraInitStatusServer {
+type nodeStatus struct {
+	Desynced            bool                    `json:"desynced"`
+	DonorRejectsQueries bool                    `json:"donor_rejects_queries"`
+	ServerID            int                     `json:"server_id"`
+	WsrepNodeName       string                  `json:"wsrep_node_name"`
+	WsrepNodeAddress    string                  `json:"wsrep_node_address"`
+	RecvQueue           int                     `json:"recv_queue"`
+	SendQueue           int                     `json:"send_queue"`
+	MaxAllowedPacket    uint64                  `json:"max_allowed_packet"`
+	ClusterStatus       string                  `json:"wsrep_cluster_status"`
+	Retries             retry_counters.Snapshot `json:"retries"`
+	Time                time.Time               `json:"time"`
+}
+
+// Pause between attempts at a failed reseed step.
+var ReseedRetryInterval = 5 * time.Second
+
+type reseedStep struct {
+	Step     string   `json:"step"`
+	Attempts int      `json:"attempts"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+type reseedResult struct {
+	Running   bool         `json:"running"`
+	Succeeded bool         `json:"succeeded"`
+	Steps     []reseedStep `json:"steps"`
+}
+
+// The most recent reseed, shared by every copy of the server.
+type reseedState struct {
+	mu     sync.Mutex
+	result *reseedResult
+}
+
+type maintenanceRequest struct {
+	DurationSeconds int `json:"duration_seconds"`
+}
+
+type maintenanceResponse struct {
+	SuspendedUntil time.Time `json:"suspended_until"`
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func NewGaleraInitStatusServer(
+	listener net.Listener,
+	history *transition_history.History,
+	counters *retry_counters.Counters,
+	nodeStateChecker NodeStateChecker,
+	credentialRotator CredentialRotator,
+	rotateCredentialsToken string,
+	reseeder Reseeder,
+	reseedToken string,
+	maxDatabaseSeedTries int,
+	healthReporter HealthReporter,
+	promoter Promoter,
+	promoteToken string,
+	recoverySuspender RecoverySuspender,
+	maintenanceToken string,
+) *GaleraInitStatusServer {
 	return &GaleraInitStatusServer{
-		listener: listener,
+		listener:               listener,
+		history:                history,
+		counters:               counters,
+		nodeStateChecker:       nodeStateChecker,
+		credentialRotator:      credentialRotator,
+		rotateCredentialsToken: rotateCredentialsToken,
+		reseeder:               reseeder,
+		reseedToken:            reseedToken,
+		maxDatabaseSeedTries:   maxDatabaseSeedTries,
+		reseed:                 &reseedState{},
+		healthReporter:         healthReporter,
+		promoter:               promoter,
+		promoteToken:           promoteToken,
+		recoverySuspender:      recoverySuspender,
+		maintenanceToken:       maintenanceToken,
 	}
 }
 
 func (s GaleraInitStatusServer) Start() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/history", s.History)
+	mux.HandleFunc("/status", s.NodeStatus)
+	mux.HandleFunc("/health", s.Health)
+	mux.HandleFunc("/ready", s.Ready)
+	mux.HandleFunc("/rotate-credentials", s.RotateCredentials)
+	mux.HandleFunc("/reseed", s.Reseed)
+	mux.HandleFunc("/promote", s.Promote)
+	mux.HandleFunc("/maintenance", s.Maintenance)
+	mux.HandleFunc("/", s.Status)
+
 	server := &http.Server{
-		Handler:        http.HandlerFunc(s.Status),
+		Handler:        mux,
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
@@ -36,3 +206,360 @@ func (s GaleraInitStatusServer) Start() error {
 func (s GaleraInitStatusServer) Status(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "galera init done")
 }
+
+// NodeStatus reports whether mysqld is currently desynced from the cluster,
+// e.g. because it is shutting down as part of a rolling restart, and whether
+// it refuses queries while serving as an SST donor, along with how much
+// retrying startup took. The local time lets peers detect clock skew, and the
+// server identity lets them detect duplicated server_id or node names. The
+// replication queue depths show whether the node is falling behind, and the
+// cluster status lets joining peers wait for a primary component.
+func (s GaleraInitStatusServer) NodeStatus(w http.ResponseWriter, r *http.Request) {
+	desynced, err := s.nodeStateChecker.IsDesynced()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	donorRejectsQueries, err := s.nodeStateChecker.IsDonorRejectingQueries()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	identity, err := s.nodeStateChecker.GetServerIdentity()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	queues, err := s.nodeStateChecker.GetReplicationQueues()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	maxAllowedPacket, err := s.nodeStateChecker.GetMaxAllowedPacket()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	wsrepStatus, err := s.nodeStateChecker.GetWsrepStatus()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(nodeStatus{
+		Desynced:            desynced,
+		DonorRejectsQueries: donorRejectsQueries,
+		ServerID:            identity.ServerID,
+		WsrepNodeName:       identity.WsrepNodeName,
+		WsrepNodeAddress:    identity.WsrepNodeAddress,
+		RecvQueue:           queues.Recv,
+		SendQueue:           queues.Send,
+		MaxAllowedPacket:    maxAllowedPacket,
+		ClusterStatus:       wsrepStatus.ClusterStatus,
+		Retries:             s.counters.Snapshot(),
+		Time:                time.Now().UTC(),
+	})
+}
+
+// Health is the liveness check, for restart logic: it returns 503 with the
+// reason when mysqld does not answer a ping, or when the health reporter, if
+// there is one, considers the node stuck. A node that is alive but joining or
+// desynced is still healthy; use Ready to decide whether to send it traffic.
+// A node in warm standby is healthy although mysqld is deliberately stopped.
+func (s GaleraInitStatusServer) Health(w http.ResponseWriter, r *http.Request) {
+	if s.inStandby() {
+		fmt.Fprintf(w, "warm standby")
+		return
+	}
+
+	if !s.nodeStateChecker.Ping() {
+		http.Error(w, "mysqld is not responding", http.StatusServiceUnavailable)
+		return
+	}
+
+	if s.healthReporter != nil {
+		if healthy, reason := s.healthReporter.Healthy(); !healthy {
+			http.Error(w, reason, http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	fmt.Fprintf(w, "healthy")
+}
+
+// Ready is the readiness check, for load balancers: it returns 200 only when
+// mysqld is reachable, a member of a primary component, and Synced. Seeding
+// is implied, since the status server only starts once the node has been
+// started and seeded. Anything else returns 503 with the reason.
+func (s GaleraInitStatusServer) Ready(w http.ResponseWriter, r *http.Request) {
+	if s.inStandby() {
+		http.Error(w, "node is in warm standby", http.StatusServiceUnavailable)
+		return
+	}
+
+	status, err := s.nodeStateChecker.GetWsrepStatus()
+	if err != nil {
+		http.Error(w, "mysqld is not reachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	if status.ClusterStatus != "Primary" {
+		http.Error(w, fmt.Sprintf("node is not in a primary component (wsrep_cluster_status %s)", status.ClusterStatus), http.StatusServiceUnavailable)
+		return
+	}
+
+	if status.LocalStateComment != "Synced" {
+		http.Error(w, fmt.Sprintf("node is not synced (wsrep_local_state_comment %s)", status.LocalStateComment), http.StatusServiceUnavailable)
+		return
+	}
+
+	fmt.Fprintf(w, "ready")
+}
+
+func (s GaleraInitStatusServer) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.history.Transitions())
+}
+
+// RotateCredentials changes a database user's password. The request body is
+// {"username": ..., "password": ...}; when no password is given a random one
+// is generated. The new credentials are returned so the caller can update its
+// secret store. The endpoint only exists when a token is configured, and
+// requests must present it as a bearer token.
+func (s GaleraInitStatusServer) RotateCredentials(w http.ResponseWriter, r *http.Request) {
+	if !authorizePost(w, r, s.rotateCredentialsToken) {
+		return
+	}
+
+	var req credentials
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Username == "" {
+		http.Error(w, "request body must be JSON with a username", http.StatusBadRequest)
+		return
+	}
+
+	if req.Password == "" {
+		password, err := generatePassword()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		req.Password = password
+	}
+
+	if err := s.credentialRotator.RotateUserPassword(req.Username, req.Password); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(req)
+}
+
+// Reseed re-runs the idempotent database and user seeding, e.g. after manual
+// schema changes. Like RotateCredentials it only exists when a token is
+// configured. POST starts a reseed in the background and answers 202, since
+// seeding can outlast the server's write timeout; only one runs at a time and
+// concurrent requests get 409 Conflict. GET reports the latest reseed: whether
+// it is still running and each step's attempts and errors.
+func (s GaleraInitStatusServer) Reseed(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		if !authorize(w, r, s.reseedToken, http.MethodGet) {
+			return
+		}
+		s.reportReseed(w)
+		return
+	}
+
+	if !authorizePost(w, r, s.reseedToken) {
+		return
+	}
+
+	s.reseed.mu.Lock()
+	if s.reseed.result != nil && s.reseed.result.Running {
+		s.reseed.mu.Unlock()
+		http.Error(w, "a reseed is already running", http.StatusConflict)
+		return
+	}
+	s.reseed.result = &reseedResult{Running: true}
+	s.reseed.mu.Unlock()
+
+	go s.runReseed()
+
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "reseeding")
+}
+
+func (s GaleraInitStatusServer) reportReseed(w http.ResponseWriter) {
+	s.reseed.mu.Lock()
+	defer s.reseed.mu.Unlock()
+
+	if s.reseed.result == nil {
+		http.Error(w, "no reseed has run", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !s.reseed.result.Running && !s.reseed.result.Succeeded {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(s.reseed.result)
+}
+
+// Runs each seeding step up to maxDatabaseSeedTries times, stopping at the
+// first step that never succeeds, and records every attempt's error.
+func (s GaleraInitStatusServer) runReseed() {
+	tries := s.maxDatabaseSeedTries
+	if tries < 1 {
+		tries = 1
+	}
+
+	succeeded := true
+	for _, step := range []struct {
+		name string
+		run  func() error
+	}{
+		{"seed-databases", s.reseeder.Seed},
+		{"seed-users", s.reseeder.SeedUsers},
+	} {
+		s.updateReseed(func(result *reseedResult) {
+			result.Steps = append(result.Steps, reseedStep{Step: step.name})
+		})
+
+		var err error
+		for attempt := 1; attempt <= tries; attempt++ {
+			if attempt > 1 {
+				time.Sleep(ReseedRetryInterval)
+			}
+			err = step.run()
+			s.updateReseed(func(result *reseedResult) {
+				outcome := &result.Steps[len(result.Steps)-1]
+				outcome.Attempts = attempt
+				if err != nil {
+					outcome.Errors = append(outcome.Errors, err.Error())
+				}
+			})
+			if err == nil {
+				break
+			}
+		}
+
+		if err != nil {
+			succeeded = false
+			break
+		}
+	}
+
+	s.updateReseed(func(result *reseedResult) {
+		result.Running = false
+		result.Succeeded = succeeded
+	})
+}
+
+func (s GaleraInitStatusServer) updateReseed(update func(*reseedResult)) {
+	s.reseed.mu.Lock()
+	defer s.reseed.mu.Unlock()
+	update(s.reseed.result)
+}
+
+// Promote starts a node waiting in warm standby, which then joins or
+// bootstraps as a first deploy would. It returns once the promotion has been
+// handed over, not once mysqld is up; poll /ready for that.
+func (s GaleraInitStatusServer) Promote(w http.ResponseWriter, r *http.Request) {
+	if !authorizePost(w, r, s.promoteToken) {
+		return
+	}
+
+	if s.promoter == nil {
+		http.Error(w, "warm standby is not supported", http.StatusConflict)
+		return
+	}
+
+	if err := s.promoter.Promote(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "promoting")
+}
+
+// Maintenance suspends the recovery monitor for duration_seconds, e.g. before
+// a planned long SST, so that it neither reports the node unhealthy nor
+// restarts galera-init meanwhile. The checks resume on their own once the time
+// has passed, whether or not anyone clears it; a duration of 0 resumes them
+// straight away. Like Promote it only exists when a token is configured.
+func (s GaleraInitStatusServer) Maintenance(w http.ResponseWriter, r *http.Request) {
+	if !authorizePost(w, r, s.maintenanceToken) {
+		return
+	}
+
+	if s.recoverySuspender == nil {
+		http.Error(w, "the recovery monitor is not enabled", http.StatusConflict)
+		return
+	}
+
+	var req maintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "request body must be JSON with duration_seconds", http.StatusBadRequest)
+		return
+	}
+
+	until, err := s.recoverySuspender.Suspend(time.Now(), time.Duration(req.DurationSeconds)*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(maintenanceResponse{SuspendedUntil: until})
+}
+
+func (s GaleraInitStatusServer) inStandby() bool {
+	return s.promoter != nil && s.promoter.InStandby()
+}
+
+// Administrative endpoints are hidden unless their token is configured, only
+// accept POST (or GET, for reports), and require the token as a bearer token.
+// Writes the response and returns false when the request should not be served.
+func authorizePost(w http.ResponseWriter, r *http.Request, token string) bool {
+	return authorize(w, r, token, http.MethodPost)
+}
+
+func authorize(w http.ResponseWriter, r *http.Request, token string, method string) bool {
+	if token == "" {
+		http.NotFound(w, r)
+		return false
+	}
+
+	if r.Method != method {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return false
+	}
+
+	expected := []byte("Bearer " + token)
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
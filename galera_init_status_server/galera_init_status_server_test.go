@@ -1,28 +1,663 @@
 package galera_init_status_server_test
 
 import (
+	"encoding/json"
+	"errors"
+	"github.com/cloudfoundry/galera-init/db_helper"
 	"github.com/cloudfoundry/galera-init/galera_init_status_server"
+	"github.com/cloudfoundry/galera-init/galera_init_status_server/galera_init_status_serverfakes"
+	"github.com/cloudfoundry/galera-init/retry_counters"
+	"github.com/cloudfoundry/galera-init/transition_history"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
 )
 
 var _ = Describe("GaleraInitStatusServer", func() {
 	var (
-		serviceStatusServer *galera_init_status_server.GaleraInitStatusServer
+		serviceStatusServer   *galera_init_status_server.GaleraInitStatusServer
+		history               *transition_history.History
+		counters              *retry_counters.Counters
+		fakeNodeStateChecker  *galera_init_status_serverfakes.FakeNodeStateChecker
+		fakeCredentialRotator *galera_init_status_serverfakes.FakeCredentialRotator
+		fakeReseeder          *galera_init_status_serverfakes.FakeReseeder
 	)
 
 	BeforeEach(func() {
-		listener, err := net.Listen("tcp", "127.0.0.1:8999")
-		Expect(err).ToNot(HaveOccurred())
-		serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(listener)
+		fakeNodeStateChecker = new(galera_init_status_serverfakes.FakeNodeStateChecker)
+		fakeCredentialRotator = new(galera_init_status_serverfakes.FakeCredentialRotator)
+		fakeReseeder = new(galera_init_status_serverfakes.FakeReseeder)
+		history = transition_history.New(10)
+		counters = retry_counters.New()
+		serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, nil, "", nil, "")
 	})
 
 	It("start a service status server listen on the port configured", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:8999")
+		Expect(err).ToNot(HaveOccurred())
+		serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(listener, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, nil, "", nil, "")
+
 		Expect(serviceStatusServer.Start()).To(Succeed())
 		resp, err := http.Get("http://127.0.0.1:8999")
 		Expect(err).ToNot(HaveOccurred())
 		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		resp, err = http.Get("http://127.0.0.1:8999/history")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+	})
+
+	Describe("NodeStatus", func() {
+		It("reports whether mysqld is desynced and how much retrying startup took", func() {
+			fakeNodeStateChecker.IsDesyncedReturns(true, nil)
+			fakeNodeStateChecker.IsDonorRejectingQueriesReturns(true, nil)
+			fakeNodeStateChecker.GetServerIdentityReturns(db_helper.ServerIdentity{ServerID: 2, WsrepNodeName: "mysql-1", WsrepNodeAddress: "10.0.0.2:4567"}, nil)
+			fakeNodeStateChecker.GetReplicationQueuesReturns(db_helper.ReplicationQueues{Recv: 4, Send: 1}, nil)
+			fakeNodeStateChecker.GetMaxAllowedPacketReturns(16777216, nil)
+			fakeNodeStateChecker.GetWsrepStatusReturns(db_helper.WsrepStatus{ClusterStatus: "Primary"}, nil)
+			counters.IncJoinAttempts()
+			counters.IncJoinAttempts()
+			counters.IncReachabilityPolls()
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.NodeStatus(recorder, httptest.NewRequest("GET", "/status", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var status map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &status)).To(Succeed())
+			Expect(status).To(HaveKeyWithValue("desynced", true))
+			Expect(status).To(HaveKeyWithValue("donor_rejects_queries", true))
+			Expect(status).To(HaveKeyWithValue("server_id", 2.0))
+			Expect(status).To(HaveKeyWithValue("wsrep_node_name", "mysql-1"))
+			Expect(status).To(HaveKeyWithValue("wsrep_node_address", "10.0.0.2:4567"))
+			Expect(status).To(HaveKeyWithValue("recv_queue", 4.0))
+			Expect(status).To(HaveKeyWithValue("send_queue", 1.0))
+			Expect(status).To(HaveKeyWithValue("max_allowed_packet", 16777216.0))
+			Expect(status).To(HaveKeyWithValue("wsrep_cluster_status", "Primary"))
+			Expect(status).To(HaveKeyWithValue("retries", map[string]interface{}{
+				"join_attempts":      2.0,
+				"reachability_polls": 1.0,
+				"seed_attempts":      0.0,
+			}))
+		})
+
+		It("reports the local time so peers can detect clock skew", func() {
+			fakeNodeStateChecker.IsDesyncedReturns(false, nil)
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.NodeStatus(recorder, httptest.NewRequest("GET", "/status", nil))
+
+			var status struct {
+				Time time.Time `json:"time"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &status)).To(Succeed())
+			Expect(status.Time).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+
+		It("returns service unavailable when the desync state cannot be read", func() {
+			fakeNodeStateChecker.IsDesyncedReturns(false, errors.New("database not reachable"))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.NodeStatus(recorder, httptest.NewRequest("GET", "/status", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(ContainSubstring("database not reachable"))
+		})
+
+		It("returns service unavailable when the donor setting cannot be read", func() {
+			fakeNodeStateChecker.IsDonorRejectingQueriesReturns(false, errors.New("database not reachable"))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.NodeStatus(recorder, httptest.NewRequest("GET", "/status", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("returns service unavailable when the replication queues cannot be read", func() {
+			fakeNodeStateChecker.GetReplicationQueuesReturns(db_helper.ReplicationQueues{}, errors.New("database not reachable"))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.NodeStatus(recorder, httptest.NewRequest("GET", "/status", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("returns service unavailable when max_allowed_packet cannot be read", func() {
+			fakeNodeStateChecker.GetMaxAllowedPacketReturns(0, errors.New("database not reachable"))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.NodeStatus(recorder, httptest.NewRequest("GET", "/status", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+
+	Describe("History", func() {
+		It("returns the recorded transitions as JSON", func() {
+			history.Record("CLUSTERED", "CLUSTERED", "startup", nil)
+			history.Record("CLUSTERED", "STOPPED", "mysqld-exited", errors.New("exit status 1"))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.History(recorder, httptest.NewRequest("GET", "/history", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var transitions []transition_history.Transition
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &transitions)).To(Succeed())
+			Expect(transitions).To(HaveLen(2))
+			Expect(transitions[0].Reason).To(Equal("startup"))
+			Expect(transitions[1].Outcome).To(Equal("exit status 1"))
+		})
+
+		It("returns an empty list when history is disabled", func() {
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, nil, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, nil, "", nil, "")
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.History(recorder, httptest.NewRequest("GET", "/history", nil))
+
+			Expect(recorder.Body.String()).To(MatchJSON(`[]`))
+		})
+
+		It("rejects methods other than GET", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.History(recorder, httptest.NewRequest("POST", "/history", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
+
+	Describe("RotateCredentials", func() {
+		rotateRequest := func(token, body string) *http.Request {
+			req := httptest.NewRequest("POST", "/rotate-credentials", strings.NewReader(body))
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			return req
+		}
+
+		BeforeEach(func() {
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "secret-token", fakeReseeder, "", 3, nil, nil, "", nil, "")
+		})
+
+		It("rotates the password and returns the new credentials", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.RotateCredentials(recorder, rotateRequest("secret-token", `{"username":"app","password":"new-password"}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"username":"app","password":"new-password"}`))
+			Expect(fakeCredentialRotator.RotateUserPasswordCallCount()).To(Equal(1))
+			username, password := fakeCredentialRotator.RotateUserPasswordArgsForCall(0)
+			Expect(username).To(Equal("app"))
+			Expect(password).To(Equal("new-password"))
+		})
+
+		It("generates a password when none is given", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.RotateCredentials(recorder, rotateRequest("secret-token", `{"username":"app"}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var creds map[string]string
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &creds)).To(Succeed())
+			Expect(creds["password"]).ToNot(BeEmpty())
+
+			_, password := fakeCredentialRotator.RotateUserPasswordArgsForCall(0)
+			Expect(password).To(Equal(creds["password"]))
+		})
+
+		It("rejects requests without the token", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.RotateCredentials(recorder, rotateRequest("wrong-token", `{"username":"app"}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(fakeCredentialRotator.RotateUserPasswordCallCount()).To(Equal(0))
+		})
+
+		It("rejects requests without a username", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.RotateCredentials(recorder, rotateRequest("secret-token", `{}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("rejects methods other than POST", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.RotateCredentials(recorder, httptest.NewRequest("GET", "/rotate-credentials", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("returns an error when the rotation fails", func() {
+			fakeCredentialRotator.RotateUserPasswordReturns(errors.New("User app does not exist"))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.RotateCredentials(recorder, rotateRequest("secret-token", `{"username":"app"}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).To(ContainSubstring("User app does not exist"))
+		})
+
+		It("is not found when no token is configured", func() {
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, nil, "", nil, "")
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.RotateCredentials(recorder, rotateRequest("", `{"username":"app"}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("Health", func() {
+		BeforeEach(func() {
+			fakeNodeStateChecker.PingReturns(true)
+		})
+
+		It("is unhealthy when mysqld does not answer a ping", func() {
+			fakeNodeStateChecker.PingReturns(false)
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Health(recorder, httptest.NewRequest("GET", "/health", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(ContainSubstring("mysqld is not responding"))
+		})
+
+		It("stays healthy while the node is alive but not synced", func() {
+			fakeNodeStateChecker.GetWsrepStatusReturns(db_helper.WsrepStatus{LocalStateComment: "Joining", ClusterStatus: "Primary"}, nil)
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Health(recorder, httptest.NewRequest("GET", "/health", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("is healthy without a health reporter", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Health(recorder, httptest.NewRequest("GET", "/health", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal("healthy"))
+		})
+
+		Context("with a health reporter", func() {
+			var fakeHealthReporter *galera_init_status_serverfakes.FakeHealthReporter
+
+			BeforeEach(func() {
+				fakeHealthReporter = new(galera_init_status_serverfakes.FakeHealthReporter)
+				serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, fakeHealthReporter, nil, "", nil, "")
+			})
+
+			It("is healthy while the reporter is", func() {
+				fakeHealthReporter.HealthyReturns(true, "")
+
+				recorder := httptest.NewRecorder()
+				serviceStatusServer.Health(recorder, httptest.NewRequest("GET", "/health", nil))
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+
+			It("returns service unavailable with the reason when the reporter is unhealthy", func() {
+				fakeHealthReporter.HealthyReturns(false, "node has been Initialized (non-Primary) for 1h0m0s")
+
+				recorder := httptest.NewRecorder()
+				serviceStatusServer.Health(recorder, httptest.NewRequest("GET", "/health", nil))
+
+				Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+				Expect(recorder.Body.String()).To(ContainSubstring("node has been Initialized (non-Primary) for 1h0m0s"))
+			})
+		})
+
+		Context("with several health reporters", func() {
+			var recoveryReporter, clusterSizeReporter *galera_init_status_serverfakes.FakeHealthReporter
+
+			BeforeEach(func() {
+				recoveryReporter = new(galera_init_status_serverfakes.FakeHealthReporter)
+				recoveryReporter.HealthyReturns(true, "")
+				clusterSizeReporter = new(galera_init_status_serverfakes.FakeHealthReporter)
+				clusterSizeReporter.HealthyReturns(true, "")
+				reporters := galera_init_status_server.HealthReporters{recoveryReporter, clusterSizeReporter}
+				serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, reporters, nil, "", nil, "")
+			})
+
+			It("is healthy while every reporter is", func() {
+				recorder := httptest.NewRecorder()
+				serviceStatusServer.Health(recorder, httptest.NewRequest("GET", "/health", nil))
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+
+			It("returns service unavailable with the reasons of every unhealthy reporter", func() {
+				recoveryReporter.HealthyReturns(false, "node has been Initialized (non-Primary) for 1h0m0s")
+				clusterSizeReporter.HealthyReturns(false, "only 1 of 3 peers have been reachable for 10m0s")
+
+				recorder := httptest.NewRecorder()
+				serviceStatusServer.Health(recorder, httptest.NewRequest("GET", "/health", nil))
+
+				Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+				Expect(recorder.Body.String()).To(ContainSubstring("node has been Initialized (non-Primary) for 1h0m0s; only 1 of 3 peers have been reachable for 10m0s"))
+			})
+		})
+	})
+
+	Describe("Ready", func() {
+		ready := func() *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Ready(recorder, httptest.NewRequest("GET", "/ready", nil))
+			return recorder
+		}
+
+		It("is ready when the node is synced in a primary component", func() {
+			fakeNodeStateChecker.GetWsrepStatusReturns(db_helper.WsrepStatus{LocalStateComment: "Synced", ClusterStatus: "Primary"}, nil)
+
+			recorder := ready()
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal("ready"))
+		})
+
+		It("is not ready when mysqld is unreachable", func() {
+			fakeNodeStateChecker.GetWsrepStatusReturns(db_helper.WsrepStatus{}, errors.New("connection refused"))
+
+			recorder := ready()
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(ContainSubstring("mysqld is not reachable: connection refused"))
+		})
+
+		It("is not ready outside a primary component", func() {
+			fakeNodeStateChecker.GetWsrepStatusReturns(db_helper.WsrepStatus{LocalStateComment: "Synced", ClusterStatus: "non-Primary"}, nil)
+
+			recorder := ready()
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(ContainSubstring("wsrep_cluster_status non-Primary"))
+		})
+
+		It("is not ready while the node is not synced", func() {
+			fakeNodeStateChecker.GetWsrepStatusReturns(db_helper.WsrepStatus{LocalStateComment: "Donor/Desynced", ClusterStatus: "Primary"}, nil)
+
+			recorder := ready()
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(ContainSubstring("wsrep_local_state_comment Donor/Desynced"))
+		})
+	})
+
+	Describe("Reseed", func() {
+		var originalRetryInterval time.Duration
+
+		reseedRequest := func(method string, token string) *http.Request {
+			req := httptest.NewRequest(method, "/reseed", nil)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			return req
+		}
+
+		reseedReport := func() string {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Reseed(recorder, reseedRequest("GET", "reseed-token"))
+			return recorder.Body.String()
+		}
+
+		BeforeEach(func() {
+			originalRetryInterval = galera_init_status_server.ReseedRetryInterval
+			galera_init_status_server.ReseedRetryInterval = time.Millisecond
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "reseed-token", 3, nil, nil, "", nil, "")
+		})
+
+		AfterEach(func() {
+			galera_init_status_server.ReseedRetryInterval = originalRetryInterval
+		})
+
+		It("re-runs database and user seeding in the background", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Reseed(recorder, reseedRequest("POST", "reseed-token"))
+
+			Expect(recorder.Code).To(Equal(http.StatusAccepted))
+			Eventually(reseedReport).Should(MatchJSON(`{
+				"running": false,
+				"succeeded": true,
+				"steps": [{"step": "seed-databases", "attempts": 1}, {"step": "seed-users", "attempts": 1}]
+			}`))
+			Expect(fakeReseeder.SeedCallCount()).To(Equal(1))
+			Expect(fakeReseeder.SeedUsersCallCount()).To(Equal(1))
+		})
+
+		It("retries a failing step up to MaxDatabaseSeedTries times", func() {
+			fakeReseeder.SeedReturnsOnCall(0, errors.New("Error creating database"))
+
+			serviceStatusServer.Reseed(httptest.NewRecorder(), reseedRequest("POST", "reseed-token"))
+
+			Eventually(reseedReport).Should(MatchJSON(`{
+				"running": false,
+				"succeeded": true,
+				"steps": [
+					{"step": "seed-databases", "attempts": 2, "errors": ["Error creating database"]},
+					{"step": "seed-users", "attempts": 1}
+				]
+			}`))
+		})
+
+		It("reports the step that failed every attempt and stops there", func() {
+			fakeReseeder.SeedReturns(errors.New("Error creating database"))
+
+			serviceStatusServer.Reseed(httptest.NewRecorder(), reseedRequest("POST", "reseed-token"))
+
+			Eventually(fakeReseeder.SeedCallCount).Should(Equal(3))
+			recorder := httptest.NewRecorder()
+			Eventually(func() int {
+				recorder = httptest.NewRecorder()
+				serviceStatusServer.Reseed(recorder, reseedRequest("GET", "reseed-token"))
+				return recorder.Code
+			}).Should(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).To(MatchJSON(`{
+				"running": false,
+				"succeeded": false,
+				"steps": [{
+					"step": "seed-databases",
+					"attempts": 3,
+					"errors": ["Error creating database", "Error creating database", "Error creating database"]
+				}]
+			}`))
+			Expect(fakeReseeder.SeedUsersCallCount()).To(Equal(0))
+		})
+
+		It("rejects a reseed while another is running and reports it as running", func() {
+			release := make(chan struct{})
+			fakeReseeder.SeedStub = func() error {
+				<-release
+				return nil
+			}
+
+			serviceStatusServer.Reseed(httptest.NewRecorder(), reseedRequest("POST", "reseed-token"))
+			Eventually(fakeReseeder.SeedCallCount).Should(Equal(1))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Reseed(recorder, reseedRequest("POST", "reseed-token"))
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+			Expect(reseedReport()).To(ContainSubstring(`"running":true`))
+
+			close(release)
+			Eventually(reseedReport).Should(ContainSubstring(`"succeeded":true`))
+		})
+
+		It("is not found before any reseed has run", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Reseed(recorder, reseedRequest("GET", "reseed-token"))
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("rejects requests without the token", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Reseed(recorder, reseedRequest("POST", "wrong-token"))
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+
+			recorder = httptest.NewRecorder()
+			serviceStatusServer.Reseed(recorder, reseedRequest("GET", "wrong-token"))
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+
+			Expect(fakeReseeder.SeedCallCount()).To(Equal(0))
+		})
+
+		It("is not found when no token is configured", func() {
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, nil, "", nil, "")
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Reseed(recorder, reseedRequest("POST", ""))
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("Promote", func() {
+		var fakePromoter *galera_init_status_serverfakes.FakePromoter
+
+		promoteRequest := func(token string) *http.Request {
+			req := httptest.NewRequest("POST", "/promote", nil)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			return req
+		}
+
+		BeforeEach(func() {
+			fakePromoter = new(galera_init_status_serverfakes.FakePromoter)
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, fakePromoter, "promote-token", nil, "")
+		})
+
+		It("promotes a node in warm standby", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Promote(recorder, promoteRequest("promote-token"))
+
+			Expect(recorder.Code).To(Equal(http.StatusAccepted))
+			Expect(fakePromoter.PromoteCallCount()).To(Equal(1))
+		})
+
+		It("reports a conflict when the node is not in warm standby", func() {
+			fakePromoter.PromoteReturns(errors.New("node is not in warm standby"))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Promote(recorder, promoteRequest("promote-token"))
+
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+			Expect(recorder.Body.String()).To(ContainSubstring("node is not in warm standby"))
+		})
+
+		It("rejects requests without the token", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Promote(recorder, promoteRequest("wrong-token"))
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(fakePromoter.PromoteCallCount()).To(Equal(0))
+		})
+
+		It("is not found when no token is configured", func() {
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, fakePromoter, "", nil, "")
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Promote(recorder, promoteRequest(""))
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		Context("while the node is in warm standby", func() {
+			BeforeEach(func() {
+				fakePromoter.InStandbyReturns(true)
+				fakeNodeStateChecker.PingReturns(false)
+			})
+
+			It("is healthy although mysqld is stopped", func() {
+				recorder := httptest.NewRecorder()
+				serviceStatusServer.Health(recorder, httptest.NewRequest("GET", "/health", nil))
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(Equal("warm standby"))
+			})
+
+			It("is not ready", func() {
+				recorder := httptest.NewRecorder()
+				serviceStatusServer.Ready(recorder, httptest.NewRequest("GET", "/ready", nil))
+
+				Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+				Expect(recorder.Body.String()).To(ContainSubstring("node is in warm standby"))
+			})
+		})
+	})
+
+	Describe("Maintenance", func() {
+		var fakeSuspender *galera_init_status_serverfakes.FakeRecoverySuspender
+
+		maintenanceRequest := func(token string, body string) *http.Request {
+			req := httptest.NewRequest("POST", "/maintenance", strings.NewReader(body))
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			return req
+		}
+
+		BeforeEach(func() {
+			fakeSuspender = new(galera_init_status_serverfakes.FakeRecoverySuspender)
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, nil, "", fakeSuspender, "maintenance-token")
+		})
+
+		It("suspends the recovery monitor for the requested duration", func() {
+			until := time.Date(2020, time.March, 10, 13, 0, 0, 0, time.UTC)
+			fakeSuspender.SuspendReturns(until, nil)
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Maintenance(recorder, maintenanceRequest("maintenance-token", `{"duration_seconds": 3600}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"suspended_until": "2020-03-10T13:00:00Z"}`))
+			Expect(fakeSuspender.SuspendCallCount()).To(Equal(1))
+			_, duration := fakeSuspender.SuspendArgsForCall(0)
+			Expect(duration).To(Equal(time.Hour))
+		})
+
+		It("rejects a duration the monitor refuses", func() {
+			fakeSuspender.SuspendReturns(time.Time{}, errors.New("suspension must be between 0 and 4h0m0s, got 5h0m0s"))
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Maintenance(recorder, maintenanceRequest("maintenance-token", `{"duration_seconds": 18000}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("suspension must be between 0 and 4h0m0s"))
+		})
+
+		It("rejects a malformed body", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Maintenance(recorder, maintenanceRequest("maintenance-token", `not json`))
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeSuspender.SuspendCallCount()).To(Equal(0))
+		})
+
+		It("rejects requests without the token", func() {
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Maintenance(recorder, maintenanceRequest("wrong-token", `{"duration_seconds": 3600}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(fakeSuspender.SuspendCallCount()).To(Equal(0))
+		})
+
+		It("is not found when no token is configured", func() {
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, nil, "", fakeSuspender, "")
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Maintenance(recorder, maintenanceRequest("", `{"duration_seconds": 3600}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("reports a conflict when the recovery monitor is not enabled", func() {
+			serviceStatusServer = galera_init_status_server.NewGaleraInitStatusServer(nil, history, counters, fakeNodeStateChecker, fakeCredentialRotator, "", fakeReseeder, "", 3, nil, nil, "", nil, "maintenance-token")
+
+			recorder := httptest.NewRecorder()
+			serviceStatusServer.Maintenance(recorder, maintenanceRequest("maintenance-token", `{"duration_seconds": 3600}`))
+
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+		})
 	})
 })
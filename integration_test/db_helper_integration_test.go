@@ -61,7 +61,7 @@ var _ = Describe("DB Helper", func() {
 		}
 
 		//override db connection to use test DB
-		db_helper.OpenDBConnection = func(config *config.DBHelper) (*sql.DB, error) {
+		db_helper.OpenDBConnection = func(config *config.DBHelper, protocol string) (*sql.DB, error) {
 			return sql.Open("mysql", rootDsn)
 		}
 	})
@@ -119,6 +119,7 @@ var _ = Describe("DB Helper", func() {
 		JustBeforeEach(func() {
 			helper = db_helper.NewDBHelper(
 				fakeOs,
+				db_helper.NewDirectProcessManager(fakeOs),
 				dbConfig,
 				logFile,
 				testLogger,
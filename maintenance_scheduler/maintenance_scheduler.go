@@ -0,0 +1,113 @@
+package maintenance_scheduler
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/db_helper"
+)
+
+// How often the scheduler wakes up to check whether the maintenance window is open
+var CheckInterval = time.Minute
+
+type Scheduler struct {
+	dbHelper db_helper.DBHelper
+	config   config.Maintenance
+	logger   lager.Logger
+	lastRun  time.Time
+}
+
+func NewScheduler(dbHelper db_helper.DBHelper, config config.Maintenance, logger lager.Logger) *Scheduler {
+	return &Scheduler{
+		dbHelper: dbHelper,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Run checks the maintenance window every CheckInterval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.RunIfDue(now)
+		}
+	}
+}
+
+// RunIfDue runs the maintenance statements at most once per window, and only
+// while the node is synced and not throttling the cluster with flow control.
+// It reports whether the statements were run.
+func (s *Scheduler) RunIfDue(now time.Time) bool {
+	windowStart, ok := s.windowStart(now)
+	if !ok || now.Before(windowStart) || !now.Before(windowStart.Add(s.windowDuration())) {
+		return false
+	}
+
+	if !s.lastRun.Before(windowStart) {
+		return false
+	}
+
+	status, err := s.dbHelper.GetWsrepStatus()
+	if err != nil {
+		s.logger.Error("maintenance-status-check-failed", err)
+		return false
+	}
+	if status.LocalStateComment != "Synced" {
+		s.logger.Info("maintenance-skipped-node-not-synced", lager.Data{
+			"localState": status.LocalStateComment,
+		})
+		return false
+	}
+
+	flowControlActive, err := s.dbHelper.IsFlowControlActive()
+	if err != nil {
+		s.logger.Error("maintenance-status-check-failed", err)
+		return false
+	}
+	if flowControlActive {
+		s.logger.Info("maintenance-skipped-flow-control-active")
+		return false
+	}
+
+	s.logger.Info("maintenance-starting")
+	for _, statement := range s.config.Statements {
+		if err := s.dbHelper.RunQuery(statement); err != nil {
+			s.logger.Error("maintenance-statement-failed", err, lager.Data{
+				"statement": statement,
+			})
+		}
+	}
+	s.lastRun = now
+	s.logger.Info("maintenance-finished")
+
+	return true
+}
+
+// Returns the start of the most recent window that began at or before now,
+// so a window spanning midnight is still recognised after the date changes.
+func (s *Scheduler) windowStart(now time.Time) (time.Time, bool) {
+	startOfDay, err := time.Parse(config.MaintenanceWindowLayout, s.config.WindowStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	start := time.Date(now.Year(), now.Month(), now.Day(), startOfDay.Hour(), startOfDay.Minute(), 0, 0, now.Location())
+	if start.After(now) {
+		start = start.AddDate(0, 0, -1)
+	}
+
+	return start, true
+}
+
+func (s *Scheduler) windowDuration() time.Duration {
+	return time.Duration(s.config.WindowDurationMinutes) * time.Minute
+}
@@ -0,0 +1,120 @@
+package maintenance_scheduler_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/db_helper/db_helperfakes"
+	"github.com/cloudfoundry/galera-init/maintenance_scheduler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Scheduler", func() {
+	var (
+		scheduler    *maintenance_scheduler.Scheduler
+		fakeDBHelper *db_helperfakes.FakeDBHelper
+		testLogger   *lagertest.TestLogger
+	)
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2020, time.March, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	BeforeEach(func() {
+		fakeDBHelper = new(db_helperfakes.FakeDBHelper)
+		fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{LocalStateComment: "Synced"}, nil)
+		testLogger = lagertest.NewTestLogger("maintenance_scheduler")
+
+		scheduler = maintenance_scheduler.NewScheduler(
+			fakeDBHelper,
+			config.Maintenance{
+				Statements:            []string{"ANALYZE TABLE db.t1", "ANALYZE TABLE db.t2"},
+				WindowStart:           "23:30",
+				WindowDurationMinutes: 60,
+			},
+			testLogger,
+		)
+	})
+
+	Describe("RunIfDue", func() {
+		It("runs each statement when the window is open", func() {
+			Expect(scheduler.RunIfDue(at(23, 45))).To(BeTrue())
+
+			Expect(fakeDBHelper.RunQueryCallCount()).To(Equal(2))
+			Expect(fakeDBHelper.RunQueryArgsForCall(0)).To(Equal("ANALYZE TABLE db.t1"))
+			Expect(fakeDBHelper.RunQueryArgsForCall(1)).To(Equal("ANALYZE TABLE db.t2"))
+		})
+
+		It("recognises a window that spans midnight", func() {
+			Expect(scheduler.RunIfDue(at(0, 15))).To(BeTrue())
+		})
+
+		It("does nothing outside the window", func() {
+			Expect(scheduler.RunIfDue(at(0, 30))).To(BeFalse())
+			Expect(scheduler.RunIfDue(at(12, 0))).To(BeFalse())
+			Expect(fakeDBHelper.GetWsrepStatusCallCount()).To(Equal(0))
+		})
+
+		It("runs only once per window", func() {
+			Expect(scheduler.RunIfDue(at(23, 45))).To(BeTrue())
+			Expect(scheduler.RunIfDue(at(23, 50))).To(BeFalse())
+			Expect(scheduler.RunIfDue(at(23, 45).AddDate(0, 0, 1))).To(BeTrue())
+		})
+
+		It("skips when the node is not synced", func() {
+			fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{LocalStateComment: "Donor/Desynced"}, nil)
+
+			Expect(scheduler.RunIfDue(at(23, 45))).To(BeFalse())
+			Expect(fakeDBHelper.RunQueryCallCount()).To(Equal(0))
+			Expect(testLogger.Buffer()).To(gbytes.Say("maintenance-skipped-node-not-synced"))
+		})
+
+		It("skips when flow control is active, and retries later in the window", func() {
+			fakeDBHelper.IsFlowControlActiveReturns(true, nil)
+
+			Expect(scheduler.RunIfDue(at(23, 45))).To(BeFalse())
+			Expect(fakeDBHelper.RunQueryCallCount()).To(Equal(0))
+
+			fakeDBHelper.IsFlowControlActiveReturns(false, nil)
+			Expect(scheduler.RunIfDue(at(23, 50))).To(BeTrue())
+		})
+
+		It("skips when the node status cannot be read", func() {
+			fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{}, errors.New("some error"))
+
+			Expect(scheduler.RunIfDue(at(23, 45))).To(BeFalse())
+			Expect(fakeDBHelper.RunQueryCallCount()).To(Equal(0))
+		})
+
+		It("keeps going when a statement fails", func() {
+			fakeDBHelper.RunQueryReturnsOnCall(0, errors.New("table does not exist"))
+
+			Expect(scheduler.RunIfDue(at(23, 45))).To(BeTrue())
+			Expect(fakeDBHelper.RunQueryCallCount()).To(Equal(2))
+			Expect(testLogger.Buffer()).To(gbytes.Say("maintenance-statement-failed"))
+		})
+	})
+
+	Describe("Run", func() {
+		It("returns when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+
+			go func() {
+				scheduler.Run(ctx)
+				close(done)
+			}()
+
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
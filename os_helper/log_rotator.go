@@ -0,0 +1,122 @@
+package os_helper
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// Suffix appended to rotated log files; it sorts in creation order
+const rotatedFileTimeLayout = "20060102T150405.000000000"
+
+// How often the LogRotator checks the size of the log file
+var LogRotationCheckInterval = 10 * time.Second
+
+// LogRotator keeps a log file that other processes append to, such as
+// mysqld's output, under maxBytes. Once the file is larger it is copied to
+// <path>.<timestamp> and truncated in place, keeping at most backups copies.
+// The writers keep their own file descriptor, so they carry on logging
+// whether or not galera-init is still running; as with logrotate's
+// copytruncate, lines written between the copy and the truncation are lost.
+type LogRotator struct {
+	path     string
+	maxBytes int64
+	backups  int
+	logger   lager.Logger
+}
+
+func NewLogRotator(path string, maxBytes int64, backups int, logger lager.Logger) *LogRotator {
+	return &LogRotator{
+		path:     path,
+		maxBytes: maxBytes,
+		backups:  backups,
+		logger:   logger,
+	}
+}
+
+// Run checks the log file every LogRotationCheckInterval until ctx is
+// cancelled.
+func (r *LogRotator) Run(ctx context.Context) {
+	ticker := time.NewTicker(LogRotationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.RotateIfNeeded(); err != nil {
+				r.logger.Error("log-rotation-failed", err, lager.Data{"path": r.path})
+			}
+		}
+	}
+}
+
+func (r *LogRotator) RotateIfNeeded() error {
+	info, err := os.Stat(r.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() <= r.maxBytes {
+		return nil
+	}
+
+	rotated := r.path + "." + time.Now().UTC().Format(rotatedFileTimeLayout)
+	if err := copyLogFile(r.path, rotated); err != nil {
+		return err
+	}
+
+	if err := os.Truncate(r.path, 0); err != nil {
+		return err
+	}
+
+	r.logger.Info("log-rotated", lager.Data{"path": r.path, "rotatedTo": rotated})
+	return r.removeOldBackups()
+}
+
+func copyLogFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	return out.Close()
+}
+
+func (r *LogRotator) removeOldBackups() error {
+	backups, err := filepath.Glob(r.path + ".*")
+	if err != nil {
+		return err
+	}
+	if len(backups) <= r.backups {
+		return nil
+	}
+
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-r.backups] {
+		if err := os.Remove(backup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package os_helper_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/cloudfoundry/galera-init/os_helper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogRotator", func() {
+	var (
+		tempDir string
+		logPath string
+		rotator *LogRotator
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "log_rotator")
+		Expect(err).NotTo(HaveOccurred())
+		logPath = filepath.Join(tempDir, "mysql.log")
+
+		rotator = NewLogRotator(logPath, 10, 2, lagertest.NewTestLogger("log_rotator"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	rotatedFiles := func() []string {
+		rotated, err := filepath.Glob(logPath + ".*")
+		Expect(err).NotTo(HaveOccurred())
+		return rotated
+	}
+
+	It("leaves the file alone while it is within the size limit", func() {
+		Expect(ioutil.WriteFile(logPath, []byte("0123456789"), 0644)).To(Succeed())
+
+		Expect(rotator.RotateIfNeeded()).To(Succeed())
+
+		Expect(ioutil.ReadFile(logPath)).To(Equal([]byte("0123456789")))
+		Expect(rotatedFiles()).To(BeEmpty())
+	})
+
+	It("copies the file aside and truncates it once it is over the limit", func() {
+		Expect(ioutil.WriteFile(logPath, []byte("0123456789abc"), 0644)).To(Succeed())
+
+		Expect(rotator.RotateIfNeeded()).To(Succeed())
+
+		Expect(ioutil.ReadFile(logPath)).To(BeEmpty())
+		Expect(rotatedFiles()).To(HaveLen(1))
+		Expect(ioutil.ReadFile(rotatedFiles()[0])).To(Equal([]byte("0123456789abc")))
+	})
+
+	It("lets a process appending to the file keep writing to it", func() {
+		writer, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		defer writer.Close()
+
+		_, err = writer.WriteString("0123456789abc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotator.RotateIfNeeded()).To(Succeed())
+
+		_, err = writer.WriteString("after")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ioutil.ReadFile(logPath)).To(Equal([]byte("after")))
+	})
+
+	It("keeps only the newest backups", func() {
+		for _, chunk := range []string{"first------", "second-----", "third------", "fourth-----"} {
+			Expect(ioutil.WriteFile(logPath, []byte(chunk), 0644)).To(Succeed())
+			Expect(rotator.RotateIfNeeded()).To(Succeed())
+		}
+
+		rotated := rotatedFiles()
+		Expect(rotated).To(HaveLen(2))
+		Expect(ioutil.ReadFile(rotated[0])).To(Equal([]byte("third------")))
+		Expect(ioutil.ReadFile(rotated[1])).To(Equal([]byte("fourth-----")))
+	})
+
+	It("does nothing when the file does not exist", func() {
+		Expect(rotator.RotateIfNeeded()).To(Succeed())
+		Expect(rotatedFiles()).To(BeEmpty())
+	})
+
+	Describe("Run", func() {
+		var originalInterval time.Duration
+
+		BeforeEach(func() {
+			originalInterval = LogRotationCheckInterval
+			LogRotationCheckInterval = 10 * time.Millisecond
+		})
+
+		AfterEach(func() {
+			LogRotationCheckInterval = originalInterval
+		})
+
+		It("rotates the file until ctx is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				rotator.Run(ctx)
+				close(done)
+			}()
+
+			Expect(ioutil.WriteFile(logPath, []byte("0123456789abc"), 0644)).To(Succeed())
+			Eventually(rotatedFiles).Should(HaveLen(1))
+
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
@@ -1,9 +1,15 @@
 package os_helper
 
 import (
+	"bufio"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,10 +23,25 @@ type OsHelper interface {
 	FileExists(filename string) bool
 	ReadFile(filename string) (string, error)
 	WriteStringToFile(filename string, contents string) error
+	WriteSecretFile(filename string, contents string) error
+	WriteStringToFileAtomically(filename string, contents string) error
+	WriteStringToFileDurably(filename string, contents string) error
 	Sleep(duration time.Duration)
 	KillCommand(cmd *exec.Cmd, signal os.Signal) error
+	AcquireLock(filename string) (release func() error, err error)
+	TotalMemory() (uint64, error)
+	ProcessExists(pid int) bool
+	SignalProcess(pid int, signal os.Signal) error
+	RemoveFile(filename string) error
+	MakeDir(dir string, mode os.FileMode) error
+	CopyFile(src string, dst string) error
+	ProbeWritable(dir string) error
+	Hostname() (string, error)
+	IsPortInUse(port int) bool
 }
 
+var MemInfoPath = "/proc/meminfo"
+
 type OsHelperImpl struct{}
 
 func NewImpl() *OsHelperImpl {
@@ -37,7 +58,16 @@ func (h OsHelperImpl) RunCommand(executable string, args ...string) (string, err
 	return string(out), nil
 }
 
+// Starts the command with its stdout and stderr appended to logFileName. An
+// empty logFileName passes galera-init's own stdout and stderr through.
 func (h OsHelperImpl) StartCommand(logFileName string, executable string, args ...string) (*exec.Cmd, error) {
+	if logFileName == "" {
+		cmd := exec.Command(executable, args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd, errors.Wrapf(cmd.Start(), "error starting %q", executable)
+	}
+
 	cmd := exec.Command(executable, args...)
 	logFile, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
@@ -77,6 +107,73 @@ func (h OsHelperImpl) WriteStringToFile(filename string, contents string) error
 	return err
 }
 
+// Writes to a temporary file in the same directory, syncs it and renames it
+// into place, so neither readers nor a crash can observe a partial file
+func (h OsHelperImpl) WriteStringToFileAtomically(filename string, contents string) error {
+	return writeFileAtomically(filename, contents, 0644)
+}
+
+// Writes atomically like WriteStringToFileAtomically, but readable only by
+// its owner, for files holding credentials
+func (h OsHelperImpl) WriteSecretFile(filename string, contents string) error {
+	return writeFileAtomically(filename, contents, 0600)
+}
+
+func writeFileAtomically(filename string, contents string, mode os.FileMode) error {
+	tmpFile, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(contents)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmpFile.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), filename)
+}
+
+// Writes atomically, then fsyncs the parent directory so the rename itself
+// survives power loss, and reads the file back to verify its contents
+func (h OsHelperImpl) WriteStringToFileDurably(filename string, contents string) error {
+	if err := h.WriteStringToFileAtomically(filename, contents); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(filename))
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	if closeErr := dir.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error syncing directory of %q", filename)
+	}
+
+	written, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if string(written) != contents {
+		return fmt.Errorf("%q contains %q after writing %q", filename, written, contents)
+	}
+
+	return nil
+}
+
 func (h OsHelperImpl) Sleep(duration time.Duration) {
 	time.Sleep(duration)
 }
@@ -89,3 +186,154 @@ func (h OsHelperImpl) KillCommand(cmd *exec.Cmd, signal os.Signal) error {
 	err := cmd.Process.Signal(signal)
 	return errors.Wrap(err, `unable-to-kill-process`)
 }
+
+// Takes an exclusive advisory lock on the file, creating it if necessary.
+// Fails immediately if another process already holds the lock.
+func (h OsHelperImpl) AcquireLock(filename string) (func() error, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening lock file %q", filename)
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errors.Errorf("already running: lock file %q is held by another process", filename)
+		}
+		return nil, errors.Wrapf(err, "error locking file %q", filename)
+	}
+
+	return func() error {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		return file.Close()
+	}, nil
+}
+
+// Returns the total system memory in bytes
+func (h OsHelperImpl) TotalMemory() (uint64, error) {
+	file, err := os.Open(MemInfoPath)
+	if err != nil {
+		return 0, errors.Wrap(err, "error reading system memory")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var kilobytes uint64
+		if _, err := fmt.Sscanf(scanner.Text(), "MemTotal: %d kB", &kilobytes); err == nil {
+			return kilobytes * 1024, nil
+		}
+	}
+
+	return 0, errors.Errorf("MemTotal not found in %s", MemInfoPath)
+}
+
+// Reports whether a process with the given pid is alive, by sending it signal 0
+func (h OsHelperImpl) ProcessExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	err := syscall.Kill(pid, syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
+
+// Sends a signal to a process that is not a child of galera-init, such as one
+// found through a pid file
+func (h OsHelperImpl) SignalProcess(pid int, signal os.Signal) error {
+	if pid <= 0 {
+		return errors.Errorf("invalid pid %d", pid)
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return errors.Wrapf(err, "unable-to-find-process %d", pid)
+	}
+
+	return errors.Wrapf(process.Signal(signal), "unable-to-signal-process %d", pid)
+}
+
+func (h OsHelperImpl) Hostname() (string, error) {
+	return os.Hostname()
+}
+
+// Reports whether something is already listening on the TCP port, by trying
+// to bind it on all interfaces
+func (h OsHelperImpl) IsPortInUse(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err == nil {
+		listener.Close()
+		return false
+	}
+
+	if opErr, ok := err.(*net.OpError); ok {
+		if sysErr, ok := opErr.Err.(*os.SyscallError); ok {
+			return sysErr.Err == syscall.EADDRINUSE
+		}
+	}
+	return false
+}
+
+// Removes the file, treating an already missing file as success
+func (h OsHelperImpl) RemoveFile(filename string) error {
+	err := os.Remove(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Creates dir with mode, treating an existing directory as success. Unlike
+// os.MkdirAll it never creates missing parents, so a directory whose disk is
+// not mounted yet is not created on the filesystem underneath.
+func (h OsHelperImpl) MakeDir(dir string, mode os.FileMode) error {
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return nil
+	}
+	return os.Mkdir(dir, mode)
+}
+
+// Copies src to dst, creating dst's directory if necessary
+func (h OsHelperImpl) CopyFile(src string, dst string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+
+	destination, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(destination, source)
+	if closeErr := destination.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Creates, writes and deletes a scratch file in dir to prove the filesystem
+// is mounted read-write
+func (h OsHelperImpl) ProbeWritable(dir string) error {
+	file, err := ioutil.TempFile(dir, ".write-probe")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString("probe")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(file.Name())
+}
@@ -2,6 +2,7 @@ package os_helper_test
 
 import (
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -76,6 +77,17 @@ var _ = Describe("OsHelper", func() {
 			})
 		})
 
+		When("no log file is given", func() {
+			It("passes galera-init's own output through", func() {
+				cmd, err := helper.StartCommand("", "echo", "-n", "some argument")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cmd.Wait()).To(Succeed())
+
+				Expect(cmd.Stdout).To(Equal(os.Stdout))
+				Expect(cmd.Stderr).To(Equal(os.Stderr))
+			})
+		})
+
 		When("an invalid executable path is requested", func() {
 			It("returns an error", func() {
 				badExecutable := filepath.Join(tempDir, "command-does-not-exist")
@@ -148,4 +160,350 @@ var _ = Describe("OsHelper", func() {
 		})
 
 	})
+
+	Describe("AcquireLock", func() {
+		var (
+			tempDir  string
+			lockFile string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir(os.TempDir(), "acquire_lock_")
+			Expect(err).NotTo(HaveOccurred())
+
+			lockFile = filepath.Join(tempDir, "galera-init.pid")
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tempDir)
+		})
+
+		It("creates the lock file", func() {
+			release, err := helper.AcquireLock(lockFile)
+			Expect(err).NotTo(HaveOccurred())
+			defer release()
+
+			Expect(lockFile).To(BeAnExistingFile())
+		})
+
+		It("fails when the lock is already held", func() {
+			release, err := helper.AcquireLock(lockFile)
+			Expect(err).NotTo(HaveOccurred())
+			defer release()
+
+			_, err = helper.AcquireLock(lockFile)
+			Expect(err).To(MatchError(ContainSubstring("already running")))
+		})
+
+		It("can be acquired again once released", func() {
+			release, err := helper.AcquireLock(lockFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release()).To(Succeed())
+
+			release, err = helper.AcquireLock(lockFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release()).To(Succeed())
+		})
+
+		It("returns an error when the lock file cannot be opened", func() {
+			_, err := helper.AcquireLock(filepath.Join(tempDir, "missing", "galera-init.pid"))
+			Expect(err).To(MatchError(ContainSubstring("error opening lock file")))
+		})
+	})
+
+	Describe("TotalMemory", func() {
+		var (
+			memInfo             *os.File
+			originalMemInfoPath string
+		)
+
+		BeforeEach(func() {
+			var err error
+			memInfo, err = ioutil.TempFile(os.TempDir(), "meminfo")
+			Expect(err).NotTo(HaveOccurred())
+
+			originalMemInfoPath = MemInfoPath
+			MemInfoPath = memInfo.Name()
+		})
+
+		AfterEach(func() {
+			MemInfoPath = originalMemInfoPath
+			_ = os.Remove(memInfo.Name())
+		})
+
+		It("returns MemTotal in bytes", func() {
+			Expect(ioutil.WriteFile(memInfo.Name(), []byte("MemTotal:        2048 kB\nMemFree:         1024 kB\n"), 0644)).To(Succeed())
+
+			total, err := helper.TotalMemory()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(uint64(2048 * 1024)))
+		})
+
+		It("returns an error when MemTotal is missing", func() {
+			Expect(ioutil.WriteFile(memInfo.Name(), []byte("MemFree: 1024 kB\n"), 0644)).To(Succeed())
+
+			_, err := helper.TotalMemory()
+			Expect(err).To(MatchError(ContainSubstring("MemTotal not found")))
+		})
+	})
+
+	Describe("ProcessExists", func() {
+		It("returns true for a running process", func() {
+			Expect(helper.ProcessExists(os.Getpid())).To(BeTrue())
+		})
+
+		It("returns false for a process that has exited", func() {
+			cmd := exec.Command("true")
+			Expect(cmd.Run()).To(Succeed())
+
+			Expect(helper.ProcessExists(cmd.Process.Pid)).To(BeFalse())
+		})
+
+		It("returns false for an invalid pid", func() {
+			Expect(helper.ProcessExists(0)).To(BeFalse())
+		})
+	})
+
+	Describe("SignalProcess", func() {
+		It("signals the process", func() {
+			cmd := exec.Command("sleep", "60")
+			Expect(cmd.Start()).To(Succeed())
+
+			Expect(helper.SignalProcess(cmd.Process.Pid, syscall.SIGTERM)).To(Succeed())
+			Expect(cmd.Wait()).To(MatchError("signal: terminated"))
+		})
+
+		It("returns an error for an invalid pid", func() {
+			Expect(helper.SignalProcess(0, syscall.SIGTERM)).To(MatchError("invalid pid 0"))
+		})
+	})
+
+	Describe("IsPortInUse", func() {
+		It("returns true when something is listening on the port", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			Expect(helper.IsPortInUse(listener.Addr().(*net.TCPAddr).Port)).To(BeTrue())
+		})
+
+		It("returns false when the port is free", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			port := listener.Addr().(*net.TCPAddr).Port
+			Expect(listener.Close()).To(Succeed())
+
+			Expect(helper.IsPortInUse(port)).To(BeFalse())
+		})
+	})
+
+	Describe("Hostname", func() {
+		It("returns the system hostname", func() {
+			expected, err := os.Hostname()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(helper.Hostname()).To(Equal(expected))
+		})
+	})
+
+	Describe("RemoveFile", func() {
+		It("removes the file", func() {
+			file, err := ioutil.TempFile(os.TempDir(), "remove_file_")
+			Expect(err).NotTo(HaveOccurred())
+			file.Close()
+
+			Expect(helper.RemoveFile(file.Name())).To(Succeed())
+			Expect(file.Name()).NotTo(BeAnExistingFile())
+		})
+
+		It("succeeds when the file does not exist", func() {
+			Expect(helper.RemoveFile(filepath.Join(os.TempDir(), "does-not-exist"))).To(Succeed())
+		})
+	})
+
+	Describe("MakeDir", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir(os.TempDir(), "make_dir_")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tempDir)
+		})
+
+		It("creates the directory with the given mode", func() {
+			dir := filepath.Join(tempDir, "log")
+			Expect(helper.MakeDir(dir, 0750)).To(Succeed())
+
+			info, err := os.Stat(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.IsDir()).To(BeTrue())
+			Expect(info.Mode().Perm() & 0007).To(BeZero())
+		})
+
+		It("succeeds when the directory already exists", func() {
+			Expect(helper.MakeDir(tempDir, 0750)).To(Succeed())
+		})
+
+		It("fails without creating anything when the parent does not exist", func() {
+			dir := filepath.Join(tempDir, "var", "log")
+			Expect(helper.MakeDir(dir, 0750)).NotTo(Succeed())
+
+			_, err := os.Stat(filepath.Join(tempDir, "var"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Describe("CopyFile", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir(os.TempDir(), "copy_file_")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tempDir)
+		})
+
+		It("copies the file, creating the destination directory", func() {
+			src := filepath.Join(tempDir, "grastate.dat")
+			Expect(ioutil.WriteFile(src, []byte("seqno: 42"), 0644)).To(Succeed())
+
+			dst := filepath.Join(tempDir, "backups", "1", "grastate.dat")
+			Expect(helper.CopyFile(src, dst)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(dst)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("seqno: 42"))
+		})
+
+		It("returns an error when the source does not exist", func() {
+			Expect(helper.CopyFile(filepath.Join(tempDir, "missing"), filepath.Join(tempDir, "copy"))).NotTo(Succeed())
+		})
+	})
+
+	Describe("WriteStringToFileAtomically", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir(os.TempDir(), "atomic_write_")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tempDir)
+		})
+
+		It("writes the contents and leaves no temporary files behind", func() {
+			filename := filepath.Join(tempDir, "ready")
+			Expect(ioutil.WriteFile(filename, []byte("old"), 0644)).To(Succeed())
+
+			Expect(helper.WriteStringToFileAtomically(filename, "ready")).To(Succeed())
+
+			contents, err := ioutil.ReadFile(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("ready"))
+
+			entries, err := ioutil.ReadDir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Mode().Perm()).To(Equal(os.FileMode(0644)))
+		})
+
+		It("returns an error when the directory does not exist", func() {
+			Expect(helper.WriteStringToFileAtomically(filepath.Join(tempDir, "missing", "ready"), "ready")).NotTo(Succeed())
+		})
+	})
+
+	Describe("WriteSecretFile", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir(os.TempDir(), "secret_write_")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tempDir)
+		})
+
+		It("replaces the file with one only its owner can read", func() {
+			filename := filepath.Join(tempDir, "secret.cnf")
+			Expect(ioutil.WriteFile(filename, []byte("old"), 0644)).To(Succeed())
+
+			Expect(helper.WriteSecretFile(filename, "secret")).To(Succeed())
+
+			contents, err := ioutil.ReadFile(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("secret"))
+
+			entries, err := ioutil.ReadDir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+	})
+
+	Describe("WriteStringToFileDurably", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir(os.TempDir(), "durable_write_")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tempDir)
+		})
+
+		It("writes the contents", func() {
+			filename := filepath.Join(tempDir, "state.txt")
+			Expect(ioutil.WriteFile(filename, []byte("NEEDS_BOOTSTRAP"), 0644)).To(Succeed())
+
+			Expect(helper.WriteStringToFileDurably(filename, "CLUSTERED")).To(Succeed())
+
+			contents, err := ioutil.ReadFile(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("CLUSTERED"))
+		})
+
+		It("returns an error when the directory does not exist", func() {
+			Expect(helper.WriteStringToFileDurably(filepath.Join(tempDir, "missing", "state.txt"), "CLUSTERED")).NotTo(Succeed())
+		})
+	})
+
+	Describe("ProbeWritable", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir(os.TempDir(), "probe_writable_")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tempDir)
+		})
+
+		It("succeeds and leaves nothing behind in a writable directory", func() {
+			Expect(helper.ProbeWritable(tempDir)).To(Succeed())
+
+			entries, err := ioutil.ReadDir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("returns an error when the directory does not exist", func() {
+			Expect(helper.ProbeWritable(filepath.Join(tempDir, "missing"))).NotTo(Succeed())
+		})
+	})
 })
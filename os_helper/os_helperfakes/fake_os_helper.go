@@ -11,6 +11,31 @@ import (
 )
 
 type FakeOsHelper struct {
+	AcquireLockStub        func(string) (func() error, error)
+	acquireLockMutex       sync.RWMutex
+	acquireLockArgsForCall []struct {
+		arg1 string
+	}
+	acquireLockReturns struct {
+		result1 func() error
+		result2 error
+	}
+	acquireLockReturnsOnCall map[int]struct {
+		result1 func() error
+		result2 error
+	}
+	CopyFileStub        func(string, string) error
+	copyFileMutex       sync.RWMutex
+	copyFileArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	copyFileReturns struct {
+		result1 error
+	}
+	copyFileReturnsOnCall map[int]struct {
+		result1 error
+	}
 	FileExistsStub        func(string) bool
 	fileExistsMutex       sync.RWMutex
 	fileExistsArgsForCall []struct {
@@ -22,6 +47,29 @@ type FakeOsHelper struct {
 	fileExistsReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	HostnameStub        func() (string, error)
+	hostnameMutex       sync.RWMutex
+	hostnameArgsForCall []struct {
+	}
+	hostnameReturns struct {
+		result1 string
+		result2 error
+	}
+	hostnameReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	IsPortInUseStub        func(int) bool
+	isPortInUseMutex       sync.RWMutex
+	isPortInUseArgsForCall []struct {
+		arg1 int
+	}
+	isPortInUseReturns struct {
+		result1 bool
+	}
+	isPortInUseReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	KillCommandStub        func(*exec.Cmd, os.Signal) error
 	killCommandMutex       sync.RWMutex
 	killCommandArgsForCall []struct {
@@ -34,6 +82,40 @@ type FakeOsHelper struct {
 	killCommandReturnsOnCall map[int]struct {
 		result1 error
 	}
+	MakeDirStub        func(string, os.FileMode) error
+	makeDirMutex       sync.RWMutex
+	makeDirArgsForCall []struct {
+		arg1 string
+		arg2 os.FileMode
+	}
+	makeDirReturns struct {
+		result1 error
+	}
+	makeDirReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ProbeWritableStub        func(string) error
+	probeWritableMutex       sync.RWMutex
+	probeWritableArgsForCall []struct {
+		arg1 string
+	}
+	probeWritableReturns struct {
+		result1 error
+	}
+	probeWritableReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ProcessExistsStub        func(int) bool
+	processExistsMutex       sync.RWMutex
+	processExistsArgsForCall []struct {
+		arg1 int
+	}
+	processExistsReturns struct {
+		result1 bool
+	}
+	processExistsReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	ReadFileStub        func(string) (string, error)
 	readFileMutex       sync.RWMutex
 	readFileArgsForCall []struct {
@@ -47,6 +129,17 @@ type FakeOsHelper struct {
 		result1 string
 		result2 error
 	}
+	RemoveFileStub        func(string) error
+	removeFileMutex       sync.RWMutex
+	removeFileArgsForCall []struct {
+		arg1 string
+	}
+	removeFileReturns struct {
+		result1 error
+	}
+	removeFileReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RunCommandStub        func(string, ...string) (string, error)
 	runCommandMutex       sync.RWMutex
 	runCommandArgsForCall []struct {
@@ -61,6 +154,18 @@ type FakeOsHelper struct {
 		result1 string
 		result2 error
 	}
+	SignalProcessStub        func(int, os.Signal) error
+	signalProcessMutex       sync.RWMutex
+	signalProcessArgsForCall []struct {
+		arg1 int
+		arg2 os.Signal
+	}
+	signalProcessReturns struct {
+		result1 error
+	}
+	signalProcessReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SleepStub        func(time.Duration)
 	sleepMutex       sync.RWMutex
 	sleepArgsForCall []struct {
@@ -81,6 +186,18 @@ type FakeOsHelper struct {
 		result1 *exec.Cmd
 		result2 error
 	}
+	TotalMemoryStub        func() (uint64, error)
+	totalMemoryMutex       sync.RWMutex
+	totalMemoryArgsForCall []struct {
+	}
+	totalMemoryReturns struct {
+		result1 uint64
+		result2 error
+	}
+	totalMemoryReturnsOnCall map[int]struct {
+		result1 uint64
+		result2 error
+	}
 	WaitForCommandStub        func(*exec.Cmd) chan error
 	waitForCommandMutex       sync.RWMutex
 	waitForCommandArgsForCall []struct {
@@ -92,6 +209,18 @@ type FakeOsHelper struct {
 	waitForCommandReturnsOnCall map[int]struct {
 		result1 chan error
 	}
+	WriteSecretFileStub        func(string, string) error
+	writeSecretFileMutex       sync.RWMutex
+	writeSecretFileArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	writeSecretFileReturns struct {
+		result1 error
+	}
+	writeSecretFileReturnsOnCall map[int]struct {
+		result1 error
+	}
 	WriteStringToFileStub        func(string, string) error
 	writeStringToFileMutex       sync.RWMutex
 	writeStringToFileArgsForCall []struct {
@@ -104,10 +233,158 @@ type FakeOsHelper struct {
 	writeStringToFileReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WriteStringToFileAtomicallyStub        func(string, string) error
+	writeStringToFileAtomicallyMutex       sync.RWMutex
+	writeStringToFileAtomicallyArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	writeStringToFileAtomicallyReturns struct {
+		result1 error
+	}
+	writeStringToFileAtomicallyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WriteStringToFileDurablyStub        func(string, string) error
+	writeStringToFileDurablyMutex       sync.RWMutex
+	writeStringToFileDurablyArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	writeStringToFileDurablyReturns struct {
+		result1 error
+	}
+	writeStringToFileDurablyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeOsHelper) AcquireLock(arg1 string) (func() error, error) {
+	fake.acquireLockMutex.Lock()
+	ret, specificReturn := fake.acquireLockReturnsOnCall[len(fake.acquireLockArgsForCall)]
+	fake.acquireLockArgsForCall = append(fake.acquireLockArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("AcquireLock", []interface{}{arg1})
+	fake.acquireLockMutex.Unlock()
+	if fake.AcquireLockStub != nil {
+		return fake.AcquireLockStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.acquireLockReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeOsHelper) AcquireLockCallCount() int {
+	fake.acquireLockMutex.RLock()
+	defer fake.acquireLockMutex.RUnlock()
+	return len(fake.acquireLockArgsForCall)
+}
+
+func (fake *FakeOsHelper) AcquireLockCalls(stub func(string) (func() error, error)) {
+	fake.acquireLockMutex.Lock()
+	defer fake.acquireLockMutex.Unlock()
+	fake.AcquireLockStub = stub
+}
+
+func (fake *FakeOsHelper) AcquireLockArgsForCall(i int) string {
+	fake.acquireLockMutex.RLock()
+	defer fake.acquireLockMutex.RUnlock()
+	argsForCall := fake.acquireLockArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeOsHelper) AcquireLockReturns(result1 func() error, result2 error) {
+	fake.acquireLockMutex.Lock()
+	defer fake.acquireLockMutex.Unlock()
+	fake.AcquireLockStub = nil
+	fake.acquireLockReturns = struct {
+		result1 func() error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOsHelper) AcquireLockReturnsOnCall(i int, result1 func() error, result2 error) {
+	fake.acquireLockMutex.Lock()
+	defer fake.acquireLockMutex.Unlock()
+	fake.AcquireLockStub = nil
+	if fake.acquireLockReturnsOnCall == nil {
+		fake.acquireLockReturnsOnCall = make(map[int]struct {
+			result1 func() error
+			result2 error
+		})
+	}
+	fake.acquireLockReturnsOnCall[i] = struct {
+		result1 func() error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOsHelper) CopyFile(arg1 string, arg2 string) error {
+	fake.copyFileMutex.Lock()
+	ret, specificReturn := fake.copyFileReturnsOnCall[len(fake.copyFileArgsForCall)]
+	fake.copyFileArgsForCall = append(fake.copyFileArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("CopyFile", []interface{}{arg1, arg2})
+	fake.copyFileMutex.Unlock()
+	if fake.CopyFileStub != nil {
+		return fake.CopyFileStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.copyFileReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) CopyFileCallCount() int {
+	fake.copyFileMutex.RLock()
+	defer fake.copyFileMutex.RUnlock()
+	return len(fake.copyFileArgsForCall)
+}
+
+func (fake *FakeOsHelper) CopyFileCalls(stub func(string, string) error) {
+	fake.copyFileMutex.Lock()
+	defer fake.copyFileMutex.Unlock()
+	fake.CopyFileStub = stub
+}
+
+func (fake *FakeOsHelper) CopyFileArgsForCall(i int) (string, string) {
+	fake.copyFileMutex.RLock()
+	defer fake.copyFileMutex.RUnlock()
+	argsForCall := fake.copyFileArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOsHelper) CopyFileReturns(result1 error) {
+	fake.copyFileMutex.Lock()
+	defer fake.copyFileMutex.Unlock()
+	fake.CopyFileStub = nil
+	fake.copyFileReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) CopyFileReturnsOnCall(i int, result1 error) {
+	fake.copyFileMutex.Lock()
+	defer fake.copyFileMutex.Unlock()
+	fake.CopyFileStub = nil
+	if fake.copyFileReturnsOnCall == nil {
+		fake.copyFileReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.copyFileReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeOsHelper) FileExists(arg1 string) bool {
 	fake.fileExistsMutex.Lock()
 	ret, specificReturn := fake.fileExistsReturnsOnCall[len(fake.fileExistsArgsForCall)]
@@ -168,6 +445,121 @@ func (fake *FakeOsHelper) FileExistsReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *FakeOsHelper) Hostname() (string, error) {
+	fake.hostnameMutex.Lock()
+	ret, specificReturn := fake.hostnameReturnsOnCall[len(fake.hostnameArgsForCall)]
+	fake.hostnameArgsForCall = append(fake.hostnameArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Hostname", []interface{}{})
+	fake.hostnameMutex.Unlock()
+	if fake.HostnameStub != nil {
+		return fake.HostnameStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.hostnameReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeOsHelper) HostnameCallCount() int {
+	fake.hostnameMutex.RLock()
+	defer fake.hostnameMutex.RUnlock()
+	return len(fake.hostnameArgsForCall)
+}
+
+func (fake *FakeOsHelper) HostnameCalls(stub func() (string, error)) {
+	fake.hostnameMutex.Lock()
+	defer fake.hostnameMutex.Unlock()
+	fake.HostnameStub = stub
+}
+
+func (fake *FakeOsHelper) HostnameReturns(result1 string, result2 error) {
+	fake.hostnameMutex.Lock()
+	defer fake.hostnameMutex.Unlock()
+	fake.HostnameStub = nil
+	fake.hostnameReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOsHelper) HostnameReturnsOnCall(i int, result1 string, result2 error) {
+	fake.hostnameMutex.Lock()
+	defer fake.hostnameMutex.Unlock()
+	fake.HostnameStub = nil
+	if fake.hostnameReturnsOnCall == nil {
+		fake.hostnameReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.hostnameReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOsHelper) IsPortInUse(arg1 int) bool {
+	fake.isPortInUseMutex.Lock()
+	ret, specificReturn := fake.isPortInUseReturnsOnCall[len(fake.isPortInUseArgsForCall)]
+	fake.isPortInUseArgsForCall = append(fake.isPortInUseArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	fake.recordInvocation("IsPortInUse", []interface{}{arg1})
+	fake.isPortInUseMutex.Unlock()
+	if fake.IsPortInUseStub != nil {
+		return fake.IsPortInUseStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.isPortInUseReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) IsPortInUseCallCount() int {
+	fake.isPortInUseMutex.RLock()
+	defer fake.isPortInUseMutex.RUnlock()
+	return len(fake.isPortInUseArgsForCall)
+}
+
+func (fake *FakeOsHelper) IsPortInUseCalls(stub func(int) bool) {
+	fake.isPortInUseMutex.Lock()
+	defer fake.isPortInUseMutex.Unlock()
+	fake.IsPortInUseStub = stub
+}
+
+func (fake *FakeOsHelper) IsPortInUseArgsForCall(i int) int {
+	fake.isPortInUseMutex.RLock()
+	defer fake.isPortInUseMutex.RUnlock()
+	argsForCall := fake.isPortInUseArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeOsHelper) IsPortInUseReturns(result1 bool) {
+	fake.isPortInUseMutex.Lock()
+	defer fake.isPortInUseMutex.Unlock()
+	fake.IsPortInUseStub = nil
+	fake.isPortInUseReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeOsHelper) IsPortInUseReturnsOnCall(i int, result1 bool) {
+	fake.isPortInUseMutex.Lock()
+	defer fake.isPortInUseMutex.Unlock()
+	fake.IsPortInUseStub = nil
+	if fake.isPortInUseReturnsOnCall == nil {
+		fake.isPortInUseReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.isPortInUseReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeOsHelper) KillCommand(arg1 *exec.Cmd, arg2 os.Signal) error {
 	fake.killCommandMutex.Lock()
 	ret, specificReturn := fake.killCommandReturnsOnCall[len(fake.killCommandArgsForCall)]
@@ -229,6 +621,187 @@ func (fake *FakeOsHelper) KillCommandReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeOsHelper) MakeDir(arg1 string, arg2 os.FileMode) error {
+	fake.makeDirMutex.Lock()
+	ret, specificReturn := fake.makeDirReturnsOnCall[len(fake.makeDirArgsForCall)]
+	fake.makeDirArgsForCall = append(fake.makeDirArgsForCall, struct {
+		arg1 string
+		arg2 os.FileMode
+	}{arg1, arg2})
+	fake.recordInvocation("MakeDir", []interface{}{arg1, arg2})
+	fake.makeDirMutex.Unlock()
+	if fake.MakeDirStub != nil {
+		return fake.MakeDirStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.makeDirReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) MakeDirCallCount() int {
+	fake.makeDirMutex.RLock()
+	defer fake.makeDirMutex.RUnlock()
+	return len(fake.makeDirArgsForCall)
+}
+
+func (fake *FakeOsHelper) MakeDirCalls(stub func(string, os.FileMode) error) {
+	fake.makeDirMutex.Lock()
+	defer fake.makeDirMutex.Unlock()
+	fake.MakeDirStub = stub
+}
+
+func (fake *FakeOsHelper) MakeDirArgsForCall(i int) (string, os.FileMode) {
+	fake.makeDirMutex.RLock()
+	defer fake.makeDirMutex.RUnlock()
+	argsForCall := fake.makeDirArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOsHelper) MakeDirReturns(result1 error) {
+	fake.makeDirMutex.Lock()
+	defer fake.makeDirMutex.Unlock()
+	fake.MakeDirStub = nil
+	fake.makeDirReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) MakeDirReturnsOnCall(i int, result1 error) {
+	fake.makeDirMutex.Lock()
+	defer fake.makeDirMutex.Unlock()
+	fake.MakeDirStub = nil
+	if fake.makeDirReturnsOnCall == nil {
+		fake.makeDirReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.makeDirReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) ProbeWritable(arg1 string) error {
+	fake.probeWritableMutex.Lock()
+	ret, specificReturn := fake.probeWritableReturnsOnCall[len(fake.probeWritableArgsForCall)]
+	fake.probeWritableArgsForCall = append(fake.probeWritableArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("ProbeWritable", []interface{}{arg1})
+	fake.probeWritableMutex.Unlock()
+	if fake.ProbeWritableStub != nil {
+		return fake.ProbeWritableStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.probeWritableReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) ProbeWritableCallCount() int {
+	fake.probeWritableMutex.RLock()
+	defer fake.probeWritableMutex.RUnlock()
+	return len(fake.probeWritableArgsForCall)
+}
+
+func (fake *FakeOsHelper) ProbeWritableCalls(stub func(string) error) {
+	fake.probeWritableMutex.Lock()
+	defer fake.probeWritableMutex.Unlock()
+	fake.ProbeWritableStub = stub
+}
+
+func (fake *FakeOsHelper) ProbeWritableArgsForCall(i int) string {
+	fake.probeWritableMutex.RLock()
+	defer fake.probeWritableMutex.RUnlock()
+	argsForCall := fake.probeWritableArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeOsHelper) ProbeWritableReturns(result1 error) {
+	fake.probeWritableMutex.Lock()
+	defer fake.probeWritableMutex.Unlock()
+	fake.ProbeWritableStub = nil
+	fake.probeWritableReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) ProbeWritableReturnsOnCall(i int, result1 error) {
+	fake.probeWritableMutex.Lock()
+	defer fake.probeWritableMutex.Unlock()
+	fake.ProbeWritableStub = nil
+	if fake.probeWritableReturnsOnCall == nil {
+		fake.probeWritableReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.probeWritableReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) ProcessExists(arg1 int) bool {
+	fake.processExistsMutex.Lock()
+	ret, specificReturn := fake.processExistsReturnsOnCall[len(fake.processExistsArgsForCall)]
+	fake.processExistsArgsForCall = append(fake.processExistsArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	fake.recordInvocation("ProcessExists", []interface{}{arg1})
+	fake.processExistsMutex.Unlock()
+	if fake.ProcessExistsStub != nil {
+		return fake.ProcessExistsStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.processExistsReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) ProcessExistsCallCount() int {
+	fake.processExistsMutex.RLock()
+	defer fake.processExistsMutex.RUnlock()
+	return len(fake.processExistsArgsForCall)
+}
+
+func (fake *FakeOsHelper) ProcessExistsCalls(stub func(int) bool) {
+	fake.processExistsMutex.Lock()
+	defer fake.processExistsMutex.Unlock()
+	fake.ProcessExistsStub = stub
+}
+
+func (fake *FakeOsHelper) ProcessExistsArgsForCall(i int) int {
+	fake.processExistsMutex.RLock()
+	defer fake.processExistsMutex.RUnlock()
+	argsForCall := fake.processExistsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeOsHelper) ProcessExistsReturns(result1 bool) {
+	fake.processExistsMutex.Lock()
+	defer fake.processExistsMutex.Unlock()
+	fake.ProcessExistsStub = nil
+	fake.processExistsReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeOsHelper) ProcessExistsReturnsOnCall(i int, result1 bool) {
+	fake.processExistsMutex.Lock()
+	defer fake.processExistsMutex.Unlock()
+	fake.ProcessExistsStub = nil
+	if fake.processExistsReturnsOnCall == nil {
+		fake.processExistsReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.processExistsReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeOsHelper) ReadFile(arg1 string) (string, error) {
 	fake.readFileMutex.Lock()
 	ret, specificReturn := fake.readFileReturnsOnCall[len(fake.readFileArgsForCall)]
@@ -292,6 +865,66 @@ func (fake *FakeOsHelper) ReadFileReturnsOnCall(i int, result1 string, result2 e
 	}{result1, result2}
 }
 
+func (fake *FakeOsHelper) RemoveFile(arg1 string) error {
+	fake.removeFileMutex.Lock()
+	ret, specificReturn := fake.removeFileReturnsOnCall[len(fake.removeFileArgsForCall)]
+	fake.removeFileArgsForCall = append(fake.removeFileArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("RemoveFile", []interface{}{arg1})
+	fake.removeFileMutex.Unlock()
+	if fake.RemoveFileStub != nil {
+		return fake.RemoveFileStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.removeFileReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) RemoveFileCallCount() int {
+	fake.removeFileMutex.RLock()
+	defer fake.removeFileMutex.RUnlock()
+	return len(fake.removeFileArgsForCall)
+}
+
+func (fake *FakeOsHelper) RemoveFileCalls(stub func(string) error) {
+	fake.removeFileMutex.Lock()
+	defer fake.removeFileMutex.Unlock()
+	fake.RemoveFileStub = stub
+}
+
+func (fake *FakeOsHelper) RemoveFileArgsForCall(i int) string {
+	fake.removeFileMutex.RLock()
+	defer fake.removeFileMutex.RUnlock()
+	argsForCall := fake.removeFileArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeOsHelper) RemoveFileReturns(result1 error) {
+	fake.removeFileMutex.Lock()
+	defer fake.removeFileMutex.Unlock()
+	fake.RemoveFileStub = nil
+	fake.removeFileReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) RemoveFileReturnsOnCall(i int, result1 error) {
+	fake.removeFileMutex.Lock()
+	defer fake.removeFileMutex.Unlock()
+	fake.RemoveFileStub = nil
+	if fake.removeFileReturnsOnCall == nil {
+		fake.removeFileReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeFileReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeOsHelper) RunCommand(arg1 string, arg2 ...string) (string, error) {
 	fake.runCommandMutex.Lock()
 	ret, specificReturn := fake.runCommandReturnsOnCall[len(fake.runCommandArgsForCall)]
@@ -356,6 +989,67 @@ func (fake *FakeOsHelper) RunCommandReturnsOnCall(i int, result1 string, result2
 	}{result1, result2}
 }
 
+func (fake *FakeOsHelper) SignalProcess(arg1 int, arg2 os.Signal) error {
+	fake.signalProcessMutex.Lock()
+	ret, specificReturn := fake.signalProcessReturnsOnCall[len(fake.signalProcessArgsForCall)]
+	fake.signalProcessArgsForCall = append(fake.signalProcessArgsForCall, struct {
+		arg1 int
+		arg2 os.Signal
+	}{arg1, arg2})
+	fake.recordInvocation("SignalProcess", []interface{}{arg1, arg2})
+	fake.signalProcessMutex.Unlock()
+	if fake.SignalProcessStub != nil {
+		return fake.SignalProcessStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.signalProcessReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) SignalProcessCallCount() int {
+	fake.signalProcessMutex.RLock()
+	defer fake.signalProcessMutex.RUnlock()
+	return len(fake.signalProcessArgsForCall)
+}
+
+func (fake *FakeOsHelper) SignalProcessCalls(stub func(int, os.Signal) error) {
+	fake.signalProcessMutex.Lock()
+	defer fake.signalProcessMutex.Unlock()
+	fake.SignalProcessStub = stub
+}
+
+func (fake *FakeOsHelper) SignalProcessArgsForCall(i int) (int, os.Signal) {
+	fake.signalProcessMutex.RLock()
+	defer fake.signalProcessMutex.RUnlock()
+	argsForCall := fake.signalProcessArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOsHelper) SignalProcessReturns(result1 error) {
+	fake.signalProcessMutex.Lock()
+	defer fake.signalProcessMutex.Unlock()
+	fake.SignalProcessStub = nil
+	fake.signalProcessReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) SignalProcessReturnsOnCall(i int, result1 error) {
+	fake.signalProcessMutex.Lock()
+	defer fake.signalProcessMutex.Unlock()
+	fake.SignalProcessStub = nil
+	if fake.signalProcessReturnsOnCall == nil {
+		fake.signalProcessReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.signalProcessReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeOsHelper) Sleep(arg1 time.Duration) {
 	fake.sleepMutex.Lock()
 	fake.sleepArgsForCall = append(fake.sleepArgsForCall, struct {
@@ -452,6 +1146,61 @@ func (fake *FakeOsHelper) StartCommandReturnsOnCall(i int, result1 *exec.Cmd, re
 	}{result1, result2}
 }
 
+func (fake *FakeOsHelper) TotalMemory() (uint64, error) {
+	fake.totalMemoryMutex.Lock()
+	ret, specificReturn := fake.totalMemoryReturnsOnCall[len(fake.totalMemoryArgsForCall)]
+	fake.totalMemoryArgsForCall = append(fake.totalMemoryArgsForCall, struct {
+	}{})
+	fake.recordInvocation("TotalMemory", []interface{}{})
+	fake.totalMemoryMutex.Unlock()
+	if fake.TotalMemoryStub != nil {
+		return fake.TotalMemoryStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.totalMemoryReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeOsHelper) TotalMemoryCallCount() int {
+	fake.totalMemoryMutex.RLock()
+	defer fake.totalMemoryMutex.RUnlock()
+	return len(fake.totalMemoryArgsForCall)
+}
+
+func (fake *FakeOsHelper) TotalMemoryCalls(stub func() (uint64, error)) {
+	fake.totalMemoryMutex.Lock()
+	defer fake.totalMemoryMutex.Unlock()
+	fake.TotalMemoryStub = stub
+}
+
+func (fake *FakeOsHelper) TotalMemoryReturns(result1 uint64, result2 error) {
+	fake.totalMemoryMutex.Lock()
+	defer fake.totalMemoryMutex.Unlock()
+	fake.TotalMemoryStub = nil
+	fake.totalMemoryReturns = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOsHelper) TotalMemoryReturnsOnCall(i int, result1 uint64, result2 error) {
+	fake.totalMemoryMutex.Lock()
+	defer fake.totalMemoryMutex.Unlock()
+	fake.TotalMemoryStub = nil
+	if fake.totalMemoryReturnsOnCall == nil {
+		fake.totalMemoryReturnsOnCall = make(map[int]struct {
+			result1 uint64
+			result2 error
+		})
+	}
+	fake.totalMemoryReturnsOnCall[i] = struct {
+		result1 uint64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeOsHelper) WaitForCommand(arg1 *exec.Cmd) chan error {
 	fake.waitForCommandMutex.Lock()
 	ret, specificReturn := fake.waitForCommandReturnsOnCall[len(fake.waitForCommandArgsForCall)]
@@ -512,6 +1261,67 @@ func (fake *FakeOsHelper) WaitForCommandReturnsOnCall(i int, result1 chan error)
 	}{result1}
 }
 
+func (fake *FakeOsHelper) WriteSecretFile(arg1 string, arg2 string) error {
+	fake.writeSecretFileMutex.Lock()
+	ret, specificReturn := fake.writeSecretFileReturnsOnCall[len(fake.writeSecretFileArgsForCall)]
+	fake.writeSecretFileArgsForCall = append(fake.writeSecretFileArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("WriteSecretFile", []interface{}{arg1, arg2})
+	fake.writeSecretFileMutex.Unlock()
+	if fake.WriteSecretFileStub != nil {
+		return fake.WriteSecretFileStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.writeSecretFileReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) WriteSecretFileCallCount() int {
+	fake.writeSecretFileMutex.RLock()
+	defer fake.writeSecretFileMutex.RUnlock()
+	return len(fake.writeSecretFileArgsForCall)
+}
+
+func (fake *FakeOsHelper) WriteSecretFileCalls(stub func(string, string) error) {
+	fake.writeSecretFileMutex.Lock()
+	defer fake.writeSecretFileMutex.Unlock()
+	fake.WriteSecretFileStub = stub
+}
+
+func (fake *FakeOsHelper) WriteSecretFileArgsForCall(i int) (string, string) {
+	fake.writeSecretFileMutex.RLock()
+	defer fake.writeSecretFileMutex.RUnlock()
+	argsForCall := fake.writeSecretFileArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOsHelper) WriteSecretFileReturns(result1 error) {
+	fake.writeSecretFileMutex.Lock()
+	defer fake.writeSecretFileMutex.Unlock()
+	fake.WriteSecretFileStub = nil
+	fake.writeSecretFileReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) WriteSecretFileReturnsOnCall(i int, result1 error) {
+	fake.writeSecretFileMutex.Lock()
+	defer fake.writeSecretFileMutex.Unlock()
+	fake.WriteSecretFileStub = nil
+	if fake.writeSecretFileReturnsOnCall == nil {
+		fake.writeSecretFileReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeSecretFileReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeOsHelper) WriteStringToFile(arg1 string, arg2 string) error {
 	fake.writeStringToFileMutex.Lock()
 	ret, specificReturn := fake.writeStringToFileReturnsOnCall[len(fake.writeStringToFileArgsForCall)]
@@ -573,25 +1383,173 @@ func (fake *FakeOsHelper) WriteStringToFileReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeOsHelper) WriteStringToFileAtomically(arg1 string, arg2 string) error {
+	fake.writeStringToFileAtomicallyMutex.Lock()
+	ret, specificReturn := fake.writeStringToFileAtomicallyReturnsOnCall[len(fake.writeStringToFileAtomicallyArgsForCall)]
+	fake.writeStringToFileAtomicallyArgsForCall = append(fake.writeStringToFileAtomicallyArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("WriteStringToFileAtomically", []interface{}{arg1, arg2})
+	fake.writeStringToFileAtomicallyMutex.Unlock()
+	if fake.WriteStringToFileAtomicallyStub != nil {
+		return fake.WriteStringToFileAtomicallyStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.writeStringToFileAtomicallyReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) WriteStringToFileAtomicallyCallCount() int {
+	fake.writeStringToFileAtomicallyMutex.RLock()
+	defer fake.writeStringToFileAtomicallyMutex.RUnlock()
+	return len(fake.writeStringToFileAtomicallyArgsForCall)
+}
+
+func (fake *FakeOsHelper) WriteStringToFileAtomicallyCalls(stub func(string, string) error) {
+	fake.writeStringToFileAtomicallyMutex.Lock()
+	defer fake.writeStringToFileAtomicallyMutex.Unlock()
+	fake.WriteStringToFileAtomicallyStub = stub
+}
+
+func (fake *FakeOsHelper) WriteStringToFileAtomicallyArgsForCall(i int) (string, string) {
+	fake.writeStringToFileAtomicallyMutex.RLock()
+	defer fake.writeStringToFileAtomicallyMutex.RUnlock()
+	argsForCall := fake.writeStringToFileAtomicallyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOsHelper) WriteStringToFileAtomicallyReturns(result1 error) {
+	fake.writeStringToFileAtomicallyMutex.Lock()
+	defer fake.writeStringToFileAtomicallyMutex.Unlock()
+	fake.WriteStringToFileAtomicallyStub = nil
+	fake.writeStringToFileAtomicallyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) WriteStringToFileAtomicallyReturnsOnCall(i int, result1 error) {
+	fake.writeStringToFileAtomicallyMutex.Lock()
+	defer fake.writeStringToFileAtomicallyMutex.Unlock()
+	fake.WriteStringToFileAtomicallyStub = nil
+	if fake.writeStringToFileAtomicallyReturnsOnCall == nil {
+		fake.writeStringToFileAtomicallyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeStringToFileAtomicallyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) WriteStringToFileDurably(arg1 string, arg2 string) error {
+	fake.writeStringToFileDurablyMutex.Lock()
+	ret, specificReturn := fake.writeStringToFileDurablyReturnsOnCall[len(fake.writeStringToFileDurablyArgsForCall)]
+	fake.writeStringToFileDurablyArgsForCall = append(fake.writeStringToFileDurablyArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("WriteStringToFileDurably", []interface{}{arg1, arg2})
+	fake.writeStringToFileDurablyMutex.Unlock()
+	if fake.WriteStringToFileDurablyStub != nil {
+		return fake.WriteStringToFileDurablyStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.writeStringToFileDurablyReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeOsHelper) WriteStringToFileDurablyCallCount() int {
+	fake.writeStringToFileDurablyMutex.RLock()
+	defer fake.writeStringToFileDurablyMutex.RUnlock()
+	return len(fake.writeStringToFileDurablyArgsForCall)
+}
+
+func (fake *FakeOsHelper) WriteStringToFileDurablyCalls(stub func(string, string) error) {
+	fake.writeStringToFileDurablyMutex.Lock()
+	defer fake.writeStringToFileDurablyMutex.Unlock()
+	fake.WriteStringToFileDurablyStub = stub
+}
+
+func (fake *FakeOsHelper) WriteStringToFileDurablyArgsForCall(i int) (string, string) {
+	fake.writeStringToFileDurablyMutex.RLock()
+	defer fake.writeStringToFileDurablyMutex.RUnlock()
+	argsForCall := fake.writeStringToFileDurablyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOsHelper) WriteStringToFileDurablyReturns(result1 error) {
+	fake.writeStringToFileDurablyMutex.Lock()
+	defer fake.writeStringToFileDurablyMutex.Unlock()
+	fake.WriteStringToFileDurablyStub = nil
+	fake.writeStringToFileDurablyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOsHelper) WriteStringToFileDurablyReturnsOnCall(i int, result1 error) {
+	fake.writeStringToFileDurablyMutex.Lock()
+	defer fake.writeStringToFileDurablyMutex.Unlock()
+	fake.WriteStringToFileDurablyStub = nil
+	if fake.writeStringToFileDurablyReturnsOnCall == nil {
+		fake.writeStringToFileDurablyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeStringToFileDurablyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeOsHelper) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.acquireLockMutex.RLock()
+	defer fake.acquireLockMutex.RUnlock()
+	fake.copyFileMutex.RLock()
+	defer fake.copyFileMutex.RUnlock()
 	fake.fileExistsMutex.RLock()
 	defer fake.fileExistsMutex.RUnlock()
+	fake.hostnameMutex.RLock()
+	defer fake.hostnameMutex.RUnlock()
+	fake.isPortInUseMutex.RLock()
+	defer fake.isPortInUseMutex.RUnlock()
 	fake.killCommandMutex.RLock()
 	defer fake.killCommandMutex.RUnlock()
+	fake.makeDirMutex.RLock()
+	defer fake.makeDirMutex.RUnlock()
+	fake.probeWritableMutex.RLock()
+	defer fake.probeWritableMutex.RUnlock()
+	fake.processExistsMutex.RLock()
+	defer fake.processExistsMutex.RUnlock()
 	fake.readFileMutex.RLock()
 	defer fake.readFileMutex.RUnlock()
+	fake.removeFileMutex.RLock()
+	defer fake.removeFileMutex.RUnlock()
 	fake.runCommandMutex.RLock()
 	defer fake.runCommandMutex.RUnlock()
+	fake.signalProcessMutex.RLock()
+	defer fake.signalProcessMutex.RUnlock()
 	fake.sleepMutex.RLock()
 	defer fake.sleepMutex.RUnlock()
 	fake.startCommandMutex.RLock()
 	defer fake.startCommandMutex.RUnlock()
+	fake.totalMemoryMutex.RLock()
+	defer fake.totalMemoryMutex.RUnlock()
 	fake.waitForCommandMutex.RLock()
 	defer fake.waitForCommandMutex.RUnlock()
+	fake.writeSecretFileMutex.RLock()
+	defer fake.writeSecretFileMutex.RUnlock()
 	fake.writeStringToFileMutex.RLock()
 	defer fake.writeStringToFileMutex.RUnlock()
+	fake.writeStringToFileAtomicallyMutex.RLock()
+	defer fake.writeStringToFileAtomicallyMutex.RUnlock()
+	fake.writeStringToFileDurablyMutex.RLock()
+	defer fake.writeStringToFileDurablyMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
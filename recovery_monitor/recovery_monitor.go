@@ -0,0 +1,178 @@
+package recovery_monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/cloudfoundry/galera-init/db_helper"
+)
+
+var errStuckInRecovery = errors.New("node has not returned to a synced primary component")
+
+// How often the monitor checks whether the node has recovered
+var CheckInterval = 30 * time.Second
+
+// Monitor raises an alert when the node has been outside a synced primary
+// component, e.g. non-primary after a partition or stuck joining, for longer
+// than alertAfter. From then on every check logs an error, the node reports
+// itself unhealthy, and when exitWhenStuck is set onStuck is called once so
+// that galera-init can shut down and let its supervisor restart it.
+//
+// Checks only count once Started has been called, so that a node still
+// receiving its first SST is not taken for a stuck one, and are skipped while
+// the monitor is suspended for a planned SST.
+type Monitor struct {
+	dbHelper      db_helper.DBHelper
+	alertAfter    time.Duration
+	maxSuspension time.Duration
+	exitWhenStuck bool
+	onStuck       func()
+	logger        lager.Logger
+
+	mutex           sync.Mutex
+	started         bool
+	suspendedUntil  time.Time
+	recoveringSince time.Time
+	stuckReason     string
+	exitRequested   bool
+}
+
+func NewMonitor(dbHelper db_helper.DBHelper, alertAfter time.Duration, maxSuspension time.Duration, exitWhenStuck bool, onStuck func(), logger lager.Logger) *Monitor {
+	return &Monitor{
+		dbHelper:      dbHelper,
+		alertAfter:    alertAfter,
+		maxSuspension: maxSuspension,
+		exitWhenStuck: exitWhenStuck,
+		onStuck:       onStuck,
+		logger:        logger,
+	}
+}
+
+// Started arms the monitor once galera-init has finished starting the node.
+func (m *Monitor) Started() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.started = true
+}
+
+// Suspend skips the checks for duration from now, e.g. while the node serves
+// or receives a planned long SST, after which they resume on their own. The
+// duration may not exceed the maximum the monitor was built with; zero
+// resumes the checks straight away.
+func (m *Monitor) Suspend(now time.Time, duration time.Duration) (time.Time, error) {
+	if duration < 0 || duration > m.maxSuspension {
+		return time.Time{}, fmt.Errorf("suspension must be between 0 and %s, got %s", m.maxSuspension, duration)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.suspendedUntil = now.Add(duration)
+	m.recoveringSince = time.Time{}
+	m.stuckReason = ""
+	m.logger.Info("recovery-monitor-suspended", lager.Data{"until": m.suspendedUntil})
+	return m.suspendedUntil, nil
+}
+
+// Run checks the node's state every CheckInterval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.Check(now)
+		}
+	}
+}
+
+func (m *Monitor) Check(now time.Time) {
+	if !m.armed(now) {
+		return
+	}
+
+	status, err := m.dbHelper.GetWsrepStatus()
+	if err != nil {
+		m.logger.Debug("recovery-check-skipped", lager.Data{"err": err.Error()})
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if isRecovered(status) {
+		if !m.recoveringSince.IsZero() {
+			m.logger.Info("node-recovered", lager.Data{"recoveringFor": now.Sub(m.recoveringSince).String()})
+		}
+		m.recoveringSince = time.Time{}
+		m.stuckReason = ""
+		return
+	}
+
+	if m.recoveringSince.IsZero() {
+		m.recoveringSince = now
+	}
+
+	recoveringFor := now.Sub(m.recoveringSince)
+	if recoveringFor < m.alertAfter {
+		return
+	}
+
+	m.stuckReason = fmt.Sprintf("node has been %s (%s) for %s", status.LocalStateComment, status.ClusterStatus, recoveringFor)
+	m.logger.Error("node-stuck-in-recovery", errStuckInRecovery, lager.Data{
+		"localState":    status.LocalStateComment,
+		"clusterStatus": status.ClusterStatus,
+		"recoveringFor": recoveringFor.String(),
+	})
+
+	if m.exitWhenStuck && !m.exitRequested {
+		m.exitRequested = true
+		m.logger.Info("exiting-node-stuck-in-recovery")
+		m.onStuck()
+	}
+}
+
+func (m *Monitor) armed(now time.Time) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.started {
+		return false
+	}
+
+	if !m.suspendedUntil.IsZero() {
+		if now.Before(m.suspendedUntil) {
+			return false
+		}
+		m.suspendedUntil = time.Time{}
+		m.logger.Info("recovery-monitor-resumed")
+	}
+
+	return true
+}
+
+// Healthy reports false, with the reason, once the node has been recovering
+// for longer than alertAfter.
+func (m *Monitor) Healthy() (bool, string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.stuckReason == "", m.stuckReason
+}
+
+// A donor is still a full member of the primary component
+func isRecovered(status db_helper.WsrepStatus) bool {
+	if status.ClusterStatus != "Primary" {
+		return false
+	}
+	return status.LocalStateComment == "Synced" || status.LocalStateComment == "Donor/Desynced"
+}
@@ -0,0 +1,14 @@
+package recovery_monitor_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRecoveryMonitor(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Recovery Monitor Suite")
+}
@@ -0,0 +1,205 @@
+package recovery_monitor_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/db_helper/db_helperfakes"
+	"github.com/cloudfoundry/galera-init/recovery_monitor"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Monitor", func() {
+	var (
+		monitor      *recovery_monitor.Monitor
+		fakeDBHelper *db_helperfakes.FakeDBHelper
+		testLogger   *lagertest.TestLogger
+		start        time.Time
+		stuckCalls   int
+	)
+
+	nodeStateIs := func(localState, clusterStatus string) {
+		fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{
+			LocalStateComment: localState,
+			ClusterStatus:     clusterStatus,
+			ClusterSize:       3,
+		}, nil)
+	}
+
+	BeforeEach(func() {
+		fakeDBHelper = new(db_helperfakes.FakeDBHelper)
+		testLogger = lagertest.NewTestLogger("recovery_monitor")
+		start = time.Date(2020, time.March, 10, 12, 0, 0, 0, time.UTC)
+		stuckCalls = 0
+
+		monitor = recovery_monitor.NewMonitor(fakeDBHelper, 10*time.Minute, 4*time.Hour, false, func() { stuckCalls++ }, testLogger)
+		monitor.Started()
+	})
+
+	It("stays healthy while the node is synced or donating in the primary component", func() {
+		nodeStateIs("Synced", "Primary")
+		monitor.Check(start)
+		nodeStateIs("Donor/Desynced", "Primary")
+		monitor.Check(start.Add(time.Hour))
+
+		Expect(monitor.Healthy()).To(BeTrue())
+		Expect(testLogger.LogMessages()).To(BeEmpty())
+	})
+
+	It("does not alert on a short recovery", func() {
+		nodeStateIs("Joined", "Primary")
+
+		monitor.Check(start)
+		monitor.Check(start.Add(5 * time.Minute))
+
+		Expect(monitor.Healthy()).To(BeTrue())
+		Expect(testLogger.LogMessages()).To(BeEmpty())
+	})
+
+	It("alerts and reports unhealthy once the node has been non-primary for too long", func() {
+		nodeStateIs("Initialized", "non-Primary")
+
+		monitor.Check(start)
+		monitor.Check(start.Add(10 * time.Minute))
+
+		Expect(testLogger.Buffer()).To(gbytes.Say(`node-stuck-in-recovery.*"log_level":2`))
+		healthy, reason := monitor.Healthy()
+		Expect(healthy).To(BeFalse())
+		Expect(reason).To(Equal("node has been Initialized (non-Primary) for 10m0s"))
+		Expect(stuckCalls).To(Equal(0))
+	})
+
+	It("recovers once the node is synced again", func() {
+		nodeStateIs("Joining: receiving State Transfer", "Primary")
+		monitor.Check(start)
+		monitor.Check(start.Add(15 * time.Minute))
+
+		nodeStateIs("Synced", "Primary")
+		monitor.Check(start.Add(16 * time.Minute))
+
+		Expect(testLogger.Buffer()).To(gbytes.Say("node-recovered"))
+		Expect(monitor.Healthy()).To(BeTrue())
+	})
+
+	It("skips the check when the node state cannot be read", func() {
+		fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{}, errors.New("not reachable"))
+
+		monitor.Check(start)
+		monitor.Check(start.Add(time.Hour))
+
+		Expect(monitor.Healthy()).To(BeTrue())
+	})
+
+	It("does not check the node until it has started", func() {
+		monitor = recovery_monitor.NewMonitor(fakeDBHelper, 10*time.Minute, 4*time.Hour, false, func() { stuckCalls++ }, testLogger)
+		nodeStateIs("Joining: receiving State Transfer", "Primary")
+
+		monitor.Check(start)
+		monitor.Check(start.Add(time.Hour))
+
+		Expect(fakeDBHelper.GetWsrepStatusCallCount()).To(Equal(0))
+		Expect(monitor.Healthy()).To(BeTrue())
+
+		monitor.Started()
+		monitor.Check(start.Add(time.Hour))
+		monitor.Check(start.Add(time.Hour + 10*time.Minute))
+
+		healthy, _ := monitor.Healthy()
+		Expect(healthy).To(BeFalse())
+	})
+
+	Context("when suspended", func() {
+		BeforeEach(func() {
+			nodeStateIs("Joining: receiving State Transfer", "Primary")
+		})
+
+		It("skips the checks until the suspension expires, then starts the alert window afresh", func() {
+			monitor.Check(start)
+			until, err := monitor.Suspend(start.Add(5*time.Minute), time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(until).To(Equal(start.Add(65 * time.Minute)))
+
+			monitor.Check(start.Add(30 * time.Minute))
+			monitor.Check(start.Add(64 * time.Minute))
+			Expect(monitor.Healthy()).To(BeTrue())
+			Expect(fakeDBHelper.GetWsrepStatusCallCount()).To(Equal(1))
+
+			monitor.Check(start.Add(65 * time.Minute))
+			Expect(testLogger.Buffer()).To(gbytes.Say("recovery-monitor-resumed"))
+			monitor.Check(start.Add(74 * time.Minute))
+			Expect(monitor.Healthy()).To(BeTrue())
+
+			monitor.Check(start.Add(75 * time.Minute))
+			healthy, _ := monitor.Healthy()
+			Expect(healthy).To(BeFalse())
+		})
+
+		It("clears an alert that was already raised", func() {
+			monitor.Check(start)
+			monitor.Check(start.Add(10 * time.Minute))
+			healthy, _ := monitor.Healthy()
+			Expect(healthy).To(BeFalse())
+
+			_, err := monitor.Suspend(start.Add(11*time.Minute), time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(monitor.Healthy()).To(BeTrue())
+		})
+
+		It("resumes straight away for a zero duration", func() {
+			_, err := monitor.Suspend(start, time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = monitor.Suspend(start.Add(time.Minute), 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			monitor.Check(start.Add(2 * time.Minute))
+			Expect(fakeDBHelper.GetWsrepStatusCallCount()).To(Equal(1))
+		})
+
+		It("refuses a suspension longer than the maximum", func() {
+			_, err := monitor.Suspend(start, 5*time.Hour)
+			Expect(err).To(MatchError("suspension must be between 0 and 4h0m0s, got 5h0m0s"))
+
+			monitor.Check(start)
+			Expect(fakeDBHelper.GetWsrepStatusCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when configured to exit", func() {
+		BeforeEach(func() {
+			monitor = recovery_monitor.NewMonitor(fakeDBHelper, 10*time.Minute, 4*time.Hour, true, func() { stuckCalls++ }, testLogger)
+			monitor.Started()
+		})
+
+		It("asks galera-init to exit once", func() {
+			nodeStateIs("Initialized", "non-Primary")
+
+			monitor.Check(start)
+			monitor.Check(start.Add(10 * time.Minute))
+			monitor.Check(start.Add(11 * time.Minute))
+
+			Expect(stuckCalls).To(Equal(1))
+			Expect(testLogger.Buffer()).To(gbytes.Say("exiting-node-stuck-in-recovery"))
+		})
+	})
+
+	It("stops running when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			monitor.Run(ctx)
+			close(done)
+		}()
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
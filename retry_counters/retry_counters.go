@@ -0,0 +1,53 @@
+package retry_counters
+
+import "sync/atomic"
+
+type Snapshot struct {
+	JoinAttempts      int64 `json:"join_attempts"`
+	ReachabilityPolls int64 `json:"reachability_polls"`
+	SeedAttempts      int64 `json:"seed_attempts"`
+}
+
+// Counters tallies how much retrying startup needed. A node that comes up
+// only after many join attempts or reachability polls is healthy now but
+// running in an environment worth looking at. A nil *Counters discards
+// everything counted.
+type Counters struct {
+	joinAttempts      int64
+	reachabilityPolls int64
+	seedAttempts      int64
+}
+
+func New() *Counters {
+	return &Counters{}
+}
+
+func (c *Counters) IncJoinAttempts() {
+	if c != nil {
+		atomic.AddInt64(&c.joinAttempts, 1)
+	}
+}
+
+func (c *Counters) IncReachabilityPolls() {
+	if c != nil {
+		atomic.AddInt64(&c.reachabilityPolls, 1)
+	}
+}
+
+func (c *Counters) IncSeedAttempts() {
+	if c != nil {
+		atomic.AddInt64(&c.seedAttempts, 1)
+	}
+}
+
+func (c *Counters) Snapshot() Snapshot {
+	if c == nil {
+		return Snapshot{}
+	}
+
+	return Snapshot{
+		JoinAttempts:      atomic.LoadInt64(&c.joinAttempts),
+		ReachabilityPolls: atomic.LoadInt64(&c.reachabilityPolls),
+		SeedAttempts:      atomic.LoadInt64(&c.seedAttempts),
+	}
+}
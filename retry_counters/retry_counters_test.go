@@ -0,0 +1,35 @@
+package retry_counters_test
+
+import (
+	"github.com/cloudfoundry/galera-init/retry_counters"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Counters", func() {
+	It("counts each kind of retry separately", func() {
+		c := retry_counters.New()
+		c.IncJoinAttempts()
+		c.IncJoinAttempts()
+		c.IncReachabilityPolls()
+		c.IncSeedAttempts()
+		c.IncSeedAttempts()
+		c.IncSeedAttempts()
+
+		Expect(c.Snapshot()).To(Equal(retry_counters.Snapshot{
+			JoinAttempts:      2,
+			ReachabilityPolls: 1,
+			SeedAttempts:      3,
+		}))
+	})
+
+	It("discards everything when nil", func() {
+		var c *retry_counters.Counters
+		c.IncJoinAttempts()
+		c.IncReachabilityPolls()
+		c.IncSeedAttempts()
+
+		Expect(c.Snapshot()).To(Equal(retry_counters.Snapshot{}))
+	})
+})
@@ -0,0 +1,77 @@
+package start_manager
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/os_helper"
+	"github.com/cloudfoundry/galera-init/start_manager/node_starter"
+)
+
+// How often Leave checks whether client connections have drained
+var LeaveDrainPollInterval = time.Second
+
+// Leave takes the node out of the cluster for decommissioning. The state
+// file is marked first so that the galera-init process supervising mysqld
+// exits cleanly once mysqld stops, and a later boot exits without rejoining
+// the cluster; then the node is desynced,
+// client connections are given LeaveDrainTimeout seconds to drain, the
+// replication queues ShutdownQueueDrainTimeout seconds, and mysqld is shut
+// down.
+func Leave(osHelper os_helper.OsHelper, dbHelper db_helper.DBHelper, cfg config.StartManager, logger lager.Logger) error {
+	logger.Info("leave-marking-state-file", lager.Data{"stateFile": cfg.StateFileLocation})
+	var err error
+	if cfg.SyncStateFile {
+		err = osHelper.WriteStringToFileDurably(cfg.StateFileLocation, node_starter.LeftCluster)
+	} else {
+		err = osHelper.WriteStringToFileAtomically(cfg.StateFileLocation, node_starter.LeftCluster)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !dbHelper.Ping() {
+		logger.Info("leave-mysqld-not-running")
+		return nil
+	}
+
+	err = dbHelper.SetDesync(true)
+	if err != nil {
+		logger.Error("leave-desync-failed", err)
+	}
+
+	drainConnections(osHelper, dbHelper, time.Duration(cfg.LeaveDrainTimeout)*time.Second, logger)
+	drainReplicationQueues(osHelper, dbHelper, time.Duration(cfg.ShutdownQueueDrainTimeout)*time.Second, logger)
+
+	logger.Info("leave-stopping-mysqld")
+	dbHelper.StopMysqld()
+	logger.Info("leave-complete")
+
+	return nil
+}
+
+func drainConnections(osHelper os_helper.OsHelper, dbHelper db_helper.DBHelper, timeout time.Duration, logger lager.Logger) {
+	for waited := time.Duration(0); ; waited += LeaveDrainPollInterval {
+		connections, err := dbHelper.CountClientConnections()
+		if err != nil {
+			logger.Error("leave-count-connections-failed", err)
+			return
+		}
+
+		if connections == 0 {
+			logger.Info("leave-connections-drained")
+			return
+		}
+
+		if waited >= timeout {
+			logger.Info("leave-drain-timed-out", lager.Data{"remainingConnections": connections})
+			return
+		}
+
+		logger.Debug("leave-waiting-for-connections", lager.Data{"connections": connections})
+		osHelper.Sleep(LeaveDrainPollInterval)
+	}
+}
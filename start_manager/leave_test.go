@@ -0,0 +1,98 @@
+package start_manager_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/db_helper/db_helperfakes"
+	"github.com/cloudfoundry/galera-init/os_helper/os_helperfakes"
+	. "github.com/cloudfoundry/galera-init/start_manager"
+	"github.com/cloudfoundry/galera-init/start_manager/node_starter"
+)
+
+var _ = Describe("Leave", func() {
+	var (
+		testLogger   *lagertest.TestLogger
+		fakeOs       *os_helperfakes.FakeOsHelper
+		fakeDBHelper *db_helperfakes.FakeDBHelper
+		cfg          config.StartManager
+	)
+
+	BeforeEach(func() {
+		testLogger = lagertest.NewTestLogger("leave")
+		fakeOs = new(os_helperfakes.FakeOsHelper)
+		fakeDBHelper = new(db_helperfakes.FakeDBHelper)
+		fakeDBHelper.PingReturns(true)
+		cfg = config.StartManager{
+			StateFileLocation: "/stateFileLocation",
+			LeaveDrainTimeout: 3,
+		}
+	})
+
+	It("marks the state file, desyncs, drains connections and stops mysqld", func() {
+		fakeDBHelper.CountClientConnectionsReturnsOnCall(0, 2, nil)
+		fakeDBHelper.CountClientConnectionsReturnsOnCall(1, 0, nil)
+
+		Expect(Leave(fakeOs, fakeDBHelper, cfg, testLogger)).To(Succeed())
+
+		filename, contents := fakeOs.WriteStringToFileAtomicallyArgsForCall(0)
+		Expect(filename).To(Equal("/stateFileLocation"))
+		Expect(contents).To(Equal(node_starter.LeftCluster))
+
+		Expect(fakeDBHelper.SetDesyncArgsForCall(0)).To(BeTrue())
+		Expect(fakeDBHelper.CountClientConnectionsCallCount()).To(Equal(2))
+		Expect(fakeOs.SleepCallCount()).To(Equal(1))
+		Expect(fakeDBHelper.StopMysqldCallCount()).To(Equal(1))
+		Expect(testLogger.Buffer()).To(gbytes.Say("leave-connections-drained"))
+	})
+
+	It("stops mysqld anyway once the drain timeout passes", func() {
+		fakeDBHelper.CountClientConnectionsReturns(5, nil)
+
+		Expect(Leave(fakeOs, fakeDBHelper, cfg, testLogger)).To(Succeed())
+
+		Expect(fakeOs.SleepCallCount()).To(Equal(3))
+		Expect(fakeDBHelper.StopMysqldCallCount()).To(Equal(1))
+		Expect(testLogger.Buffer()).To(gbytes.Say("leave-drain-timed-out"))
+	})
+
+	It("waits for the replication queues to drain when ShutdownQueueDrainTimeout is set", func() {
+		cfg.ShutdownQueueDrainTimeout = 3
+		fakeDBHelper.GetReplicationQueuesReturnsOnCall(0, db_helper.ReplicationQueues{Send: 2}, nil)
+		fakeDBHelper.GetReplicationQueuesReturnsOnCall(1, db_helper.ReplicationQueues{}, nil)
+
+		Expect(Leave(fakeOs, fakeDBHelper, cfg, testLogger)).To(Succeed())
+
+		Expect(fakeDBHelper.GetReplicationQueuesCallCount()).To(Equal(2))
+		Expect(fakeDBHelper.StopMysqldCallCount()).To(Equal(1))
+		Expect(testLogger.Buffer()).To(gbytes.Say("replication-queues-drained"))
+	})
+
+	It("does not check the replication queues by default", func() {
+		Expect(Leave(fakeOs, fakeDBHelper, cfg, testLogger)).To(Succeed())
+		Expect(fakeDBHelper.GetReplicationQueuesCallCount()).To(Equal(0))
+	})
+
+	It("only marks the state file when mysqld is not running", func() {
+		fakeDBHelper.PingReturns(false)
+
+		Expect(Leave(fakeOs, fakeDBHelper, cfg, testLogger)).To(Succeed())
+
+		Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(1))
+		Expect(fakeDBHelper.SetDesyncCallCount()).To(Equal(0))
+		Expect(fakeDBHelper.StopMysqldCallCount()).To(Equal(0))
+	})
+
+	It("does not touch mysqld when the state file cannot be written", func() {
+		fakeOs.WriteStringToFileAtomicallyReturns(errors.New("read-only filesystem"))
+
+		Expect(Leave(fakeOs, fakeDBHelper, cfg, testLogger)).To(MatchError("read-only filesystem"))
+		Expect(fakeDBHelper.StopMysqldCallCount()).To(Equal(0))
+	})
+})
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,15 +16,32 @@ import (
 	"github.com/cloudfoundry/galera-init/config"
 	"github.com/cloudfoundry/galera-init/db_helper"
 	"github.com/cloudfoundry/galera-init/os_helper"
+	"github.com/cloudfoundry/galera-init/retry_counters"
+	"github.com/cloudfoundry/galera-init/startup_errors"
 )
 
 const (
 	Clustered                        = "CLUSTERED"
 	NeedsBootstrap                   = "NEEDS_BOOTSTRAP"
 	SingleNode                       = "SINGLE_NODE"
+	LeftCluster                      = "LEFT_CLUSTER"
+	WarmStandby                      = "WARM_STANDBY"
 	StartupPollingFrequencyInSeconds = 5
 )
 
+// Delay before the second join attempt; it doubles for every attempt after that
+var JoinRetryDelay = 5 * time.Second
+
+// Contents of FirstBootMarkerFile while FirstBootSQLFile runs, once it has,
+// and once it has returned an error
+const (
+	firstBootSQLInProgress = "in-progress"
+	firstBootSQLDone       = "done"
+	firstBootSQLFailed     = "failed"
+)
+
+var errNodeEvicted = errors.New("Node was evicted from the cluster due to inconsistency; operator intervention is required")
+
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Starter
 type Starter interface {
 	StartNodeFromState(string) (string, <-chan error, error)
@@ -35,7 +54,9 @@ type starter struct {
 	clusterHealthChecker cluster_health_checker.ClusterHealthChecker
 	config               config.StartManager
 	logger               lager.Logger
+	counters             *retry_counters.Counters
 	mysqlCmd             *exec.Cmd
+	bootstrapped         bool
 }
 
 func NewStarter(
@@ -44,6 +65,7 @@ func NewStarter(
 	config config.StartManager,
 	logger lager.Logger,
 	healthChecker cluster_health_checker.ClusterHealthChecker,
+	counters *retry_counters.Counters,
 ) Starter {
 	return &starter{
 		dbHelper:             dbHelper,
@@ -51,6 +73,7 @@ func NewStarter(
 		config:               config,
 		logger:               logger,
 		clusterHealthChecker: healthChecker,
+		counters:             counters,
 	}
 }
 
@@ -59,19 +82,21 @@ func (s *starter) StartNodeFromState(state string) (string, <-chan error, error)
 	var err error
 	var mysqldChan chan error
 
+	s.bootstrapped = false
+
 	switch state {
 	case SingleNode:
-		mysqldChan, err = s.bootstrapNode()
+		mysqldChan, err = s.bootstrapAndWaitForDatabase()
 		newNodeState = SingleNode
 	case NeedsBootstrap:
-		if s.clusterHealthChecker.HealthyCluster() {
-			mysqldChan, err = s.joinCluster()
+		if s.waitForHealthyCluster() {
+			mysqldChan, err = s.joinClusterWithRetries(true)
 		} else {
-			mysqldChan, err = s.bootstrapNode()
+			mysqldChan, err = s.bootstrapAndWaitForDatabase()
 		}
 		newNodeState = Clustered
 	case Clustered:
-		mysqldChan, err = s.joinCluster()
+		mysqldChan, err = s.joinClusterWithRetries(false)
 		newNodeState = Clustered
 	default:
 		err = fmt.Errorf("Unsupported state file contents: %s", state)
@@ -79,23 +104,38 @@ func (s *starter) StartNodeFromState(state string) (string, <-chan error, error)
 	if err != nil {
 		return "", nil, err
 	}
-	if mysqldChan == nil {
-		return "", nil, errors.New("Starting mysql failed, no channel created - exiting")
+
+	if !s.bootstrapped {
+		err = s.verifyClusterUUID()
+		if err != nil {
+			return "", nil, s.stopUnverifiedMember(err, mysqldChan)
+		}
+
+		err = s.verifyMembership()
+		if err != nil {
+			return "", nil, s.stopUnverifiedMember(err, mysqldChan)
+		}
 	}
 
-	err = s.waitForDatabaseToAcceptConnections(mysqldChan)
-	if err != nil {
-		return "", nil, err
+	if s.config.SeedOnlyOnBootstrap && !s.bootstrapped {
+		s.logger.Info("Skipping database seeding on joining node, SeedOnlyOnBootstrap is set")
+	} else {
+		err = s.seedDatabases()
+		if err != nil {
+			return "", nil, s.handleSeedFailure(err, mysqldChan)
+		}
 	}
 
-	err = s.seedDatabases()
-	if err != nil {
-		return "", nil, err
+	if !s.bootstrapped {
+		err = s.waitForPreseededDatabases()
+		if err != nil {
+			return "", nil, err
+		}
 	}
 
 	err = s.seedUsers()
 	if err != nil {
-		return "", nil, err
+		return "", nil, s.handleSeedFailure(err, mysqldChan)
 	}
 
 	err = s.runPostStartSQL()
@@ -103,6 +143,27 @@ func (s *starter) StartNodeFromState(state string) (string, <-chan error, error)
 		return "", nil, err
 	}
 
+	if s.bootstrapped {
+		err = s.runFirstBootSQL()
+		if err != nil {
+			return "", nil, s.handleSeedFailure(err, mysqldChan)
+		}
+	}
+
+	err = s.checkAllowedDatabases()
+	if err != nil {
+		return "", nil, s.handleSeedFailure(err, mysqldChan)
+	}
+
+	s.checkMaxConnections()
+	s.checkBufferPoolSize()
+	s.applyDonorRejectsQueries()
+	s.applyMaxAllowedPacket()
+
+	s.dbHelper.CheckSSTAuth()
+
+	s.logger.Info("startup-retry-counts", lager.Data{"counts": s.counters.Snapshot()})
+
 	return newNodeState, mysqldChan, nil
 }
 
@@ -110,34 +171,224 @@ func (s *starter) GetMysqlCmd() *exec.Cmd {
 	return s.mysqlCmd
 }
 
+// Keeps probing peers for up to ReachabilityProbeWindow seconds so that peers
+// which are still starting up are not mistaken for a dead cluster.
+func (s *starter) waitForHealthyCluster() bool {
+	for elapsed := 0; ; elapsed += StartupPollingFrequencyInSeconds {
+		if s.clusterHealthChecker.HealthyCluster() {
+			return true
+		}
+
+		if elapsed >= s.config.ReachabilityProbeWindow {
+			s.logger.Info("No healthy cluster found within probe window", lager.Data{
+				"ReachabilityProbeWindow": s.config.ReachabilityProbeWindow,
+			})
+			return false
+		}
+
+		s.logger.Debug("No healthy cluster found yet, retrying...")
+		s.osHelper.Sleep(StartupPollingFrequencyInSeconds * time.Second)
+	}
+}
+
 func (s *starter) bootstrapNode() (chan error, error) {
+	if s.config.NeverBootstrap {
+		s.logger.Info("Refusing to bootstrap because NeverBootstrap is set")
+		return nil, &startup_errors.BootstrapError{
+			Err: &startup_errors.FatalError{
+				Err: errors.New("Refusing to bootstrap a new cluster: NeverBootstrap is set and no healthy cluster members are reachable"),
+			},
+		}
+	}
+
+	s.backupRecoveryMetadata("bootstrap")
+
 	s.logger.Info("Updating safe_to_bootstrap flag")
 	read, err := ioutil.ReadFile(s.config.GrastateFileLocation)
 	if err == nil {
 		subbed := strings.Replace(string(read), "safe_to_bootstrap: 0", "safe_to_bootstrap: 1", -1)
 		err = ioutil.WriteFile(s.config.GrastateFileLocation, []byte(subbed), 0777)
 		if err != nil {
-			return nil, err
+			return nil, &startup_errors.BootstrapError{Err: err}
 		}
 	}
 
 	s.logger.Info("Bootstrapping node")
 	cmd, err := s.dbHelper.StartMysqldInBootstrap()
 	if err != nil {
-		return nil, err
+		return nil, &startup_errors.BootstrapError{Err: err}
 	}
 	s.mysqlCmd = cmd
+	s.bootstrapped = true
 	s.logger.Info("Issusing a non-blocking Wait for mysqld in bootstrapping mode")
 	errorChan := s.osHelper.WaitForCommand(cmd)
 	return errorChan, nil
 }
 
+func (s *starter) startAndWaitForDatabase(start func() (chan error, error)) (chan error, error) {
+	mysqldChan, err := start()
+	if err != nil {
+		return nil, err
+	}
+	if mysqldChan == nil {
+		return nil, errors.New("Starting mysql failed, no channel created - exiting")
+	}
+
+	err = s.waitForDatabaseToAcceptConnections(mysqldChan)
+	if err == errNodeEvicted {
+		return s.recoverFromEviction(mysqldChan, start)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return mysqldChan, nil
+}
+
+// Any failure while bootstrapping, including mysqld exiting before it became
+// reachable, is reported as a BootstrapError.
+func (s *starter) bootstrapAndWaitForDatabase() (chan error, error) {
+	mysqldChan, err := s.startAndWaitForDatabase(s.bootstrapNode)
+	if err == errNodeEvicted {
+		// mysqld is left running for the operator, so it must not be
+		// bootstrapped again
+		err = &startup_errors.FatalError{Err: err}
+	}
+
+	var bootstrapErr *startup_errors.BootstrapError
+	if err != nil && !errors.As(err, &bootstrapErr) {
+		err = &startup_errors.BootstrapError{Err: err}
+	}
+
+	return mysqldChan, err
+}
+
+// Retries a failed join up to MaxJoinAttempts times with a doubling delay.
+// Before each retry the cluster is probed again; when canBootstrap is set and
+// no healthy peers remain, the node bootstraps instead of joining a cluster
+// that has gone away. A join is only retried once the failed mysqld has
+// stopped, and not at all after a fatal error.
+func (s *starter) joinClusterWithRetries(canBootstrap bool) (chan error, error) {
+	maxAttempts := s.config.MaxJoinAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	delay := JoinRetryDelay
+
+	s.waitForMinPeers()
+	s.waitForPrimaryComponent()
+
+	for attempt := 1; ; attempt++ {
+		mysqldChan, err := s.startAndWaitForDatabase(s.joinCluster)
+		var fatalErr *startup_errors.FatalError
+		if err == nil || err == errNodeEvicted || errors.As(err, &fatalErr) || attempt >= maxAttempts {
+			return mysqldChan, err
+		}
+
+		s.logger.Error("join-attempt-failed", err, lager.Data{
+			"attempt":         attempt,
+			"maxJoinAttempts": maxAttempts,
+			"retryIn":         delay.String(),
+		})
+		s.osHelper.Sleep(delay)
+		delay *= 2
+
+		if !s.clusterHealthChecker.HealthyCluster() && canBootstrap {
+			s.logger.Info("No healthy cluster found while retrying join, bootstrapping instead")
+			return s.bootstrapAndWaitForDatabase()
+		}
+	}
+}
+
+// Polls peers for up to MinPeersWaitTimeout seconds until at least
+// MinPeersBeforeJoin of them report healthy, so that nodes starting together
+// do not all join the first node to come up. The join is attempted regardless
+// once the window passes.
+func (s *starter) waitForMinPeers() {
+	required := s.config.MinPeersBeforeJoin
+	if required <= 0 {
+		return
+	}
+
+	for elapsed := 0; ; elapsed += StartupPollingFrequencyInSeconds {
+		healthyPeers := s.clusterHealthChecker.HealthyPeerCount()
+		if healthyPeers >= required {
+			s.logger.Info("proceeding-to-join", lager.Data{
+				"reason":             "enough-healthy-peers",
+				"healthyPeers":       healthyPeers,
+				"MinPeersBeforeJoin": required,
+			})
+			return
+		}
+
+		if elapsed >= s.config.MinPeersWaitTimeout {
+			s.logger.Info("proceeding-to-join", lager.Data{
+				"reason":              "min-peers-wait-timed-out",
+				"healthyPeers":        healthyPeers,
+				"MinPeersBeforeJoin":  required,
+				"MinPeersWaitTimeout": s.config.MinPeersWaitTimeout,
+			})
+			return
+		}
+
+		s.logger.Info("waiting-for-peers", lager.Data{
+			"healthyPeers":       healthyPeers,
+			"MinPeersBeforeJoin": required,
+			"elapsedSeconds":     elapsed,
+		})
+		s.osHelper.Sleep(StartupPollingFrequencyInSeconds * time.Second)
+	}
+}
+
+// Polls peers for up to JoinWaitForPrimaryTimeout seconds until one reports
+// that it is in a primary component. Joining a cluster that has lost quorum
+// fails, so waiting lets a node started during quorum recovery join once
+// quorum returns. The join is attempted regardless once the window passes.
+func (s *starter) waitForPrimaryComponent() {
+	timeout := s.config.JoinWaitForPrimaryTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	for elapsed := 0; ; elapsed += StartupPollingFrequencyInSeconds {
+		primary, err := s.clusterHealthChecker.IsClusterPrimary()
+		if primary {
+			if elapsed > 0 {
+				s.logger.Info("primary-component-found", lager.Data{"waitedSeconds": elapsed})
+			}
+			return
+		}
+
+		if elapsed >= timeout {
+			if err != nil {
+				// Most likely the peers' status servers are bound to an
+				// interface this node cannot reach
+				s.logger.Error("peer-status-unreachable-within-window-joining-anyway", err, lager.Data{
+					"JoinWaitForPrimaryTimeout": timeout,
+				})
+			} else {
+				s.logger.Info("no-primary-component-within-window-joining-anyway", lager.Data{
+					"JoinWaitForPrimaryTimeout": timeout,
+				})
+			}
+			return
+		}
+
+		s.logger.Info("waiting-for-primary-component", lager.Data{
+			"elapsedSeconds":            elapsed,
+			"JoinWaitForPrimaryTimeout": timeout,
+		})
+		s.osHelper.Sleep(StartupPollingFrequencyInSeconds * time.Second)
+	}
+}
+
 func (s *starter) joinCluster() (chan error, error) {
 	s.logger.Info("Joining a multi-node cluster")
+	s.counters.IncJoinAttempts()
 	cmd, err := s.dbHelper.StartMysqldInJoin()
 
 	if err != nil {
-		return nil, err
+		return nil, &startup_errors.JoinError{Err: err}
 	}
 
 	s.mysqlCmd = cmd
@@ -150,6 +401,7 @@ func (s *starter) joinCluster() (chan error, error) {
 func (s *starter) waitForDatabaseToAcceptConnections(mysqldChan chan error) error {
 	s.logger.Info(fmt.Sprintf("Attempting to reach database."))
 	numTries := 0
+	lastProgressLog := 0
 
 	for {
 		numTries++
@@ -157,41 +409,402 @@ func (s *starter) waitForDatabaseToAcceptConnections(mysqldChan chan error) erro
 		select {
 		case <-mysqldChan:
 			s.logger.Info("Database process exited, stop trying to connect to database")
+			if err := s.dbHelper.CheckErrorLog(); err != nil {
+				return &startup_errors.FatalError{Err: err}
+			}
 			return errors.New("Mysqld exited with error; aborting. Review the mysqld error logs for more information.")
 		default:
-			if s.dbHelper.IsDatabaseReachable() {
+			s.counters.IncReachabilityPolls()
+			if s.isDatabaseReady() {
 				s.logger.Info(fmt.Sprintf("Database became reachable after %d seconds", numTries*StartupPollingFrequencyInSeconds))
 				return nil
+			} else if s.dbHelper.IsNodeEvicted() {
+				return errNodeEvicted
+			} else if err := s.dbHelper.CheckErrorLog(); err != nil {
+				// mysqld is still running; stop it so that it is not left
+				// holding the datadir
+				s.logger.Error("mysqld-fatal-error-logged", err)
+				StopMysqld(s.osHelper, s.mysqlCmd, mysqldChan, s.config, s.logger)
+				return &startup_errors.FatalError{Err: err}
 			} else {
-				s.logger.Info("Database not reachable, retrying...")
+				s.logger.Debug("Database not reachable, retrying...")
 				s.osHelper.Sleep(StartupPollingFrequencyInSeconds * time.Second)
+
+				elapsed := numTries * StartupPollingFrequencyInSeconds
+				if s.config.JoinProgressLogInterval > 0 && elapsed-lastProgressLog >= s.config.JoinProgressLogInterval {
+					s.logProgress(elapsed)
+					lastProgressLog = elapsed
+				}
 			}
 		}
 	}
 }
 
+// Uses ReadinessProbeScript, when configured, in place of the SQL
+// reachability check; the script exiting zero means ready.
+func (s *starter) isDatabaseReady() bool {
+	if s.config.ReadinessProbeScript == "" {
+		return s.dbHelper.IsDatabaseReachable()
+	}
+
+	output, err := s.osHelper.RunCommand(s.config.ReadinessProbeScript)
+	if err != nil {
+		s.logger.Debug("readiness-probe-not-ready", lager.Data{
+			"script": s.config.ReadinessProbeScript,
+			"error":  err.Error(),
+			"output": output,
+		})
+		return false
+	}
+
+	return true
+}
+
+// Reports how far along a slow join is, so a long state transfer can be told
+// apart from a hung one.
+func (s *starter) logProgress(elapsed int) {
+	data := lager.Data{"elapsedSeconds": elapsed}
+
+	status, err := s.dbHelper.GetWsrepStatus()
+	if err != nil {
+		data["wsrepStatusError"] = err.Error()
+	} else {
+		data["localState"] = status.LocalStateComment
+		data["clusterSize"] = status.ClusterSize
+	}
+
+	s.logger.Info("waiting-for-database-to-sync", data)
+}
+
+// Refuses to carry on as a member of a cluster other than ExpectedClusterUUID,
+// which would mean the peer list pointed this node at a foreign cluster.
+func (s *starter) verifyClusterUUID() error {
+	if s.config.ExpectedClusterUUID == "" {
+		return nil
+	}
+
+	uuid, err := s.dbHelper.GetClusterStateUUID()
+	if err != nil {
+		s.logger.Error("cluster-uuid-check-failed", err)
+		return &startup_errors.JoinError{Err: err}
+	}
+
+	if !strings.EqualFold(uuid, s.config.ExpectedClusterUUID) {
+		err = fmt.Errorf("Joined cluster %s but ExpectedClusterUUID is %s; refusing to start as a member of the wrong cluster", uuid, s.config.ExpectedClusterUUID)
+		s.logger.Error("cluster-uuid-mismatch", err, lager.Data{
+			"clusterUUID":         uuid,
+			"expectedClusterUUID": s.config.ExpectedClusterUUID,
+			"clusterIps":          s.config.ClusterIps,
+		})
+		return &startup_errors.JoinError{Err: err}
+	}
+
+	return nil
+}
+
+// A node that may have joined the wrong cluster must not stay a member of it,
+// so mysqld is stopped before the verification error is returned.
+func (s *starter) stopUnverifiedMember(verifyErr error, mysqldChan chan error) error {
+	s.logger.Error("cluster-verification-failed-stopping-mysqld", verifyErr)
+	StopMysqld(s.osHelper, s.mysqlCmd, mysqldChan, s.config, s.logger)
+	return verifyErr
+}
+
+// Cross-checks the members of the cluster this node joined against
+// ClusterIps, so that a node which joined the wrong cluster is noticed rather
+// than reported as healthy.
+func (s *starter) verifyMembership() error {
+	policy := s.config.MembershipCheckPolicy
+	if policy == "" || policy == config.MembershipCheckPolicyOff || len(s.config.ClusterIps) == 0 {
+		return nil
+	}
+
+	addresses, err := s.dbHelper.GetIncomingAddresses()
+	if err != nil {
+		s.logger.Error("membership-check-failed", err)
+		return nil
+	}
+
+	expected := map[string]bool{}
+	for _, ip := range s.config.ClusterIps {
+		expected[ip] = true
+	}
+
+	unexpected := []string{}
+	for _, address := range addresses {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		if host == "" || host == "AUTO" {
+			continue
+		}
+		if !expected[host] {
+			unexpected = append(unexpected, address)
+		}
+	}
+
+	if len(unexpected) == 0 {
+		return nil
+	}
+
+	if policy == config.MembershipCheckPolicyFail {
+		err = fmt.Errorf("Cluster members %v are not in ClusterIps %v; this node may have joined the wrong cluster", unexpected, s.config.ClusterIps)
+		s.logger.Error("unexpected-cluster-membership", err)
+		return &startup_errors.JoinError{Err: err}
+	}
+
+	s.logger.Info("warning-unexpected-cluster-membership", lager.Data{
+		"unexpectedMembers": unexpected,
+		"clusterIps":        s.config.ClusterIps,
+	})
+	return nil
+}
+
+// Under the resync policy the evicted mysqld is stopped, grastate.dat is
+// discarded to force a full state transfer, and the node is started again the
+// same way it was started the first time.
+func (s *starter) recoverFromEviction(mysqldChan chan error, start func() (chan error, error)) (chan error, error) {
+	if s.config.InconsistencyPolicy != config.InconsistencyPolicyResync {
+		s.logger.Error("node-evicted-for-inconsistency", errNodeEvicted, lager.Data{
+			"action": config.InconsistencyPolicyFail,
+		})
+		return nil, errNodeEvicted
+	}
+
+	s.logger.Error("node-evicted-for-inconsistency", errNodeEvicted, lager.Data{
+		"action": config.InconsistencyPolicyResync,
+	})
+
+	_, err := StopMysqld(s.osHelper, s.mysqlCmd, mysqldChan, s.config, s.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	s.backupRecoveryMetadata("resync")
+
+	s.logger.Info("Removing grastate file to force a full state transfer")
+	err = s.osHelper.RemoveFile(s.config.GrastateFileLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqldChan, err = start()
+	if err != nil {
+		return nil, err
+	}
+
+	return mysqldChan, s.waitForDatabaseToAcceptConnections(mysqldChan)
+}
+
+// Copies grastate.dat and the saved primary component view next to it into a
+// timestamped directory under RecoveryBackupDir before they are modified or
+// discarded. A failed backup is logged but does not hold up recovery.
+func (s *starter) backupRecoveryMetadata(reason string) {
+	if s.config.RecoveryBackupDir == "" {
+		return
+	}
+
+	backupDir := filepath.Join(s.config.RecoveryBackupDir, time.Now().UTC().Format("20060102T150405Z")+"-"+reason)
+	files := []string{
+		s.config.GrastateFileLocation,
+		filepath.Join(filepath.Dir(s.config.GrastateFileLocation), "gvwstate.dat"),
+	}
+
+	for _, file := range files {
+		if !s.osHelper.FileExists(file) {
+			continue
+		}
+
+		backup := filepath.Join(backupDir, filepath.Base(file))
+		if err := s.osHelper.CopyFile(file, backup); err != nil {
+			s.logger.Error("recovery-metadata-backup-failed", err, lager.Data{"file": file, "backupDir": backupDir})
+			continue
+		}
+		s.logger.Info("recovery-metadata-backed-up", lager.Data{"file": file, "backup": backup})
+	}
+}
+
+func (s *starter) checkMaxConnections() {
+	if s.config.MinExpectedMaxConnections <= 0 {
+		return
+	}
+
+	maxConnections, err := s.dbHelper.GetMaxConnections()
+	if err != nil {
+		s.logger.Error("max-connections-check-failed", err)
+		return
+	}
+
+	if maxConnections < s.config.MinExpectedMaxConnections {
+		s.logger.Info("warning-max-connections-below-expected", lager.Data{
+			"maxConnections":            maxConnections,
+			"minExpectedMaxConnections": s.config.MinExpectedMaxConnections,
+		})
+	}
+}
+
+func (s *starter) checkBufferPoolSize() {
+	if s.config.MinBufferPoolMemoryFraction <= 0 {
+		return
+	}
+
+	totalMemory, err := s.osHelper.TotalMemory()
+	if err != nil {
+		s.logger.Error("buffer-pool-size-check-failed", err)
+		return
+	}
+
+	bufferPoolSize, err := s.dbHelper.GetBufferPoolSize()
+	if err != nil {
+		s.logger.Error("buffer-pool-size-check-failed", err)
+		return
+	}
+
+	if float64(bufferPoolSize) < s.config.MinBufferPoolMemoryFraction*float64(totalMemory) {
+		s.logger.Info("warning-buffer-pool-size-below-expected", lager.Data{
+			"innodbBufferPoolSize":        bufferPoolSize,
+			"totalMemory":                 totalMemory,
+			"minBufferPoolMemoryFraction": s.config.MinBufferPoolMemoryFraction,
+		})
+	}
+}
+
+// Not fatal: the node is a healthy member either way, and the setting is
+// visible on /status.
+func (s *starter) applyDonorRejectsQueries() {
+	if !s.config.DonorRejectsQueries {
+		return
+	}
+
+	if err := s.dbHelper.SetDonorRejectsQueries(true); err != nil {
+		s.logger.Error("set-donor-rejects-queries-failed", err)
+		return
+	}
+
+	s.logger.Info("donor-rejects-queries-enabled")
+}
+
+// Not fatal for the same reason as applyDonorRejectsQueries; peers comparing
+// max_allowed_packet on /status will still report a mismatch.
+func (s *starter) applyMaxAllowedPacket() {
+	if s.config.MaxAllowedPacket == 0 {
+		return
+	}
+
+	if err := s.dbHelper.SetMaxAllowedPacket(s.config.MaxAllowedPacket); err != nil {
+		s.logger.Error("set-max-allowed-packet-failed", err)
+		return
+	}
+
+	s.logger.Info("max-allowed-packet-set", lager.Data{"maxAllowedPacket": s.config.MaxAllowedPacket})
+}
+
 func (s *starter) seedDatabases() error {
-	err := s.dbHelper.Seed()
+	s.counters.IncSeedAttempts()
+	err := s.withSeedTimeout("Seeding databases", s.dbHelper.Seed)
 	if err != nil {
 		s.logger.Info(fmt.Sprintf("There was a problem seeding the database: '%s'", err.Error()))
-		return err
+		return &startup_errors.SeedError{Err: err}
 	}
 
 	s.logger.Info("Seeding databases succeeded.")
 	return nil
 }
 
+// Returned by withSeedTimeout when a seed step overruns SeedTimeout
+type seedTimeoutError struct {
+	step    string
+	timeout int
+}
+
+func (e *seedTimeoutError) Error() string {
+	return fmt.Sprintf("%s did not finish within %d seconds", e.step, e.timeout)
+}
+
+// Bounds a seeding step by SeedTimeout seconds so that a hung seed fails
+// startup with an error naming the step. The step itself cannot be cancelled
+// and is left running against mysqld, so handleSeedFailure stops mysqld after
+// a timeout whatever the SeedFailurePolicy.
+func (s *starter) withSeedTimeout(step string, seed func() error) error {
+	if s.config.SeedTimeout <= 0 {
+		return seed()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- seed()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Duration(s.config.SeedTimeout) * time.Second):
+		err := &seedTimeoutError{step: step, timeout: s.config.SeedTimeout}
+		s.logger.Error("seed-timed-out", err)
+		return err
+	}
+}
+
+// A joining node should receive the preseeded databases through replication;
+// waits up to SeedReplicationTimeout seconds for them so that a replication
+// stall fails startup instead of surfacing later as an application error.
+func (s *starter) waitForPreseededDatabases() error {
+	if s.config.SeedReplicationTimeout <= 0 {
+		return nil
+	}
+
+	for elapsed := 0; ; elapsed += StartupPollingFrequencyInSeconds {
+		missing := s.dbHelper.MissingPreseededDatabases()
+		if len(missing) == 0 {
+			return nil
+		}
+
+		if elapsed >= s.config.SeedReplicationTimeout {
+			err := fmt.Errorf("Preseeded databases %v did not replicate to this node within %d seconds", missing, s.config.SeedReplicationTimeout)
+			s.logger.Error("preseeded-databases-not-replicated", err)
+			return &startup_errors.JoinError{Err: err}
+		}
+
+		s.logger.Info("waiting-for-preseeded-databases", lager.Data{"missing": missing})
+		s.osHelper.Sleep(StartupPollingFrequencyInSeconds * time.Second)
+	}
+}
+
 func (s *starter) seedUsers() error {
-	err := s.dbHelper.SeedUsers()
+	s.counters.IncSeedAttempts()
+	err := s.withSeedTimeout("Seeding users", s.dbHelper.SeedUsers)
 	if err != nil {
 		s.logger.Info(fmt.Sprintf("There was a problem seeding the users: '%s'", err.Error()))
-		return err
+		return &startup_errors.SeedError{Err: err}
 	}
 
 	s.logger.Info("Seeding users succeeded.")
 	return nil
 }
 
+// Under the leave-running policy a node that failed to seed stays up so the
+// operator can seed it by hand; under stop it is shut down so that an
+// unseeded node is not left serving. A seed step that timed out is still
+// running, so mysqld is stopped under either policy to keep it from writing
+// after startup has failed. The seed error is returned either way.
+func (s *starter) handleSeedFailure(seedErr error, mysqldChan chan error) error {
+	var timeoutErr *seedTimeoutError
+	switch {
+	case errors.As(seedErr, &timeoutErr):
+		s.logger.Error("seed-timed-out-stopping-mysqld", seedErr)
+	case s.config.SeedFailurePolicy != config.SeedFailurePolicyStop:
+		s.logger.Error("seed-failed-leaving-mysqld-running", seedErr)
+		return seedErr
+	default:
+		s.logger.Error("seed-failed-stopping-mysqld", seedErr)
+	}
+
+	StopMysqld(s.osHelper, s.mysqlCmd, mysqldChan, s.config, s.logger)
+
+	return seedErr
+}
+
 func (s *starter) runPostStartSQL() error {
 	err := s.dbHelper.RunPostStartSQL()
 	if err != nil {
@@ -202,3 +815,63 @@ func (s *starter) runPostStartSQL() error {
 	s.logger.Info("Post start sql succeeded.")
 	return nil
 }
+
+// Runs FirstBootSQLFile at most once per node. The marker is written as
+// in-progress before the SQL runs and as done after it succeeds, both
+// atomically, so a crash in between leaves an in-progress marker and the SQL
+// is not run again without the operator deleting it. SQL that returns an
+// error, or times out, leaves a failed marker instead, which fails every
+// start until the operator has checked its effects and deleted the marker.
+func (s *starter) runFirstBootSQL() error {
+	if s.config.FirstBootSQLFile == "" {
+		return nil
+	}
+
+	marker := s.config.FirstBootMarkerFile
+	if s.osHelper.FileExists(marker) {
+		contents, _ := s.osHelper.ReadFile(marker)
+		switch strings.TrimSpace(contents) {
+		case firstBootSQLDone:
+			s.logger.Debug("first-boot-sql-already-run", lager.Data{"marker": marker})
+		case firstBootSQLFailed:
+			return &startup_errors.SeedError{Err: fmt.Errorf("FirstBootSQLFile %s failed on an earlier start; check its effects and delete %s to run it again", s.config.FirstBootSQLFile, marker)}
+		default:
+			s.logger.Info("warning-first-boot-sql-interrupted", lager.Data{
+				"marker": marker,
+				"hint":   "a previous run did not finish; check its effects and delete the marker to run it again",
+			})
+		}
+		return nil
+	}
+
+	if err := s.osHelper.WriteStringToFileAtomically(marker, firstBootSQLInProgress); err != nil {
+		return &startup_errors.SeedError{Err: fmt.Errorf("Error writing first boot marker: %s", err)}
+	}
+
+	err := s.withSeedTimeout("Running first boot sql", func() error {
+		return s.dbHelper.RunSQLFile(s.config.FirstBootSQLFile)
+	})
+	if err != nil {
+		s.logger.Error("first-boot-sql-failed", err, lager.Data{"marker": marker})
+		if markErr := s.osHelper.WriteStringToFileAtomically(marker, firstBootSQLFailed); markErr != nil {
+			s.logger.Error("write-first-boot-marker-failed", markErr, lager.Data{"marker": marker})
+		}
+		return &startup_errors.SeedError{Err: err}
+	}
+
+	if err := s.osHelper.WriteStringToFileAtomically(marker, firstBootSQLDone); err != nil {
+		return &startup_errors.SeedError{Err: fmt.Errorf("Error writing first boot marker: %s", err)}
+	}
+
+	s.logger.Info("first-boot-sql-succeeded", lager.Data{"file": s.config.FirstBootSQLFile})
+	return nil
+}
+
+func (s *starter) checkAllowedDatabases() error {
+	err := s.dbHelper.CheckAllowedDatabases()
+	if err != nil {
+		return &startup_errors.SeedError{Err: err}
+	}
+
+	return nil
+}
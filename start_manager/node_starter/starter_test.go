@@ -5,17 +5,24 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"syscall"
 
 	"code.cloudfoundry.org/lager/lagertest"
 
+	"github.com/cloudfoundry/galera-init/cluster_health_checker"
 	"github.com/cloudfoundry/galera-init/cluster_health_checker/cluster_health_checkerfakes"
 	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/db_helper"
 	"github.com/cloudfoundry/galera-init/db_helper/db_helperfakes"
 	"github.com/cloudfoundry/galera-init/os_helper/os_helperfakes"
+	"github.com/cloudfoundry/galera-init/retry_counters"
 	"github.com/cloudfoundry/galera-init/start_manager/node_starter"
+	"github.com/cloudfoundry/galera-init/startup_errors"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("Starter", func() {
@@ -31,6 +38,7 @@ var _ = Describe("Starter", func() {
 	var fakeCommandJoin *exec.Cmd
 	var errorChan chan error
 	var grastateFile *os.File
+	var counters *retry_counters.Counters
 
 	ensureSeedDatabases := func() {
 		Expect(fakeDBHelper.SeedCallCount()).To(BeNumerically(">=", 1))
@@ -65,6 +73,7 @@ var _ = Describe("Starter", func() {
 		fakeClusterHealthChecker = new(cluster_health_checkerfakes.FakeClusterHealthChecker)
 		fakeDBHelper = new(db_helperfakes.FakeDBHelper)
 		fakeDBHelper.IsDatabaseReachableReturns(true)
+		counters = retry_counters.New()
 
 		grastateFile, _ = ioutil.TempFile(os.TempDir(), "grastateFile")
 		starter = node_starter.NewStarter(
@@ -75,6 +84,7 @@ var _ = Describe("Starter", func() {
 			},
 			testLogger,
 			fakeClusterHealthChecker,
+			counters,
 		)
 	})
 
@@ -168,6 +178,59 @@ var _ = Describe("Starter", func() {
 
 						grastateFileOutput, _ := ioutil.ReadFile(grastateFile.Name())
 						Expect(string(grastateFileOutput)).To(Equal("IMPORTANT OTHER STUFF\nsafe_to_bootstrap: 1\nLESS IMPORTANT STUFF"))
+						Expect(fakeOs.CopyFileCallCount()).To(Equal(0))
+					})
+
+					It("backs up the recovery metadata first when RecoveryBackupDir is set", func() {
+						starter = node_starter.NewStarter(
+							fakeDBHelper,
+							fakeOs,
+							config.StartManager{
+								GrastateFileLocation: grastateFile.Name(),
+								RecoveryBackupDir:    "/backups",
+							},
+							testLogger,
+							fakeClusterHealthChecker,
+							counters,
+						)
+						fakeOs.FileExistsReturns(true)
+						fakeOs.CopyFileStub = func(string, string) error {
+							grastateFileOutput, _ := ioutil.ReadFile(grastateFile.Name())
+							Expect(string(grastateFileOutput)).To(ContainSubstring("safe_to_bootstrap: 0"))
+							return nil
+						}
+
+						_, _, err := starter.StartNodeFromState("NEEDS_BOOTSTRAP")
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(fakeOs.CopyFileCallCount()).To(Equal(2))
+						src, dst := fakeOs.CopyFileArgsForCall(0)
+						Expect(src).To(Equal(grastateFile.Name()))
+						Expect(dst).To(MatchRegexp(`^/backups/\d{8}T\d{6}Z-bootstrap/`))
+						src, dst = fakeOs.CopyFileArgsForCall(1)
+						Expect(src).To(Equal(filepath.Join(filepath.Dir(grastateFile.Name()), "gvwstate.dat")))
+						Expect(dst).To(MatchRegexp(`^/backups/\d{8}T\d{6}Z-bootstrap/gvwstate.dat$`))
+					})
+
+					It("still bootstraps when the backup fails", func() {
+						starter = node_starter.NewStarter(
+							fakeDBHelper,
+							fakeOs,
+							config.StartManager{
+								GrastateFileLocation: grastateFile.Name(),
+								RecoveryBackupDir:    "/backups",
+							},
+							testLogger,
+							fakeClusterHealthChecker,
+							counters,
+						)
+						fakeOs.FileExistsReturns(true)
+						fakeOs.CopyFileReturns(errors.New("no space left on device"))
+
+						_, _, err := starter.StartNodeFromState("NEEDS_BOOTSTRAP")
+						Expect(err).ToNot(HaveOccurred())
+						ensureBootstrap()
+						Expect(testLogger).To(gbytes.Say("recovery-metadata-backup-failed"))
 					})
 
 					Describe("when it is not present", func() {
@@ -202,6 +265,42 @@ var _ = Describe("Starter", func() {
 			})
 		})
 
+		Context("starting with state NEEDS_BOOTSTRAP and a reachability probe window", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation:    grastateFile.Name(),
+						ReachabilityProbeWindow: 10,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("joins when a peer becomes healthy within the window", func() {
+				fakeClusterHealthChecker.HealthyClusterReturnsOnCall(0, false)
+				fakeClusterHealthChecker.HealthyClusterReturnsOnCall(1, true)
+
+				_, _, err := starter.StartNodeFromState("NEEDS_BOOTSTRAP")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeClusterHealthChecker.HealthyClusterCallCount()).To(Equal(2))
+				ensureJoin()
+			})
+
+			It("bootstraps when no peer becomes healthy within the window", func() {
+				fakeClusterHealthChecker.HealthyClusterReturns(false)
+
+				_, _, err := starter.StartNodeFromState("NEEDS_BOOTSTRAP")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeClusterHealthChecker.HealthyClusterCallCount()).To(Equal(3))
+				Expect(fakeOs.SleepCallCount()).To(Equal(2))
+				ensureBootstrap()
+			})
+		})
+
 		Context("starting with state CLUSTERED", func() {
 			BeforeEach(func() {
 				fakeClusterHealthChecker.HealthyClusterReturns(false)
@@ -217,6 +316,791 @@ var _ = Describe("Starter", func() {
 				ensureRunPostStartSQLs()
 				ensureMysqlCmdMatches(fakeCommandJoinStr)
 			})
+
+			It("does not wait for a primary component by default", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeClusterHealthChecker.IsClusterPrimaryCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when FirstBootSQLFile is set", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation: grastateFile.Name(),
+						FirstBootSQLFile:     "/var/vcap/jobs/mysql/config/first-boot.sql",
+						FirstBootMarkerFile:  "/var/vcap/store/mysql/first-boot-sql",
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("runs it on the first bootstrap, marking it in progress and then done", func() {
+				_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeDBHelper.RunSQLFileCallCount()).To(Equal(1))
+				Expect(fakeDBHelper.RunSQLFileArgsForCall(0)).To(Equal("/var/vcap/jobs/mysql/config/first-boot.sql"))
+
+				Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(2))
+				path, contents := fakeOs.WriteStringToFileAtomicallyArgsForCall(0)
+				Expect(path).To(Equal("/var/vcap/store/mysql/first-boot-sql"))
+				Expect(contents).To(Equal("in-progress"))
+				_, contents = fakeOs.WriteStringToFileAtomicallyArgsForCall(1)
+				Expect(contents).To(Equal("done"))
+			})
+
+			It("does not run it again once the marker says it is done", func() {
+				fakeOs.FileExistsReturns(true)
+				fakeOs.ReadFileReturns("done", nil)
+
+				_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeDBHelper.RunSQLFileCallCount()).To(BeZero())
+			})
+
+			It("does not run it again after an interrupted run", func() {
+				fakeOs.FileExistsReturns(true)
+				fakeOs.ReadFileReturns("in-progress", nil)
+
+				_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeDBHelper.RunSQLFileCallCount()).To(BeZero())
+				Expect(testLogger.Buffer()).To(gbytes.Say("warning-first-boot-sql-interrupted"))
+			})
+
+			It("fails startup and marks the SQL as failed when it returns an error", func() {
+				fakeDBHelper.RunSQLFileReturns(errors.New("syntax error"))
+
+				_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+				Expect(err).To(MatchError(ContainSubstring("syntax error")))
+				var seedErr *startup_errors.SeedError
+				Expect(errors.As(err, &seedErr)).To(BeTrue())
+
+				Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(2))
+				path, contents := fakeOs.WriteStringToFileAtomicallyArgsForCall(1)
+				Expect(path).To(Equal("/var/vcap/store/mysql/first-boot-sql"))
+				Expect(contents).To(Equal("failed"))
+			})
+
+			It("keeps failing startup after a failed run until the marker is deleted", func() {
+				fakeOs.FileExistsReturns(true)
+				fakeOs.ReadFileReturns("failed", nil)
+
+				_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+				Expect(err).To(MatchError(ContainSubstring("failed on an earlier start")))
+				var seedErr *startup_errors.SeedError
+				Expect(errors.As(err, &seedErr)).To(BeTrue())
+				Expect(fakeDBHelper.RunSQLFileCallCount()).To(BeZero())
+			})
+
+			It("does not run it when joining a cluster", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeDBHelper.RunSQLFileCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when MinPeersBeforeJoin is set", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation: grastateFile.Name(),
+						MinPeersBeforeJoin:   2,
+						MinPeersWaitTimeout:  12,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("waits until enough peers are healthy before joining", func() {
+				fakeClusterHealthChecker.HealthyPeerCountReturnsOnCall(0, 1)
+				fakeClusterHealthChecker.HealthyPeerCountReturnsOnCall(1, 2)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				ensureJoin()
+
+				Expect(fakeClusterHealthChecker.HealthyPeerCountCallCount()).To(Equal(2))
+				Expect(fakeOs.SleepCallCount()).To(Equal(1))
+				Expect(testLogger.Buffer()).To(gbytes.Say(`waiting-for-peers.*"healthyPeers":1`))
+				Expect(testLogger.Buffer()).To(gbytes.Say(`proceeding-to-join.*"reason":"enough-healthy-peers"`))
+			})
+
+			It("joins anyway once MinPeersWaitTimeout passes", func() {
+				fakeClusterHealthChecker.HealthyPeerCountReturns(1)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				ensureJoin()
+
+				Expect(fakeClusterHealthChecker.HealthyPeerCountCallCount()).To(Equal(4))
+				Expect(testLogger.Buffer()).To(gbytes.Say(`proceeding-to-join.*"reason":"min-peers-wait-timed-out"`))
+			})
+		})
+
+		Context("when JoinWaitForPrimaryTimeout is set", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation:      grastateFile.Name(),
+						JoinWaitForPrimaryTimeout: 12,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("waits for a primary component before joining", func() {
+				fakeClusterHealthChecker.IsClusterPrimaryReturnsOnCall(0, false, nil)
+				fakeClusterHealthChecker.IsClusterPrimaryReturnsOnCall(1, true, nil)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				ensureJoin()
+
+				Expect(fakeClusterHealthChecker.IsClusterPrimaryCallCount()).To(Equal(2))
+				Expect(fakeOs.SleepCallCount()).To(Equal(1))
+				Expect(testLogger.Buffer()).To(gbytes.Say("waiting-for-primary-component"))
+				Expect(testLogger.Buffer()).To(gbytes.Say("primary-component-found"))
+			})
+
+			It("joins anyway once the window passes", func() {
+				fakeClusterHealthChecker.IsClusterPrimaryReturns(false, nil)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				ensureJoin()
+
+				Expect(fakeClusterHealthChecker.IsClusterPrimaryCallCount()).To(Equal(4))
+				Expect(testLogger.Buffer()).To(gbytes.Say("no-primary-component-within-window-joining-anyway"))
+			})
+
+			It("says the peers were unreachable when no status server answered", func() {
+				fakeClusterHealthChecker.IsClusterPrimaryReturns(false, cluster_health_checker.ErrNoPeerStatusReachable)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				ensureJoin()
+
+				Expect(testLogger.Buffer()).To(gbytes.Say("peer-status-unreachable-within-window-joining-anyway"))
+			})
+		})
+
+		Context("when joining fails and MaxJoinAttempts allows retries", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation: grastateFile.Name(),
+						MaxJoinAttempts:      3,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+				fakeClusterHealthChecker.HealthyClusterReturns(true)
+
+				fakeDBHelper.StartMysqldInJoinStub = func() (*exec.Cmd, error) {
+					if fakeDBHelper.StartMysqldInJoinCallCount() < 3 {
+						errorChan <- errors.New("donor busy")
+					}
+					return fakeCommandJoin, nil
+				}
+				fakeDBHelper.IsDatabaseReachableStub = func() bool {
+					return fakeDBHelper.StartMysqldInJoinCallCount() >= 3
+				}
+			})
+
+			It("retries the join with a doubling delay", func() {
+				newNodeState, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newNodeState).To(Equal("CLUSTERED"))
+				Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(3))
+
+				Expect(fakeOs.SleepCallCount()).To(Equal(2))
+				Expect(fakeOs.SleepArgsForCall(0)).To(Equal(node_starter.JoinRetryDelay))
+				Expect(fakeOs.SleepArgsForCall(1)).To(Equal(2 * node_starter.JoinRetryDelay))
+				Expect(testLogger.Buffer()).To(gbytes.Say("join-attempt-failed"))
+			})
+
+			It("counts the join attempts and reachability polls it took", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(counters.Snapshot()).To(Equal(retry_counters.Snapshot{
+					JoinAttempts:      3,
+					ReachabilityPolls: 1,
+					SeedAttempts:      2,
+				}))
+				Expect(testLogger.Buffer()).To(gbytes.Say("startup-retry-counts"))
+			})
+
+			It("gives up after MaxJoinAttempts", func() {
+				fakeDBHelper.IsDatabaseReachableStub = nil
+				fakeDBHelper.IsDatabaseReachableReturns(false)
+				fakeDBHelper.StartMysqldInJoinStub = func() (*exec.Cmd, error) {
+					errorChan <- errors.New("donor busy")
+					return fakeCommandJoin, nil
+				}
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).To(MatchError(ContainSubstring("Mysqld exited with error")))
+				Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(3))
+			})
+
+			It("stops mysqld and does not retry when it logs a fatal error", func() {
+				fakeDBHelper.IsDatabaseReachableStub = nil
+				fakeDBHelper.IsDatabaseReachableReturns(false)
+				fakeDBHelper.StartMysqldInJoinStub = nil
+				fakeDBHelper.StartMysqldInJoinReturns(fakeCommandJoin, nil)
+				fakeDBHelper.CheckErrorLogReturns(errors.New("mysqld reported a fatal error in /log: No space left on device"))
+				fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+					errorChan <- nil
+					return nil
+				}
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).To(MatchError(ContainSubstring("No space left on device")))
+				Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(1))
+				Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+				Expect(testLogger.Buffer()).NotTo(gbytes.Say("join-attempt-failed"))
+			})
+
+			It("bootstraps instead when the cluster becomes unhealthy during NEEDS_BOOTSTRAP retries", func() {
+				fakeClusterHealthChecker.HealthyClusterReturnsOnCall(0, true)
+				fakeClusterHealthChecker.HealthyClusterReturnsOnCall(1, false)
+				fakeDBHelper.IsDatabaseReachableStub = func() bool {
+					return fakeDBHelper.StartMysqldInBootstrapCallCount() > 0
+				}
+
+				_, _, err := starter.StartNodeFromState("NEEDS_BOOTSTRAP")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(1))
+				ensureBootstrap()
+			})
+
+			It("keeps retrying the join for a CLUSTERED node even if the cluster looks unhealthy", func() {
+				fakeClusterHealthChecker.HealthyClusterReturns(false)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(3))
+				Expect(fakeDBHelper.StartMysqldInBootstrapCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a SeedReplicationTimeout is set", func() {
+			var startManagerConfig config.StartManager
+
+			BeforeEach(func() {
+				startManagerConfig = config.StartManager{
+					GrastateFileLocation:   grastateFile.Name(),
+					ClusterIps:             []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+					SeedReplicationTimeout: 10,
+				}
+			})
+
+			JustBeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					startManagerConfig,
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("waits for the preseeded databases to replicate after joining", func() {
+				fakeDBHelper.MissingPreseededDatabasesReturnsOnCall(0, []string{"app"})
+				fakeDBHelper.MissingPreseededDatabasesReturnsOnCall(1, []string{})
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeDBHelper.MissingPreseededDatabasesCallCount()).To(Equal(2))
+				Expect(testLogger.Buffer()).To(gbytes.Say("waiting-for-preseeded-databases"))
+			})
+
+			It("returns a join error when they do not replicate in time", func() {
+				fakeDBHelper.MissingPreseededDatabasesReturns([]string{"app"})
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+
+				var joinErr *startup_errors.JoinError
+				Expect(errors.As(err, &joinErr)).To(BeTrue())
+				Expect(err).To(MatchError("Preseeded databases [app] did not replicate to this node within 10 seconds"))
+				Expect(fakeDBHelper.MissingPreseededDatabasesCallCount()).To(Equal(3))
+			})
+
+			It("does not wait after bootstrapping", func() {
+				_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeDBHelper.MissingPreseededDatabasesCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when an ExpectedClusterUUID is set", func() {
+			var startManagerConfig config.StartManager
+
+			BeforeEach(func() {
+				startManagerConfig = config.StartManager{
+					GrastateFileLocation: grastateFile.Name(),
+					ClusterIps:           []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+					ExpectedClusterUUID:  "6f2bbf1c-8c2a-11ee-9d8e-6b1f4a2b3c4d",
+				}
+				fakeDBHelper.GetClusterStateUUIDReturns("6F2BBF1C-8C2A-11EE-9D8E-6B1F4A2B3C4D", nil)
+			})
+
+			JustBeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					startManagerConfig,
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("starts when the joined cluster has the expected UUID", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeDBHelper.GetClusterStateUUIDCallCount()).To(Equal(1))
+			})
+
+			It("refuses to start as a member of a different cluster and stops mysqld", func() {
+				fakeDBHelper.GetClusterStateUUIDReturns("00000000-1111-2222-3333-444444444444", nil)
+				fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+					errorChan <- nil
+					return nil
+				}
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+
+				var joinErr *startup_errors.JoinError
+				Expect(errors.As(err, &joinErr)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring("Joined cluster 00000000-1111-2222-3333-444444444444")))
+				Expect(testLogger.Buffer()).To(gbytes.Say("cluster-uuid-mismatch"))
+				Expect(fakeDBHelper.SeedUsersCallCount()).To(Equal(0))
+
+				Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+				cmd, signal := fakeOs.KillCommandArgsForCall(0)
+				Expect(cmd).To(Equal(fakeCommandJoin))
+				Expect(signal).To(Equal(syscall.SIGTERM))
+			})
+
+			It("refuses to start and stops mysqld when the UUID cannot be read", func() {
+				fakeDBHelper.GetClusterStateUUIDReturns("", errors.New("some error"))
+				fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+					errorChan <- nil
+					return nil
+				}
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).To(MatchError("some error"))
+				Expect(testLogger.Buffer()).To(gbytes.Say("cluster-uuid-check-failed"))
+				Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+			})
+
+			It("does not check the UUID after bootstrapping", func() {
+				_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeDBHelper.GetClusterStateUUIDCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a MembershipCheckPolicy is set", func() {
+			var startManagerConfig config.StartManager
+
+			BeforeEach(func() {
+				startManagerConfig = config.StartManager{
+					GrastateFileLocation:  grastateFile.Name(),
+					ClusterIps:            []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+					MembershipCheckPolicy: config.MembershipCheckPolicyWarn,
+				}
+				fakeDBHelper.GetIncomingAddressesReturns([]string{"10.0.0.1:3306", "10.0.0.2:3306", "10.9.9.9:3306"}, nil)
+			})
+
+			JustBeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					startManagerConfig,
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("warns when the joined cluster has members outside ClusterIps", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say("warning-unexpected-cluster-membership"))
+				Expect(testLogger.Buffer()).To(gbytes.Say("10.9.9.9:3306"))
+			})
+
+			It("does not warn when every member is expected", func() {
+				fakeDBHelper.GetIncomingAddressesReturns([]string{"10.0.0.1:3306", "AUTO", "10.0.0.3:3306"}, nil)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(testLogger.Buffer()).NotTo(gbytes.Say("unexpected-cluster-membership"))
+			})
+
+			It("does not check membership after bootstrapping", func() {
+				_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeDBHelper.GetIncomingAddressesCallCount()).To(Equal(0))
+			})
+
+			It("does not fail startup when the membership cannot be read", func() {
+				fakeDBHelper.GetIncomingAddressesReturns(nil, errors.New("some error"))
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say("membership-check-failed"))
+			})
+
+			Context("when the policy is fail", func() {
+				BeforeEach(func() {
+					startManagerConfig.MembershipCheckPolicy = config.MembershipCheckPolicyFail
+				})
+
+				It("stops mysqld and returns a join error", func() {
+					fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+						errorChan <- nil
+						return nil
+					}
+
+					_, _, err := starter.StartNodeFromState("CLUSTERED")
+
+					var joinErr *startup_errors.JoinError
+					Expect(errors.As(err, &joinErr)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring("may have joined the wrong cluster")))
+					Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the policy is off", func() {
+				BeforeEach(func() {
+					startManagerConfig.MembershipCheckPolicy = config.MembershipCheckPolicyOff
+				})
+
+				It("does not check membership", func() {
+					_, _, err := starter.StartNodeFromState("CLUSTERED")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeDBHelper.GetIncomingAddressesCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when SeedOnlyOnBootstrap is set", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation: grastateFile.Name(),
+						SeedOnlyOnBootstrap:  true,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("seeds databases when bootstrapping", func() {
+				fakeClusterHealthChecker.HealthyClusterReturns(false)
+
+				_, _, err := starter.StartNodeFromState("NEEDS_BOOTSTRAP")
+				Expect(err).ToNot(HaveOccurred())
+				ensureBootstrap()
+				Expect(fakeDBHelper.SeedCallCount()).To(Equal(1))
+			})
+
+			It("skips seeding databases when joining, but still seeds users", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				ensureJoin()
+				Expect(fakeDBHelper.SeedCallCount()).To(Equal(0))
+				ensureSeedUsers()
+			})
+		})
+
+		Context("when NeverBootstrap is set", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation: grastateFile.Name(),
+						NeverBootstrap:       true,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("joins when the cluster is healthy", func() {
+				fakeClusterHealthChecker.HealthyClusterReturns(true)
+
+				_, _, err := starter.StartNodeFromState("NEEDS_BOOTSTRAP")
+				Expect(err).ToNot(HaveOccurred())
+				ensureJoin()
+			})
+
+			It("returns an error instead of bootstrapping when the cluster is not healthy", func() {
+				fakeClusterHealthChecker.HealthyClusterReturns(false)
+
+				_, _, err := starter.StartNodeFromState("NEEDS_BOOTSTRAP")
+				Expect(err).To(MatchError(ContainSubstring("NeverBootstrap is set")))
+
+				var fatalErr *startup_errors.FatalError
+				Expect(errors.As(err, &fatalErr)).To(BeTrue())
+				Expect(fakeDBHelper.StartMysqldInBootstrapCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when joining takes a while", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation:    grastateFile.Name(),
+						JoinProgressLogInterval: 10,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+				fakeDBHelper.IsDatabaseReachableStub = func() bool {
+					return fakeDBHelper.IsDatabaseReachableCallCount() > 4
+				}
+				fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{
+					LocalStateComment: "Joining: receiving State Transfer",
+					ClusterSize:       3,
+				}, nil)
+			})
+
+			It("periodically logs the join progress", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeDBHelper.GetWsrepStatusCallCount()).To(Equal(2))
+				Expect(testLogger.Buffer()).To(gbytes.Say(`waiting-for-database-to-sync.*"clusterSize":3,"elapsedSeconds":10,"localState":"Joining: receiving State Transfer"`))
+				Expect(testLogger.Buffer()).To(gbytes.Say(`waiting-for-database-to-sync.*"elapsedSeconds":20`))
+			})
+
+			It("still logs progress when the wsrep status cannot be read", func() {
+				fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{}, errors.New("some error"))
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say(`waiting-for-database-to-sync.*"wsrepStatusError":"some error"`))
+			})
+		})
+
+		Context("checking max_connections", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation:      grastateFile.Name(),
+						MinExpectedMaxConnections: 100,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("warns when max_connections is below the expected minimum", func() {
+				fakeDBHelper.GetMaxConnectionsReturns(10, nil)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say("warning-max-connections-below-expected"))
+			})
+
+			It("does not warn when max_connections is high enough", func() {
+				fakeDBHelper.GetMaxConnectionsReturns(100, nil)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).NotTo(gbytes.Say("warning-max-connections-below-expected"))
+			})
+
+			It("does not fail startup when max_connections cannot be read", func() {
+				fakeDBHelper.GetMaxConnectionsReturns(0, errors.New("some error"))
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say("max-connections-check-failed"))
+			})
+		})
+
+		Context("checking innodb_buffer_pool_size", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation:        grastateFile.Name(),
+						MinBufferPoolMemoryFraction: 0.5,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+				fakeOs.TotalMemoryReturns(1000, nil)
+			})
+
+			It("warns when the buffer pool is below the expected fraction of memory", func() {
+				fakeDBHelper.GetBufferPoolSizeReturns(100, nil)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say("warning-buffer-pool-size-below-expected"))
+			})
+
+			It("does not warn when the buffer pool is large enough", func() {
+				fakeDBHelper.GetBufferPoolSizeReturns(500, nil)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).NotTo(gbytes.Say("warning-buffer-pool-size-below-expected"))
+			})
+
+			It("does not fail startup when system memory cannot be read", func() {
+				fakeOs.TotalMemoryReturns(0, errors.New("no meminfo"))
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say("buffer-pool-size-check-failed"))
+			})
+		})
+
+		Context("when a ReadinessProbeScript is set", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation: grastateFile.Name(),
+						ReadinessProbeScript: "/probe.sh",
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+				fakeDBHelper.IsDatabaseReachableReturns(false)
+			})
+
+			It("polls the script instead of checking SQL reachability", func() {
+				fakeOs.RunCommandReturnsOnCall(0, "lagging", errors.New("exit status 1"))
+				fakeOs.RunCommandReturnsOnCall(1, "", nil)
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeOs.RunCommandCallCount()).To(Equal(2))
+				script, _ := fakeOs.RunCommandArgsForCall(0)
+				Expect(script).To(Equal("/probe.sh"))
+				Expect(fakeDBHelper.IsDatabaseReachableCallCount()).To(Equal(0))
+				Expect(fakeOs.SleepCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when DonorRejectsQueries is set", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation: grastateFile.Name(),
+						DonorRejectsQueries:  true,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("makes the node reject queries while it is a donor", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeDBHelper.SetDonorRejectsQueriesCallCount()).To(Equal(1))
+				Expect(fakeDBHelper.SetDonorRejectsQueriesArgsForCall(0)).To(BeTrue())
+			})
+
+			It("does not fail startup when the setting cannot be applied", func() {
+				fakeDBHelper.SetDonorRejectsQueriesReturns(errors.New("some error"))
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say("set-donor-rejects-queries-failed"))
+			})
+		})
+
+		Context("when MaxAllowedPacket is set", func() {
+			BeforeEach(func() {
+				starter = node_starter.NewStarter(
+					fakeDBHelper,
+					fakeOs,
+					config.StartManager{
+						GrastateFileLocation: grastateFile.Name(),
+						MaxAllowedPacket:     67108864,
+					},
+					testLogger,
+					fakeClusterHealthChecker,
+					counters,
+				)
+			})
+
+			It("enforces it once mysqld is running", func() {
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeDBHelper.SetMaxAllowedPacketCallCount()).To(Equal(1))
+				Expect(fakeDBHelper.SetMaxAllowedPacketArgsForCall(0)).To(Equal(uint64(67108864)))
+			})
+
+			It("does not fail startup when it cannot be applied", func() {
+				fakeDBHelper.SetMaxAllowedPacketReturns(errors.New("some error"))
+
+				_, _, err := starter.StartNodeFromState("CLUSTERED")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(testLogger.Buffer()).To(gbytes.Say("set-max-allowed-packet-failed"))
+			})
+		})
+
+		It("checks the SST credentials once mysqld is running", func() {
+			_, _, err := starter.StartNodeFromState("CLUSTERED")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeDBHelper.CheckSSTAuthCallCount()).To(Equal(1))
+		})
+
+		It("leaves donor behaviour alone by default", func() {
+			_, _, err := starter.StartNodeFromState("CLUSTERED")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeDBHelper.SetDonorRejectsQueriesCallCount()).To(Equal(0))
+			Expect(fakeDBHelper.SetMaxAllowedPacketCallCount()).To(Equal(0))
 		})
 
 		Context("error handling", func() {
@@ -240,6 +1124,17 @@ var _ = Describe("Starter", func() {
 					Expect(err.Error()).To(ContainSubstring(expectedErr))
 				})
 
+				It("reports the failure as a bootstrap error when bootstrapping", func() {
+					errorChan <- errors.New("db exited")
+					fakeDBHelper.IsDatabaseReachableReturns(false)
+
+					_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+					Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+
+					var bootstrapErr *startup_errors.BootstrapError
+					Expect(errors.As(err, &bootstrapErr)).To(BeTrue())
+				})
+
 				It("forwards an error, even if mysql start exits successfully", func() {
 					errorChan <- nil
 					fakeDBHelper.IsDatabaseReachableReturns(false)
@@ -251,6 +1146,149 @@ var _ = Describe("Starter", func() {
 				})
 			})
 
+			Context("when mysqld logs a fatal disk error", func() {
+				BeforeEach(func() {
+					fakeDBHelper.IsDatabaseReachableReturns(false)
+					fakeDBHelper.CheckErrorLogReturns(errors.New("mysqld reported a fatal error in /log: Input/output error"))
+				})
+
+				It("stops mysqld and fails with the logged error instead of waiting", func() {
+					fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+						errorChan <- nil
+						return nil
+					}
+
+					_, _, err := starter.StartNodeFromState("CLUSTERED")
+					Expect(err).To(MatchError(ContainSubstring("Input/output error")))
+					Expect(fakeOs.SleepCallCount()).To(Equal(0))
+
+					Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+					cmd, signal := fakeOs.KillCommandArgsForCall(0)
+					Expect(cmd).To(Equal(fakeCommandJoin))
+					Expect(signal).To(Equal(syscall.SIGTERM))
+
+					var fatalErr *startup_errors.FatalError
+					Expect(errors.As(err, &fatalErr)).To(BeTrue())
+				})
+
+				It("reports the logged error when mysqld exits", func() {
+					errorChan <- errors.New("db exited")
+
+					_, _, err := starter.StartNodeFromState("CLUSTERED")
+					Expect(err).To(MatchError(ContainSubstring("Input/output error")))
+					Expect(fakeOs.KillCommandCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the node is evicted from the cluster for inconsistency", func() {
+				BeforeEach(func() {
+					fakeDBHelper.IsDatabaseReachableReturns(false)
+					fakeDBHelper.IsNodeEvictedReturns(true)
+				})
+
+				It("fails for operator intervention by default", func() {
+					_, _, err := starter.StartNodeFromState("CLUSTERED")
+					Expect(err).To(MatchError(ContainSubstring("evicted from the cluster due to inconsistency")))
+					Expect(fakeOs.KillCommandCallCount()).To(Equal(0))
+					Expect(grastateFile.Name()).To(BeAnExistingFile())
+				})
+
+				Context("when the inconsistency policy is resync", func() {
+					BeforeEach(func() {
+						starter = node_starter.NewStarter(
+							fakeDBHelper,
+							fakeOs,
+							config.StartManager{
+								GrastateFileLocation: grastateFile.Name(),
+								InconsistencyPolicy:  config.InconsistencyPolicyResync,
+							},
+							testLogger,
+							fakeClusterHealthChecker,
+							counters,
+						)
+
+						fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+							errorChan <- nil
+							return nil
+						}
+						fakeDBHelper.IsDatabaseReachableStub = func() bool {
+							return fakeOs.KillCommandCallCount() > 0
+						}
+					})
+
+					It("stops mysqld, discards the grastate file and rejoins the cluster", func() {
+						newNodeState, _, err := starter.StartNodeFromState("CLUSTERED")
+						Expect(err).ToNot(HaveOccurred())
+						Expect(newNodeState).To(Equal("CLUSTERED"))
+
+						Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+						_, signal := fakeOs.KillCommandArgsForCall(0)
+						Expect(signal).To(Equal(syscall.SIGTERM))
+						Expect(fakeOs.RemoveFileCallCount()).To(Equal(1))
+						Expect(fakeOs.RemoveFileArgsForCall(0)).To(Equal(grastateFile.Name()))
+						Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(2))
+						ensureSeedDatabases()
+					})
+
+					It("bootstraps again when it was evicted while bootstrapping", func() {
+						_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(fakeOs.RemoveFileCallCount()).To(Equal(1))
+						Expect(fakeDBHelper.StartMysqldInBootstrapCallCount()).To(Equal(2))
+						Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(0))
+					})
+
+					It("forwards the error when grastate.dat cannot be removed", func() {
+						fakeOs.RemoveFileReturns(errors.New("read-only file system"))
+
+						_, _, err := starter.StartNodeFromState("CLUSTERED")
+						Expect(err).To(MatchError("read-only file system"))
+						Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(1))
+					})
+
+					It("backs up the recovery metadata before discarding it when RecoveryBackupDir is set", func() {
+						starter = node_starter.NewStarter(
+							fakeDBHelper,
+							fakeOs,
+							config.StartManager{
+								GrastateFileLocation: grastateFile.Name(),
+								InconsistencyPolicy:  config.InconsistencyPolicyResync,
+								RecoveryBackupDir:    "/backups",
+							},
+							testLogger,
+							fakeClusterHealthChecker,
+							counters,
+						)
+						fakeOs.FileExistsStub = func(file string) bool {
+							return file == grastateFile.Name()
+						}
+						fakeOs.CopyFileStub = func(string, string) error {
+							Expect(grastateFile.Name()).To(BeAnExistingFile())
+							return nil
+						}
+
+						_, _, err := starter.StartNodeFromState("CLUSTERED")
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(fakeOs.CopyFileCallCount()).To(Equal(1))
+						src, dst := fakeOs.CopyFileArgsForCall(0)
+						Expect(src).To(Equal(grastateFile.Name()))
+						Expect(dst).To(MatchRegexp(`^/backups/\d{8}T\d{6}Z-resync/` + filepath.Base(grastateFile.Name()) + `$`))
+						Expect(fakeOs.RemoveFileCallCount()).To(Equal(1))
+					})
+
+					It("forwards the error when mysqld cannot be stopped", func() {
+						fakeOs.KillCommandStub = nil
+						fakeOs.KillCommandReturns(errors.New("no such process"))
+
+						_, _, err := starter.StartNodeFromState("CLUSTERED")
+						Expect(err).To(MatchError("no such process"))
+						Expect(fakeDBHelper.StartMysqldInJoinCallCount()).To(Equal(1))
+					})
+				})
+			})
+
 			Context("starting cluster returns an error", func() {
 				BeforeEach(func() {
 					fakeDBHelper.StartMysqldInBootstrapReturns(nil, errors.New("some errors"))
@@ -262,6 +1300,9 @@ var _ = Describe("Starter", func() {
 						_, _, err := starter.StartNodeFromState("SINGLE_NODE")
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("some errors"))
+
+						var bootstrapErr *startup_errors.BootstrapError
+						Expect(errors.As(err, &bootstrapErr)).To(BeTrue())
 					})
 				})
 
@@ -278,6 +1319,9 @@ var _ = Describe("Starter", func() {
 						_, _, err := starter.StartNodeFromState("CLUSTERED")
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("some errors"))
+
+						var joinErr *startup_errors.JoinError
+						Expect(errors.As(err, &joinErr)).To(BeTrue())
 					})
 				})
 			})
@@ -289,10 +1333,115 @@ var _ = Describe("Starter", func() {
 					fakeDBHelper.SeedReturns(expectedErr)
 				})
 
-				It("forwards the error", func() {
+				It("returns a seed error wrapping the cause", func() {
+					_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+					Expect(err).To(MatchError(expectedErr.Error()))
+
+					var seedErr *startup_errors.SeedError
+					Expect(errors.As(err, &seedErr)).To(BeTrue())
+					Expect(errors.Is(err, expectedErr)).To(BeTrue())
+				})
+
+				It("leaves mysqld running by default", func() {
 					_, _, err := starter.StartNodeFromState("SINGLE_NODE")
 					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(expectedErr))
+
+					Expect(fakeOs.KillCommandCallCount()).To(Equal(0))
+					Expect(testLogger.Buffer()).To(gbytes.Say("seed-failed-leaving-mysqld-running"))
+				})
+
+				Context("when SeedFailurePolicy is stop", func() {
+					BeforeEach(func() {
+						starter = node_starter.NewStarter(
+							fakeDBHelper,
+							fakeOs,
+							config.StartManager{
+								GrastateFileLocation: grastateFile.Name(),
+								SeedFailurePolicy:    config.SeedFailurePolicyStop,
+								ShutdownKillTimeout:  1,
+							},
+							testLogger,
+							fakeClusterHealthChecker,
+							counters,
+						)
+					})
+
+					It("shuts mysqld down and still returns the seed error", func() {
+						fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+							errorChan <- nil
+							return nil
+						}
+
+						_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+						Expect(err).To(MatchError(expectedErr.Error()))
+
+						Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+						_, signal := fakeOs.KillCommandArgsForCall(0)
+						Expect(signal).To(Equal(syscall.SIGTERM))
+						Expect(testLogger.Buffer()).To(gbytes.Say(`mysqld-stopped.*"forced":false`))
+					})
+
+					It("sends SIGKILL when mysqld does not exit in time", func() {
+						fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+							if signal == syscall.SIGKILL {
+								errorChan <- nil
+							}
+							return nil
+						}
+
+						_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+						Expect(err).To(MatchError(expectedErr.Error()))
+
+						Expect(fakeOs.KillCommandCallCount()).To(Equal(2))
+						_, signal := fakeOs.KillCommandArgsForCall(1)
+						Expect(signal).To(Equal(syscall.SIGKILL))
+						Expect(testLogger.Buffer()).To(gbytes.Say(`mysqld-stopped.*"forced":true`))
+					})
+				})
+			})
+
+			Context("when seeding does not finish within SeedTimeout", func() {
+				var unblockSeed chan struct{}
+
+				BeforeEach(func() {
+					unblockSeed = make(chan struct{})
+					fakeDBHelper.SeedStub = func() error {
+						<-unblockSeed
+						return nil
+					}
+
+					starter = node_starter.NewStarter(
+						fakeDBHelper,
+						fakeOs,
+						config.StartManager{
+							GrastateFileLocation: grastateFile.Name(),
+							SeedTimeout:          1,
+						},
+						testLogger,
+						fakeClusterHealthChecker,
+						counters,
+					)
+				})
+
+				AfterEach(func() {
+					close(unblockSeed)
+				})
+
+				It("fails startup with a seed error naming the step and stops mysqld", func() {
+					fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+						errorChan <- nil
+						return nil
+					}
+
+					_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+					Expect(err).To(MatchError("Seeding databases did not finish within 1 seconds"))
+
+					var seedErr *startup_errors.SeedError
+					Expect(errors.As(err, &seedErr)).To(BeTrue())
+					Expect(fakeDBHelper.SeedUsersCallCount()).To(Equal(0))
+
+					Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+					Expect(testLogger.Buffer()).To(gbytes.Say("seed-timed-out-stopping-mysqld"))
 				})
 			})
 
@@ -307,6 +1456,20 @@ var _ = Describe("Starter", func() {
 					Expect(err.Error()).To(ContainSubstring("post start sql failed"))
 				})
 			})
+
+			Context("when seeding left databases outside the allowlist", func() {
+				BeforeEach(func() {
+					fakeDBHelper.CheckAllowedDatabasesReturns(errors.New("Found databases not in AllowedSeedDatabases: rogue"))
+				})
+
+				It("returns a seed error", func() {
+					_, _, err := starter.StartNodeFromState("SINGLE_NODE")
+					Expect(err).To(MatchError("Found databases not in AllowedSeedDatabases: rogue"))
+
+					var seedErr *startup_errors.SeedError
+					Expect(errors.As(err, &seedErr)).To(BeTrue())
+				})
+			})
 		})
 	})
 })
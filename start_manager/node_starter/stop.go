@@ -0,0 +1,55 @@
+package node_starter
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/os_helper"
+)
+
+// StopMysqld stops the mysqld galera-init started: it is sent ShutdownSignal
+// and, if it is still running ShutdownKillTimeout seconds later, SIGKILL (0
+// waits indefinitely). It returns mysqld's exit error, or the error from
+// signalling it, in which case mysqld may still be running.
+//
+// mysqldChan must not have been received from yet; a mysqld that is known
+// to have exited needs no stopping.
+func StopMysqld(osHelper os_helper.OsHelper, cmd *exec.Cmd, mysqldChan <-chan error, cfg config.StartManager, logger lager.Logger) (exitErr, signalErr error) {
+	signal := syscall.SIGTERM
+	if cfg.ShutdownSignal == config.ShutdownSignalKill {
+		signal = syscall.SIGKILL
+	}
+
+	if err := osHelper.KillCommand(cmd, signal); err != nil {
+		logger.Error("signal-mysqld-failed", err, lager.Data{"signal": signal.String()})
+		return nil, err
+	}
+	logger.Info("signal-mysqld-ok", lager.Data{"signal": signal.String()})
+
+	if signal == syscall.SIGKILL || cfg.ShutdownKillTimeout <= 0 {
+		exitErr = <-mysqldChan
+		logger.Info("mysqld-stopped", lager.Data{"forced": signal == syscall.SIGKILL})
+		return exitErr, nil
+	}
+
+	select {
+	case exitErr = <-mysqldChan:
+		logger.Info("mysqld-stopped", lager.Data{"forced": false})
+		return exitErr, nil
+	case <-time.After(time.Duration(cfg.ShutdownKillTimeout) * time.Second):
+	}
+
+	logger.Info("mysqld-shutdown-timed-out-sending-sigkill", lager.Data{"timeout": cfg.ShutdownKillTimeout})
+	if err := osHelper.KillCommand(cmd, syscall.SIGKILL); err != nil {
+		logger.Error("signal-mysqld-failed", err, lager.Data{"signal": syscall.SIGKILL.String()})
+		return nil, err
+	}
+
+	exitErr = <-mysqldChan
+	logger.Info("mysqld-stopped", lager.Data{"forced": true})
+	return exitErr, nil
+}
@@ -0,0 +1,49 @@
+package start_manager
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/os_helper"
+)
+
+// How often shutdown checks whether the replication queues have drained
+var ReplicationQueueDrainPollInterval = time.Second
+
+// Waits up to timeout for the node to apply everything it has received and
+// replicate everything it has sent, so that it is not behind the cluster when
+// it stops. Shutdown goes ahead regardless once the wait is over.
+func drainReplicationQueues(osHelper os_helper.OsHelper, dbHelper db_helper.DBHelper, timeout time.Duration, logger lager.Logger) {
+	if timeout <= 0 {
+		return
+	}
+
+	for waited := time.Duration(0); ; waited += ReplicationQueueDrainPollInterval {
+		queues, err := dbHelper.GetReplicationQueues()
+		if err != nil {
+			logger.Error("replication-queue-check-failed", err)
+			return
+		}
+
+		if queues.Recv == 0 && queues.Send == 0 {
+			logger.Info("replication-queues-drained")
+			return
+		}
+
+		if waited >= timeout {
+			logger.Info("replication-queue-drain-timed-out", lager.Data{
+				"recvQueue": queues.Recv,
+				"sendQueue": queues.Send,
+			})
+			return
+		}
+
+		logger.Debug("waiting-for-replication-queues", lager.Data{
+			"recvQueue": queues.Recv,
+			"sendQueue": queues.Send,
+		})
+		osHelper.Sleep(ReplicationQueueDrainPollInterval)
+	}
+}
@@ -0,0 +1,59 @@
+package start_manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrNotInStandby = errors.New("node is not in warm standby")
+
+// Standby hands a promotion from the status server to a node waiting in warm
+// standby. Promote fails unless the node is waiting.
+type Standby struct {
+	mutex   sync.Mutex
+	waiting bool
+	promote chan struct{}
+}
+
+func NewStandby() *Standby {
+	return &Standby{promote: make(chan struct{}, 1)}
+}
+
+func (s *Standby) Promote() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.waiting {
+		return ErrNotInStandby
+	}
+
+	s.waiting = false
+	s.promote <- struct{}{}
+	return nil
+}
+
+func (s *Standby) InStandby() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.waiting
+}
+
+// wait blocks until the node is promoted, returning true, or ctx is
+// cancelled, returning false.
+func (s *Standby) wait(ctx context.Context) bool {
+	s.mutex.Lock()
+	s.waiting = true
+	s.mutex.Unlock()
+
+	select {
+	case <-s.promote:
+		return true
+	case <-ctx.Done():
+		s.mutex.Lock()
+		s.waiting = false
+		s.mutex.Unlock()
+		return false
+	}
+}
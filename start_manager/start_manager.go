@@ -2,10 +2,11 @@ package start_manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
-	"syscall"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 
@@ -14,9 +15,27 @@ import (
 	"github.com/cloudfoundry/galera-init/db_helper"
 	"github.com/cloudfoundry/galera-init/os_helper"
 	"github.com/cloudfoundry/galera-init/start_manager/node_starter"
+	"github.com/cloudfoundry/galera-init/startup_errors"
+	"github.com/cloudfoundry/galera-init/transition_history"
 	"github.com/cloudfoundry/galera-init/upgrader"
 )
 
+// Delay before the second bootstrap attempt; it doubles for every attempt after that
+var BootstrapRetryDelay = 5 * time.Second
+
+// How often a written ready file is checked against mysqld, and how long a
+// ping may take before mysqld counts as unresponsive
+var (
+	ReadyCheckInterval    = 10 * time.Second
+	ReadyCheckPingTimeout = 5 * time.Second
+)
+
+// Pseudo-state recorded in the transition history once mysqld is no longer running
+const stoppedState = "STOPPED"
+
+// The grastate.dat UUID of a node with no recoverable cluster position
+const zeroUUID = "00000000-0000-0000-0000-000000000000"
+
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . StartManager
 type StartManager interface {
 	Execute(ctx context.Context) error
@@ -28,6 +47,14 @@ type ServiceStatus interface {
 	Start() error
 }
 
+// StartupListener is told once the node has started, e.g. to arm a monitor
+// that would take a node still starting up for a failed one.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . StartupListener
+type StartupListener interface {
+	Started()
+}
+
 type startManager struct {
 	osHelper               os_helper.OsHelper
 	config                 config.StartManager
@@ -39,6 +66,10 @@ type startManager struct {
 	mysqlCmd               *exec.Cmd
 	mysqldPid              int
 	galeraInitStatusServer ServiceStatus
+	statusServerStarted    bool
+	history                *transition_history.History
+	standby                *Standby
+	startupListeners       []StartupListener
 }
 
 func New(
@@ -50,6 +81,9 @@ func New(
 	logger lager.Logger,
 	healthChecker cluster_health_checker.ClusterHealthChecker,
 	galeraInitStatusServer ServiceStatus,
+	history *transition_history.History,
+	standby *Standby,
+	startupListeners ...StartupListener,
 ) StartManager {
 	return &startManager{
 		osHelper:               osHelper,
@@ -60,12 +94,51 @@ func New(
 		startCaller:            startCaller,
 		healthChecker:          healthChecker,
 		galeraInitStatusServer: galeraInitStatusServer,
+		history:                history,
+		standby:                standby,
+		startupListeners:       startupListeners,
 	}
 }
 
-func (m *startManager) Execute(ctx context.Context) error {
+func (m *startManager) Execute(ctx context.Context) (err error) {
 	var newNodeState string
-	var err error
+
+	// A decommissioned node stays down, and galera-init exits cleanly so that
+	// its supervisor does not treat the node as crashing.
+	if m.leftCluster() {
+		m.logger.Info("node-left-cluster-not-starting", lager.Data{
+			"hint": fmt.Sprintf("remove %s to let the node rejoin", m.config.StateFileLocation),
+		})
+		return nil
+	}
+
+	if !m.waitForCooldown(ctx) {
+		m.logger.Info("shutdown-detected")
+		return nil
+	}
+	defer func() {
+		if err != nil {
+			m.recordFailure()
+		}
+	}()
+
+	m.removeReadyFile()
+
+	err = m.runPreStartHealthCheck()
+	if err != nil {
+		return err
+	}
+
+	err = m.checkClusterSize()
+	if err != nil {
+		return err
+	}
+
+	err = m.dbHelper.CheckDatadirWritable()
+	if err != nil {
+		m.logger.Error("datadir-not-writable", err)
+		return err
+	}
 
 	if m.dbHelper.IsProcessRunning() {
 		m.logger.Info("mysqld-already-running")
@@ -73,12 +146,24 @@ func (m *startManager) Execute(ctx context.Context) error {
 		m.Shutdown()
 	}
 
+	err = m.checkPortFree()
+	if err != nil {
+		return err
+	}
+
+	err = m.dbHelper.InitializeDatadirIfNeeded()
+	if err != nil {
+		m.logger.Error("datadir-initialization-failed", err)
+		return err
+	}
+
 	needsUpgrade, err := m.upgrader.NeedsUpgrade()
 	if err != nil {
 		m.logger.Error("upgrade-check-failed", err)
 		return err
 	}
-	if needsUpgrade {
+	upgradeAfterStart := needsUpgrade && m.upgrader.UpgradesRunningNode()
+	if needsUpgrade && !upgradeAfterStart {
 		err = m.upgrader.Upgrade()
 		if err != nil {
 			m.logger.Error("mysql-upgrade-failed", err)
@@ -96,9 +181,17 @@ func (m *startManager) Execute(ctx context.Context) error {
 		return err
 	}
 
+	if currentState == node_starter.WarmStandby {
+		var promoted bool
+		currentState, promoted, err = m.waitInStandby(ctx)
+		if err != nil || !promoted {
+			return err
+		}
+	}
+
 	var mysqldChan <-chan error
 
-	newNodeState, mysqldChan, err = m.startCaller.StartNodeFromState(currentState)
+	newNodeState, mysqldChan, err = m.startNode(currentState)
 	if err != nil {
 		return err
 	}
@@ -108,41 +201,191 @@ func (m *startManager) Execute(ctx context.Context) error {
 		return err
 	}
 
+	if upgradeAfterStart {
+		err = m.upgradeRunningNode(newNodeState)
+		if err != nil {
+			m.logger.Error("mysql-upgrade-failed", err)
+			m.stopAfterFailedUpgrade(newNodeState, mysqldChan)
+			return err
+		}
+	}
+
 	m.logger.Info("bootstrap-complete")
 	m.logger.Info("waiting-for-mysqld")
 
-	m.logger.Info("status-server-starting")
-	m.galeraInitStatusServer.Start()
-	m.logger.Info("status-server-started")
+	m.startStatusServer()
+
+	m.writeReadyFile()
+	defer m.removeReadyFile()
+	defer m.checkReadyFile(ctx)()
+	m.clearFailure()
+
+	for _, listener := range m.startupListeners {
+		listener.Started()
+	}
 
 	select {
 	case err := <-mysqldChan:
+		if m.leftCluster() {
+			m.logger.Info("mysqld-stopped-after-leaving-cluster", lager.Data{
+				"error": err,
+			})
+			m.history.Record(newNodeState, stoppedState, "left-cluster", err)
+			return nil
+		}
+
 		m.logger.Info("mysqld-exited", lager.Data{
 			"error": err,
 		})
+		m.history.Record(newNodeState, stoppedState, "mysqld-exited", err)
 		return err
 	case <-ctx.Done():
 		m.logger.Info("shutdown-detected")
 
-		err := m.osHelper.KillCommand(m.startCaller.GetMysqlCmd(), syscall.SIGTERM)
-		if err != nil {
-			m.logger.Error("sigterm-mysqld-failed", err)
-			return err
+		// Desync first so the rest of the cluster is not throttled by flow
+		// control while this node shuts down; it resyncs when it rejoins.
+		if err := m.dbHelper.SetDesync(true); err != nil {
+			m.logger.Error("desync-mysqld-failed", err)
 		}
-		m.logger.Info("sigterm-mysqld-ok")
-		m.logger.Info("mysqld-shutdown-started")
 
-		err = <-mysqldChan
+		drainReplicationQueues(m.osHelper, m.dbHelper, time.Duration(m.config.ShutdownQueueDrainTimeout)*time.Second, m.logger)
+
+		m.logger.Info("mysqld-shutdown-started")
+		err, signalErr := node_starter.StopMysqld(m.osHelper, m.startCaller.GetMysqlCmd(), mysqldChan, m.config, m.logger)
+		if signalErr != nil {
+			m.history.Record(newNodeState, newNodeState, "shutdown-requested", signalErr)
+			return signalErr
+		}
 
 		m.logger.Info("mysqld-shutdown-complete", lager.Data{
 			"error": err,
 		})
+		m.history.Record(newNodeState, stoppedState, "shutdown-requested", err)
 
 		return err
 	}
 }
 
+// Galera-init exits once the post-start upgrade fails, so the mysqld it
+// started, possibly still desynced, is stopped rather than left running as a
+// cluster member with no status server and nothing watching it.
+func (m *startManager) stopAfterFailedUpgrade(nodeState string, mysqldChan <-chan error) {
+	m.logger.Info("mysqld-shutdown-started")
+	err, signalErr := node_starter.StopMysqld(m.osHelper, m.startCaller.GetMysqlCmd(), mysqldChan, m.config, m.logger)
+	if signalErr != nil {
+		m.history.Record(nodeState, nodeState, "upgrade-failed", signalErr)
+		return
+	}
+
+	m.logger.Info("mysqld-shutdown-complete", lager.Data{
+		"error": err,
+	})
+	m.history.Record(nodeState, stoppedState, "upgrade-failed", err)
+}
+
+// Runs the post-start upgrade. Unless the node runs alone it is a cluster
+// member by now, so it is desynced while mysql_upgrade and the upgrade
+// scripts run, keeping them from throttling the cluster through flow
+// control, and resynced afterwards. A node that cannot be resynced is left
+// desynced and startup fails.
+func (m *startManager) upgradeRunningNode(nodeState string) error {
+	if nodeState == node_starter.SingleNode {
+		return m.upgrader.UpgradeRunningNode()
+	}
+
+	m.logger.Info("desyncing-for-upgrade")
+	if err := m.dbHelper.SetDesync(true); err != nil {
+		return fmt.Errorf("Error desyncing node for upgrade: %s", err)
+	}
+
+	upgradeErr := m.upgrader.UpgradeRunningNode()
+
+	m.logger.Info("resyncing-after-upgrade")
+	if err := m.dbHelper.SetDesync(false); err != nil {
+		if upgradeErr != nil {
+			m.logger.Error("resync-after-upgrade-failed", err)
+			return upgradeErr
+		}
+		return fmt.Errorf("Error resyncing node after upgrade: %s", err)
+	}
+
+	return upgradeErr
+}
+
+func (m *startManager) startStatusServer() {
+	if m.statusServerStarted {
+		return
+	}
+
+	m.logger.Info("status-server-starting")
+	m.galeraInitStatusServer.Start()
+	m.statusServerStarted = true
+	m.logger.Info("status-server-started")
+}
+
+// Records warm standby in the state file and serves the status server without
+// starting mysqld until the node is promoted. The promoted node starts as a
+// first deploy would, and that state is written before starting so a crash
+// does not put it back in standby.
+func (m *startManager) waitInStandby(ctx context.Context) (string, bool, error) {
+	if err := m.writeStringToFile(node_starter.WarmStandby); err != nil {
+		return "", false, err
+	}
+	m.startStatusServer()
+
+	m.logger.Info("warm-standby-waiting-for-promotion")
+	if !m.standby.wait(ctx) {
+		m.logger.Info("shutdown-detected")
+		m.history.Record(node_starter.WarmStandby, stoppedState, "shutdown-requested", nil)
+		return "", false, nil
+	}
+
+	promotedState := m.freshState()
+	m.logger.Info("warm-standby-promoted", lager.Data{"startAs": promotedState})
+	m.history.Record(node_starter.WarmStandby, promotedState, "promoted", nil)
+
+	if err := m.writeStringToFile(promotedState); err != nil {
+		return "", false, err
+	}
+	return promotedState, true, nil
+}
+
+// Under the retry policy a failed bootstrap is retried here with a doubling
+// delay, so repeated failures are visible in the logs and transition history
+// rather than hidden in an external restart loop. The starter only returns a
+// bootstrap error once the mysqld it started has stopped; fatal errors, such
+// as NeverBootstrap refusing or a disk error in the error log, are not retried.
+func (m *startManager) startNode(currentState string) (string, <-chan error, error) {
+	maxAttempts := 1
+	if m.config.BootstrapFailurePolicy == config.BootstrapFailurePolicyRetry && m.config.MaxBootstrapAttempts > 1 {
+		maxAttempts = m.config.MaxBootstrapAttempts
+	}
+	delay := BootstrapRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		newNodeState, mysqldChan, err := m.startCaller.StartNodeFromState(currentState)
+		m.history.Record(currentState, newNodeState, "startup", err)
+
+		var bootstrapErr *startup_errors.BootstrapError
+		var fatalErr *startup_errors.FatalError
+		if err == nil || !errors.As(err, &bootstrapErr) || errors.As(err, &fatalErr) || attempt >= maxAttempts {
+			return newNodeState, mysqldChan, err
+		}
+
+		m.logger.Error("bootstrap-attempt-failed", err, lager.Data{
+			"attempt":              attempt,
+			"maxBootstrapAttempts": maxAttempts,
+			"retryIn":              delay.String(),
+		})
+		m.osHelper.Sleep(delay)
+		delay *= 2
+	}
+}
+
 func (m *startManager) getCurrentNodeState() (string, error) {
+	if m.config.WarmStandby && m.firstTimeDeploy() {
+		return node_starter.WarmStandby, nil
+	}
 
 	// Single-node deploy always requires bootstrapping of new cluster
 	if len(m.config.ClusterIps) == 1 {
@@ -160,10 +403,43 @@ func (m *startManager) getCurrentNodeState() (string, error) {
 	// If we are not a first time deploy we must already have a state file
 	state, err := m.readStateFromFile()
 	if err != nil {
+		if m.config.UnreadableStateFilePolicy == config.UnreadableStateFilePolicyFreshStart {
+			m.logger.Info("warning-state-file-unreadable-starting-fresh", lager.Data{
+				"err":     err.Error(),
+				"startAs": m.freshState(),
+			})
+			return m.freshState(), nil
+		}
+
 		m.logger.Info("state file could not be read", lager.Data{"err": err.Error()})
 		return "", err
 	}
 
+	if state == node_starter.WarmStandby {
+		if m.config.WarmStandby {
+			return node_starter.WarmStandby, nil
+		}
+		m.logger.Info("warm-standby-disabled-starting-as-promoted", lager.Data{"startAs": m.freshState()})
+		return m.freshState(), nil
+	}
+
+	if m.config.EmptyDatadirPolicy == config.EmptyDatadirPolicyJoin && !m.osHelper.FileExists(m.config.GrastateFileLocation) {
+		m.logger.Info("state-file-datadir-mismatch", lager.Data{
+			"stateFile": state,
+			"grastate":  m.config.GrastateFileLocation,
+			"startAs":   node_starter.Clustered,
+		})
+		return node_starter.Clustered, nil
+	}
+
+	if m.config.ZeroGrastateUUIDPolicy == config.ZeroGrastateUUIDPolicyFreshStart && m.grastateUUIDIsZero() {
+		m.logger.Info("grastate-uuid-all-zeros", lager.Data{
+			"stateFile": state,
+			"startAs":   m.freshState(),
+		})
+		return m.freshState(), nil
+	}
+
 	if state == node_starter.SingleNode && len(m.config.ClusterIps) > 1 {
 		// Upgrading from a single-node cluster means we have to re-bootstrap
 		return node_starter.NeedsBootstrap, nil
@@ -172,16 +448,53 @@ func (m *startManager) getCurrentNodeState() (string, error) {
 	return state, nil
 }
 
+// The state a first deploy starts from
+func (m *startManager) freshState() string {
+	if len(m.config.ClusterIps) == 1 {
+		return node_starter.SingleNode
+	}
+	if m.config.BootstrapNode {
+		return node_starter.NeedsBootstrap
+	}
+	return node_starter.Clustered
+}
+
 func (m *startManager) readStateFromFile() (string, error) {
 	state, err := m.osHelper.ReadFile(m.config.StateFileLocation)
 	if err != nil {
 		return "", err
 	}
 	state = strings.TrimSpace(state)
-	m.logger.Info(fmt.Sprintf("state file exists and contains: '%s'", state))
+	m.logger.Debug(fmt.Sprintf("state file exists and contains: '%s'", state))
 	return state, nil
 }
 
+// An all-zero grastate UUID means the datadir holds no recoverable cluster
+// position, either because it is fresh or because recovery never completed.
+func (m *startManager) grastateUUIDIsZero() bool {
+	grastate, err := m.osHelper.ReadFile(m.config.GrastateFileLocation)
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(grastate, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "uuid:" {
+			return fields[1] == zeroUUID
+		}
+	}
+	return false
+}
+
+func (m *startManager) leftCluster() bool {
+	if m.firstTimeDeploy() {
+		return false
+	}
+
+	state, err := m.readStateFromFile()
+	return err == nil && state == node_starter.LeftCluster
+}
+
 func (m *startManager) firstTimeDeploy() bool {
 	return !m.osHelper.FileExists(m.config.StateFileLocation)
 }
@@ -191,7 +504,216 @@ func (m *startManager) Shutdown() {
 	m.dbHelper.StopMysqld()
 }
 
+// Lets operators verify the persistent disk is mounted and writable before
+// mysqld is touched, rather than discovering it from a confusing mysqld error.
+func (m *startManager) runPreStartHealthCheck() error {
+	script := m.config.PreStartHealthCheckScript
+	if script == "" {
+		return nil
+	}
+
+	m.logger.Info("pre-start-health-check-starting", lager.Data{"script": script})
+	output, err := m.osHelper.RunCommand(script)
+	if err != nil {
+		m.logger.Error("pre-start-health-check-failed", err, lager.Data{"output": output})
+		return fmt.Errorf("pre-start health check %q failed: %s: %s", script, err, strings.TrimSpace(output))
+	}
+
+	m.logger.Info("pre-start-health-check-complete")
+	return nil
+}
+
+// A port held by a mysqld galera-init does not know about, or by something
+// else entirely, only shows up later as an opaque bind error from mysqld.
+func (m *startManager) checkPortFree() error {
+	if m.config.MysqlPort <= 0 {
+		return nil
+	}
+
+	if m.osHelper.IsPortInUse(m.config.MysqlPort) {
+		err := fmt.Errorf("port %d is already in use; stop the process listening on it before starting mysqld", m.config.MysqlPort)
+		m.logger.Error("mysql-port-in-use", err)
+		return err
+	}
+
+	return nil
+}
+
+// After a recent failed start, waits out the rest of StartupCooldown so that
+// a supervisor restarting galera-init in a tight loop cannot overwhelm the node.
+// Returns false if ctx is cancelled before the cooldown is over.
+func (m *startManager) waitForCooldown(ctx context.Context) bool {
+	if m.config.StartupCooldown <= 0 || m.config.LastFailureFileLocation == "" {
+		return true
+	}
+	if !m.osHelper.FileExists(m.config.LastFailureFileLocation) {
+		return true
+	}
+
+	contents, err := m.osHelper.ReadFile(m.config.LastFailureFileLocation)
+	if err != nil {
+		m.logger.Error("read-last-failure-failed", err)
+		return true
+	}
+
+	lastFailure, err := time.Parse(time.RFC3339, strings.TrimSpace(contents))
+	if err != nil {
+		m.logger.Error("read-last-failure-failed", err)
+		return true
+	}
+
+	remaining := time.Until(lastFailure.Add(time.Duration(m.config.StartupCooldown) * time.Second))
+	if remaining <= 0 {
+		return true
+	}
+
+	m.logger.Info("startup-cooldown", lager.Data{
+		"lastFailure": lastFailure,
+		"waiting":     remaining.String(),
+	})
+
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (m *startManager) recordFailure() {
+	if m.config.LastFailureFileLocation == "" {
+		return
+	}
+
+	err := m.osHelper.WriteStringToFileAtomically(m.config.LastFailureFileLocation, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		m.logger.Error("write-last-failure-failed", err)
+	}
+}
+
+func (m *startManager) clearFailure() {
+	if m.config.LastFailureFileLocation == "" {
+		return
+	}
+
+	err := m.osHelper.RemoveFile(m.config.LastFailureFileLocation)
+	if err != nil {
+		m.logger.Error("remove-last-failure-failed", err)
+	}
+}
+
+// Galera needs a strict majority for quorum, so a cluster with an even number
+// of nodes tolerates no more failures than one with a node fewer, and a two
+// node cluster stops entirely when either node fails.
+func (m *startManager) checkClusterSize() error {
+	clusterSize := len(m.config.ClusterIps)
+	if clusterSize == 0 || clusterSize%2 != 0 {
+		return nil
+	}
+
+	if m.config.RefuseEvenClusterSize {
+		err := fmt.Errorf("Refusing to start a cluster of %d nodes: an even number of nodes cannot keep quorum when half of them fail; use an odd number of nodes or add an arbitrator", clusterSize)
+		m.logger.Error("even-cluster-size", err)
+		return err
+	}
+
+	m.logger.Info("warning-even-cluster-size", lager.Data{
+		"clusterSize": clusterSize,
+		"risk":        "the cluster loses quorum when half of its nodes fail; use an odd number of nodes or add an arbitrator",
+	})
+	return nil
+}
+
+// The ready file is a marker for shell-based orchestration: it exists only
+// while mysqld is fully started, seeded and part of the cluster, and answers
+// pings.
+func (m *startManager) writeReadyFile() {
+	if m.config.ReadyFileLocation == "" {
+		return
+	}
+
+	err := m.osHelper.WriteStringToFileAtomically(m.config.ReadyFileLocation, "ready")
+	if err != nil {
+		m.logger.Error("write-ready-file-failed", err)
+	}
+}
+
+// Keeps the ready file in step with mysqld once it has been written: it is
+// removed while mysqld does not answer a ping, e.g. because it hangs, and
+// written again once it does. At most one ping is outstanding at a time.
+// The returned function stops the checks and waits for them to finish.
+func (m *startManager) checkReadyFile(ctx context.Context) (stop func()) {
+	if m.config.ReadyFileLocation == "" {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(ReadyCheckInterval)
+		defer ticker.Stop()
+
+		ready := true
+		var pinged chan bool
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if pinged == nil {
+				pinged = make(chan bool, 1)
+				go func(pinged chan<- bool) { pinged <- m.dbHelper.Ping() }(pinged)
+			}
+
+			responsive := false
+			select {
+			case responsive = <-pinged:
+				pinged = nil
+			case <-time.After(ReadyCheckPingTimeout):
+			case <-ctx.Done():
+				return
+			}
+
+			if ready && !responsive {
+				m.logger.Info("mysqld-unresponsive-removing-ready-file")
+				m.removeReadyFile()
+			} else if !ready && responsive {
+				m.logger.Info("mysqld-responsive-writing-ready-file")
+				m.writeReadyFile()
+			}
+			ready = responsive
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (m *startManager) removeReadyFile() {
+	if m.config.ReadyFileLocation == "" {
+		return
+	}
+
+	err := m.osHelper.RemoveFile(m.config.ReadyFileLocation)
+	if err != nil {
+		m.logger.Error("remove-ready-file-failed", err)
+	}
+}
+
 func (m *startManager) writeStringToFile(contents string) error {
 	m.logger.Info(fmt.Sprintf("updating file with contents: '%s'", contents))
-	return m.osHelper.WriteStringToFile(m.config.StateFileLocation, contents)
+	if m.config.SyncStateFile {
+		return m.osHelper.WriteStringToFileDurably(m.config.StateFileLocation, contents)
+	}
+	return m.osHelper.WriteStringToFileAtomically(m.config.StateFileLocation, contents)
 }
@@ -6,21 +6,26 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"code.cloudfoundry.org/lager/lagertest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 
 	"github.com/cloudfoundry/galera-init/cluster_health_checker/cluster_health_checkerfakes"
 	"github.com/cloudfoundry/galera-init/config"
+	"github.com/cloudfoundry/galera-init/db_helper"
 	"github.com/cloudfoundry/galera-init/db_helper/db_helperfakes"
 	"github.com/cloudfoundry/galera-init/os_helper/os_helperfakes"
 	. "github.com/cloudfoundry/galera-init/start_manager"
 	"github.com/cloudfoundry/galera-init/start_manager/node_starter"
 	"github.com/cloudfoundry/galera-init/start_manager/node_starter/node_starterfakes"
 	"github.com/cloudfoundry/galera-init/start_manager/start_managerfakes"
+	"github.com/cloudfoundry/galera-init/startup_errors"
+	"github.com/cloudfoundry/galera-init/transition_history"
 	"github.com/cloudfoundry/galera-init/upgrader/upgraderfakes"
 )
 
@@ -38,23 +43,43 @@ var _ = Describe("StartManager", func() {
 	var startNodeReturnError error
 	var mysqldErrChan chan error
 	var fakeserviceStatusServer *start_managerfakes.FakeServiceStatus
+	var fakeStartupListener *start_managerfakes.FakeStartupListener
+	var history *transition_history.History
+	var standby *Standby
 
 	const stateFileLocation = "/stateFileLocation"
+	const grastateFileLocation = "/grastate.dat"
 
 	type managerArgs struct {
-		BootstrapNode bool
-		NodeCount     int
+		BootstrapNode             bool
+		NodeCount                 int
+		ReadyFileLocation         string
+		PreStartHealthCheckScript string
+		BootstrapFailurePolicy    string
+		MaxBootstrapAttempts      int
+		SyncStateFile             bool
+		LastFailureFileLocation   string
+		StartupCooldown           int
+		RefuseEvenClusterSize     bool
+		ZeroGrastateUUIDPolicy    string
+		EmptyDatadirPolicy        string
+		UnreadableStateFilePolicy string
+		WarmStandby               bool
+		MysqlPort                 int
+		ShutdownQueueDrainTimeout int
+		ShutdownSignal            string
+		ShutdownKillTimeout       int
 	}
 
 	ensureStateFileContentIs := func(expected string) {
-		count := fakeOs.WriteStringToFileCallCount()
-		filename, contents := fakeOs.WriteStringToFileArgsForCall(count - 1)
+		count := fakeOs.WriteStringToFileAtomicallyCallCount()
+		filename, contents := fakeOs.WriteStringToFileAtomicallyArgsForCall(count - 1)
 		Expect(filename).To(Equal(stateFileLocation))
 		Expect(contents).To(Equal(expected))
 	}
 
 	ensureNoWriteToStateFile := func() {
-		count := fakeOs.WriteStringToFileCallCount()
+		count := fakeOs.WriteStringToFileAtomicallyCallCount()
 		Expect(count).To(Equal(0))
 	}
 
@@ -73,9 +98,26 @@ var _ = Describe("StartManager", func() {
 		return New(
 			fakeOs,
 			config.StartManager{
-				StateFileLocation: stateFileLocation,
-				BootstrapNode:     args.BootstrapNode,
-				ClusterIps:        clusterIps,
+				StateFileLocation:         stateFileLocation,
+				SyncStateFile:             args.SyncStateFile,
+				LastFailureFileLocation:   args.LastFailureFileLocation,
+				StartupCooldown:           args.StartupCooldown,
+				RefuseEvenClusterSize:     args.RefuseEvenClusterSize,
+				ZeroGrastateUUIDPolicy:    args.ZeroGrastateUUIDPolicy,
+				EmptyDatadirPolicy:        args.EmptyDatadirPolicy,
+				UnreadableStateFilePolicy: args.UnreadableStateFilePolicy,
+				WarmStandby:               args.WarmStandby,
+				GrastateFileLocation:      grastateFileLocation,
+				ReadyFileLocation:         args.ReadyFileLocation,
+				PreStartHealthCheckScript: args.PreStartHealthCheckScript,
+				MysqlPort:                 args.MysqlPort,
+				ShutdownQueueDrainTimeout: args.ShutdownQueueDrainTimeout,
+				ShutdownSignal:            args.ShutdownSignal,
+				ShutdownKillTimeout:       args.ShutdownKillTimeout,
+				BootstrapFailurePolicy:    args.BootstrapFailurePolicy,
+				MaxBootstrapAttempts:      args.MaxBootstrapAttempts,
+				BootstrapNode:             args.BootstrapNode,
+				ClusterIps:                clusterIps,
 			},
 			fakeDBHelper,
 			fakeUpgrader,
@@ -83,6 +125,9 @@ var _ = Describe("StartManager", func() {
 			testLogger,
 			fakeHealthChecker,
 			fakeserviceStatusServer,
+			history,
+			standby,
+			fakeStartupListener,
 		)
 	}
 
@@ -94,6 +139,9 @@ var _ = Describe("StartManager", func() {
 		fakeDBHelper = new(db_helperfakes.FakeDBHelper)
 		fakeHealthChecker = new(cluster_health_checkerfakes.FakeClusterHealthChecker)
 		fakeserviceStatusServer = new(start_managerfakes.FakeServiceStatus)
+		fakeStartupListener = new(start_managerfakes.FakeStartupListener)
+		history = transition_history.New(10)
+		standby = NewStandby()
 		fakeDBHelper.IsProcessRunningReturns(false)
 		fakeDBHelper.IsDatabaseReachableReturns(true)
 		startNodeReturn = "CLUSTERED"
@@ -124,6 +172,156 @@ var _ = Describe("StartManager", func() {
 			err := mgr.Execute(context.TODO())
 			Expect(err).To(MatchError(`some mysql error`))
 		})
+
+		It("records the startup and the exit in the transition history", func() {
+			mgr.Execute(context.TODO())
+
+			transitions := history.Transitions()
+			Expect(transitions).To(HaveLen(2))
+			Expect(transitions[0].From).To(Equal(node_starter.Clustered))
+			Expect(transitions[0].To).To(Equal(node_starter.Clustered))
+			Expect(transitions[0].Reason).To(Equal("startup"))
+			Expect(transitions[0].Outcome).To(Equal("ok"))
+			Expect(transitions[1].From).To(Equal(node_starter.Clustered))
+			Expect(transitions[1].To).To(Equal("STOPPED"))
+			Expect(transitions[1].Reason).To(Equal("mysqld-exited"))
+			Expect(transitions[1].Outcome).To(Equal("some mysql error"))
+		})
+	})
+
+	Context("startup listeners", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount: 3,
+			})
+		})
+
+		It("tells them once the node has started", func() {
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(fakeStartupListener.StartedCallCount()).To(Equal(1))
+		})
+
+		It("does not tell them when the node fails to start", func() {
+			startNodeReturnError = errors.New("join failed")
+
+			Expect(mgr.Execute(context.TODO())).NotTo(Succeed())
+			Expect(fakeStartupListener.StartedCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when mysqld stops because the node left the cluster", func() {
+		var stateFile string
+
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount: 3,
+			})
+
+			stateFile = node_starter.Clustered
+			fakeOs.FileExistsReturns(true)
+			fakeOs.ReadFileStub = func(string) (string, error) {
+				return stateFile, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			fakeStarter.StartNodeFromStateStub = func(string) (string, <-chan error, error) {
+				// The leave subcommand marks the state file, then stops mysqld
+				stateFile = node_starter.LeftCluster
+				mysqldErrChan <- errors.New("exit status 1")
+				return startNodeReturn, mysqldErrChan, nil
+			}
+		})
+
+		It("exits cleanly so the supervisor does not restart the node", func() {
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(testLogger.Buffer()).To(gbytes.Say("mysqld-stopped-after-leaving-cluster"))
+
+			transitions := history.Transitions()
+			Expect(transitions).To(HaveLen(2))
+			Expect(transitions[1].To).To(Equal("STOPPED"))
+			Expect(transitions[1].Reason).To(Equal("left-cluster"))
+		})
+
+		It("exits cleanly on the next start as well", func() {
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when WarmStandby is set on a first deploy", func() {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+			done   chan error
+		)
+
+		JustBeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount:   3,
+				WarmStandby: true,
+			})
+
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan error, 1)
+			go func(mgr StartManager, ctx context.Context, done chan<- error) {
+				done <- mgr.Execute(ctx)
+			}(mgr, ctx, done)
+			Eventually(standby.InStandby).Should(BeTrue())
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("records the standby and serves the status server without starting mysqld", func() {
+			ensureStateFileContentIs(node_starter.WarmStandby)
+			Expect(fakeserviceStatusServer.StartCallCount()).To(Equal(1))
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+		})
+
+		It("starts like a first deploy once promoted", func() {
+			Expect(standby.Promote()).To(Succeed())
+			Eventually(done).Should(Receive(BeNil()))
+
+			ensureStartNodeWithMode(node_starter.Clustered)
+			Expect(fakeserviceStatusServer.StartCallCount()).To(Equal(1))
+			Expect(testLogger.Buffer()).To(gbytes.Say("warm-standby-promoted"))
+
+			transitions := history.Transitions()
+			Expect(transitions[0].From).To(Equal(node_starter.WarmStandby))
+			Expect(transitions[0].To).To(Equal(node_starter.Clustered))
+			Expect(transitions[0].Reason).To(Equal("promoted"))
+		})
+
+		It("exits without starting mysqld on shutdown", func() {
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+			Expect(standby.InStandby()).To(BeFalse())
+		})
+	})
+
+	Context("when the state file records warm standby", func() {
+		BeforeEach(func() {
+			fakeOs.FileExistsReturns(true)
+			fakeOs.ReadFileReturns(node_starter.WarmStandby, nil)
+		})
+
+		It("starts as though promoted once WarmStandby is unset", func() {
+			mgr = createManager(managerArgs{NodeCount: 3})
+
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			ensureStartNodeWithMode(node_starter.Clustered)
+			Expect(testLogger.Buffer()).To(gbytes.Say("warm-standby-disabled-starting-as-promoted"))
+		})
+	})
+
+	It("cannot promote a node that is not in warm standby", func() {
+		Expect(standby.Promote()).To(MatchError(ErrNotInStandby))
 	})
 
 	Context("When a mysql process is already running", func() {
@@ -157,7 +355,7 @@ var _ = Describe("StartManager", func() {
 			}
 		})
 
-		ensureTimeoutOfMySQLIfExecuteHangs := func() {
+		ensureTimeoutOfMySQLIfExecuteHangs := func(mysqldErrChan chan<- error) {
 			time.Sleep(2 * time.Second)
 			mysqldErrChan <- errors.New("failed-to-cancel")
 		}
@@ -166,13 +364,32 @@ var _ = Describe("StartManager", func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			cancel()
 
-			go ensureTimeoutOfMySQLIfExecuteHangs()
+			go ensureTimeoutOfMySQLIfExecuteHangs(mysqldErrChan)
 
 			err := mgr.Execute(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
 			_, signal := fakeOs.KillCommandArgsForCall(0)
 			Expect(signal).To(Equal(syscall.SIGTERM))
+
+			Expect(fakeDBHelper.SetDesyncCallCount()).To(Equal(1))
+			Expect(fakeDBHelper.SetDesyncArgsForCall(0)).To(BeTrue())
+
+			transitions := history.Transitions()
+			Expect(transitions).To(HaveLen(2))
+			Expect(transitions[1].Reason).To(Equal("shutdown-requested"))
+			Expect(transitions[1].To).To(Equal("STOPPED"))
+		})
+
+		It("still stops mysqld when desyncing fails", func() {
+			fakeDBHelper.SetDesyncReturns(errors.New("not reachable"))
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			go ensureTimeoutOfMySQLIfExecuteHangs(mysqldErrChan)
+
+			Expect(mgr.Execute(ctx)).To(Succeed())
+			Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
 		})
 
 		It("should return an error if terminating mysql fails", func() {
@@ -180,11 +397,554 @@ var _ = Describe("StartManager", func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			cancel()
 
-			go ensureTimeoutOfMySQLIfExecuteHangs()
+			go ensureTimeoutOfMySQLIfExecuteHangs(mysqldErrChan)
 
 			err := mgr.Execute(ctx)
 			Expect(err).To(MatchError(`mysqld process does not exist`))
 		})
+
+		Context("when ShutdownSignal is SIGKILL", func() {
+			JustBeforeEach(func() {
+				mgr = createManager(managerArgs{
+					NodeCount:      3,
+					ShutdownSignal: config.ShutdownSignalKill,
+				})
+			})
+
+			It("kills mysqld immediately", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				go ensureTimeoutOfMySQLIfExecuteHangs(mysqldErrChan)
+
+				Expect(mgr.Execute(ctx)).To(Succeed())
+				Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+				_, signal := fakeOs.KillCommandArgsForCall(0)
+				Expect(signal).To(Equal(syscall.SIGKILL))
+			})
+		})
+
+		Context("when ShutdownKillTimeout is set", func() {
+			JustBeforeEach(func() {
+				mgr = createManager(managerArgs{
+					NodeCount:           3,
+					ShutdownKillTimeout: 1,
+				})
+			})
+
+			It("does not escalate when mysqld exits in time", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				Expect(mgr.Execute(ctx)).To(Succeed())
+				Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+				_, signal := fakeOs.KillCommandArgsForCall(0)
+				Expect(signal).To(Equal(syscall.SIGTERM))
+			})
+
+			It("sends SIGKILL once the timeout passes", func() {
+				fakeOs.KillCommandStub = func(cmd *exec.Cmd, signal os.Signal) error {
+					if signal == syscall.SIGKILL {
+						mysqldErrChan <- errors.New("signal: killed")
+					}
+					return nil
+				}
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				Expect(mgr.Execute(ctx)).To(MatchError("signal: killed"))
+				Expect(fakeOs.KillCommandCallCount()).To(Equal(2))
+				_, signal := fakeOs.KillCommandArgsForCall(0)
+				Expect(signal).To(Equal(syscall.SIGTERM))
+				_, signal = fakeOs.KillCommandArgsForCall(1)
+				Expect(signal).To(Equal(syscall.SIGKILL))
+				Expect(testLogger.Buffer()).To(gbytes.Say("mysqld-shutdown-timed-out-sending-sigkill"))
+			})
+		})
+
+		Context("when ShutdownQueueDrainTimeout is set", func() {
+			JustBeforeEach(func() {
+				mgr = createManager(managerArgs{
+					NodeCount:                 3,
+					ShutdownQueueDrainTimeout: 2,
+				})
+			})
+
+			It("waits for the replication queues to drain before stopping mysqld", func() {
+				fakeDBHelper.GetReplicationQueuesReturnsOnCall(0, db_helper.ReplicationQueues{Recv: 3, Send: 1}, nil)
+				fakeDBHelper.GetReplicationQueuesReturnsOnCall(1, db_helper.ReplicationQueues{}, nil)
+				fakeOs.SleepStub = func(time.Duration) {
+					Expect(fakeOs.KillCommandCallCount()).To(Equal(0))
+				}
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				go ensureTimeoutOfMySQLIfExecuteHangs(mysqldErrChan)
+
+				Expect(mgr.Execute(ctx)).To(Succeed())
+				Expect(fakeDBHelper.GetReplicationQueuesCallCount()).To(Equal(2))
+				Expect(fakeOs.SleepCallCount()).To(Equal(1))
+				Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+				Expect(testLogger.Buffer()).To(gbytes.Say("replication-queues-drained"))
+			})
+
+			It("stops mysqld anyway once the timeout passes", func() {
+				fakeDBHelper.GetReplicationQueuesReturns(db_helper.ReplicationQueues{Recv: 3}, nil)
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				go ensureTimeoutOfMySQLIfExecuteHangs(mysqldErrChan)
+
+				Expect(mgr.Execute(ctx)).To(Succeed())
+				Expect(fakeOs.SleepCallCount()).To(Equal(2))
+				Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+				Expect(testLogger.Buffer()).To(gbytes.Say("replication-queue-drain-timed-out"))
+			})
+		})
+	})
+
+	Context("when a ready file location is configured", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount:         3,
+				ReadyFileLocation: "/ready",
+			})
+		})
+
+		It("writes the ready file after startup and removes it when mysqld exits", func() {
+			err := mgr.Execute(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(2))
+			filename, _ := fakeOs.WriteStringToFileAtomicallyArgsForCall(1)
+			Expect(filename).To(Equal("/ready"))
+
+			Expect(fakeOs.RemoveFileCallCount()).To(Equal(2))
+			Expect(fakeOs.RemoveFileArgsForCall(1)).To(Equal("/ready"))
+		})
+
+		It("removes a stale ready file before starting", func() {
+			startCallsAtRemoval := []int{}
+			fakeOs.RemoveFileStub = func(string) error {
+				startCallsAtRemoval = append(startCallsAtRemoval, fakeStarter.StartNodeFromStateCallCount())
+				return nil
+			}
+
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(fakeOs.RemoveFileArgsForCall(0)).To(Equal("/ready"))
+			Expect(startCallsAtRemoval[0]).To(Equal(0))
+		})
+
+		It("does not write the ready file when startup fails", func() {
+			startNodeReturnError = errors.New("join failed")
+
+			Expect(mgr.Execute(context.TODO())).To(MatchError("join failed"))
+			Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(0))
+		})
+
+		Context("once mysqld is running", func() {
+			var originalInterval, originalTimeout time.Duration
+
+			BeforeEach(func() {
+				originalInterval, originalTimeout = ReadyCheckInterval, ReadyCheckPingTimeout
+				ReadyCheckInterval, ReadyCheckPingTimeout = 10*time.Millisecond, time.Second
+			})
+
+			AfterEach(func() {
+				ReadyCheckInterval, ReadyCheckPingTimeout = originalInterval, originalTimeout
+			})
+
+			JustBeforeEach(func() {
+				fakeStarter.StartNodeFromStateStub = func(string) (string, <-chan error, error) {
+					return startNodeReturn, mysqldErrChan, nil
+				}
+			})
+
+			readyFileRemovals := func() int {
+				removals := 0
+				for i := 0; i < fakeOs.RemoveFileCallCount(); i++ {
+					if fakeOs.RemoveFileArgsForCall(i) == "/ready" {
+						removals++
+					}
+				}
+				return removals
+			}
+
+			It("removes the ready file while mysqld does not answer pings and writes it again once it does", func() {
+				var pingResult atomic.Value
+				pingResult.Store(true)
+				fakeDBHelper.PingStub = func() bool { return pingResult.Load().(bool) }
+
+				done := make(chan error)
+				go func(mgr StartManager, done chan<- error) {
+					done <- mgr.Execute(context.Background())
+				}(mgr, done)
+
+				Eventually(fakeOs.WriteStringToFileAtomicallyCallCount).Should(Equal(2))
+				Consistently(readyFileRemovals, 50*time.Millisecond).Should(Equal(1))
+
+				pingResult.Store(false)
+				Eventually(readyFileRemovals).Should(Equal(2))
+				Eventually(testLogger.Buffer()).Should(gbytes.Say("mysqld-unresponsive-removing-ready-file"))
+
+				pingResult.Store(true)
+				Eventually(fakeOs.WriteStringToFileAtomicallyCallCount).Should(Equal(3))
+				filename, _ := fakeOs.WriteStringToFileAtomicallyArgsForCall(2)
+				Expect(filename).To(Equal("/ready"))
+
+				mysqldErrChan <- nil
+				Eventually(done).Should(Receive(BeNil()))
+			})
+
+			It("treats a ping that does not answer in time as a failure", func() {
+				ReadyCheckPingTimeout = 10 * time.Millisecond
+				hung := make(chan struct{})
+				defer close(hung)
+				fakeDBHelper.PingStub = func() bool {
+					<-hung
+					return true
+				}
+
+				done := make(chan error)
+				go func(mgr StartManager, done chan<- error) {
+					done <- mgr.Execute(context.Background())
+				}(mgr, done)
+
+				Eventually(readyFileRemovals).Should(Equal(2))
+				Expect(fakeDBHelper.PingCallCount()).To(Equal(1))
+
+				mysqldErrChan <- nil
+				Eventually(done).Should(Receive(BeNil()))
+			})
+		})
+	})
+
+	Context("when a pre-start health check script is configured", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount:                 3,
+				PreStartHealthCheckScript: "/check-disk",
+			})
+		})
+
+		It("runs the script before touching mysqld", func() {
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+
+			Expect(fakeOs.RunCommandCallCount()).To(Equal(1))
+			executable, args := fakeOs.RunCommandArgsForCall(0)
+			Expect(executable).To(Equal("/check-disk"))
+			Expect(args).To(BeEmpty())
+		})
+
+		It("aborts startup with the script output when the script fails", func() {
+			fakeOs.RunCommandReturns("/var/vcap/store is not mounted\n", errors.New("exit status 1"))
+
+			err := mgr.Execute(context.TODO())
+			Expect(err).To(MatchError(`pre-start health check "/check-disk" failed: exit status 1: /var/vcap/store is not mounted`))
+			Expect(fakeDBHelper.IsProcessRunningCallCount()).To(Equal(0))
+			Expect(fakeDBHelper.InitializeDatadirIfNeededCallCount()).To(Equal(0))
+			Expect(fakeUpgrader.NeedsUpgradeCallCount()).To(Equal(0))
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when bootstrapping fails", func() {
+		var bootstrapErr error
+
+		BeforeEach(func() {
+			bootstrapErr = &startup_errors.BootstrapError{Err: errors.New("mysqld exited")}
+		})
+
+		JustBeforeEach(func() {
+			fakeStarter.StartNodeFromStateStub = func(state string) (string, <-chan error, error) {
+				if fakeStarter.StartNodeFromStateCallCount() < 3 {
+					return "", nil, bootstrapErr
+				}
+				mysqldErrChan <- nil
+				return node_starter.SingleNode, mysqldErrChan, nil
+			}
+		})
+
+		Context("with the exit policy", func() {
+			BeforeEach(func() {
+				mgr = createManager(managerArgs{
+					NodeCount:              1,
+					BootstrapFailurePolicy: config.BootstrapFailurePolicyExit,
+					MaxBootstrapAttempts:   3,
+				})
+			})
+
+			It("returns the error without retrying", func() {
+				Expect(mgr.Execute(context.TODO())).To(MatchError("mysqld exited"))
+				Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("with the retry policy", func() {
+			BeforeEach(func() {
+				mgr = createManager(managerArgs{
+					NodeCount:              1,
+					BootstrapFailurePolicy: config.BootstrapFailurePolicyRetry,
+					MaxBootstrapAttempts:   3,
+				})
+			})
+
+			It("retries with a doubling delay until bootstrap succeeds", func() {
+				Expect(mgr.Execute(context.TODO())).To(Succeed())
+				Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(3))
+
+				Expect(fakeOs.SleepCallCount()).To(Equal(2))
+				Expect(fakeOs.SleepArgsForCall(0)).To(Equal(BootstrapRetryDelay))
+				Expect(fakeOs.SleepArgsForCall(1)).To(Equal(2 * BootstrapRetryDelay))
+
+				transitions := history.Transitions()
+				Expect(transitions[0].Outcome).To(Equal("mysqld exited"))
+				Expect(transitions[2].Outcome).To(Equal("ok"))
+			})
+
+			It("gives up after MaxBootstrapAttempts", func() {
+				mgr = createManager(managerArgs{
+					NodeCount:              1,
+					BootstrapFailurePolicy: config.BootstrapFailurePolicyRetry,
+					MaxBootstrapAttempts:   2,
+				})
+
+				Expect(mgr.Execute(context.TODO())).To(MatchError("mysqld exited"))
+				Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(2))
+			})
+
+			Context("when the failure is not a bootstrap failure", func() {
+				BeforeEach(func() {
+					bootstrapErr = &startup_errors.JoinError{Err: errors.New("join failed")}
+				})
+
+				It("does not retry", func() {
+					Expect(mgr.Execute(context.TODO())).To(MatchError("join failed"))
+					Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the bootstrap failure is fatal", func() {
+				BeforeEach(func() {
+					bootstrapErr = &startup_errors.BootstrapError{
+						Err: &startup_errors.FatalError{Err: errors.New("NeverBootstrap is set")},
+					}
+				})
+
+				It("does not retry", func() {
+					Expect(mgr.Execute(context.TODO())).To(MatchError("NeverBootstrap is set"))
+					Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(1))
+				})
+			})
+		})
+	})
+
+	Context("when the datadir is not writable", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount: 3,
+			})
+			fakeDBHelper.CheckDatadirWritableReturns(errors.New("datadir /datadir not writable"))
+		})
+
+		It("fails before touching mysqld", func() {
+			err := mgr.Execute(context.TODO())
+			Expect(err).To(MatchError("datadir /datadir not writable"))
+			Expect(fakeDBHelper.IsProcessRunningCallCount()).To(Equal(0))
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when MysqlPort is set", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount: 3,
+				MysqlPort: 3306,
+			})
+		})
+
+		It("checks the port after shutting down a leftover mysqld", func() {
+			fakeDBHelper.IsProcessRunningReturns(true)
+
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(fakeOs.IsPortInUseCallCount()).To(Equal(1))
+			Expect(fakeOs.IsPortInUseArgsForCall(0)).To(Equal(3306))
+			Expect(fakeDBHelper.StopMysqldCallCount()).To(Equal(1))
+		})
+
+		It("refuses to start mysqld when the port is in use", func() {
+			fakeOs.IsPortInUseReturns(true)
+
+			err := mgr.Execute(context.TODO())
+			Expect(err).To(MatchError("port 3306 is already in use; stop the process listening on it before starting mysqld"))
+			Expect(testLogger).To(gbytes.Say("mysql-port-in-use"))
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when SyncStateFile is set", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount:     3,
+				SyncStateFile: true,
+			})
+		})
+
+		It("writes the state file durably", func() {
+			err := mgr.Execute(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeOs.WriteStringToFileDurablyCallCount()).To(Equal(1))
+			filename, _ := fakeOs.WriteStringToFileDurablyArgsForCall(0)
+			Expect(filename).To(Equal(stateFileLocation))
+			ensureNoWriteToStateFile()
+		})
+
+		It("returns the error when the durable write fails", func() {
+			fakeOs.WriteStringToFileDurablyReturns(errors.New("verification failed"))
+
+			err := mgr.Execute(context.TODO())
+			Expect(err).To(MatchError("verification failed"))
+			Expect(fakeserviceStatusServer.StartCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the cluster has an even number of nodes", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount: 2,
+			})
+		})
+
+		It("warns about the quorum risk and starts anyway", func() {
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(testLogger.Buffer()).To(gbytes.Say("warning-even-cluster-size"))
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(1))
+		})
+
+		Context("and RefuseEvenClusterSize is set", func() {
+			BeforeEach(func() {
+				mgr = createManager(managerArgs{
+					NodeCount:             2,
+					RefuseEvenClusterSize: true,
+				})
+			})
+
+			It("refuses to start", func() {
+				err := mgr.Execute(context.TODO())
+				Expect(err).To(MatchError(ContainSubstring("Refusing to start a cluster of 2 nodes")))
+				Expect(fakeDBHelper.IsProcessRunningCallCount()).To(Equal(0))
+				Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("when the cluster has an odd number of nodes", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount:             3,
+				RefuseEvenClusterSize: true,
+			})
+		})
+
+		It("does not warn", func() {
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(testLogger.Buffer()).NotTo(gbytes.Say("even-cluster-size"))
+		})
+	})
+
+	Context("when a startup cooldown is configured", func() {
+		var lastFailure string
+
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount:               3,
+				LastFailureFileLocation: "/last-failure",
+				StartupCooldown:         60,
+			})
+			lastFailure = ""
+			fakeOs.FileExistsStub = func(filename string) bool {
+				return filename == "/last-failure" && lastFailure != ""
+			}
+			fakeOs.ReadFileStub = func(filename string) (string, error) {
+				return lastFailure, nil
+			}
+		})
+
+		It("waits out the rest of the cooldown after a recent failure", func() {
+			lastFailure = time.Now().Add(-59 * time.Second).UTC().Format(time.RFC3339)
+
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+
+			Expect(testLogger.Buffer()).To(gbytes.Say("startup-cooldown"))
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(1))
+		})
+
+		It("stops waiting and starts nothing when shutdown is requested during the cooldown", func() {
+			lastFailure = time.Now().Add(-20 * time.Second).UTC().Format(time.RFC3339)
+			ctx, cancel := context.WithCancel(context.Background())
+
+			done := make(chan error, 1)
+			go func() { done <- mgr.Execute(ctx) }()
+
+			Eventually(testLogger.Buffer()).Should(gbytes.Say("startup-cooldown"))
+			Consistently(done).ShouldNot(Receive())
+
+			cancel()
+
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(testLogger.Buffer()).To(gbytes.Say("shutdown-detected"))
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+			Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(0))
+		})
+
+		It("does not wait when the last failure is older than the cooldown", func() {
+			lastFailure = time.Now().Add(-2 * time.Minute).UTC().Format(time.RFC3339)
+
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(testLogger.Buffer()).NotTo(gbytes.Say("startup-cooldown"))
+		})
+
+		It("does not wait when there has been no failure", func() {
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+			Expect(testLogger.Buffer()).NotTo(gbytes.Say("startup-cooldown"))
+		})
+
+		It("records the time of a failed start", func() {
+			fakeDBHelper.CheckDatadirWritableReturns(errors.New("datadir /datadir not writable"))
+
+			Expect(mgr.Execute(context.TODO())).NotTo(Succeed())
+
+			count := fakeOs.WriteStringToFileAtomicallyCallCount()
+			filename, contents := fakeOs.WriteStringToFileAtomicallyArgsForCall(count - 1)
+			Expect(filename).To(Equal("/last-failure"))
+			recorded, err := time.Parse(time.RFC3339, contents)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorded).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		It("clears the last failure once startup completes", func() {
+			Expect(mgr.Execute(context.TODO())).To(Succeed())
+
+			Expect(fakeOs.RemoveFileCallCount()).To(Equal(1))
+			Expect(fakeOs.RemoveFileArgsForCall(0)).To(Equal("/last-failure"))
+		})
+	})
+
+	Context("when initializing the datadir fails", func() {
+		BeforeEach(func() {
+			mgr = createManager(managerArgs{
+				NodeCount: 3,
+			})
+			fakeDBHelper.InitializeDatadirIfNeededReturns(errors.New("mysql_install_db failed"))
+		})
+
+		It("forwards the error before upgrading or starting", func() {
+			err := mgr.Execute(context.TODO())
+			Expect(err).To(MatchError("mysql_install_db failed"))
+			Expect(fakeUpgrader.NeedsUpgradeCallCount()).To(Equal(0))
+			Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+		})
 	})
 
 	Describe("Upgrading the cluster", func() {
@@ -221,6 +981,117 @@ var _ = Describe("StartManager", func() {
 					Expect(fakeserviceStatusServer.StartCallCount()).To(Equal(0))
 				})
 			})
+
+			Context("ordering", func() {
+				var calls []string
+
+				BeforeEach(func() {
+					mgr = createManager(managerArgs{
+						NodeCount: 3,
+					})
+
+					calls = nil
+					fakeUpgrader.NeedsUpgradeReturns(true, nil)
+					fakeUpgrader.UpgradeStub = func() error {
+						calls = append(calls, "upgrade")
+						return nil
+					}
+					fakeUpgrader.UpgradeRunningNodeStub = func() error {
+						calls = append(calls, "upgrade-running-node")
+						return nil
+					}
+					fakeDBHelper.SetDesyncStub = func(desync bool) error {
+						calls = append(calls, fmt.Sprintf("desync=%t", desync))
+						return nil
+					}
+				})
+
+				JustBeforeEach(func() {
+					fakeStarter.StartNodeFromStateStub = func(string) (string, <-chan error, error) {
+						calls = append(calls, "start")
+						mysqldErrChan <- nil
+						return startNodeReturn, mysqldErrChan, nil
+					}
+				})
+
+				It("upgrades a stand-alone mysqld before starting the node by default", func() {
+					Expect(mgr.Execute(context.TODO())).To(Succeed())
+					Expect(calls).To(Equal([]string{"upgrade", "start"}))
+				})
+
+				It("upgrades the running node once it has started when configured to, desynced from the cluster", func() {
+					fakeUpgrader.UpgradesRunningNodeReturns(true)
+
+					Expect(mgr.Execute(context.TODO())).To(Succeed())
+					Expect(calls).To(Equal([]string{"start", "desync=true", "upgrade-running-node", "desync=false"}))
+				})
+
+				It("does not desync a single node for the upgrade", func() {
+					fakeUpgrader.UpgradesRunningNodeReturns(true)
+					startNodeReturn = node_starter.SingleNode
+
+					Expect(mgr.Execute(context.TODO())).To(Succeed())
+					Expect(calls).To(Equal([]string{"start", "upgrade-running-node"}))
+				})
+
+				It("resyncs the node when upgrading it fails", func() {
+					fakeUpgrader.UpgradesRunningNodeReturns(true)
+					fakeUpgrader.UpgradeRunningNodeStub = func() error {
+						calls = append(calls, "upgrade-running-node")
+						return errors.New("mysql_upgrade failed")
+					}
+
+					Expect(mgr.Execute(context.TODO())).To(MatchError("mysql_upgrade failed"))
+					Expect(calls).To(Equal([]string{"start", "desync=true", "upgrade-running-node", "desync=false"}))
+				})
+
+				It("does not upgrade a node it cannot desync", func() {
+					fakeUpgrader.UpgradesRunningNodeReturns(true)
+					fakeDBHelper.SetDesyncStub = nil
+					fakeDBHelper.SetDesyncReturns(errors.New("some error"))
+
+					Expect(mgr.Execute(context.TODO())).To(MatchError("Error desyncing node for upgrade: some error"))
+					Expect(calls).To(Equal([]string{"start"}))
+				})
+
+				It("stops mysqld when upgrading the running node fails", func() {
+					fakeUpgrader.UpgradesRunningNodeReturns(true)
+					fakeDBHelper.SetDesyncStub = func(desync bool) error {
+						if !desync {
+							return errors.New("resync failed")
+						}
+						return nil
+					}
+
+					Expect(mgr.Execute(context.TODO())).To(MatchError("Error resyncing node after upgrade: resync failed"))
+
+					Expect(fakeOs.KillCommandCallCount()).To(Equal(1))
+					_, signal := fakeOs.KillCommandArgsForCall(0)
+					Expect(signal).To(Equal(syscall.SIGTERM))
+
+					transitions := history.Transitions()
+					Expect(transitions).To(HaveLen(2))
+					Expect(transitions[1].To).To(Equal("STOPPED"))
+					Expect(transitions[1].Reason).To(Equal("upgrade-failed"))
+				})
+
+				It("does not stop mysqld when the upgrade succeeds", func() {
+					fakeUpgrader.UpgradesRunningNodeReturns(true)
+
+					Expect(mgr.Execute(context.TODO())).To(Succeed())
+					Expect(fakeOs.KillCommandCallCount()).To(BeZero())
+				})
+
+				It("does not start the status server when upgrading the running node fails", func() {
+					fakeUpgrader.UpgradesRunningNodeReturns(true)
+					fakeUpgrader.UpgradeRunningNodeReturns(errors.New("mysql_upgrade failed"))
+					fakeUpgrader.UpgradeRunningNodeStub = nil
+
+					err := mgr.Execute(context.TODO())
+					Expect(err).To(MatchError("mysql_upgrade failed"))
+					Expect(fakeserviceStatusServer.StartCallCount()).To(Equal(0))
+				})
+			})
 		})
 	})
 
@@ -325,6 +1196,113 @@ var _ = Describe("StartManager", func() {
 				})
 			})
 
+			Context("And the datadir has no grastate.dat", func() {
+				var policy string
+
+				BeforeEach(func() {
+					policy = config.EmptyDatadirPolicyJoin
+					fakeOs.FileExistsStub = func(filename string) bool {
+						return filename != grastateFileLocation
+					}
+					fakeOs.ReadFileReturns(node_starter.NeedsBootstrap, nil)
+				})
+
+				JustBeforeEach(func() {
+					mgr = createManager(managerArgs{
+						NodeCount:          3,
+						BootstrapNode:      true,
+						EmptyDatadirPolicy: policy,
+					})
+				})
+
+				It("joins instead of bootstrapping and logs the mismatch", func() {
+					err := mgr.Execute(context.TODO())
+					Expect(err).ToNot(HaveOccurred())
+					ensureStartNodeWithMode("CLUSTERED")
+					Expect(testLogger.Buffer()).To(gbytes.Say("state-file-datadir-mismatch"))
+					Expect(testLogger.Buffer()).To(gbytes.Say(node_starter.NeedsBootstrap))
+				})
+
+				Context("when the policy is ignore", func() {
+					BeforeEach(func() {
+						policy = config.EmptyDatadirPolicyIgnore
+					})
+
+					It("follows the state file", func() {
+						err := mgr.Execute(context.TODO())
+						Expect(err).ToNot(HaveOccurred())
+						ensureStartNodeWithMode("NEEDS_BOOTSTRAP")
+					})
+				})
+			})
+
+			Context("And grastate.dat has an all-zero UUID", func() {
+				var bootstrapNode bool
+
+				BeforeEach(func() {
+					bootstrapNode = false
+					fakeOs.ReadFileStub = func(filename string) (string, error) {
+						if filename == grastateFileLocation {
+							return "# GALERA saved state\nversion: 2.1\nuuid:    00000000-0000-0000-0000-000000000000\nseqno:   -1\nsafe_to_bootstrap: 0\n", nil
+						}
+						return node_starter.SingleNode, nil
+					}
+				})
+
+				JustBeforeEach(func() {
+					mgr = createManager(managerArgs{
+						NodeCount:              3,
+						BootstrapNode:          bootstrapNode,
+						ZeroGrastateUUIDPolicy: config.ZeroGrastateUUIDPolicyFreshStart,
+					})
+				})
+
+				It("joins the cluster on a node other than the bootstrap node", func() {
+					err := mgr.Execute(context.TODO())
+					Expect(err).ToNot(HaveOccurred())
+					ensureStartNodeWithMode("CLUSTERED")
+					Expect(testLogger.Buffer()).To(gbytes.Say("grastate-uuid-all-zeros"))
+				})
+
+				Context("on the bootstrap node", func() {
+					BeforeEach(func() {
+						bootstrapNode = true
+					})
+
+					It("starts as a fresh deploy", func() {
+						err := mgr.Execute(context.TODO())
+						Expect(err).ToNot(HaveOccurred())
+						ensureStartNodeWithMode("NEEDS_BOOTSTRAP")
+					})
+				})
+
+				It("follows the state file when the policy is ignore", func() {
+					mgr = createManager(managerArgs{
+						NodeCount:              3,
+						ZeroGrastateUUIDPolicy: config.ZeroGrastateUUIDPolicyIgnore,
+					})
+
+					err := mgr.Execute(context.TODO())
+					Expect(err).ToNot(HaveOccurred())
+					ensureStartNodeWithMode("NEEDS_BOOTSTRAP")
+				})
+			})
+
+			Context("And reads '"+node_starter.LeftCluster+"'", func() {
+				BeforeEach(func() {
+					fakeOs.ReadFileReturns(node_starter.LeftCluster, nil)
+				})
+
+				It("exits cleanly without rejoining the cluster", func() {
+					err := mgr.Execute(context.TODO())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeStarter.StartNodeFromStateCallCount()).To(Equal(0))
+					Expect(fakeDBHelper.InitializeDatadirIfNeededCallCount()).To(Equal(0))
+					ensureNoWriteToStateFile()
+					Expect(testLogger.Buffer()).To(gbytes.Say("node-left-cluster-not-starting"))
+				})
+			})
+
 			Context("And reads '"+node_starter.Clustered+"'", func() {
 				BeforeEach(func() {
 					fakeOs.ReadFileReturns(node_starter.Clustered, nil)
@@ -371,7 +1349,7 @@ var _ = Describe("StartManager", func() {
 
 				Context("And writing the statefile fails", func() {
 					BeforeEach(func() {
-						fakeOs.WriteStringToFileReturns(errors.New("writing failed"))
+						fakeOs.WriteStringToFileAtomicallyReturns(errors.New("writing failed"))
 					})
 
 					It("returns the error", func() {
@@ -423,6 +1401,30 @@ var _ = Describe("StartManager", func() {
 					ensureNoWriteToStateFile()
 					Expect(fakeserviceStatusServer.StartCallCount()).To(Equal(0))
 				})
+
+				Context("when UnreadableStateFilePolicy is fresh-start", func() {
+					It("joins like a first deploy on a node other than the bootstrap node", func() {
+						mgr = createManager(managerArgs{
+							NodeCount:                 3,
+							UnreadableStateFilePolicy: config.UnreadableStateFilePolicyFreshStart,
+						})
+
+						Expect(mgr.Execute(context.TODO())).To(Succeed())
+						ensureStartNodeWithMode(node_starter.Clustered)
+						Expect(testLogger.Buffer()).To(gbytes.Say("warning-state-file-unreadable-starting-fresh"))
+					})
+
+					It("starts as a fresh deploy on the bootstrap node", func() {
+						mgr = createManager(managerArgs{
+							NodeCount:                 3,
+							BootstrapNode:             true,
+							UnreadableStateFilePolicy: config.UnreadableStateFilePolicyFreshStart,
+						})
+
+						Expect(mgr.Execute(context.TODO())).To(Succeed())
+						ensureStartNodeWithMode(node_starter.NeedsBootstrap)
+					})
+				})
 			})
 		})
 	})
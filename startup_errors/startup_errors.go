@@ -0,0 +1,45 @@
+// Package startup_errors defines the error types returned while starting a
+// node, so callers can tell the failure classes apart with errors.As.
+package startup_errors
+
+// TimeoutError is returned when mysqld does not become reachable in time.
+type TimeoutError struct {
+	Err error
+}
+
+func (e *TimeoutError) Error() string { return e.Err.Error() }
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// BootstrapError is returned when bootstrapping a new cluster fails.
+type BootstrapError struct {
+	Err error
+}
+
+func (e *BootstrapError) Error() string { return e.Err.Error() }
+func (e *BootstrapError) Unwrap() error { return e.Err }
+
+// JoinError is returned when joining an existing cluster fails.
+type JoinError struct {
+	Err error
+}
+
+func (e *JoinError) Error() string { return e.Err.Error() }
+func (e *JoinError) Unwrap() error { return e.Err }
+
+// SeedError is returned when seeding databases or users fails.
+type SeedError struct {
+	Err error
+}
+
+func (e *SeedError) Error() string { return e.Err.Error() }
+func (e *SeedError) Unwrap() error { return e.Err }
+
+// FatalError marks a failure that will recur on every attempt until an
+// operator intervenes, such as a disk error in the mysqld error log, so that
+// it is not retried. It is wrapped in the class of the step that failed.
+type FatalError struct {
+	Err error
+}
+
+func (e *FatalError) Error() string { return e.Err.Error() }
+func (e *FatalError) Unwrap() error { return e.Err }
@@ -0,0 +1,78 @@
+package transition_history
+
+import (
+	"sync"
+	"time"
+)
+
+type Transition struct {
+	Timestamp time.Time `json:"timestamp"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	Outcome   string    `json:"outcome"`
+}
+
+// History keeps the most recent node state transitions in a fixed size ring
+// buffer so that repeated bootstrap or join attempts can be diagnosed without
+// trawling through logs. A nil *History discards everything recorded.
+type History struct {
+	mu          sync.Mutex
+	transitions []Transition
+	next        int
+	full        bool
+}
+
+func New(size int) *History {
+	if size <= 0 {
+		return nil
+	}
+
+	return &History{
+		transitions: make([]Transition, size),
+	}
+}
+
+// Record stores a transition whose outcome is "ok", or the error message when
+// err is non-nil.
+func (h *History) Record(from, to, reason string, err error) {
+	if h == nil {
+		return
+	}
+
+	outcome := "ok"
+	if err != nil {
+		outcome = err.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.transitions[h.next] = Transition{
+		Timestamp: time.Now().UTC(),
+		From:      from,
+		To:        to,
+		Reason:    reason,
+		Outcome:   outcome,
+	}
+	h.next = (h.next + 1) % len(h.transitions)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Transitions returns the recorded transitions, oldest first.
+func (h *History) Transitions() []Transition {
+	if h == nil {
+		return []Transition{}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]Transition{}, h.transitions[:h.next]...)
+	}
+
+	return append(append([]Transition{}, h.transitions[h.next:]...), h.transitions[:h.next]...)
+}
@@ -0,0 +1,14 @@
+package transition_history_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTransitionHistory(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Transition History Suite")
+}
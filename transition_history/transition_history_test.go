@@ -0,0 +1,54 @@
+package transition_history_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/galera-init/transition_history"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("History", func() {
+	reasons := func(h *transition_history.History) []string {
+		result := []string{}
+		for _, transition := range h.Transitions() {
+			result = append(result, transition.Reason)
+		}
+		return result
+	}
+
+	It("records transitions oldest first", func() {
+		h := transition_history.New(3)
+		h.Record("CLUSTERED", "CLUSTERED", "startup", nil)
+		h.Record("CLUSTERED", "STOPPED", "mysqld-exited", errors.New("exit status 1"))
+
+		transitions := h.Transitions()
+		Expect(transitions).To(HaveLen(2))
+		Expect(transitions[0].From).To(Equal("CLUSTERED"))
+		Expect(transitions[0].To).To(Equal("CLUSTERED"))
+		Expect(transitions[0].Reason).To(Equal("startup"))
+		Expect(transitions[0].Outcome).To(Equal("ok"))
+		Expect(transitions[0].Timestamp).NotTo(BeZero())
+		Expect(transitions[1].Outcome).To(Equal("exit status 1"))
+	})
+
+	It("keeps only the most recent transitions once full", func() {
+		h := transition_history.New(2)
+		h.Record("", "", "first", nil)
+		h.Record("", "", "second", nil)
+		h.Record("", "", "third", nil)
+
+		Expect(reasons(h)).To(Equal([]string{"second", "third"}))
+
+		h.Record("", "", "fourth", nil)
+		Expect(reasons(h)).To(Equal([]string{"third", "fourth"}))
+	})
+
+	It("records nothing when the size is not positive", func() {
+		h := transition_history.New(0)
+		h.Record("", "", "first", nil)
+
+		Expect(h.Transitions()).To(BeEmpty())
+	})
+})
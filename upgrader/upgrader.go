@@ -11,11 +11,14 @@ import (
 	"github.com/cloudfoundry/galera-init/config"
 	"github.com/cloudfoundry/galera-init/db_helper"
 	"github.com/cloudfoundry/galera-init/os_helper"
+	"github.com/cloudfoundry/galera-init/startup_errors"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Upgrader
 type Upgrader interface {
 	Upgrade() error
+	UpgradeRunningNode() error
+	UpgradesRunningNode() bool
 	NeedsUpgrade() (bool, error)
 }
 
@@ -29,6 +32,11 @@ type upgrader struct {
 var (
 	DBReachablePollingAttempts = 30
 	DBReachablePollingDelay    = 10 * time.Second
+	UpgradeRetryDelay          = 5 * time.Second
+	VersionFileWriteRetryDelay = 1 * time.Second
+
+	retriableUpgradeErrors = regexp.MustCompile(
+		"Can't connect to (local )?MySQL server|Lost connection to MySQL server|Connection refused")
 )
 
 func NewUpgrader(
@@ -58,8 +66,110 @@ func (u upgrader) Upgrade() error {
 		return err
 	}
 
+	err = u.runUpgradeSteps()
+
+	u.logger.Info("stopping-upgrade-mysqld")
+	u.stopStandaloneDatabaseSynchronously()
+
+	if mysqldErr := <-mysqldExitChan; mysqldErr != nil {
+		return errors.Wrap(mysqldErr, `mysqld failed during upgrade`)
+	}
+
+	u.logger.Info("mysqld-stopped")
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpgradeRunningNode runs the upgrade against the mysqld galera-init has
+// already started and waited for, instead of a stand-alone one.
+func (u upgrader) UpgradeRunningNode() error {
+	u.logger.Info("upgrading-running-node")
+	return u.runUpgradeSteps()
+}
+
+// UpgradesRunningNode reports whether Upgrader.Timing asks for the upgrade to
+// run after the node has started rather than before.
+func (u upgrader) UpgradesRunningNode() bool {
+	return u.config.Timing == config.UpgradeTimingPostStart
+}
+
+func (u upgrader) runUpgradeSteps() error {
+	err := u.runUpgradeHook("pre-upgrade-script", u.config.PreUpgradeScript)
+	if err == nil {
+		err = u.runMysqlUpgrade()
+	}
+	if err == nil {
+		err = u.runUpgradeHook("post-upgrade-script", u.config.PostUpgradeScript)
+	}
+	if err == nil {
+		u.stampUpgradedVersion()
+	}
+	return err
+}
+
+// stampUpgradedVersion makes sure LastUpgradedVersionFile records the package
+// version once the upgrade has succeeded, so NeedsUpgrade does not ask for the
+// same upgrade again on the next start. MySQL upgrade normally writes it
+// itself. Failing to write it is logged rather than returned: the node is
+// upgraded either way, and the cost is a redundant upgrade next time.
+func (u upgrader) stampUpgradedVersion() {
+	packageVersion, err := u.osHelper.ReadFile(u.config.PackageVersionFile)
+	if err != nil {
+		u.logger.Error("upgrade-version-stamp-failed", err, lager.Data{
+			"packageVersionFile": u.config.PackageVersionFile,
+		})
+		return
+	}
+
+	if u.osHelper.FileExists(u.config.LastUpgradedVersionFile) {
+		existingVersion, err := u.osHelper.ReadFile(u.config.LastUpgradedVersionFile)
+		if err == nil && strings.TrimSpace(existingVersion) == strings.TrimSpace(packageVersion) {
+			return
+		}
+	}
+
+	attempts := u.config.VersionFileWriteAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := VersionFileWriteRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		err = u.osHelper.WriteStringToFileAtomically(u.config.LastUpgradedVersionFile, strings.TrimSpace(packageVersion))
+		if err == nil {
+			u.logger.Info("upgrade-version-stamped", lager.Data{
+				"lastUpgradedVersionFile": u.config.LastUpgradedVersionFile,
+				"version":                 strings.TrimSpace(packageVersion),
+			})
+			return
+		}
+
+		if attempt >= attempts {
+			u.logger.Error("upgrade-version-stamp-failed", err, lager.Data{
+				"lastUpgradedVersionFile": u.config.LastUpgradedVersionFile,
+				"attempts":                attempts,
+				"consequence":             "the upgrade will run again on the next start",
+			})
+			return
+		}
+
+		u.logger.Info("upgrade-version-stamp-retrying", lager.Data{
+			"attempt": attempt,
+			"delay":   delay.String(),
+			"err":     err.Error(),
+		})
+		u.osHelper.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (u upgrader) runMysqlUpgrade() error {
 	u.logger.Info("mysql-upgrade-starting")
-	output, upgrade_err := u.dbHelper.Upgrade()
+	output, upgrade_err := u.runUpgradeWithRetries()
 
 	if upgrade_err != nil {
 		acceptableErrorsCompiled, _ := regexp.Compile(
@@ -75,7 +185,7 @@ func (u upgrader) Upgrade() error {
 				"output string does not match acceptable errors - aborting startup.",
 				lager.Data{"upgradeErr": upgrade_err, "upgradeOutput": output},
 			)
-			err = upgrade_err
+			return upgrade_err
 		}
 	} else {
 		u.logger.Info("mysql-upgrade-complete", lager.Data{
@@ -83,22 +193,46 @@ func (u upgrader) Upgrade() error {
 		})
 	}
 
-	u.logger.Info("stopping-upgrade-mysqld")
-	u.stopStandaloneDatabaseSynchronously()
+	return nil
+}
 
-	if mysqldErr := <-mysqldExitChan; mysqldErr != nil {
-		return errors.Wrap(mysqldErr, `mysqld failed during upgrade`)
+func (u upgrader) runUpgradeHook(name string, script string) error {
+	if script == "" {
+		return nil
 	}
 
-	u.logger.Info("mysqld-stopped")
-
+	u.logger.Info(name+"-starting", lager.Data{"script": script})
+	output, err := u.osHelper.RunCommand(script)
 	if err != nil {
-		return err
+		u.logger.Error(name+"-failed", err, lager.Data{"output": output})
+		return errors.Wrapf(err, "%s %q failed", name, script)
 	}
 
+	u.logger.Info(name+"-complete", lager.Data{"output": output})
 	return nil
 }
 
+func (u upgrader) runUpgradeWithRetries() (string, error) {
+	delay := UpgradeRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		output, err := u.dbHelper.Upgrade()
+		if err == nil || attempt > u.config.UpgradeMaxRetries || !retriableUpgradeErrors.MatchString(output) {
+			return output, err
+		}
+
+		u.logger.Info("mysql-upgrade-retrying", lager.Data{
+			"attempt":       attempt,
+			"maxRetries":    u.config.UpgradeMaxRetries,
+			"delay":         delay.String(),
+			"upgradeErr":    err,
+			"upgradeOutput": output,
+		})
+		u.osHelper.Sleep(delay)
+		delay *= 2
+	}
+}
+
 func (u upgrader) waitUntilMySQLReachable() error {
 	u.logger.Info("wait-for-upgrade-mysqld", lager.Data{
 		"state": "starting",
@@ -112,7 +246,7 @@ func (u upgrader) waitUntilMySQLReachable() error {
 			return nil
 		}
 
-		u.logger.Info("wait-for-upgrade-mysqld", lager.Data{
+		u.logger.Debug("wait-for-upgrade-mysqld", lager.Data{
 			"state": "polling",
 		})
 		u.osHelper.Sleep(DBReachablePollingDelay)
@@ -121,7 +255,9 @@ func (u upgrader) waitUntilMySQLReachable() error {
 	u.logger.Info("wait-for-upgrade-mysqld", lager.Data{
 		"state": "timeout",
 	})
-	return errors.New("Database is not reachable after 30 tries.")
+	return &startup_errors.TimeoutError{
+		Err: errors.New("Database is not reachable after 30 tries."),
+	}
 }
 
 func (u upgrader) stopStandaloneDatabaseSynchronously() {
@@ -3,14 +3,17 @@ package upgrader_test
 import (
 	"errors"
 	"os/exec"
+	"time"
 
 	"code.cloudfoundry.org/lager/lagertest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 
 	"github.com/cloudfoundry/galera-init/config"
 	"github.com/cloudfoundry/galera-init/db_helper/db_helperfakes"
 	"github.com/cloudfoundry/galera-init/os_helper/os_helperfakes"
+	"github.com/cloudfoundry/galera-init/startup_errors"
 	. "github.com/cloudfoundry/galera-init/upgrader"
 )
 
@@ -33,6 +36,7 @@ var _ = Describe("Upgrader", func() {
 			config.Upgrader{
 				PackageVersionFile:      packageVersionFile,
 				LastUpgradedVersionFile: lastUpgradedVersionFile,
+				UpgradeMaxRetries:       2,
 			},
 			testLogger,
 			fakeDbHelper,
@@ -83,9 +87,12 @@ var _ = Describe("Upgrader", func() {
 				fakeDbHelper.IsDatabaseReachableReturns(false)
 			})
 
-			It("returns an error", func() {
+			It("returns a timeout error", func() {
 				err := upgrader.Upgrade()
 				Expect(err).To(MatchError(`Database is not reachable after 30 tries.`))
+
+				var timeoutErr *startup_errors.TimeoutError
+				Expect(errors.As(err, &timeoutErr)).To(BeTrue())
 			})
 		})
 
@@ -116,6 +123,93 @@ var _ = Describe("Upgrader", func() {
 			})
 		})
 
+		Context("when the upgrade script fails to connect to mysqld", func() {
+			It("retries with backoff until the upgrade succeeds", func() {
+				fakeDbHelper.UpgradeReturnsOnCall(0, "ERROR 2002 (HY000): Can't connect to local MySQL server", errors.New("exited 1"))
+				fakeDbHelper.UpgradeReturnsOnCall(1, "ERROR 2013 (HY000): Lost connection to MySQL server", errors.New("exited 1"))
+				fakeDbHelper.UpgradeReturnsOnCall(2, "", nil)
+
+				err := upgrader.Upgrade()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeDbHelper.UpgradeCallCount()).To(Equal(3))
+
+				var sleeps []time.Duration
+				for i := 0; i < fakeOs.SleepCallCount(); i++ {
+					sleeps = append(sleeps, fakeOs.SleepArgsForCall(i))
+				}
+				Expect(sleeps).To(ContainElement(UpgradeRetryDelay))
+				Expect(sleeps).To(ContainElement(2 * UpgradeRetryDelay))
+			})
+
+			It("gives up after the configured number of retries", func() {
+				fakeDbHelper.UpgradeReturns("ERROR 2002 (HY000): Can't connect to local MySQL server", errors.New("exited 1"))
+
+				err := upgrader.Upgrade()
+				Expect(err).To(MatchError("exited 1"))
+				Expect(fakeDbHelper.UpgradeCallCount()).To(Equal(3))
+				Expect(fakeDbHelper.StopMysqldCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the upgrade script fails with a fatal error", func() {
+			It("does not retry", func() {
+				fakeDbHelper.UpgradeReturns("Table 'mysql.user' is corrupt", errors.New("exited 1"))
+
+				err := upgrader.Upgrade()
+				Expect(err).To(MatchError("exited 1"))
+				Expect(fakeDbHelper.UpgradeCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when pre and post upgrade scripts are configured", func() {
+			BeforeEach(func() {
+				upgrader = NewUpgrader(
+					fakeOs,
+					config.Upgrader{
+						PackageVersionFile:      packageVersionFile,
+						LastUpgradedVersionFile: lastUpgradedVersionFile,
+						PreUpgradeScript:        "/pre-upgrade",
+						PostUpgradeScript:       "/post-upgrade",
+					},
+					testLogger,
+					fakeDbHelper,
+				)
+			})
+
+			It("runs the scripts around the upgrade", func() {
+				var calls []string
+				fakeOs.RunCommandStub = func(executable string, args ...string) (string, error) {
+					calls = append(calls, executable)
+					return "", nil
+				}
+				fakeDbHelper.UpgradeStub = func() (string, error) {
+					calls = append(calls, "mysql_upgrade")
+					return "", nil
+				}
+
+				Expect(upgrader.Upgrade()).To(Succeed())
+				Expect(calls).To(Equal([]string{"/pre-upgrade", "mysql_upgrade", "/post-upgrade"}))
+			})
+
+			It("fails the upgrade without running mysql_upgrade when the pre upgrade script fails", func() {
+				fakeOs.RunCommandReturns("some output", errors.New("exit status 1"))
+
+				err := upgrader.Upgrade()
+				Expect(err).To(MatchError(`pre-upgrade-script "/pre-upgrade" failed: exit status 1`))
+				Expect(fakeDbHelper.UpgradeCallCount()).To(Equal(0))
+				Expect(fakeDbHelper.StopMysqldCallCount()).To(Equal(1))
+			})
+
+			It("fails the upgrade when the post upgrade script fails", func() {
+				fakeOs.RunCommandReturnsOnCall(1, "some output", errors.New("exit status 1"))
+
+				err := upgrader.Upgrade()
+				Expect(err).To(MatchError(`post-upgrade-script "/post-upgrade" failed: exit status 1`))
+				Expect(fakeDbHelper.UpgradeCallCount()).To(Equal(1))
+				Expect(fakeDbHelper.StopMysqldCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("when mysqld fails on shutdown", func() {
 			BeforeEach(func() {
 				fakeOs.WaitForCommandStub = func(cmd *exec.Cmd) chan error {
@@ -132,6 +226,108 @@ var _ = Describe("Upgrader", func() {
 		})
 	})
 
+	Describe("stamping the upgraded version", func() {
+		BeforeEach(func() {
+			fakeDbHelper.IsDatabaseReachableReturns(true)
+			fakeOs.ReadFileStub = func(path string) (string, error) {
+				if path == packageVersionFile {
+					return "10.5.9\n", nil
+				}
+				return "10.4.1\n", nil
+			}
+			fakeOs.FileExistsReturns(true)
+
+			upgrader = NewUpgrader(
+				fakeOs,
+				config.Upgrader{
+					PackageVersionFile:       packageVersionFile,
+					LastUpgradedVersionFile:  lastUpgradedVersionFile,
+					VersionFileWriteAttempts: 3,
+				},
+				testLogger,
+				fakeDbHelper,
+			)
+		})
+
+		It("writes the package version when MySQL upgrade left the file stale", func() {
+			Expect(upgrader.Upgrade()).To(Succeed())
+
+			Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(1))
+			path, contents := fakeOs.WriteStringToFileAtomicallyArgsForCall(0)
+			Expect(path).To(Equal(lastUpgradedVersionFile))
+			Expect(contents).To(Equal("10.5.9"))
+		})
+
+		It("leaves the file alone when MySQL upgrade already wrote the package version", func() {
+			fakeOs.ReadFileReturns("10.5.9", nil)
+			fakeOs.ReadFileStub = nil
+
+			Expect(upgrader.Upgrade()).To(Succeed())
+			Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(0))
+		})
+
+		It("retries a failed write", func() {
+			fakeOs.WriteStringToFileAtomicallyReturnsOnCall(0, errors.New("disk busy"))
+
+			Expect(upgrader.Upgrade()).To(Succeed())
+			Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(2))
+			Expect(testLogger.Buffer()).To(gbytes.Say("upgrade-version-stamp-retrying"))
+		})
+
+		It("logs an error but does not fail the upgrade when every attempt fails", func() {
+			fakeOs.WriteStringToFileAtomicallyReturns(errors.New("read-only file system"))
+
+			Expect(upgrader.Upgrade()).To(Succeed())
+			Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(3))
+			Expect(testLogger.Buffer()).To(gbytes.Say("upgrade-version-stamp-failed"))
+		})
+
+		It("does not stamp the version when the upgrade fails", func() {
+			fakeDbHelper.UpgradeReturns("fatal error", errors.New("exit status 1"))
+
+			Expect(upgrader.Upgrade()).NotTo(Succeed())
+			Expect(fakeOs.WriteStringToFileAtomicallyCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("UpgradeRunningNode", func() {
+		It("runs the upgrade scripts without starting or stopping mysqld", func() {
+			upgrader = NewUpgrader(
+				fakeOs,
+				config.Upgrader{
+					UpgradeMaxRetries: 2,
+					PreUpgradeScript:  "/pre-upgrade",
+					PostUpgradeScript: "/post-upgrade",
+				},
+				testLogger,
+				fakeDbHelper,
+			)
+
+			Expect(upgrader.UpgradeRunningNode()).To(Succeed())
+			Expect(fakeOs.RunCommandCallCount()).To(Equal(2))
+			Expect(fakeDbHelper.UpgradeCallCount()).To(Equal(1))
+			Expect(fakeDbHelper.StartMysqldForUpgradeCallCount()).To(Equal(0))
+			Expect(fakeDbHelper.StopMysqldCallCount()).To(Equal(0))
+		})
+
+		It("returns the upgrade error", func() {
+			fakeDbHelper.UpgradeReturns("fatal error", errors.New("exit status 1"))
+
+			Expect(upgrader.UpgradeRunningNode()).To(MatchError("exit status 1"))
+		})
+	})
+
+	Describe("UpgradesRunningNode", func() {
+		It("is false by default", func() {
+			Expect(upgrader.UpgradesRunningNode()).To(BeFalse())
+		})
+
+		It("is true when Timing is post-start", func() {
+			upgrader = NewUpgrader(fakeOs, config.Upgrader{Timing: config.UpgradeTimingPostStart}, testLogger, fakeDbHelper)
+			Expect(upgrader.UpgradesRunningNode()).To(BeTrue())
+		})
+	})
+
 	Describe("NeedsUpgrade", func() {
 		Context("when the last upgraded version file in the MySQL datadir does not exist", func() {
 			It("requires upgrade", func() {
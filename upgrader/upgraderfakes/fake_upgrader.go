@@ -30,6 +30,26 @@ type FakeUpgrader struct {
 	upgradeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UpgradeRunningNodeStub        func() error
+	upgradeRunningNodeMutex       sync.RWMutex
+	upgradeRunningNodeArgsForCall []struct {
+	}
+	upgradeRunningNodeReturns struct {
+		result1 error
+	}
+	upgradeRunningNodeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpgradesRunningNodeStub        func() bool
+	upgradesRunningNodeMutex       sync.RWMutex
+	upgradesRunningNodeArgsForCall []struct {
+	}
+	upgradesRunningNodeReturns struct {
+		result1 bool
+	}
+	upgradesRunningNodeReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -141,6 +161,110 @@ func (fake *FakeUpgrader) UpgradeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeUpgrader) UpgradeRunningNode() error {
+	fake.upgradeRunningNodeMutex.Lock()
+	ret, specificReturn := fake.upgradeRunningNodeReturnsOnCall[len(fake.upgradeRunningNodeArgsForCall)]
+	fake.upgradeRunningNodeArgsForCall = append(fake.upgradeRunningNodeArgsForCall, struct {
+	}{})
+	fake.recordInvocation("UpgradeRunningNode", []interface{}{})
+	fake.upgradeRunningNodeMutex.Unlock()
+	if fake.UpgradeRunningNodeStub != nil {
+		return fake.UpgradeRunningNodeStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.upgradeRunningNodeReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeUpgrader) UpgradeRunningNodeCallCount() int {
+	fake.upgradeRunningNodeMutex.RLock()
+	defer fake.upgradeRunningNodeMutex.RUnlock()
+	return len(fake.upgradeRunningNodeArgsForCall)
+}
+
+func (fake *FakeUpgrader) UpgradeRunningNodeCalls(stub func() error) {
+	fake.upgradeRunningNodeMutex.Lock()
+	defer fake.upgradeRunningNodeMutex.Unlock()
+	fake.UpgradeRunningNodeStub = stub
+}
+
+func (fake *FakeUpgrader) UpgradeRunningNodeReturns(result1 error) {
+	fake.upgradeRunningNodeMutex.Lock()
+	defer fake.upgradeRunningNodeMutex.Unlock()
+	fake.UpgradeRunningNodeStub = nil
+	fake.upgradeRunningNodeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUpgrader) UpgradeRunningNodeReturnsOnCall(i int, result1 error) {
+	fake.upgradeRunningNodeMutex.Lock()
+	defer fake.upgradeRunningNodeMutex.Unlock()
+	fake.UpgradeRunningNodeStub = nil
+	if fake.upgradeRunningNodeReturnsOnCall == nil {
+		fake.upgradeRunningNodeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.upgradeRunningNodeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUpgrader) UpgradesRunningNode() bool {
+	fake.upgradesRunningNodeMutex.Lock()
+	ret, specificReturn := fake.upgradesRunningNodeReturnsOnCall[len(fake.upgradesRunningNodeArgsForCall)]
+	fake.upgradesRunningNodeArgsForCall = append(fake.upgradesRunningNodeArgsForCall, struct {
+	}{})
+	fake.recordInvocation("UpgradesRunningNode", []interface{}{})
+	fake.upgradesRunningNodeMutex.Unlock()
+	if fake.UpgradesRunningNodeStub != nil {
+		return fake.UpgradesRunningNodeStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.upgradesRunningNodeReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeUpgrader) UpgradesRunningNodeCallCount() int {
+	fake.upgradesRunningNodeMutex.RLock()
+	defer fake.upgradesRunningNodeMutex.RUnlock()
+	return len(fake.upgradesRunningNodeArgsForCall)
+}
+
+func (fake *FakeUpgrader) UpgradesRunningNodeCalls(stub func() bool) {
+	fake.upgradesRunningNodeMutex.Lock()
+	defer fake.upgradesRunningNodeMutex.Unlock()
+	fake.UpgradesRunningNodeStub = stub
+}
+
+func (fake *FakeUpgrader) UpgradesRunningNodeReturns(result1 bool) {
+	fake.upgradesRunningNodeMutex.Lock()
+	defer fake.upgradesRunningNodeMutex.Unlock()
+	fake.UpgradesRunningNodeStub = nil
+	fake.upgradesRunningNodeReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeUpgrader) UpgradesRunningNodeReturnsOnCall(i int, result1 bool) {
+	fake.upgradesRunningNodeMutex.Lock()
+	defer fake.upgradesRunningNodeMutex.Unlock()
+	fake.UpgradesRunningNodeStub = nil
+	if fake.upgradesRunningNodeReturnsOnCall == nil {
+		fake.upgradesRunningNodeReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.upgradesRunningNodeReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeUpgrader) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -148,6 +272,10 @@ func (fake *FakeUpgrader) Invocations() map[string][][]interface{} {
 	defer fake.needsUpgradeMutex.RUnlock()
 	fake.upgradeMutex.RLock()
 	defer fake.upgradeMutex.RUnlock()
+	fake.upgradeRunningNodeMutex.RLock()
+	defer fake.upgradeRunningNodeMutex.RUnlock()
+	fake.upgradesRunningNodeMutex.RLock()
+	defer fake.upgradesRunningNodeMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,66 @@
+package user_reconciler
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+
+	"github.com/cloudfoundry/galera-init/db_helper"
+)
+
+// Reconciler periodically re-applies the configured users and grants, so
+// that manual changes or a partially failed seed converge back to the
+// configuration without restarting the node.
+type Reconciler struct {
+	dbHelper db_helper.DBHelper
+	interval time.Duration
+	logger   lager.Logger
+}
+
+func NewReconciler(dbHelper db_helper.DBHelper, interval time.Duration, logger lager.Logger) *Reconciler {
+	return &Reconciler{
+		dbHelper: dbHelper,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run reconciles the users every interval until ctx is cancelled.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reconcile()
+		}
+	}
+}
+
+// Reconcile only writes while the node is synced, since writes are rejected
+// outside a primary component and would otherwise only produce errors.
+func (r *Reconciler) Reconcile() {
+	status, err := r.dbHelper.GetWsrepStatus()
+	if err != nil {
+		r.logger.Debug("user-reconciliation-skipped", lager.Data{"err": err.Error()})
+		return
+	}
+	if status.LocalStateComment != "Synced" {
+		r.logger.Debug("user-reconciliation-skipped", lager.Data{"localState": status.LocalStateComment})
+		return
+	}
+
+	corrected, err := r.dbHelper.ReconcileUsers()
+	if err != nil {
+		r.logger.Error("user-reconciliation-failed", err)
+		return
+	}
+
+	if len(corrected) > 0 {
+		r.logger.Info("users-reconciled", lager.Data{"corrected": corrected})
+	}
+}
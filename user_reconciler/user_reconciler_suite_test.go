@@ -0,0 +1,14 @@
+package user_reconciler_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestUserReconciler(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "User Reconciler Suite")
+}
@@ -0,0 +1,73 @@
+package user_reconciler_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+
+	"github.com/cloudfoundry/galera-init/db_helper"
+	"github.com/cloudfoundry/galera-init/db_helper/db_helperfakes"
+	"github.com/cloudfoundry/galera-init/user_reconciler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Reconciler", func() {
+	var (
+		reconciler   *user_reconciler.Reconciler
+		fakeDBHelper *db_helperfakes.FakeDBHelper
+		testLogger   *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		fakeDBHelper = new(db_helperfakes.FakeDBHelper)
+		fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{LocalStateComment: "Synced"}, nil)
+		testLogger = lagertest.NewTestLogger("user_reconciler")
+
+		reconciler = user_reconciler.NewReconciler(fakeDBHelper, time.Minute, testLogger)
+	})
+
+	It("re-applies the users without logging when nothing had drifted", func() {
+		reconciler.Reconcile()
+
+		Expect(fakeDBHelper.ReconcileUsersCallCount()).To(Equal(1))
+		Expect(testLogger.LogMessages()).To(BeEmpty())
+	})
+
+	It("logs the accounts that were corrected", func() {
+		fakeDBHelper.ReconcileUsersReturns([]string{"`app`@`%`"}, nil)
+
+		reconciler.Reconcile()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say("users-reconciled"))
+		Expect(testLogger.Buffer()).To(gbytes.Say("app"))
+	})
+
+	It("logs an error when re-applying the users fails", func() {
+		fakeDBHelper.ReconcileUsersReturns(nil, errors.New("grant failed"))
+
+		reconciler.Reconcile()
+
+		Expect(testLogger.Buffer()).To(gbytes.Say("user-reconciliation-failed"))
+		Expect(testLogger.Buffer()).To(gbytes.Say("grant failed"))
+	})
+
+	It("does nothing while the node is not synced", func() {
+		fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{LocalStateComment: "Joining: receiving State Transfer"}, nil)
+
+		reconciler.Reconcile()
+
+		Expect(fakeDBHelper.ReconcileUsersCallCount()).To(Equal(0))
+	})
+
+	It("does nothing while mysqld is unreachable", func() {
+		fakeDBHelper.GetWsrepStatusReturns(db_helper.WsrepStatus{}, errors.New("connection refused"))
+
+		reconciler.Reconcile()
+
+		Expect(fakeDBHelper.ReconcileUsersCallCount()).To(Equal(0))
+	})
+})